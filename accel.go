@@ -0,0 +1,499 @@
+package mframe
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IndexKind identifies an acceleration structure built on top of the indexes of a key.
+type IndexKind int
+
+const (
+	// SortedNumericIndex keeps the unique values of a numeric key in a sorted tree, so range operators
+	// seek the bounds of the range instead of scanning every value.
+	SortedNumericIndex IndexKind = 1
+	// LowercaseIndex maps the lower case form of the values of a string key to the values,
+	// so case-insensitive Equals does not scan every value.
+	LowercaseIndex IndexKind = 2
+	// PrefixIndex keeps the unique values of a string key in a sorted tree, so StartsWith seeks the values
+	// holding the prefix instead of scanning every value, and NotStartsWith skips them without comparing
+	// the others. Case-insensitive queries are not served.
+	PrefixIndex IndexKind = 3
+	// SortedTimeIndex keeps the unique values of a time key in a sorted tree, so Between and NotBetween
+	// seek the bounds of the range instead of scanning every value.
+	SortedTimeIndex IndexKind = 4
+	// TrigramIndex maps the trigrams of the values of a string key to the values, so Contains, NotContains
+	// and regular expressions requiring literal substrings only check the values sharing their trigrams.
+	// It is never built by adaptive index creation, since it uses several times the memory of the values.
+	TrigramIndex IndexKind = 5
+	// CompositeIndex maps the combined values of several keys to the rows holding them, so a FilterSpec with
+	// case-sensitive Equals conditions on every key of the structure reads its rows with a single lookup
+	// instead of intersecting the rows of each condition. It is created with CreateCompositeIndex.
+	CompositeIndex IndexKind = 6
+)
+
+// String returns the name of the index kind.
+func (k IndexKind) String() string {
+	switch k {
+	case SortedNumericIndex:
+		return "SortedNumeric"
+	case LowercaseIndex:
+		return "Lowercase"
+	case PrefixIndex:
+		return "Prefix"
+	case SortedTimeIndex:
+		return "SortedTime"
+	case TrigramIndex:
+		return "Trigram"
+	case CompositeIndex:
+		return "Composite"
+	default:
+		return "Unknown"
+	}
+}
+
+// accelerators holds the acceleration structures of the DataFrame. They are maintained as unique
+// values are added to and removed from the indexes, under the lock of the DataFrame.
+type accelerators struct {
+	sortedNumerics map[KeyName]*orderedSet[float64]
+	lowercase      map[KeyName]map[string]map[string]bool
+	sortedStrings  map[KeyName]*orderedSet[string]
+	sortedTimes    map[KeyName]*orderedSet[time.Time]
+	trigrams       map[KeyName]trigramIndex
+	composites     map[KeyName]*compositeIndex
+}
+
+// keyType returns the type of the keys the structure of the given kind applies to.
+func (k IndexKind) keyType() (KeyType, bool) {
+	switch k {
+	case SortedNumericIndex:
+		return Numeric, true
+	case LowercaseIndex, PrefixIndex, TrigramIndex:
+		return String, true
+	case SortedTimeIndex:
+		return Time, true
+	default:
+		return 0, false
+	}
+}
+
+// CreateIndex builds the acceleration structure of the given kind for the key, so the filters it serves
+// skip scanning every value from the first query instead of waiting for adaptive index creation.
+// The structure is maintained by inserts and removals from then on. The key does not need to exist yet.
+// Returns an error if the kind is unknown or the key is mapped to a type the kind does not apply to.
+func (d *DataFrame) CreateIndex(key KeyName, kind IndexKind) error {
+	if kind == CompositeIndex {
+		return fmt.Errorf("%s indexes span several keys, use CreateCompositeIndex", kind)
+	}
+	keyType, ok := kind.keyType()
+	if !ok {
+		return fmt.Errorf("unknown index kind %d", kind)
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	if mapped, exists := d.Keys[key]; exists && mapped != keyType {
+		return fmt.Errorf("%s index requires a %s key, but key '%s' is %s", kind, keyTypeToString(keyType), key, keyTypeToString(mapped))
+	}
+
+	if !d.accel.has(key, kind) {
+		d.buildAcceleratorUnlocked(key, kind)
+	}
+
+	return nil
+}
+
+// DropIndex removes the acceleration structure of the given kind for the key, if it exists,
+// whether it was created with CreateIndex or by adaptive index creation. Composite indexes are
+// named by CompositeKey.
+func (d *DataFrame) DropIndex(key KeyName, kind IndexKind) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.dropAcceleratorUnlocked(key, kind)
+}
+
+// dropAcceleratorUnlocked removes the structure of the given kind for the key, without acquiring locks.
+func (d *DataFrame) dropAcceleratorUnlocked(key KeyName, kind IndexKind) {
+	a := &d.accel
+	switch kind {
+	case SortedNumericIndex:
+		delete(a.sortedNumerics, key)
+	case LowercaseIndex:
+		delete(a.lowercase, key)
+	case PrefixIndex:
+		delete(a.sortedStrings, key)
+	case SortedTimeIndex:
+		delete(a.sortedTimes, key)
+	case TrigramIndex:
+		delete(a.trigrams, key)
+	case CompositeIndex:
+		delete(a.composites, key)
+	}
+}
+
+// has reports whether the structure of the given kind exists for the key.
+func (a *accelerators) has(key KeyName, kind IndexKind) bool {
+	switch kind {
+	case SortedNumericIndex:
+		_, ok := a.sortedNumerics[key]
+		return ok
+	case LowercaseIndex:
+		_, ok := a.lowercase[key]
+		return ok
+	case PrefixIndex:
+		_, ok := a.sortedStrings[key]
+		return ok
+	case SortedTimeIndex:
+		_, ok := a.sortedTimes[key]
+		return ok
+	case TrigramIndex:
+		_, ok := a.trigrams[key]
+		return ok
+	case CompositeIndex:
+		_, ok := a.composites[key]
+		return ok
+	default:
+		return false
+	}
+}
+
+// buildAcceleratorUnlocked builds the structure of the given kind for the key from the indexes, without acquiring locks.
+func (d *DataFrame) buildAcceleratorUnlocked(key KeyName, kind IndexKind) {
+	a := &d.accel
+	switch kind {
+	case SortedNumericIndex:
+		values := newOrderedSet(cmp.Compare[float64])
+		for value := range d.Numerics[key] {
+			values.add(value)
+		}
+		if a.sortedNumerics == nil {
+			a.sortedNumerics = make(map[KeyName]*orderedSet[float64])
+		}
+		a.sortedNumerics[key] = values
+	case LowercaseIndex:
+		lower := make(map[string]map[string]bool, len(d.Strings[key]))
+		for value := range d.Strings[key] {
+			addLowercase(lower, value)
+		}
+		if a.lowercase == nil {
+			a.lowercase = make(map[KeyName]map[string]map[string]bool)
+		}
+		a.lowercase[key] = lower
+	case PrefixIndex:
+		values := newOrderedSet(strings.Compare)
+		for value := range d.Strings[key] {
+			values.add(value)
+		}
+		if a.sortedStrings == nil {
+			a.sortedStrings = make(map[KeyName]*orderedSet[string])
+		}
+		a.sortedStrings[key] = values
+	case SortedTimeIndex:
+		values := newOrderedSet(compareTimes)
+		for value := range d.Times[key] {
+			values.add(value)
+		}
+		if a.sortedTimes == nil {
+			a.sortedTimes = make(map[KeyName]*orderedSet[time.Time])
+		}
+		a.sortedTimes[key] = values
+	case TrigramIndex:
+		index := make(trigramIndex)
+		for value := range d.Strings[key] {
+			index.add(value)
+		}
+		if a.trigrams == nil {
+			a.trigrams = make(map[KeyName]trigramIndex)
+		}
+		a.trigrams[key] = index
+	}
+}
+
+// sizeOf estimates the memory used by the structure of the given kind for the key in bytes.
+func (a *accelerators) sizeOf(key KeyName, kind IndexKind) int {
+	switch kind {
+	case SortedNumericIndex:
+		if values, ok := a.sortedNumerics[key]; ok {
+			return values.size(8)
+		}
+		return 0
+	case LowercaseIndex:
+		size := 48
+		for lower, values := range a.lowercase[key] {
+			size += 64 + len(lower)
+			for value := range values {
+				size += 24 + len(value)
+			}
+		}
+		return size
+	case PrefixIndex:
+		values, ok := a.sortedStrings[key]
+		if !ok {
+			return 0
+		}
+		size := values.size(16)
+		values.walk(values.first(), nil, func(value string) bool {
+			size += len(value)
+			return true
+		})
+		return size
+	case SortedTimeIndex:
+		if values, ok := a.sortedTimes[key]; ok {
+			return values.size(24)
+		}
+		return 0
+	case TrigramIndex:
+		size := 48
+		for gram, values := range a.trigrams[key] {
+			size += 64 + len(gram)
+			for value := range values {
+				size += 24 + len(value)
+			}
+		}
+		return size
+	case CompositeIndex:
+		if composite, ok := a.composites[key]; ok {
+			return composite.size()
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+// onNewValue updates the acceleration structures of the key when a unique value is added to its index.
+func (d *DataFrame) onNewValue(key KeyName, value interface{}) {
+	a := &d.accel
+	switch v := value.(type) {
+	case float64:
+		if values, ok := a.sortedNumerics[key]; ok {
+			values.add(v)
+		}
+	case string:
+		if lower, ok := a.lowercase[key]; ok {
+			addLowercase(lower, v)
+		}
+		if values, ok := a.sortedStrings[key]; ok {
+			values.add(v)
+		}
+		if index, ok := a.trigrams[key]; ok {
+			index.add(v)
+		}
+	case time.Time:
+		if values, ok := a.sortedTimes[key]; ok {
+			values.add(v)
+		}
+	}
+}
+
+// onValueGone updates the acceleration structures of the key when a unique value is removed from its index.
+func (d *DataFrame) onValueGone(key KeyName, value interface{}) {
+	a := &d.accel
+	switch v := value.(type) {
+	case float64:
+		if values, ok := a.sortedNumerics[key]; ok {
+			values.remove(v)
+		}
+	case string:
+		if lower, ok := a.lowercase[key]; ok {
+			l := strings.ToLower(v)
+			delete(lower[l], v)
+			if len(lower[l]) == 0 {
+				delete(lower, l)
+			}
+		}
+		if values, ok := a.sortedStrings[key]; ok {
+			values.remove(v)
+		}
+		if index, ok := a.trigrams[key]; ok {
+			index.remove(v)
+		}
+	case time.Time:
+		if values, ok := a.sortedTimes[key]; ok {
+			values.remove(v)
+		}
+	}
+}
+
+// matchAcceleratedUnlocked walks the rows of a single key matching the filter through an acceleration
+// structure, calling visit like matchUnlocked. Returns handled as false when no structure applies, and
+// stopped as true when visit stopped the walk.
+func (d *DataFrame) matchAcceleratedUnlocked(poll *scanPoll, key KeyName, keyType KeyType, operator Operator, value any, options map[FilterOption]bool, visit func(id uuid.UUID) bool) (handled bool, stopped bool) {
+	a := &d.accel
+	switch keyType {
+	case Numeric:
+		values, ok := a.sortedNumerics[key]
+		if !ok {
+			return false, false
+		}
+
+		var from, to *orderedNode[float64]
+		switch operator {
+		case Major, Minor, MajorEquals, MinorEquals:
+			floatValue, ok := value.(float64)
+			if !ok {
+				return false, false
+			}
+			switch operator {
+			case Major:
+				from = values.search(func(v float64) bool { return v > floatValue })
+			case MajorEquals:
+				from = values.search(func(v float64) bool { return v >= floatValue })
+			case Minor:
+				from, to = values.first(), values.search(func(v float64) bool { return v >= floatValue })
+			case MinorEquals:
+				from, to = values.first(), values.search(func(v float64) bool { return v > floatValue })
+			}
+		case Between:
+			rangeValues, ok := value.([]float64)
+			if !ok || len(rangeValues) != 2 {
+				return false, false
+			}
+			min, max := rangeValues[0], rangeValues[1]
+			if min > max {
+				min, max = max, min
+			}
+			from = values.search(func(v float64) bool { return v >= min })
+			to = values.search(func(v float64) bool { return v > max })
+		default:
+			return false, false
+		}
+
+		stopped := !values.walk(from, to, func(v float64) bool {
+			if poll.interrupted() {
+				return false
+			}
+			for id := range d.Numerics[key][v] {
+				if !visit(id) {
+					return false
+				}
+			}
+			return true
+		})
+		return true, stopped
+	case String:
+		stringValue, ok := value.(string)
+		if !ok {
+			return false, false
+		}
+		sensitive, set := options[CaseSensitive]
+		insensitive := set && !sensitive
+
+		switch {
+		case operator == Equals && insensitive:
+			lower, ok := a.lowercase[key]
+			if !ok {
+				return false, false
+			}
+			for original := range lower[strings.ToLower(stringValue)] {
+				for id := range d.Strings[key][original] {
+					if !visit(id) {
+						return true, true
+					}
+				}
+			}
+			return true, false
+		case (operator == StartsWith || operator == NotStartsWith) && !insensitive:
+			values, ok := a.sortedStrings[key]
+			if !ok {
+				return false, false
+			}
+			from, to := prefixRange(values, stringValue)
+
+			ranges := [][2]*orderedNode[string]{{from, to}}
+			if operator == NotStartsWith {
+				ranges = [][2]*orderedNode[string]{{values.first(), from}, {to, nil}}
+			}
+			for _, r := range ranges {
+				stopped := !values.walk(r[0], r[1], func(v string) bool {
+					if poll.interrupted() {
+						return false
+					}
+					for id := range d.Strings[key][v] {
+						if !visit(id) {
+							return false
+						}
+					}
+					return true
+				})
+				if stopped {
+					return true, true
+				}
+			}
+			return true, false
+		case operator == Contains || operator == NotContains || operator == RegExp:
+			return d.matchTrigramUnlocked(poll, key, operator, stringValue, options, visit)
+		}
+	case Time:
+		values, ok := a.sortedTimes[key]
+		if !ok || (operator != Between && operator != NotBetween) {
+			return false, false
+		}
+		timeValues, ok := value.([]time.Time)
+		if !ok || len(timeValues) != 2 {
+			return false, false
+		}
+		startTime, endTime := timeValues[0], timeValues[1]
+		if startTime.After(endTime) {
+			startTime, endTime = endTime, startTime
+		}
+
+		from := values.search(func(v time.Time) bool { return !v.Before(startTime) })
+		to := values.search(func(v time.Time) bool { return v.After(endTime) })
+
+		ranges := [][2]*orderedNode[time.Time]{{from, to}}
+		if operator == NotBetween {
+			ranges = [][2]*orderedNode[time.Time]{{values.first(), from}, {to, nil}}
+		}
+		for _, r := range ranges {
+			stopped := !values.walk(r[0], r[1], func(v time.Time) bool {
+				if poll.interrupted() {
+					return false
+				}
+				for id := range d.Times[key][v] {
+					if !visit(id) {
+						return false
+					}
+				}
+				return true
+			})
+			if stopped {
+				return true, true
+			}
+		}
+		return true, false
+	}
+
+	return false, false
+}
+
+// prefixRange returns the bounds of the sorted values starting with prefix, which are contiguous: the first
+// node holding the prefix and the first node after them.
+func prefixRange(values *orderedSet[string], prefix string) (from, to *orderedNode[string]) {
+	from = values.search(func(v string) bool { return v >= prefix })
+	to = values.search(func(v string) bool { return v >= prefix && !strings.HasPrefix(v, prefix) })
+	return from, to
+}
+
+// addLowercase adds a value to a lower case map.
+func addLowercase(lower map[string]map[string]bool, value string) {
+	l := strings.ToLower(value)
+	if lower[l] == nil {
+		lower[l] = make(map[string]bool)
+	}
+	lower[l][value] = true
+}
+
+// compareTimes orders times by instant, and times of the same instant by the name of their location.
+func compareTimes(a, b time.Time) int {
+	if c := a.Compare(b); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Location().String(), b.Location().String())
+}
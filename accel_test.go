@@ -0,0 +1,230 @@
+package mframe_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSortedTimeIndex(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	if err := cache.CreateIndex("seen", mframe.SortedTimeIndex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"seen": base.Add(time.Duration(9-i) * time.Hour)})
+	}
+
+	tests := []struct {
+		operator mframe.Operator
+		from, to time.Time
+		expected int
+	}{
+		{mframe.Between, base.Add(2 * time.Hour), base.Add(5 * time.Hour), 4},
+		{mframe.Between, base.Add(5 * time.Hour), base.Add(2 * time.Hour), 4},
+		{mframe.Between, base.Add(-time.Hour), base.Add(30 * time.Minute), 1},
+		{mframe.NotBetween, base.Add(2 * time.Hour), base.Add(5 * time.Hour), 6},
+		{mframe.Between, base.Add(20 * time.Hour), base.Add(30 * time.Hour), 0},
+	}
+	for _, test := range tests {
+		if n := cache.Filter(test.operator, "seen", []time.Time{test.from, test.to}, nil).Count(); n != test.expected {
+			t.Errorf("expected %d rows for %v, but got %d", test.expected, test, n)
+		}
+	}
+
+	cache.DeleteWhere(mframe.Between, "seen", []time.Time{base, base.Add(4 * time.Hour)}, nil)
+	if n := cache.Filter(mframe.Between, "seen", []time.Time{base, base.Add(5 * time.Hour)}, nil).Count(); n != 1 {
+		t.Errorf("expected 1 row after removing a range, but got %d", n)
+	}
+
+	explain := cache.Explain(mframe.Between, "seen", []time.Time{base, base.Add(time.Hour)})
+	if !strings.Contains(strings.Join(explain.Details, "\n"), "SortedTime index") {
+		t.Errorf("expected the sorted time index in %v", explain.Details)
+	}
+
+	cache.DropIndex("seen", mframe.SortedTimeIndex)
+	if n := cache.Filter(mframe.Between, "seen", []time.Time{base, base.Add(9 * time.Hour)}, nil).Count(); n != 5 {
+		t.Errorf("expected 5 rows without the index, but got %d", n)
+	}
+}
+
+func TestCreateIndexErrors(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+
+	if err := cache.CreateIndex("host", mframe.SortedTimeIndex); err == nil {
+		t.Errorf("expected an error for a string key")
+	}
+	if err := cache.CreateIndex("host", mframe.IndexKind(99)); err == nil {
+		t.Errorf("expected an error for an unknown kind")
+	}
+	if err := cache.CreateIndex("host", mframe.PrefixIndex); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPrefixIndex(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	if err := cache.CreateIndex("url", mframe.PrefixIndex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, url := range []string{"https://a.com/x", "https://a.com/y", "https://b.com/", "http://a.com/", "ftp://a.com"} {
+		cache.Insert(map[mframe.KeyName]interface{}{"url": url})
+	}
+
+	tests := []struct {
+		operator mframe.Operator
+		prefix   string
+		options  map[mframe.FilterOption]bool
+		expected int
+	}{
+		{mframe.StartsWith, "https://a.com/", nil, 2},
+		{mframe.StartsWith, "http", nil, 4},
+		{mframe.StartsWith, "zzz", nil, 0},
+		{mframe.NotStartsWith, "https://", nil, 2},
+		{mframe.NotStartsWith, "", nil, 0},
+		{mframe.NotStartsWith, "HTTPS://", map[mframe.FilterOption]bool{mframe.CaseSensitive: false}, 2},
+	}
+	for _, test := range tests {
+		if n := cache.Filter(test.operator, "url", test.prefix, test.options).Count(); n != test.expected {
+			t.Errorf("expected %d rows for %v, but got %d", test.expected, test, n)
+		}
+	}
+}
+
+func TestTrigramIndex(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	messages := []string{"Failed password for root", "Accepted password for admin", "connection closed", "FAILED login", "ok"}
+	for _, message := range messages {
+		cache.Insert(map[mframe.KeyName]interface{}{"message": message})
+	}
+	if err := cache.CreateIndex("message", mframe.TrigramIndex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache.Insert(map[mframe.KeyName]interface{}{"message": "password reset"})
+
+	insensitive := map[mframe.FilterOption]bool{mframe.CaseSensitive: false}
+	tests := []struct {
+		operator mframe.Operator
+		value    string
+		options  map[mframe.FilterOption]bool
+		expected int
+	}{
+		{mframe.Contains, "password", nil, 3},
+		{mframe.Contains, "password for", nil, 2},
+		{mframe.Contains, "failed", nil, 0},
+		{mframe.Contains, "failed", insensitive, 2},
+		{mframe.Contains, "ok", nil, 1},
+		{mframe.Contains, "missing", nil, 0},
+		{mframe.NotContains, "password", nil, 3},
+		{mframe.NotContains, "FAILED", insensitive, 4},
+		{mframe.RegExp, "^(Failed|Accepted) password", nil, 2},
+		{mframe.RegExp, "pass(word)+ for (root|admin)$", nil, 2},
+		{mframe.RegExp, "(?i)failed", nil, 2},
+		{mframe.RegExp, "^.{2}$", nil, 1},
+	}
+	for _, test := range tests {
+		if n := cache.Filter(test.operator, "message", test.value, test.options).Count(); n != test.expected {
+			t.Errorf("expected %d rows for %v, but got %d", test.expected, test, n)
+		}
+	}
+
+	cache.DeleteWhere(mframe.Contains, "message", "reset", nil)
+	if n := cache.Filter(mframe.Contains, "message", "password", nil).Count(); n != 2 {
+		t.Errorf("expected 2 rows after removing one, but got %d", n)
+	}
+
+	explain := cache.Explain(mframe.Contains, "message", "password")
+	if !strings.Contains(strings.Join(explain.Details, "\n"), "Trigram index") {
+		t.Errorf("expected the trigram index in %v", explain.Details)
+	}
+}
+
+func TestSortedIndexesUnderChurn(t *testing.T) {
+	var plain, indexed mframe.DataFrame
+	plain.Init(24 * time.Hour)
+	indexed.Init(24 * time.Hour)
+	indexed.CreateIndex("score", mframe.SortedNumericIndex)
+	indexed.CreateIndex("seen", mframe.SortedTimeIndex)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		paris = time.FixedZone("CET", 3600)
+	}
+
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 200; i++ {
+			seen := base.Add(time.Duration((i*37+round*11)%500) * time.Minute)
+			if i%3 == 0 {
+				seen = seen.In(paris)
+			}
+			row := map[mframe.KeyName]interface{}{"score": float64((i*7 + round) % 97), "seen": seen}
+			plain.Insert(row)
+			indexed.Insert(row)
+		}
+
+		for _, frame := range []*mframe.DataFrame{&plain, &indexed} {
+			frame.DeleteWhere(mframe.Between, "score", []float64{float64(round * 10), float64(round*10 + 15)}, nil)
+		}
+
+		for _, score := range []float64{-1, 0, 13, 50, 96, 200} {
+			for _, operator := range []mframe.Operator{mframe.Greater, mframe.GreaterOrEqual, mframe.Less, mframe.LessOrEqual} {
+				expected := plain.Filter(operator, "score", score, nil).Count()
+				if got := indexed.Filter(operator, "score", score, nil).Count(); got != expected {
+					t.Errorf("round %d: expected %d rows for %v %v, but got %d", round, expected, operator, score, got)
+				}
+			}
+		}
+		for _, minutes := range [][2]int{{0, 60}, {100, 101}, {-10, 1000}, {499, 700}} {
+			bounds := []time.Time{base.Add(time.Duration(minutes[0]) * time.Minute), base.Add(time.Duration(minutes[1]) * time.Minute).In(paris)}
+			for _, operator := range []mframe.Operator{mframe.Between, mframe.NotBetween} {
+				expected := plain.Filter(operator, "seen", bounds, nil).Count()
+				if got := indexed.Filter(operator, "seen", bounds, nil).Count(); got != expected {
+					t.Errorf("round %d: expected %d rows for %v %v, but got %d", round, expected, operator, minutes, got)
+				}
+			}
+		}
+	}
+}
+
+func TestPrefixIndexUnderChurn(t *testing.T) {
+	var plain, indexed mframe.DataFrame
+	plain.Init(24 * time.Hour)
+	indexed.Init(24 * time.Hour)
+	indexed.CreateIndex("domain", mframe.PrefixIndex)
+
+	hosts := []string{"a", "ab", "abc", "b", "ba", "mail", "www", "wwx"}
+	for round := 0; round < 5; round++ {
+		for i := 0; i < 100; i++ {
+			row := map[mframe.KeyName]interface{}{"domain": fmt.Sprintf("%s.example-%d.com", hosts[(i+round)%len(hosts)], i%13)}
+			plain.Insert(row)
+			indexed.Insert(row)
+		}
+
+		for _, frame := range []*mframe.DataFrame{&plain, &indexed} {
+			frame.DeleteWhere(mframe.StartsWith, "domain", hosts[round]+".", nil)
+		}
+
+		for _, prefix := range []string{"", "a", "ab", "abc.", "b", "c", "mail.example-1", "ww", "www.", "zz"} {
+			for _, operator := range []mframe.Operator{mframe.StartsWith, mframe.NotStartsWith} {
+				expected := plain.Filter(operator, "domain", prefix, nil).Count()
+				if got := indexed.Filter(operator, "domain", prefix, nil).Count(); got != expected {
+					t.Errorf("round %d: expected %d rows for %v %q, but got %d", round, expected, operator, prefix, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package mframe
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// AdaptiveOptions configures adaptive index creation.
+type AdaptiveOptions struct {
+	// MinQueries is the number of queries that would benefit from an acceleration structure after which
+	// the structure is built. Zero disables adaptive index creation.
+	MinQueries int
+}
+
+// AdaptiveIndex describes an acceleration structure built by adaptive index creation.
+type AdaptiveIndex struct {
+	// Key is the key of the structure, or the CompositeKey of the keys of a CompositeIndex.
+	Key  KeyName
+	Kind IndexKind
+	// Keys are the keys combined by a CompositeIndex, nil for the other kinds.
+	Keys []KeyName
+	// Queries is the number of queries of the pattern when the structure was built.
+	Queries int
+	BuiltAt time.Time
+	// Bytes is the estimated memory currently used by the structure.
+	Bytes int
+}
+
+// indexTarget identifies an acceleration structure of a key.
+type indexTarget struct {
+	key  KeyName
+	kind IndexKind
+}
+
+// adaptive tracks the query patterns of a DataFrame to decide which acceleration structures to build.
+type adaptive struct {
+	mutex   sync.Mutex
+	options AdaptiveOptions
+	counts  map[indexTarget]int
+	built   map[indexTarget]AdaptiveIndex
+}
+
+// SetAdaptiveIndexing enables adaptive index creation: the DataFrame counts the queries that would
+// benefit from an acceleration structure, by key and operator, and builds the structure once a pattern
+// has been queried options.MinQueries times. Sorted numeric structures serve range operators on numeric
+// keys, lowercase structures serve case-insensitive Equals, prefix structures serve StartsWith and NotStartsWith and sorted time
+// structures serve Between and NotBetween on time keys.
+func (d *DataFrame) SetAdaptiveIndexing(options AdaptiveOptions) {
+	d.adaptive.mutex.Lock()
+	defer d.adaptive.mutex.Unlock()
+	d.adaptive.options = options
+}
+
+// AdaptiveIndexes reports the acceleration structures built by adaptive index creation and their
+// estimated memory cost, ordered by key and kind.
+func (d *DataFrame) AdaptiveIndexes() []AdaptiveIndex {
+	d.adaptive.mutex.Lock()
+	result := make([]AdaptiveIndex, 0, len(d.adaptive.built))
+	for _, index := range d.adaptive.built {
+		result = append(result, index)
+	}
+	d.adaptive.mutex.Unlock()
+
+	d.Locker.RLock()
+	kept := result[:0]
+	for _, index := range result {
+		if !d.accel.has(index.Key, index.Kind) {
+			continue
+		}
+		index.Bytes = d.accel.sizeOf(index.Key, index.Kind)
+		kept = append(kept, index)
+	}
+	d.Locker.RUnlock()
+
+	sort.Slice(kept, func(i, j int) bool {
+		if kept[i].Key != kept[j].Key {
+			return kept[i].Key < kept[j].Key
+		}
+		return kept[i].Kind < kept[j].Kind
+	})
+
+	return kept
+}
+
+// recordQuery adds the query execution to the query history, counts the query towards its acceleration
+// structure and builds the structure when the pattern becomes hot. It must be called without holding
+// the lock of the DataFrame.
+func (d *DataFrame) recordQuery(query Condition, latency time.Duration, rows int) {
+	d.queryHistory.add(query, latency, rows)
+	d.metrics.observeFilter(latency)
+
+	d.adaptive.mutex.Lock()
+	minQueries := d.adaptive.options.MinQueries
+	d.adaptive.mutex.Unlock()
+	if minQueries <= 0 {
+		return
+	}
+
+	d.Locker.RLock()
+	target, ok := d.accelTargetUnlocked(query)
+	exists := ok && d.accel.has(target.key, target.kind)
+	d.Locker.RUnlock()
+	if !ok {
+		return
+	}
+
+	queries := d.adaptive.count(target)
+	if exists || queries < minQueries {
+		return
+	}
+
+	d.Locker.Lock()
+	if !d.accel.has(target.key, target.kind) {
+		d.buildAcceleratorUnlocked(target.key, target.kind)
+	}
+	d.Locker.Unlock()
+
+	d.adaptive.record(target, AdaptiveIndex{Key: target.key, Kind: target.kind, Queries: queries})
+}
+
+// recordSpec counts a FilterSpec towards the composite structure of the keys of its case-sensitive Equals
+// conditions and builds the structure when the combination becomes hot. It must be called without holding
+// the lock of the DataFrame.
+func (d *DataFrame) recordSpec(spec FilterSpec) {
+	if len(spec.Conditions) < 2 {
+		return
+	}
+
+	d.adaptive.mutex.Lock()
+	minQueries := d.adaptive.options.MinQueries
+	d.adaptive.mutex.Unlock()
+	if minQueries <= 0 {
+		return
+	}
+
+	d.Locker.RLock()
+	keys := d.compositeCandidateUnlocked(spec.Conditions)
+	target := indexTarget{key: CompositeKey(keys...), kind: CompositeIndex}
+	exists := d.accel.has(target.key, target.kind)
+	d.Locker.RUnlock()
+	if keys == nil {
+		return
+	}
+
+	queries := d.adaptive.count(target)
+	if exists || queries < minQueries {
+		return
+	}
+
+	d.Locker.Lock()
+	if !d.accel.has(target.key, target.kind) {
+		d.buildCompositeUnlocked(keys)
+	}
+	d.Locker.Unlock()
+
+	d.adaptive.record(target, AdaptiveIndex{Key: target.key, Kind: target.kind, Keys: keys, Queries: queries})
+}
+
+// count counts a query towards the structure and returns the number of queries counted so far.
+func (a *adaptive) count(target indexTarget) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.counts == nil {
+		a.counts = make(map[indexTarget]int)
+	}
+	a.counts[target]++
+	return a.counts[target]
+}
+
+// record adds a structure built by adaptive index creation to the report.
+func (a *adaptive) record(target indexTarget, index AdaptiveIndex) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.built == nil {
+		a.built = make(map[indexTarget]AdaptiveIndex)
+	}
+	index.BuiltAt = time.Now().UTC()
+	a.built[target] = index
+}
+
+// accelTargetUnlocked returns the acceleration structure that would serve the query, without acquiring locks.
+// Returns false for key patterns and for queries no structure serves.
+func (d *DataFrame) accelTargetUnlocked(query Condition) (indexTarget, bool) {
+	keyType, ok := d.Keys[query.Key]
+	if !ok {
+		return indexTarget{}, false
+	}
+
+	sensitive, set := query.Options[CaseSensitive]
+	insensitive := set && !sensitive
+
+	switch {
+	case keyType == Numeric && (query.Operator == Major || query.Operator == Minor ||
+		query.Operator == MajorEquals || query.Operator == MinorEquals || query.Operator == Between):
+		return indexTarget{key: query.Key, kind: SortedNumericIndex}, true
+	case keyType == String && query.Operator == Equals && insensitive:
+		return indexTarget{key: query.Key, kind: LowercaseIndex}, true
+	case keyType == String && (query.Operator == StartsWith || query.Operator == NotStartsWith) && !insensitive:
+		return indexTarget{key: query.Key, kind: PrefixIndex}, true
+	case keyType == Time && (query.Operator == Between || query.Operator == NotBetween):
+		return indexTarget{key: query.Key, kind: SortedTimeIndex}, true
+	default:
+		return indexTarget{}, false
+	}
+}
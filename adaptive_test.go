@@ -0,0 +1,89 @@
+package mframe_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestAdaptiveIndexing(t *testing.T) {
+	var plain, adaptive mframe.DataFrame
+	plain.Init(24 * time.Hour)
+	adaptive.Init(24 * time.Hour)
+	adaptive.SetAdaptiveIndexing(mframe.AdaptiveOptions{MinQueries: 2})
+
+	for i := 0; i < 50; i++ {
+		row := map[mframe.KeyName]interface{}{"score": i % 20, "host": fmt.Sprintf("Host-%d", i%7)}
+		plain.Insert(row)
+		adaptive.Insert(row)
+	}
+
+	insensitive := map[mframe.FilterOption]bool{mframe.CaseSensitive: false}
+
+	queries := []struct {
+		name     string
+		operator mframe.Operator
+		key      mframe.KeyName
+		value    any
+		options  map[mframe.FilterOption]bool
+	}{
+		{"greater", mframe.Greater, "score", 15.0, nil},
+		{"greater or equal", mframe.GreaterOrEqual, "score", 15.0, nil},
+		{"less", mframe.Less, "score", 3.0, nil},
+		{"less or equal", mframe.LessOrEqual, "score", 3.0, nil},
+		{"between", mframe.Between, "score", []float64{12, 5}, nil},
+		{"between outside", mframe.Between, "score", []float64{100, 200}, nil},
+		{"equals insensitive", mframe.Equals, "host", "host-3", insensitive},
+		{"starts with", mframe.StartsWith, "host", "Host-", nil},
+		{"starts with none", mframe.StartsWith, "host", "host", nil},
+	}
+
+	check := func(t *testing.T) {
+		for _, q := range queries {
+			for i := 0; i < 3; i++ {
+				expected := plain.Filter(q.operator, q.key, q.value, q.options).Count()
+				got := adaptive.Filter(q.operator, q.key, q.value, q.options).Count()
+				if got != expected {
+					t.Errorf("%s: expected %d rows, but got %d", q.name, expected, got)
+				}
+			}
+		}
+	}
+
+	check(t)
+
+	indexes := adaptive.AdaptiveIndexes()
+	if len(indexes) != 3 {
+		t.Fatalf("expected 3 adaptive indexes, but got %v", indexes)
+	}
+	expected := []struct {
+		key  mframe.KeyName
+		kind mframe.IndexKind
+	}{{"host", mframe.LowercaseIndex}, {"host", mframe.PrefixIndex}, {"score", mframe.SortedNumericIndex}}
+	for i, e := range expected {
+		if indexes[i].Key != e.key || indexes[i].Kind != e.kind {
+			t.Errorf("expected %s index on %s, but got %s on %s", e.kind, e.key, indexes[i].Kind, indexes[i].Key)
+		}
+		if indexes[i].Bytes <= 0 || indexes[i].Queries < 2 {
+			t.Errorf("expected a memory cost and query count, but got %+v", indexes[i])
+		}
+	}
+
+	t.Run("maintained", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			row := map[mframe.KeyName]interface{}{"score": 100 + i, "host": fmt.Sprintf("HOST-%d", i)}
+			plain.Insert(row)
+			adaptive.Insert(row)
+		}
+		for _, frame := range []*mframe.DataFrame{&plain, &adaptive} {
+			for id, row := range frame.Data {
+				if row["score"] == 4.0 || row["host"] == "Host-2" {
+					frame.RemoveElement(id)
+				}
+			}
+		}
+		check(t)
+	})
+}
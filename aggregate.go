@@ -0,0 +1,233 @@
+package mframe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/montanaflynn/stats"
+)
+
+// AggregateFunc computes a single value from the values found in a field.
+// Values are passed as stored in the rows, so a function may handle strings,
+// numbers, booleans or times as it sees fit.
+type AggregateFunc func(values []interface{}) (float64, error)
+
+var (
+	aggregatesMutex sync.RWMutex
+	aggregates      = map[string]AggregateFunc{
+		"count":    countAggregate,
+		"distinct": distinctAggregate,
+		"entropy":  entropyAggregate,
+		"sum":      numericAggregate(stats.Sum),
+		"average":  numericAggregate(stats.Mean),
+		"median":   numericAggregate(stats.Median),
+		"min":      numericAggregate(stats.Min),
+		"max":      numericAggregate(stats.Max),
+		"variance": numericAggregate(stats.Variance),
+		"stddev":   numericAggregate(stats.StandardDeviation),
+	}
+)
+
+// RegisterAggregate makes a custom aggregation available under the given name.
+// Returns an error if the name is empty, the function is nil or the name is already registered.
+func RegisterAggregate(name string, fn AggregateFunc) error {
+	if name == "" {
+		return fmt.Errorf("aggregate name cannot be empty")
+	}
+	if fn == nil {
+		return fmt.Errorf("aggregate '%s' cannot be nil", name)
+	}
+
+	aggregatesMutex.Lock()
+	defer aggregatesMutex.Unlock()
+
+	if _, exists := aggregates[name]; exists {
+		return fmt.Errorf("aggregate '%s' is already registered", name)
+	}
+
+	aggregates[name] = fn
+
+	return nil
+}
+
+// LookupAggregate returns the aggregation registered under the given name.
+func LookupAggregate(name string) (AggregateFunc, bool) {
+	aggregatesMutex.RLock()
+	defer aggregatesMutex.RUnlock()
+	fn, ok := aggregates[name]
+	return fn, ok
+}
+
+// AggregateField applies the aggregation registered under name to the values of the specified field.
+func (d *DataFrame) AggregateField(name string, field KeyName) (float64, error) {
+	fn, ok := LookupAggregate(name)
+	if !ok {
+		return 0, fmt.Errorf("unknown aggregate '%s'", name)
+	}
+
+	d.Locker.RLock()
+	values := d.sliceOfUnlocked(field)
+	d.Locker.RUnlock()
+
+	return fn(values)
+}
+
+// AggSpec selects the field to aggregate and the registered aggregations to compute over it.
+type AggSpec struct {
+	Field     KeyName
+	Functions []string
+}
+
+// Aggregate computes the aggregations in agg over the rows matching spec, reading the matching
+// IDs from the indexes instead of materializing a filtered DataFrame.
+// Returns the result of each aggregation keyed by its name.
+func (d *DataFrame) Aggregate(spec FilterSpec, agg AggSpec) (map[string]float64, error) {
+	functions, err := lookupAggregates(agg.Functions)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Locker.RLock()
+	ids := d.specIDsUnlocked(spec)
+	values := make([]interface{}, 0, len(ids))
+	for id := range ids {
+		if value, ok := d.Data[id][agg.Field]; ok {
+			values = append(values, value)
+		}
+	}
+	d.Locker.RUnlock()
+	d.recordSpec(spec)
+
+	return applyAggregates(functions, values)
+}
+
+// GroupResult holds the aggregations computed over the rows sharing the values of the group keys.
+type GroupResult struct {
+	// Group holds the value of each group key, nil for rows without the key.
+	Group  map[KeyName]interface{}
+	Rows   int
+	Values map[string]float64
+}
+
+// GroupBy splits the rows matching spec by the values of the group keys and computes the
+// aggregations in agg for each group. Groups are sorted by the values of their keys. Aggregations failing
+// for a group, such as the average of a group without numeric values, are left out of its Values.
+func (d *DataFrame) GroupBy(spec FilterSpec, keys []KeyName, agg AggSpec) ([]GroupResult, error) {
+	functions, err := lookupAggregates(agg.Functions)
+	if err != nil {
+		return nil, err
+	}
+
+	type group struct {
+		values []interface{}
+		result GroupResult
+	}
+
+	groups := make(map[string]*group)
+	d.Locker.RLock()
+	for id := range d.specIDsUnlocked(spec) {
+		row := d.Data[id]
+
+		values := make(map[KeyName]interface{}, len(keys))
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			value, _ := d.fieldUnlocked(row, key)
+			values[key] = value
+			parts[i] = fmt.Sprintf("%T:%v", value, value)
+		}
+		name := strings.Join(parts, "\x00")
+
+		g, ok := groups[name]
+		if !ok {
+			g = &group{result: GroupResult{Group: values}}
+			groups[name] = g
+		}
+		g.result.Rows++
+		if value, ok := d.fieldUnlocked(row, agg.Field); ok {
+			g.values = append(g.values, value)
+		}
+	}
+	d.Locker.RUnlock()
+	d.recordSpec(spec)
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]GroupResult, 0, len(groups))
+	for _, name := range names {
+		g := groups[name]
+		g.result.Values = make(map[string]float64, len(functions))
+		for function, fn := range functions {
+			if value, err := fn(g.values); err == nil {
+				g.result.Values[function] = value
+			}
+		}
+		result = append(result, g.result)
+	}
+
+	return result, nil
+}
+
+// lookupAggregates returns the aggregations registered under the given names.
+func lookupAggregates(names []string) (map[string]AggregateFunc, error) {
+	functions := make(map[string]AggregateFunc, len(names))
+	for _, name := range names {
+		fn, ok := LookupAggregate(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown aggregate '%s'", name)
+		}
+		functions[name] = fn
+	}
+	return functions, nil
+}
+
+// applyAggregates computes every aggregation over values, keyed by its name.
+func applyAggregates(functions map[string]AggregateFunc, values []interface{}) (map[string]float64, error) {
+	result := make(map[string]float64, len(functions))
+	for name, fn := range functions {
+		value, err := fn(values)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate '%s' failed: %w", name, err)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
+
+// numericAggregate adapts a function over float64 values into an AggregateFunc,
+// ignoring values that are not float64.
+func numericAggregate(fn func(stats.Float64Data) (float64, error)) AggregateFunc {
+	return func(values []interface{}) (float64, error) {
+		return fn(float64Values(values))
+	}
+}
+
+// countAggregate returns the number of values.
+func countAggregate(values []interface{}) (float64, error) {
+	return float64(len(values)), nil
+}
+
+// distinctAggregate returns the number of distinct values.
+func distinctAggregate(values []interface{}) (float64, error) {
+	seen := make(map[interface{}]struct{}, len(values))
+	for _, v := range values {
+		seen[v] = struct{}{}
+	}
+	return float64(len(seen)), nil
+}
+
+// float64Values extracts the float64 values from a slice of interface values.
+func float64Values(values []interface{}) []float64 {
+	result := make([]float64, 0, len(values))
+	for _, value := range values {
+		if v, ok := value.(float64); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestAggregateField(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"id": 1, "value": 1.0, "name": "John"},
+		{"id": 2, "value": 2.0, "name": "Jane"},
+		{"id": 3, "value": 3.0, "name": "John"},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	tests := []struct {
+		name      string
+		aggregate string
+		field     mframe.KeyName
+		expected  float64
+	}{
+		{"sum", "sum", "value", 6.0},
+		{"average", "average", "value", 2.0},
+		{"max", "max", "value", 3.0},
+		{"count", "count", "name", 3.0},
+		{"distinct", "distinct", "name", 2.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := cache.AggregateField(tt.aggregate, tt.field)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := cache.AggregateField("nonexistent", "value"); err == nil {
+		t.Error("expected error for unknown aggregate")
+	}
+}
+
+func TestRegisterAggregate(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for _, name := range []string{"a", "a", "b", "c"} {
+		cache.Insert(map[mframe.KeyName]interface{}{"name": name})
+	}
+
+	err := mframe.RegisterAggregate("test_distinct_ratio", func(values []interface{}) (float64, error) {
+		if len(values) == 0 {
+			return 0, nil
+		}
+		seen := make(map[interface{}]bool)
+		for _, v := range values {
+			seen[v] = true
+		}
+		return float64(len(seen)) / float64(len(values)), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := cache.AggregateField("test_distinct_ratio", "name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != 0.75 {
+		t.Errorf("expected 0.75, but got %v", result)
+	}
+
+	if err := mframe.RegisterAggregate("test_distinct_ratio", func([]interface{}) (float64, error) { return 0, nil }); err == nil {
+		t.Error("expected error when registering a duplicate name")
+	}
+	if err := mframe.RegisterAggregate("", func([]interface{}) (float64, error) { return 0, nil }); err == nil {
+		t.Error("expected error when registering an empty name")
+	}
+	if err := mframe.RegisterAggregate("test_nil", nil); err == nil {
+		t.Error("expected error when registering a nil function")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"rule": "ssh", "severity": "high", "score": 8.0},
+		{"rule": "ssh", "severity": "low", "score": 2.0},
+		{"rule": "ssh", "severity": "high", "score": 6.0},
+		{"rule": "web", "severity": "high", "score": 9.0},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	result, err := cache.Aggregate(
+		mframe.FilterSpec{Conditions: []mframe.Condition{
+			{Operator: mframe.Equals, Key: "rule", Value: "ssh"},
+			{Operator: mframe.Equals, Key: "severity", Value: "high"},
+		}},
+		mframe.AggSpec{Field: "score", Functions: []string{"sum", "average", "count"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]float64{"sum": 14, "average": 7, "count": 2}
+	for name, value := range expected {
+		if result[name] != value {
+			t.Errorf("expected %s to be %v, but got %v", name, value, result[name])
+		}
+	}
+
+	all, err := cache.Aggregate(mframe.FilterSpec{}, mframe.AggSpec{Field: "score", Functions: []string{"max"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if all["max"] != 9 {
+		t.Errorf("expected max 9 over all rows, but got %v", all["max"])
+	}
+
+	none, err := cache.Aggregate(
+		mframe.FilterSpec{Conditions: []mframe.Condition{{Operator: mframe.Equals, Key: "rule", Value: "dns"}}},
+		mframe.AggSpec{Field: "score", Functions: []string{"count"}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if none["count"] != 0 {
+		t.Errorf("expected count 0, but got %v", none["count"])
+	}
+
+	if _, err := cache.Aggregate(mframe.FilterSpec{}, mframe.AggSpec{Field: "score", Functions: []string{"nonexistent"}}); err == nil {
+		t.Error("expected error for an unknown aggregate")
+	}
+	if _, err := cache.Aggregate(mframe.FilterSpec{}, mframe.AggSpec{Field: "missing", Functions: []string{"sum"}}); err == nil {
+		t.Error("expected error when the aggregation fails")
+	}
+}
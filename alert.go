@@ -0,0 +1,128 @@
+package mframe
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AlertState is the state of a threshold alert.
+type AlertState int
+
+const (
+	AlertResolved AlertState = 1
+	AlertFiring   AlertState = 2
+)
+
+// String returns the name of the alert state.
+func (s AlertState) String() string {
+	switch s {
+	case AlertResolved:
+		return "resolved"
+	case AlertFiring:
+		return "firing"
+	default:
+		return "unknown"
+	}
+}
+
+// ThresholdOptions configures when a threshold alert fires and resolves. The gap between FireAt and
+// ResolveBelow and the Consecutive requirement suppress flapping around a single threshold.
+type ThresholdOptions struct {
+	// FireAt is the number of matching rows at or above which the alert may fire.
+	FireAt int
+	// Consecutive is the number of consecutive evaluations at or above FireAt needed to fire. Defaults to 1.
+	Consecutive int
+	// ResolveBelow is the number of matching rows below which a firing alert resolves. Defaults to FireAt.
+	ResolveBelow int
+}
+
+// AlertEvent reports the outcome of evaluating a threshold alert.
+type AlertEvent struct {
+	State    AlertState
+	Previous AlertState
+	// Changed is set when the evaluation moved the alert to a different state.
+	Changed bool
+	Count   int
+	Delta   QueryDelta
+	At      time.Time
+}
+
+// ThresholdAlert turns the number of rows matched by a standing query into a firing or resolved state.
+type ThresholdAlert struct {
+	query   *StandingQuery
+	options ThresholdOptions
+	mutex   sync.Mutex
+	state   AlertState
+	streak  int
+}
+
+// WithThreshold returns a threshold alert evaluating the standing query. The alert starts resolved.
+// Evaluating the alert evaluates the query, so its deltas are reported in the events of the alert.
+func (q *StandingQuery) WithThreshold(options ThresholdOptions) *ThresholdAlert {
+	if options.Consecutive <= 0 {
+		options.Consecutive = 1
+	}
+	if options.ResolveBelow <= 0 {
+		options.ResolveBelow = options.FireAt
+	}
+
+	return &ThresholdAlert{query: q, options: options, state: AlertResolved}
+}
+
+// State returns the current state of the alert.
+func (a *ThresholdAlert) State() AlertState {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.state
+}
+
+// Evaluate evaluates the standing query and updates the state of the alert.
+func (a *ThresholdAlert) Evaluate() AlertEvent {
+	delta := a.query.Evaluate()
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	event := AlertEvent{Previous: a.state, Count: delta.Total, Delta: delta, At: time.Now().UTC()}
+
+	switch a.state {
+	case AlertResolved:
+		if delta.Total >= a.options.FireAt {
+			a.streak++
+		} else {
+			a.streak = 0
+		}
+		if a.streak >= a.options.Consecutive {
+			a.state = AlertFiring
+			a.streak = 0
+		}
+	case AlertFiring:
+		if delta.Total < a.options.ResolveBelow {
+			a.state = AlertResolved
+		}
+	}
+
+	event.State = a.state
+	event.Changed = event.State != event.Previous
+
+	return event
+}
+
+// Run evaluates the alert every interval until ctx is done, calling emit with every state change.
+func (a *ThresholdAlert) Run(ctx context.Context, interval time.Duration, emit func(AlertEvent)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if event := a.Evaluate(); event.Changed {
+			emit(event)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestThresholdAlert(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	alert := cache.NewStandingQuery(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "event", Value: "failed_login"},
+	}}).WithThreshold(mframe.ThresholdOptions{FireAt: 3, Consecutive: 2, ResolveBelow: 2})
+
+	setCount := func(n int) {
+		for id := range cache.Data {
+			cache.RemoveElement(id)
+		}
+		for i := 0; i < n; i++ {
+			cache.Insert(map[mframe.KeyName]interface{}{"event": "failed_login"})
+		}
+	}
+
+	steps := []struct {
+		count   int
+		state   mframe.AlertState
+		changed bool
+	}{
+		{1, mframe.AlertResolved, false},
+		{3, mframe.AlertResolved, false},
+		{1, mframe.AlertResolved, false},
+		{3, mframe.AlertResolved, false},
+		{4, mframe.AlertFiring, true},
+		{2, mframe.AlertFiring, false},
+		{5, mframe.AlertFiring, false},
+		{1, mframe.AlertResolved, true},
+		{2, mframe.AlertResolved, false},
+	}
+
+	for i, step := range steps {
+		setCount(step.count)
+		event := alert.Evaluate()
+		if event.State != step.state || event.Changed != step.changed || event.Count != step.count {
+			t.Errorf("step %d: expected state %s (changed %v) with count %d, but got %s (changed %v) with count %d",
+				i, step.state, step.changed, step.count, event.State, event.Changed, event.Count)
+		}
+	}
+
+	if alert.State() != mframe.AlertResolved {
+		t.Errorf("expected the alert to be resolved, but got %s", alert.State())
+	}
+}
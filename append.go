@@ -1,11 +1,81 @@
 package mframe
 
+import (
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AppendOptions adjusts how AppendWithOptions copies rows from another DataFrame.
+type AppendOptions struct {
+	// TagKey is the key set to Tag in every appended row. No tag is added when empty.
+	TagKey KeyName
+	Tag    string
+	// PreserveIDs keeps the IDs of the source rows instead of generating new ones.
+	PreserveIDs bool
+	// Conflict selects what happens when PreserveIDs is set and a row with the same ID already exists:
+	// ConflictKeepLeft keeps the existing row and ConflictKeepRight replaces it with the appended one.
+	Conflict ConflictPolicy
+	// InheritTTL keeps the expiration of the source rows instead of applying the TTL of the DataFrame.
+	InheritTTL bool
+	// Provenance records the name of the given DataFrame and the ID of the original row in every copy,
+	// under ProvenanceSourceFrame and ProvenanceSourceID, unless provenance is disabled with SetProvenance.
+	Provenance bool
+}
+
 // Append adds all rows from the given DataFrame to the current DataFrame with the specified key.
+// Rows are copied, so the given DataFrame is not modified.
+// It is equivalent to AppendWithOptions with TagKey "key" and Tag key.
 func (d *DataFrame) Append(df *DataFrame, key string) {
+	d.AppendWithOptions(df, AppendOptions{TagKey: "key", Tag: key})
+}
+
+// AppendWithOptions copies all rows from the given DataFrame into the current DataFrame according to options
+// and returns the number of appended rows. The given DataFrame is not modified.
+// The rows are read under the lock of the given DataFrame and then inserted as one batch,
+// so the two frames are never locked at the same time and a DataFrame can be appended to itself.
+func (d *DataFrame) AppendWithOptions(df *DataFrame, options AppendOptions) int {
+	d.Locker.RLock()
+	provenance := options.Provenance && !d.noProvenance
+	generateID := d.idGeneratorUnlocked()
+	d.Locker.RUnlock()
+
+	batch := batchOptions{skipExisting: options.Conflict != ConflictKeepRight}
+	if options.InheritTTL {
+		batch.expireAt = make(map[uuid.UUID]time.Time)
+	}
+
 	df.Locker.RLock()
-	defer df.Locker.RUnlock()
-	for _, value := range df.Data {
-		value["key"] = key
-		d.Insert(value)
+	entries := make([]batchEntry, 0, len(df.Data))
+	for _, id := range df.arrivalOrderUnlocked() {
+		row := copyRow(df.Data[id])
+		if options.TagKey != "" {
+			row[options.TagKey] = options.Tag
+		}
+		if provenance {
+			if df.name != "" {
+				row[ProvenanceSourceFrame] = df.name
+			}
+			row[ProvenanceSourceID] = id.String()
+		}
+
+		newID := id
+		if !options.PreserveIDs {
+			newID = generateID()
+		}
+		entries = append(entries, batchEntry{id: newID, data: row})
+
+		if batch.expireAt != nil {
+			batch.expireAt[newID] = df.ExpireAt[id]
+		}
 	}
+	df.Locker.RUnlock()
+
+	count, err := d.insertEntriesWithOptions(entries, batch)
+	if err != nil {
+		log.Printf("error appending rows: %s", err.Error())
+	}
+
+	return count
 }
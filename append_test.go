@@ -1,6 +1,7 @@
 package mframe_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -46,3 +47,153 @@ func TestAppend(t *testing.T) {
 		t.Errorf("expected count 10, but got %v", result)
 	}
 }
+
+func TestAppendProvenance(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	var feed mframe.DataFrame
+	feed.Init(24 * time.Hour)
+	feed.SetName("intel-feed")
+	feed.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1"})
+
+	cache.Append(&feed, "feed")
+	if _, ok := cache.ToSlice()[0][mframe.ProvenanceSourceID]; ok {
+		t.Error("expected no provenance keys from a plain Append")
+	}
+
+	cache.Init(24 * time.Hour)
+	cache.AppendWithOptions(&feed, mframe.AppendOptions{TagKey: "key", Tag: "feed", Provenance: true})
+
+	for _, row := range feed.Data {
+		if _, ok := row["key"]; ok {
+			t.Error("Append should not modify the source rows")
+		}
+	}
+
+	rows := cache.ToSlice()
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, but got %d", len(rows))
+	}
+	if rows[0][mframe.ProvenanceSourceFrame] != "intel-feed" {
+		t.Errorf("expected source frame 'intel-feed', but got %v", rows[0][mframe.ProvenanceSourceFrame])
+	}
+	id, _, _ := feed.FindFirstByKey("ip")
+	if rows[0][mframe.ProvenanceSourceID] != id.String() {
+		t.Errorf("expected source id %s, but got %v", id, rows[0][mframe.ProvenanceSourceID])
+	}
+
+	var plain mframe.DataFrame
+	plain.Init(24 * time.Hour)
+	plain.SetProvenance(false)
+	plain.AppendWithOptions(&feed, mframe.AppendOptions{Provenance: true})
+	if _, ok := plain.ToSlice()[0][mframe.ProvenanceSourceID]; ok {
+		t.Error("expected no provenance keys when provenance is disabled")
+	}
+}
+
+func TestAppendSelf(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"name": "John"})
+	cache.Insert(map[mframe.KeyName]interface{}{"name": "Jane"})
+
+	cache.Append(&cache, "copy")
+
+	if cache.Count() != 4 {
+		t.Errorf("expected 4 rows, but got %d", cache.Count())
+	}
+}
+
+func TestAppendConcurrent(t *testing.T) {
+	var a, b mframe.DataFrame
+	a.Init(24 * time.Hour)
+	b.Init(24 * time.Hour)
+	a.SetProvenance(false)
+	b.SetProvenance(false)
+
+	a.Insert(map[mframe.KeyName]interface{}{"name": "a"})
+	b.Insert(map[mframe.KeyName]interface{}{"name": "b"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(4)
+		go func() { defer wg.Done(); a.Append(&b, "b") }()
+		go func() { defer wg.Done(); b.Append(&a, "a") }()
+		go func() { defer wg.Done(); a.Join(&b, "id", mframe.JoinInner) }()
+		go func() { defer wg.Done(); b.Join(&a, "id", mframe.JoinInner) }()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("concurrent appends and joins did not finish")
+	}
+}
+
+func TestAppendWithOptions(t *testing.T) {
+	var source mframe.DataFrame
+	source.Init(time.Hour)
+	source.Insert(map[mframe.KeyName]interface{}{"name": "John"})
+	source.Insert(map[mframe.KeyName]interface{}{"name": "Jane"})
+
+	var dest mframe.DataFrame
+	dest.Init(24 * time.Hour)
+	dest.SetProvenance(false)
+
+	appended := dest.AppendWithOptions(&source, mframe.AppendOptions{
+		TagKey:      "origin",
+		Tag:         "hr",
+		PreserveIDs: true,
+		InheritTTL:  true,
+	})
+	if appended != 2 {
+		t.Fatalf("expected 2 appended rows, but got %d", appended)
+	}
+
+	for id, row := range source.Data {
+		copied, ok := dest.Data[id]
+		if !ok {
+			t.Fatalf("expected row %s to keep its ID", id)
+		}
+		if copied["origin"] != "hr" {
+			t.Errorf("expected origin tag, but got %v", copied["origin"])
+		}
+		if _, ok := copied["key"]; ok {
+			t.Error("expected no default key column")
+		}
+		if !dest.ExpireAt[id].Equal(source.ExpireAt[id]) {
+			t.Errorf("expected inherited expiration %v, but got %v", source.ExpireAt[id], dest.ExpireAt[id])
+		}
+		if _, ok := row["origin"]; ok {
+			t.Error("expected the source row not to be modified")
+		}
+	}
+
+	if appended := dest.AppendWithOptions(&source, mframe.AppendOptions{TagKey: "origin", Tag: "again", PreserveIDs: true}); appended != 0 {
+		t.Errorf("expected existing rows to be kept, but got %d appended rows", appended)
+	}
+	if dest.CountWhere(mframe.Equals, "origin", "hr", nil) != 2 {
+		t.Error("expected existing rows to be unchanged")
+	}
+
+	replaced := dest.AppendWithOptions(&source, mframe.AppendOptions{
+		TagKey:      "origin",
+		Tag:         "again",
+		PreserveIDs: true,
+		Conflict:    mframe.ConflictKeepRight,
+	})
+	if replaced != 2 || dest.Count() != 2 {
+		t.Errorf("expected 2 replaced rows and 2 rows in total, but got %d and %d", replaced, dest.Count())
+	}
+	if dest.CountWhere(mframe.Equals, "origin", "hr", nil) != 0 {
+		t.Error("expected replaced rows to be removed from the indexes")
+	}
+}
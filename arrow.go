@@ -0,0 +1,290 @@
+package mframe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArrowIDColumn is the name of the column holding the ID of the rows in Arrow records.
+const ArrowIDColumn = "_id"
+
+// ArrowType identifies the Arrow data type of a column.
+type ArrowType int
+
+const (
+	// ArrowUtf8 columns hold int32 offsets into UTF-8 data.
+	ArrowUtf8 ArrowType = 1
+	// ArrowFloat64 columns hold little-endian IEEE 754 doubles.
+	ArrowFloat64 ArrowType = 2
+	// ArrowBoolean columns hold bit-packed values, least significant bit first.
+	ArrowBoolean ArrowType = 3
+	// ArrowTimestamp columns hold little-endian int64 nanoseconds since the epoch, in UTC.
+	ArrowTimestamp ArrowType = 4
+)
+
+// String returns the name of the Arrow data type.
+func (t ArrowType) String() string {
+	switch t {
+	case ArrowUtf8:
+		return "utf8"
+	case ArrowFloat64:
+		return "float64"
+	case ArrowBoolean:
+		return "bool"
+	case ArrowTimestamp:
+		return "timestamp[ns, tz=UTC]"
+	default:
+		return "unknown"
+	}
+}
+
+// ArrowColumn holds the buffers of a column following the Arrow columnar format, so they can be wrapped
+// by Arrow libraries without copying, e.g. with array.NewData in the Go implementation.
+type ArrowColumn struct {
+	Name string
+	Type ArrowType
+	// NullCount is the number of null values.
+	NullCount int
+	// Validity is the bitmap of the non-null values, least significant bit first. Nil when there are no nulls.
+	Validity []byte
+	// Offsets holds the start of each value in Data and the end of the last one, for ArrowUtf8 columns.
+	Offsets []int32
+	Data    []byte
+}
+
+// ArrowRecord is a batch of rows laid out as Arrow columns of equal length.
+type ArrowRecord struct {
+	NumRows int
+	Columns []ArrowColumn
+}
+
+// ToArrowRecord converts the rows of the DataFrame, ordered by ID, to an Arrow record with a column per key,
+// named after its dotted path, and the ID of each row in ArrowIDColumn. Rows without a key are null in
+// its column.
+func (d *DataFrame) ToArrowRecord() ArrowRecord {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for id := range d.Data {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	stored := d.storedKeysUnlocked(ids)
+	keys := make([]KeyName, 0, len(stored))
+	for key := range stored {
+		if key != ArrowIDColumn {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	record := ArrowRecord{NumRows: len(ids), Columns: make([]ArrowColumn, 0, len(keys)+1)}
+
+	idColumn := newArrowColumn(ArrowIDColumn, ArrowUtf8, len(ids))
+	for i, id := range ids {
+		idColumn.append(i, id.String())
+	}
+	record.Columns = append(record.Columns, idColumn.finish())
+
+	for _, key := range keys {
+		column := newArrowColumn(string(key), arrowTypeOf(stored[key]), len(ids))
+		for i, id := range ids {
+			column.append(i, d.Data[id][key])
+		}
+		record.Columns = append(record.Columns, column.finish())
+	}
+
+	return record
+}
+
+// FromArrowRecord inserts the rows of an Arrow record as new rows and returns the number of inserted rows.
+// Null values are left out of the rows. An ArrowIDColumn holding UUIDs sets the ID of the rows, replacing
+// existing rows with the same ID. Invalid records fail the import before any row is inserted.
+func (d *DataFrame) FromArrowRecord(record ArrowRecord) (int, error) {
+	for _, column := range record.Columns {
+		if err := column.validate(record.NumRows); err != nil {
+			return 0, fmt.Errorf("column '%s': %w", column.Name, err)
+		}
+	}
+
+	if record.NumRows == 0 {
+		return 0, nil
+	}
+
+	d.Locker.RLock()
+	newID := d.idGeneratorUnlocked()
+	d.Locker.RUnlock()
+
+	entries := make([]batchEntry, 0, record.NumRows)
+	for i := 0; i < record.NumRows; i++ {
+		id := uuid.Nil
+		data := make(map[KeyName]interface{}, len(record.Columns))
+		for _, column := range record.Columns {
+			value, ok := column.value(i)
+			if !ok {
+				continue
+			}
+			if column.Name == ArrowIDColumn {
+				if s, ok := value.(string); ok {
+					if parsed, err := uuid.Parse(s); err == nil {
+						id = parsed
+						continue
+					}
+				}
+			}
+			data[KeyName(column.Name)] = value
+		}
+
+		if id == uuid.Nil {
+			id = newID()
+		}
+		entries = append(entries, batchEntry{id: id, data: data})
+	}
+
+	return d.insertEntriesWithOptions(entries, batchOptions{})
+}
+
+// arrowTypeOf returns the Arrow data type holding the values of a key type.
+func arrowTypeOf(keyType KeyType) ArrowType {
+	switch keyType {
+	case Numeric:
+		return ArrowFloat64
+	case Boolean:
+		return ArrowBoolean
+	case Time:
+		return ArrowTimestamp
+	default:
+		return ArrowUtf8
+	}
+}
+
+// arrowBuilder builds an ArrowColumn of a known length.
+type arrowBuilder struct {
+	column ArrowColumn
+}
+
+// newArrowColumn returns a builder for a column of length values.
+func newArrowColumn(name string, arrowType ArrowType, length int) *arrowBuilder {
+	b := &arrowBuilder{column: ArrowColumn{
+		Name:     name,
+		Type:     arrowType,
+		Validity: make([]byte, (length+7)/8),
+	}}
+
+	switch arrowType {
+	case ArrowUtf8:
+		b.column.Offsets = make([]int32, 1, length+1)
+	case ArrowFloat64, ArrowTimestamp:
+		b.column.Data = make([]byte, 8*length)
+	case ArrowBoolean:
+		b.column.Data = make([]byte, (length+7)/8)
+	}
+
+	return b
+}
+
+// append sets the value of row i, which must be appended in order. Nil values and values not matching the
+// type of the column are nulls.
+func (b *arrowBuilder) append(i int, value interface{}) {
+	valid := true
+	switch v := value.(type) {
+	case string:
+		valid = b.column.Type == ArrowUtf8
+		if valid {
+			b.column.Data = append(b.column.Data, v...)
+		}
+	case float64:
+		valid = b.column.Type == ArrowFloat64
+		if valid {
+			binary.LittleEndian.PutUint64(b.column.Data[8*i:], math.Float64bits(v))
+		}
+	case bool:
+		valid = b.column.Type == ArrowBoolean
+		if valid && v {
+			b.column.Data[i/8] |= 1 << (i % 8)
+		}
+	case time.Time:
+		valid = b.column.Type == ArrowTimestamp
+		if valid {
+			binary.LittleEndian.PutUint64(b.column.Data[8*i:], uint64(v.UnixNano()))
+		}
+	default:
+		valid = false
+	}
+
+	if valid {
+		b.column.Validity[i/8] |= 1 << (i % 8)
+	} else {
+		b.column.NullCount++
+	}
+
+	if b.column.Type == ArrowUtf8 {
+		b.column.Offsets = append(b.column.Offsets, int32(len(b.column.Data)))
+	}
+}
+
+// finish returns the column, dropping the validity bitmap when there are no nulls.
+func (b *arrowBuilder) finish() ArrowColumn {
+	if b.column.NullCount == 0 {
+		b.column.Validity = nil
+	}
+	return b.column
+}
+
+// validate checks the buffers of the column against the number of rows.
+func (c ArrowColumn) validate(rows int) error {
+	if c.Validity != nil && len(c.Validity) < (rows+7)/8 {
+		return fmt.Errorf("validity bitmap too short")
+	}
+
+	switch c.Type {
+	case ArrowUtf8:
+		if len(c.Offsets) != rows+1 {
+			return fmt.Errorf("%d offsets for %d rows", len(c.Offsets), rows)
+		}
+		for i := 0; i < rows; i++ {
+			if c.Offsets[i] < 0 || c.Offsets[i] > c.Offsets[i+1] || int(c.Offsets[i+1]) > len(c.Data) {
+				return fmt.Errorf("invalid offset at row %d", i)
+			}
+		}
+	case ArrowFloat64, ArrowTimestamp:
+		if len(c.Data) < 8*rows {
+			return fmt.Errorf("data buffer too short")
+		}
+	case ArrowBoolean:
+		if len(c.Data) < (rows+7)/8 {
+			return fmt.Errorf("data buffer too short")
+		}
+	default:
+		return fmt.Errorf("unsupported Arrow type %d", c.Type)
+	}
+
+	return nil
+}
+
+// value returns the value of row i, or false when it is null.
+func (c ArrowColumn) value(i int) (interface{}, bool) {
+	if c.Validity != nil && c.Validity[i/8]&(1<<(i%8)) == 0 {
+		return nil, false
+	}
+
+	switch c.Type {
+	case ArrowUtf8:
+		return string(c.Data[c.Offsets[i]:c.Offsets[i+1]]), true
+	case ArrowFloat64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(c.Data[8*i:])), true
+	case ArrowBoolean:
+		return c.Data[i/8]&(1<<(i%8)) != 0, true
+	case ArrowTimestamp:
+		return time.Unix(0, int64(binary.LittleEndian.Uint64(c.Data[8*i:]))).UTC(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,93 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestToArrowRecord(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "bytes": 1.5, "blocked": true, "seen": seen},
+		{"host": "web-2", "bytes": 100, "blocked": false},
+		{"user": "alice"},
+	})
+
+	record := cache.ToArrowRecord()
+	if record.NumRows != 3 || len(record.Columns) != 6 {
+		t.Fatalf("expected 3 rows and 6 columns, but got %d and %d", record.NumRows, len(record.Columns))
+	}
+
+	names := []string{mframe.ArrowIDColumn, "blocked", "bytes", "host", "seen", "user"}
+	types := []mframe.ArrowType{mframe.ArrowUtf8, mframe.ArrowBoolean, mframe.ArrowFloat64, mframe.ArrowUtf8, mframe.ArrowTimestamp, mframe.ArrowUtf8}
+	for i, column := range record.Columns {
+		if column.Name != names[i] || column.Type != types[i] {
+			t.Errorf("expected column %s of type %s, but got %s of type %s", names[i], types[i], column.Name, column.Type)
+		}
+	}
+
+	if id := record.Columns[0]; id.NullCount != 0 || id.Validity != nil || len(id.Offsets) != 4 {
+		t.Errorf("expected an ID column without nulls, but got %+v", id)
+	}
+	if user := record.Columns[5]; user.NullCount != 2 || len(user.Validity) != 1 {
+		t.Errorf("expected 2 null users, but got %+v", user)
+	}
+
+	var imported mframe.DataFrame
+	imported.Init(24 * time.Hour)
+	n, err := imported.FromArrowRecord(record)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 imported rows, but got %d", n)
+	}
+
+	for id, row := range cache.Data {
+		copied, ok := imported.Data[id]
+		if !ok {
+			t.Fatalf("expected row %s to keep its ID", id)
+		}
+		if len(copied) != len(row) {
+			t.Errorf("expected %v, but got %v", row, copied)
+		}
+		for key, value := range row {
+			if copied[key] != value {
+				t.Errorf("expected %s to be %v, but got %v", key, value, copied[key])
+			}
+		}
+	}
+}
+
+func TestFromArrowRecordErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		column mframe.ArrowColumn
+	}{
+		{"missing offsets", mframe.ArrowColumn{Name: "host", Type: mframe.ArrowUtf8, Data: []byte("ab")}},
+		{"offset out of bounds", mframe.ArrowColumn{Name: "host", Type: mframe.ArrowUtf8, Offsets: []int32{0, 1, 5}, Data: []byte("ab")}},
+		{"short data", mframe.ArrowColumn{Name: "bytes", Type: mframe.ArrowFloat64, Data: make([]byte, 8)}},
+		{"short validity", mframe.ArrowColumn{Name: "flag", Type: mframe.ArrowBoolean, Data: []byte{1}, Validity: []byte{}}},
+		{"unknown type", mframe.ArrowColumn{Name: "other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache mframe.DataFrame
+			cache.Init(24 * time.Hour)
+
+			record := mframe.ArrowRecord{NumRows: 2, Columns: []mframe.ArrowColumn{tt.column}}
+			if _, err := cache.FromArrowRecord(record); err == nil {
+				t.Error("expected an error")
+			}
+			if cache.Count() != 0 {
+				t.Errorf("expected no rows, but got %d", cache.Count())
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package mframe
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// backtestSampleSize is the number of matched row IDs kept in each BacktestResult.
+const backtestSampleSize = 10
+
+// Rule is a named filter that fires when at least FireAt rows match it, as evaluated by RunRules.
+type Rule struct {
+	Name string
+	Spec FilterSpec
+	// FireAt is the number of matching rows that fires the rule, 1 when zero or less.
+	FireAt int
+}
+
+// BacktestResult reports how a rule behaved against the rows of a DataFrame.
+type BacktestResult struct {
+	Rule    string
+	Matches int
+	Fired   bool
+	// Sample holds up to 10 of the IDs of the matching rows, sorted.
+	Sample []uuid.UUID
+}
+
+// RunRules evaluates every rule against the rows of the DataFrame, including rows past their expiration
+// that the cleaner did not remove, and returns one result per rule in the order of rules.
+// Returns an error if a rule has no name or two rules share a name.
+func (d *DataFrame) RunRules(rules []Rule) ([]BacktestResult, error) {
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule name cannot be empty")
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule name '%s'", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	results := make([]BacktestResult, 0, len(rules))
+	for _, rule := range rules {
+		ids := make([]uuid.UUID, 0)
+		for id := range d.specIDsUnlocked(rule.Spec) {
+			ids = append(ids, id)
+		}
+		sortIDs(ids)
+
+		results = append(results, BacktestResult{
+			Rule:    rule.Name,
+			Matches: len(ids),
+			Fired:   len(ids) >= max(rule.FireAt, 1),
+			Sample:  ids[:min(len(ids), backtestSampleSize)],
+		})
+	}
+
+	return results, nil
+}
+
+// RunRulesAgainst loads the snapshot at snapshotPath into a private DataFrame, so live frames are not affected,
+// and evaluates the rules against it with RunRules, to check which rules would have fired and how often before
+// deploying them. Files ending in .json are read with ImportFromJSON, files ending in .ndjson with ImportFromNDJSON,
+// files ending in .gz with LoadFromFileCompressed and any other file with LoadFromFile. The cleaner never runs
+// on the loaded rows.
+// Returns an error if the snapshot cannot be loaded or a rule is invalid.
+func RunRulesAgainst(snapshotPath string, rules []Rule) ([]BacktestResult, error) {
+	var snapshot DataFrame
+	snapshot.Init(24 * time.Hour)
+
+	var err error
+	switch strings.ToLower(filepath.Ext(snapshotPath)) {
+	case ".json":
+		err = snapshot.ImportFromJSON(snapshotPath)
+	case ".ndjson":
+		err = snapshot.ImportFromNDJSON(snapshotPath)
+	case ".gz":
+		err = snapshot.LoadFromFileCompressed(snapshotPath)
+	default:
+		err = snapshot.LoadFromFile(snapshotPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	return snapshot.RunRules(rules)
+}
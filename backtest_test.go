@@ -0,0 +1,79 @@
+package mframe_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestRunRulesAgainst(t *testing.T) {
+	var live mframe.DataFrame
+	live.Init(24 * time.Hour)
+	live.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "action": "login", "failed": true},
+		{"ip": "10.0.0.1", "action": "login", "failed": true},
+		{"ip": "10.0.0.2", "action": "login", "failed": false},
+		{"ip": "10.0.0.3", "action": "scan"},
+	})
+
+	rules := []mframe.Rule{
+		{Name: "failed logins", Spec: mframe.FilterSpec{Conditions: []mframe.Condition{
+			{Operator: mframe.Equals, Key: "action", Value: "login"},
+			{Operator: mframe.Equals, Key: "failed", Value: true},
+		}}, FireAt: 2},
+		{Name: "scans", Spec: mframe.FilterSpec{Conditions: []mframe.Condition{
+			{Operator: mframe.Equals, Key: "action", Value: "scan"},
+		}}, FireAt: 5},
+		{Name: "exfiltration", Spec: mframe.FilterSpec{Conditions: []mframe.Condition{
+			{Operator: mframe.Equals, Key: "action", Value: "upload"},
+		}}},
+	}
+
+	dir := t.TempDir()
+	snapshots := map[string]func(string) error{
+		"frame.gob":    live.SaveToFile,
+		"frame.gob.gz": live.SaveToFileCompressed,
+		"frame.json":   live.ExportToJSON,
+		"frame.ndjson": live.ExportToNDJSON,
+	}
+
+	for name, save := range snapshots {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := save(path); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			results, err := mframe.RunRulesAgainst(path, rules)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := []struct {
+				matches int
+				fired   bool
+			}{{2, true}, {1, false}, {0, false}}
+
+			for i, result := range results {
+				if result.Rule != rules[i].Name || result.Matches != expected[i].matches || result.Fired != expected[i].fired {
+					t.Errorf("expected %s to match %d rows and fire %v, but got %+v", rules[i].Name, expected[i].matches, expected[i].fired, result)
+				}
+				if len(result.Sample) != result.Matches {
+					t.Errorf("expected a sample of %d ids, but got %d", result.Matches, len(result.Sample))
+				}
+			}
+		})
+	}
+
+	if _, err := mframe.RunRulesAgainst(filepath.Join(dir, "missing.gob"), rules); err == nil {
+		t.Error("expected an error for a missing snapshot")
+	}
+	if _, err := live.RunRules([]mframe.Rule{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Error("expected an error for duplicate rule names")
+	}
+	if live.Count() != 4 {
+		t.Errorf("expected the live frame to be untouched, but got %d rows", live.Count())
+	}
+}
@@ -0,0 +1,204 @@
+package mframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// BaselineOptions configures how a Baseline tracks a numeric key.
+type BaselineOptions struct {
+	// GroupKey groups rows by its value. When empty, all rows share a single group.
+	GroupKey KeyName
+	// ValueKey is the numeric key being baselined.
+	ValueKey KeyName
+	// Alpha is the EWMA smoothing factor in (0, 1]. When 0, a cumulative mean and standard deviation are used.
+	Alpha float64
+	// MinSamples is the number of observations a group needs before it reports anomalies.
+	MinSamples int
+}
+
+// GroupBaseline is a snapshot of the baseline of a single group.
+type GroupBaseline struct {
+	Group   string
+	Samples int
+	Mean    float64
+	StdDev  float64
+}
+
+// Anomaly describes a row whose value deviates from the baseline of its group.
+type Anomaly struct {
+	ID     uuid.UUID
+	Group  string
+	Value  float64
+	Mean   float64
+	StdDev float64
+	Score  float64 // Number of standard deviations between Value and Mean
+}
+
+// Baseline maintains rolling per-group mean and standard deviation of a numeric key,
+// updated on every insert into the DataFrame it tracks.
+type Baseline struct {
+	frame   *DataFrame
+	options BaselineOptions
+	mutex   sync.RWMutex
+	groups  map[string]*groupStats
+}
+
+// groupStats holds the running statistics of a group. For cumulative baselines
+// spread is the sum of squared deviations, for EWMA baselines it is the variance.
+type groupStats struct {
+	samples int
+	mean    float64
+	spread  float64
+}
+
+// TrackBaseline creates a Baseline for the DataFrame, seeded with the rows it currently holds
+// and kept up to date with every row inserted afterwards.
+func (d *DataFrame) TrackBaseline(options BaselineOptions) *Baseline {
+	b := &Baseline{
+		frame:   d,
+		options: options,
+		groups:  make(map[string]*groupStats),
+	}
+
+	d.Locker.RLock()
+	for _, row := range d.Data {
+		b.Observe(row)
+	}
+	d.Locker.RUnlock()
+
+	d.OnAfterInsert(func(_ uuid.UUID, row Row) {
+		b.Observe(row)
+	})
+
+	return b
+}
+
+// Observe updates the baseline with a row. Rows without a float64 value for the value key are ignored.
+func (b *Baseline) Observe(row Row) {
+	value, ok := row[b.options.ValueKey].(float64)
+	if !ok {
+		return
+	}
+
+	group := b.groupOf(row)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	stats, ok := b.groups[group]
+	if !ok {
+		stats = &groupStats{}
+		b.groups[group] = stats
+	}
+
+	stats.samples++
+
+	if stats.samples == 1 {
+		stats.mean = value
+		stats.spread = 0
+		return
+	}
+
+	diff := value - stats.mean
+	if b.options.Alpha > 0 {
+		increment := b.options.Alpha * diff
+		stats.mean += increment
+		stats.spread = (1 - b.options.Alpha) * (stats.spread + diff*increment)
+		return
+	}
+
+	stats.mean += diff / float64(stats.samples)
+	stats.spread += diff * (value - stats.mean)
+}
+
+// Groups returns a snapshot of the baseline of every group, sorted by group name.
+func (b *Baseline) Groups() []GroupBaseline {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	result := make([]GroupBaseline, 0, len(b.groups))
+	for group, stats := range b.groups {
+		result = append(result, GroupBaseline{
+			Group:   group,
+			Samples: stats.samples,
+			Mean:    stats.mean,
+			StdDev:  b.stdDev(stats),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Group < result[j].Group })
+
+	return result
+}
+
+// Anomalies returns the rows currently in the DataFrame whose value deviates from the baseline
+// of their group by more than threshold standard deviations, sorted by decreasing score.
+// Groups with fewer than MinSamples observations or without variation are skipped.
+func (b *Baseline) Anomalies(threshold float64) []Anomaly {
+	b.frame.Locker.RLock()
+	defer b.frame.Locker.RUnlock()
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	result := make([]Anomaly, 0)
+	for id, row := range b.frame.Data {
+		value, ok := row[b.options.ValueKey].(float64)
+		if !ok {
+			continue
+		}
+
+		group := b.groupOf(row)
+		stats, ok := b.groups[group]
+		if !ok || stats.samples < b.options.MinSamples {
+			continue
+		}
+
+		stdDev := b.stdDev(stats)
+		if stdDev == 0 {
+			continue
+		}
+
+		score := math.Abs(value-stats.mean) / stdDev
+		if score <= threshold {
+			continue
+		}
+
+		result = append(result, Anomaly{
+			ID:     id,
+			Group:  group,
+			Value:  value,
+			Mean:   stats.mean,
+			StdDev: stdDev,
+			Score:  score,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+
+	return result
+}
+
+// groupOf returns the group name of a row.
+func (b *Baseline) groupOf(row Row) string {
+	if b.options.GroupKey == "" {
+		return ""
+	}
+	return fmt.Sprint(row[b.options.GroupKey])
+}
+
+// stdDev returns the standard deviation of a group.
+func (b *Baseline) stdDev(stats *groupStats) float64 {
+	if b.options.Alpha > 0 {
+		return math.Sqrt(stats.spread)
+	}
+	if stats.samples < 2 {
+		return 0
+	}
+	return math.Sqrt(stats.spread / float64(stats.samples-1))
+}
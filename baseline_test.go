@@ -0,0 +1,71 @@
+package mframe_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestBaselineCumulative(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 5; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"host": "a", "bytes": 100.0 + float64(i%2)})
+	}
+
+	baseline := cache.TrackBaseline(mframe.BaselineOptions{
+		GroupKey:   "host",
+		ValueKey:   "bytes",
+		MinSamples: 3,
+	})
+
+	for i := 0; i < 5; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"host": "a", "bytes": 100.0 + float64(i%2)})
+		cache.Insert(map[mframe.KeyName]interface{}{"host": "b", "bytes": 10.0 + float64(i%2)})
+	}
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "b", "bytes": 50.0})
+
+	groups := baseline.Groups()
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, but got %d", len(groups))
+	}
+	if groups[0].Group != "a" || groups[0].Samples != 10 || math.Abs(groups[0].Mean-100.4) > 1e-9 {
+		t.Errorf("unexpected baseline for group a: %+v", groups[0])
+	}
+
+	anomalies := baseline.Anomalies(1.5)
+	if len(anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, but got %d: %+v", len(anomalies), anomalies)
+	}
+	if anomalies[0].Group != "b" || anomalies[0].Value != 50.0 {
+		t.Errorf("unexpected anomaly: %+v", anomalies[0])
+	}
+	if _, ok := cache.Data[anomalies[0].ID]; !ok {
+		t.Error("expected the anomaly to reference a row in the DataFrame")
+	}
+}
+
+func TestBaselineEWMA(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	baseline := cache.TrackBaseline(mframe.BaselineOptions{ValueKey: "latency", Alpha: 0.5})
+
+	for _, v := range []float64{10, 10, 10, 20} {
+		cache.Insert(map[mframe.KeyName]interface{}{"latency": v})
+	}
+
+	groups := baseline.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 group, but got %d", len(groups))
+	}
+	if math.Abs(groups[0].Mean-15) > 1e-9 {
+		t.Errorf("expected EWMA mean 15, but got %v", groups[0].Mean)
+	}
+	if math.Abs(groups[0].StdDev-5) > 1e-9 {
+		t.Errorf("expected EWMA standard deviation 5, but got %v", groups[0].StdDev)
+	}
+}
@@ -76,6 +76,26 @@ func BenchmarkFilterEquals(b *testing.B) {
 	}
 }
 
+func BenchmarkFilterIDs(b *testing.B) {
+	df := &mframe.DataFrame{}
+	df.Init(5 * time.Minute)
+
+	// Insert test data
+	for i := 0; i < 10000; i++ {
+		df.Insert(map[mframe.KeyName]interface{}{
+			"id":     i,
+			"name":   fmt.Sprintf("name_%d", i%100),
+			"value":  float64(i % 1000),
+			"active": i%2 == 0,
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = df.FilterIDs(mframe.Equals, "name", "name_50", nil)
+	}
+}
+
 func BenchmarkFilterRegex(b *testing.B) {
 	df := &mframe.DataFrame{}
 	df.Init(5 * time.Minute)
@@ -0,0 +1,59 @@
+package mframe
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrQueryMemoryLimit is returned when the result of a query would exceed the query memory limit.
+var ErrQueryMemoryLimit = errors.New("query memory limit exceeded")
+
+// rowSizeSamples is the number of rows sampled to estimate the average size of a row.
+const rowSizeSamples = 64
+
+// SetQueryMemoryLimit limits the memory a single query result may allocate, in bytes. The size of a result
+// is estimated as the number of matched rows times the average size of a row, and queries exceeding the
+// limit are aborted with ErrQueryMemoryLimit instead of building the result. Zero disables the limit.
+func (d *DataFrame) SetQueryMemoryLimit(bytes int) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+	d.queryMemoryLimit = bytes
+}
+
+// averageRowSizeUnlocked estimates the average size of a row in bytes from a sample of the rows,
+// without acquiring locks.
+func (d *DataFrame) averageRowSizeUnlocked() int {
+	total, sampled := 0, 0
+	for _, row := range d.Data {
+		total += estimateRowSize(row)
+		sampled++
+		if sampled == rowSizeSamples {
+			break
+		}
+	}
+
+	if sampled == 0 {
+		return 0
+	}
+
+	return total / sampled
+}
+
+// estimateRowSize estimates the memory used by a row, its keys and values in bytes.
+func estimateRowSize(row Row) int {
+	// Map header plus the entries of the Data and ExpireAt indexes.
+	size := 48 + 16 + 40
+	for k, v := range row {
+		// Map entry plus the key string.
+		size += 32 + len(k)
+		switch value := v.(type) {
+		case string:
+			size += 16 + len(value)
+		case time.Time:
+			size += 24
+		default:
+			size += 16
+		}
+	}
+	return size
+}
@@ -0,0 +1,38 @@
+package mframe_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestQueryMemoryLimit(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 1000; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"id": i, "user": "someone"})
+	}
+
+	cache.SetQueryMemoryLimit(10 * 1024)
+
+	if results := cache.Filter(mframe.Less, "id", 10.0, nil); results.Count() != 10 {
+		t.Errorf("expected a small query to succeed, but got %d rows", results.Count())
+	}
+
+	if results := cache.Filter(mframe.NotEquals, "id", 0.0, nil); results.Count() != 0 {
+		t.Errorf("expected a runaway query to be aborted, but got %d rows", results.Count())
+	}
+
+	if _, err := cache.FilterContext(context.Background(), mframe.NotEquals, "id", 0.0, nil); !errors.Is(err, mframe.ErrQueryMemoryLimit) {
+		t.Errorf("expected ErrQueryMemoryLimit, but got %v", err)
+	}
+
+	cache.SetQueryMemoryLimit(0)
+	if results := cache.Filter(mframe.NotEquals, "id", 0.0, nil); results.Count() != 999 {
+		t.Errorf("expected 999 rows without a limit, but got %d", results.Count())
+	}
+}
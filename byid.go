@@ -0,0 +1,77 @@
+package mframe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Get returns a copy of the row with the specified ID and whether it exists.
+func (d *DataFrame) Get(id uuid.UUID) (Row, bool) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	row, ok := d.Data[id]
+	if !ok {
+		return nil, false
+	}
+
+	d.recency.touch(id)
+	return d.copyRowUnlocked(row), true
+}
+
+// UpdateByID sets the keys in patch on the row with the specified ID, reindexing the changed keys like
+// UpdateWhere. A nil value removes the key from the row. The row keeps its ID and expiration.
+// Returns an error if the row does not exist, patch is empty or it would change the type of a key
+// held by other rows.
+func (d *DataFrame) UpdateByID(id uuid.UUID, patch map[KeyName]interface{}) error {
+	if len(patch) == 0 {
+		return fmt.Errorf("no changes to apply")
+	}
+
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	if _, ok := d.Data[id]; !ok {
+		return fmt.Errorf("row '%s' not found", id)
+	}
+
+	_, err := d.updateIDsUnlocked(map[uuid.UUID]struct{}{id: {}}, patch)
+	return err
+}
+
+// UpdateBatch replaces the content of the rows with the specified IDs under a single lock, reindexing
+// them. The rows keep their IDs and expiration. Unknown IDs and empty rows are ignored.
+// Returns the number of updated rows.
+func (d *DataFrame) UpdateBatch(rows map[uuid.UUID]Row) int {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	updated := 0
+	for id, row := range rows {
+		if _, ok := d.Data[id]; !ok || len(row) == 0 {
+			continue
+		}
+		d.reindexRowUnlocked(id, row)
+		updated++
+	}
+
+	return updated
+}
+
+// Touch restarts the expiration of the row with the specified ID from now, without modifying it.
+// Returns an error if the row does not exist.
+func (d *DataFrame) Touch(id uuid.UUID) error {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	if _, ok := d.Data[id]; !ok {
+		return fmt.Errorf("row '%s' not found", id)
+	}
+
+	d.setExpireAtUnlocked(id, time.Now().UTC().Add(d.TTL))
+	d.recency.touch(id)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestGetUpdateTouch(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "a", "status": "open"})
+
+	var id uuid.UUID
+	for k := range cache.Data {
+		id = k
+	}
+
+	row, ok := cache.Get(id)
+	if !ok || row["host"] != "a" {
+		t.Fatalf("expected the row, but got %v, %v", row, ok)
+	}
+	row["host"] = "mallory"
+	if cache.CountWhere(mframe.Equals, "host", "a", nil) != 1 {
+		t.Error("expected Get to return a copy")
+	}
+
+	if _, ok := cache.Get(uuid.New()); ok {
+		t.Error("expected a missing row not to be found")
+	}
+
+	expireAt := cache.ExpireAt[id]
+	if err := cache.UpdateByID(id, map[mframe.KeyName]interface{}{"status": "closed", "host": nil}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cache.CountWhere(mframe.Equals, "status", "closed", nil) != 1 || cache.CountWhere(mframe.Equals, "status", "open", nil) != 0 {
+		t.Error("expected the status to be reindexed")
+	}
+	if _, ok := cache.Keys["host"]; ok {
+		t.Error("expected nil to remove the key")
+	}
+	if !cache.ExpireAt[id].Equal(expireAt) {
+		t.Error("expected the expiration to be kept")
+	}
+
+	if err := cache.UpdateByID(uuid.New(), map[mframe.KeyName]interface{}{"status": "x"}); err == nil {
+		t.Error("expected error for a missing row")
+	}
+	if err := cache.UpdateByID(id, nil); err == nil {
+		t.Error("expected error for an empty patch")
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := cache.Touch(id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cache.ExpireAt[id].After(expireAt) {
+		t.Error("expected Touch to restart the expiration")
+	}
+	if err := cache.Touch(uuid.New()); err == nil {
+		t.Error("expected error for a missing row")
+	}
+}
+
+func TestUpdateBatch(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "a", "status": "open"},
+		{"host": "b", "status": "open"},
+	})
+
+	updates := make(map[uuid.UUID]mframe.Row)
+	for id, row := range cache.Data {
+		updates[id] = mframe.Row{"host": row["host"], "status": "closed"}
+	}
+	updates[uuid.New()] = mframe.Row{"host": "c"}
+
+	if updated := cache.UpdateBatch(updates); updated != 2 {
+		t.Errorf("expected 2 updated rows, but got %d", updated)
+	}
+	if count := cache.CountWhere(mframe.Equals, "status", "closed", nil); count != 2 {
+		t.Errorf("expected 2 closed rows, but got %d", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "status", "open", nil); count != 0 {
+		t.Errorf("expected no open rows, but got %d", count)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected unknown ids to be ignored, but got %d rows", cache.Count())
+	}
+}
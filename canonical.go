@@ -0,0 +1,137 @@
+package mframe
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CanonicalRow encodes a row in a canonical form suitable for digital signatures and reproducible checksums:
+// a JSON object without whitespace whose keys are sorted by their bytes, with strings escaped only where
+// JSON requires it, integral numbers up to 2^53 written without fraction or exponent, other numbers in their
+// shortest exact form and times as RFC 3339 strings in UTC with nanoseconds. Equal rows always have the
+// same encoding. Returns an error for values with no canonical form, such as NaN or infinite numbers.
+func CanonicalRow(row Row) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonicalRow(&buf, row); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CanonicalRow returns the canonical encoding of the row with the specified ID, see CanonicalRow.
+func (d *DataFrame) CanonicalRow(id uuid.UUID) ([]byte, error) {
+	row, ok := d.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("row '%s' not found", id)
+	}
+	return CanonicalRow(row)
+}
+
+// Canonical encodes the content of the DataFrame in a canonical form: a JSON array with the canonical
+// encoding of every row, see CanonicalRow, sorted by that encoding. Row IDs and expirations are not part
+// of the content, so two frames holding the same rows have the same encoding.
+func (d *DataFrame) Canonical() ([]byte, error) {
+	rows := d.Rows()
+
+	encoded := make([][]byte, 0, len(rows))
+	for _, row := range rows {
+		data, err := CanonicalRow(row)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, data)
+	}
+
+	sort.Slice(encoded, func(i, j int) bool { return bytes.Compare(encoded[i], encoded[j]) < 0 })
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, data := range encoded {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+
+	return buf.Bytes(), nil
+}
+
+// Checksum returns the hex encoded SHA-256 of the canonical encoding of the DataFrame.
+func (d *DataFrame) Checksum() (string, error) {
+	data, err := d.Canonical()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// writeCanonicalRow writes the canonical encoding of a row.
+func writeCanonicalRow(buf *bytes.Buffer, row Row) error {
+	keys := make([]string, 0, len(row))
+	for k := range row {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalString(buf, k)
+		buf.WriteByte(':')
+		if err := writeCanonicalValue(buf, row[KeyName(k)]); err != nil {
+			return fmt.Errorf("cannot encode key '%s': %w", k, err)
+		}
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// writeCanonicalValue writes the canonical encoding of a single value.
+func writeCanonicalValue(buf *bytes.Buffer, value interface{}) error {
+	switch v := normalizeValue(value).(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		writeCanonicalString(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("number %v has no canonical form", v)
+		}
+		if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+			buf.WriteString(strconv.FormatInt(int64(v), 10))
+		} else {
+			buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	case time.Time:
+		writeCanonicalString(buf, v.UTC().Format(time.RFC3339Nano))
+	default:
+		return fmt.Errorf("value of type %T has no canonical form", value)
+	}
+	return nil
+}
+
+// writeCanonicalString writes a JSON string escaping only what JSON requires.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	// Encode terminates the value with a newline.
+	buf.Truncate(buf.Len() - 1)
+}
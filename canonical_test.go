@@ -0,0 +1,79 @@
+package mframe_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestCanonicalRow(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 600, time.FixedZone("X", 3600))
+
+	tests := []struct {
+		name     string
+		row      mframe.Row
+		expected string
+	}{
+		{"sorted keys", mframe.Row{"b": "x", "a": "y"}, `{"a":"y","b":"x"}`},
+		{"integral number", mframe.Row{"n": 443.0}, `{"n":443}`},
+		{"fraction", mframe.Row{"n": 0.1}, `{"n":0.1}`},
+		{"large number", mframe.Row{"n": 1e300}, `{"n":1e+300}`},
+		{"negative zero", mframe.Row{"n": math.Copysign(0, -1)}, `{"n":0}`},
+		{"int", mframe.Row{"n": 7}, `{"n":7}`},
+		{"time in utc", mframe.Row{"t": at}, `{"t":"2024-01-02T02:04:05.0000006Z"}`},
+		{"unescaped html", mframe.Row{"s": "<a&b>"}, `{"s":"<a&b>"}`},
+		{"escaped quote", mframe.Row{"s": "say \"hi\"\n"}, `{"s":"say \"hi\"\n"}`},
+		{"bool", mframe.Row{"ok": true}, `{"ok":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := mframe.CanonicalRow(tt.row)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %s, but got %s", tt.expected, data)
+			}
+		})
+	}
+
+	if _, err := mframe.CanonicalRow(mframe.Row{"n": math.NaN()}); err == nil {
+		t.Error("expected error for NaN")
+	}
+}
+
+func TestCanonicalFrame(t *testing.T) {
+	var a, b mframe.DataFrame
+	a.Init(24 * time.Hour)
+	b.Init(time.Hour)
+
+	a.Insert(map[mframe.KeyName]interface{}{"host": "web", "port": 443})
+	a.Insert(map[mframe.KeyName]interface{}{"host": "db", "port": 5432})
+	b.Insert(map[mframe.KeyName]interface{}{"port": 5432, "host": "db"})
+	b.Insert(map[mframe.KeyName]interface{}{"port": 443, "host": "web"})
+
+	data, err := a.Canonical()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `[{"host":"db","port":5432},{"host":"web","port":443}]` {
+		t.Errorf("unexpected canonical encoding %s", data)
+	}
+
+	sumA, err := a.Checksum()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sumB, _ := b.Checksum()
+	if sumA != sumB {
+		t.Errorf("expected frames with the same rows to have the same checksum, but got %s and %s", sumA, sumB)
+	}
+
+	b.Insert(map[mframe.KeyName]interface{}{"host": "dns"})
+	if sumC, _ := b.Checksum(); sumC == sumA {
+		t.Error("expected the checksum to change with the content")
+	}
+}
@@ -0,0 +1,251 @@
+package mframe
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// CBOR major types.
+const (
+	cborUnsigned = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+	cborTag      = 6
+	cborSimple   = 7
+)
+
+// CBOR tags of date/time values.
+const (
+	cborTagDateTime = 0
+	cborTagEpoch    = 1
+)
+
+// cborEncoder writes CBOR values with definite lengths.
+type cborEncoder struct {
+	w *bufio.Writer
+}
+
+func (e cborEncoder) writeNil() {
+	_ = e.w.WriteByte(cborSimple<<5 | 22)
+}
+
+func (e cborEncoder) writeBool(v bool) {
+	if v {
+		_ = e.w.WriteByte(cborSimple<<5 | 21)
+	} else {
+		_ = e.w.WriteByte(cborSimple<<5 | 20)
+	}
+}
+
+func (e cborEncoder) writeInt(v int64) {
+	if v < 0 {
+		e.head(cborNegative, uint64(-(v + 1)))
+	} else {
+		e.head(cborUnsigned, uint64(v))
+	}
+}
+
+func (e cborEncoder) writeFloat(v float64) {
+	_ = e.w.WriteByte(cborSimple<<5 | 27)
+	_, _ = e.w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+}
+
+func (e cborEncoder) writeString(v string) {
+	e.head(cborText, uint64(len(v)))
+	_, _ = e.w.WriteString(v)
+}
+
+func (e cborEncoder) writeBytes(v []byte) {
+	e.head(cborBytes, uint64(len(v)))
+	_, _ = e.w.Write(v)
+}
+
+// writeTime writes a tag 0 RFC 3339 string, keeping the nanoseconds and the offset of the time.
+func (e cborEncoder) writeTime(v time.Time) {
+	e.head(cborTag, cborTagDateTime)
+	e.writeString(v.Format(time.RFC3339Nano))
+}
+
+func (e cborEncoder) writeArrayHeader(n int) {
+	e.head(cborArray, uint64(n))
+}
+
+func (e cborEncoder) writeMapHeader(n int) {
+	e.head(cborMap, uint64(n))
+}
+
+// head writes the initial byte of a major type with its argument in the shortest form.
+func (e cborEncoder) head(major byte, v uint64) {
+	switch {
+	case v < 24:
+		_ = e.w.WriteByte(major<<5 | byte(v))
+	case v <= math.MaxUint8:
+		_ = e.w.WriteByte(major<<5 | 24)
+		_ = e.w.WriteByte(byte(v))
+	case v <= math.MaxUint16:
+		_ = e.w.WriteByte(major<<5 | 25)
+		_, _ = e.w.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v <= math.MaxUint32:
+		_ = e.w.WriteByte(major<<5 | 26)
+		_, _ = e.w.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	default:
+		_ = e.w.WriteByte(major<<5 | 27)
+		_, _ = e.w.Write(binary.BigEndian.AppendUint64(nil, v))
+	}
+}
+
+// cborDecoder reads CBOR values with definite lengths.
+type cborDecoder struct {
+	r *bufio.Reader
+}
+
+func (d cborDecoder) token() (codecToken, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return codecToken{}, unexpectedEOF(err)
+	}
+	major, info := b>>5, b&0x1f
+
+	if major == cborSimple {
+		return d.simple(info)
+	}
+
+	v, err := d.argument(info)
+	if err != nil {
+		return codecToken{}, err
+	}
+
+	switch major {
+	case cborUnsigned:
+		if v > math.MaxInt64 {
+			return codecToken{value: float64(v)}, nil
+		}
+		return codecToken{value: int64(v)}, nil
+	case cborNegative:
+		if v > math.MaxInt64 {
+			return codecToken{value: -1 - float64(v)}, nil
+		}
+		return codecToken{value: -1 - int64(v)}, nil
+	case cborBytes:
+		data, err := readCodecBytes(d.r, v)
+		return codecToken{value: data}, err
+	case cborText:
+		data, err := readCodecBytes(d.r, v)
+		return codecToken{value: string(data)}, err
+	case cborArray:
+		n, err := codecLength(v)
+		return codecToken{kind: codecArray, n: n}, err
+	case cborMap:
+		n, err := codecLength(v)
+		return codecToken{kind: codecMap, n: n}, err
+	default:
+		return d.tagged(v)
+	}
+}
+
+// argument reads the argument of an initial byte with the given additional information.
+func (d cborDecoder) argument(info byte) (uint64, error) {
+	if info < 24 {
+		return uint64(info), nil
+	}
+	if info > 27 {
+		return 0, fmt.Errorf("unsupported CBOR additional information %d", info)
+	}
+
+	var v uint64
+	for i := 0; i < 1<<(info-24); i++ {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// simple reads the simple values and floats of major type 7.
+func (d cborDecoder) simple(info byte) (codecToken, error) {
+	switch info {
+	case 20:
+		return codecToken{value: false}, nil
+	case 21:
+		return codecToken{value: true}, nil
+	case 22, 23:
+		return codecToken{}, nil
+	case 25:
+		bits, err := d.argument(info)
+		return codecToken{value: halfFloat(uint16(bits))}, err
+	case 26:
+		bits, err := d.argument(info)
+		return codecToken{value: float64(math.Float32frombits(uint32(bits)))}, err
+	case 27:
+		bits, err := d.argument(info)
+		return codecToken{value: math.Float64frombits(bits)}, err
+	default:
+		return codecToken{}, fmt.Errorf("unsupported CBOR simple value %d", info)
+	}
+}
+
+// tagged reads the value of a tag, converting date/time tags to time.Time and ignoring other tags.
+func (d cborDecoder) tagged(tag uint64) (codecToken, error) {
+	t, err := d.token()
+	if err != nil {
+		return codecToken{}, err
+	}
+
+	switch tag {
+	case cborTagDateTime:
+		s, ok := t.value.(string)
+		if !ok {
+			return codecToken{}, fmt.Errorf("invalid CBOR date/time %v", t.value)
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return codecToken{}, fmt.Errorf("invalid CBOR date/time: %w", err)
+		}
+		return codecToken{value: parsed}, nil
+	case cborTagEpoch:
+		switch v := t.value.(type) {
+		case int64:
+			return codecToken{value: time.Unix(v, 0).UTC()}, nil
+		case float64:
+			seconds, fraction := math.Modf(v)
+			return codecToken{value: time.Unix(int64(seconds), int64(fraction*1e9)).UTC()}, nil
+		default:
+			return codecToken{}, fmt.Errorf("invalid CBOR epoch time %v", t.value)
+		}
+	default:
+		return t, nil
+	}
+}
+
+// halfFloat converts an IEEE 754 half-precision float.
+func halfFloat(bits uint16) float64 {
+	exponent := int(bits>>10) & 0x1f
+	mantissa := float64(bits & 0x3ff)
+
+	var v float64
+	switch exponent {
+	case 0:
+		v = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mantissa+1024, exponent-25)
+	}
+
+	if bits&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
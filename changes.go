@@ -0,0 +1,94 @@
+package mframe
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DefaultChangeLogSize is the number of row changes remembered for ChangedSince.
+const DefaultChangeLogSize = 65536
+
+// change records the generation at which a row was inserted, updated or removed.
+type change struct {
+	generation uint64
+	id         uuid.UUID
+}
+
+// changeLog remembers the most recent row changes.
+type changeLog struct {
+	generation uint64
+	entries    []change
+	// floor is the newest generation no longer covered by the entries.
+	floor uint64
+}
+
+// Generation returns the modification counter of the DataFrame. It increases every time a row is
+// inserted, updated or removed, so a cache layered above the DataFrame can cheaply check whether
+// anything changed since it last read it.
+func (d *DataFrame) Generation() uint64 {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return d.changes.generation
+}
+
+// ChangedSince returns the IDs of the rows inserted, updated or removed after the given generation,
+// in the order of their latest change, along with the current generation. Returns an error if the
+// changes since that generation are no longer remembered, in which case the caller should reload
+// everything.
+func (d *DataFrame) ChangedSince(generation uint64) ([]uuid.UUID, uint64, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	ids, err := d.changedSinceUnlocked(generation)
+	return ids, d.changes.generation, err
+}
+
+// changedSinceUnlocked returns the IDs of the rows changed after the given generation like ChangedSince,
+// without acquiring locks.
+func (d *DataFrame) changedSinceUnlocked(generation uint64) ([]uuid.UUID, error) {
+	changes := &d.changes
+	if generation < changes.floor {
+		return nil, fmt.Errorf("changes since generation %d are no longer available, oldest is %d", generation, changes.floor)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	result := make([]uuid.UUID, 0)
+	for i := len(changes.entries) - 1; i >= 0 && changes.entries[i].generation > generation; i-- {
+		id := changes.entries[i].id
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result, nil
+}
+
+// forgetChangesUnlocked increases the generation and forgets every change, after the rows of the DataFrame
+// are replaced wholesale, without acquiring locks.
+func (d *DataFrame) forgetChangesUnlocked() {
+	changes := &d.changes
+	changes.generation++
+	changes.entries = nil
+	changes.floor = changes.generation
+}
+
+// markChangedUnlocked records a change of the row and increases the generation, without acquiring locks.
+// Once the log is full, its oldest half is forgotten.
+func (d *DataFrame) markChangedUnlocked(id uuid.UUID) {
+	changes := &d.changes
+	changes.generation++
+	changes.entries = append(changes.entries, change{generation: changes.generation, id: id})
+
+	if len(changes.entries) > DefaultChangeLogSize {
+		drop := len(changes.entries) / 2
+		changes.floor = changes.entries[drop-1].generation
+		changes.entries = append(changes.entries[:0:0], changes.entries[drop:]...)
+	}
+}
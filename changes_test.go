@@ -0,0 +1,80 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestChangedSince(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	if cache.Generation() != 0 {
+		t.Errorf("expected generation 0, but got %d", cache.Generation())
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "a"})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "b"})
+	start := cache.Generation()
+	if start != 2 {
+		t.Errorf("expected generation 2, but got %d", start)
+	}
+
+	if ids, generation, err := cache.ChangedSince(start); err != nil || len(ids) != 0 || generation != start {
+		t.Errorf("expected no changes, but got %v, %d, %v", ids, generation, err)
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "c"})
+	if _, err := cache.UpdateWhere(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "host", Value: "a"},
+	}}, map[mframe.KeyName]interface{}{"status": "seen"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var removed uuid.UUID
+	for id := range cache.Filter(mframe.Equals, "host", "b", nil).Data {
+		removed = id
+	}
+	cache.DeleteWhere(mframe.Equals, "host", "b", nil)
+
+	ids, generation, err := cache.ChangedSince(start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 3 {
+		t.Fatalf("expected 3 changed rows, but got %v", ids)
+	}
+	if generation != cache.Generation() || generation <= start {
+		t.Errorf("expected the current generation, but got %d", generation)
+	}
+	if ids[2] != removed {
+		t.Errorf("expected the last change to be the removed row %s, but got %s", removed, ids[2])
+	}
+
+	if ids, _, _ := cache.ChangedSince(generation - 1); len(ids) != 1 {
+		t.Errorf("expected 1 change since the previous generation, but got %v", ids)
+	}
+}
+
+func TestChangedSinceForgotten(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	rows := make([]map[mframe.KeyName]interface{}, mframe.DefaultChangeLogSize+1)
+	for i := range rows {
+		rows[i] = map[mframe.KeyName]interface{}{"seq": i}
+	}
+	if err := cache.InsertBatch(rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, _, err := cache.ChangedSince(0); err == nil {
+		t.Error("expected error for a forgotten generation")
+	}
+	if _, _, err := cache.ChangedSince(cache.Generation() - 10); err != nil {
+		t.Errorf("unexpected error for a recent generation: %v", err)
+	}
+}
@@ -1,111 +1,210 @@
 package mframe
 
 import (
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// CleanExpired removes elements from the DataFrame whose expiration time has passed. It runs continuously in a loop.
+// CleanerStatus reports the state of the background cleaner.
+type CleanerStatus struct {
+	Running bool
+	Paused  bool
+	// LastRun is the time of the last tick that looked for expired rows, zero if none did.
+	LastRun time.Time
+	// LastPurged is the number of rows removed by the last tick and Purged the total since the frame was created.
+	LastPurged int
+	Purged     int
+}
+
+// cleaner holds the state of the background cleaner goroutine.
+type cleaner struct {
+	mutex   sync.Mutex
+	running bool
+	paused  bool
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	lastRun time.Time
+	last    int
+	purged  int
+}
+
+// CleanExpired removes elements from the DataFrame whose expiration time has passed. It runs continuously in a loop
+// until StopCleaner is called, and returns immediately if the cleaner is already running.
+// Expirations are kept in a min-heap, so each tick only visits the expired rows. Expiration times must be
+// changed through the methods of the DataFrame, such as Touch, rather than by writing to ExpireAt.
 func (d *DataFrame) CleanExpired() {
+	if stop, done, ok := d.cleaner.start(); ok {
+		d.cleanLoop(stop, done)
+	}
+}
+
+// PauseCleaner suspends the removal of expired rows, e.g. during bulk loads, without stopping the cleaner.
+// Rows expiring meanwhile are removed on the first tick after ResumeCleaner. Pausing a cleaner that is not
+// running takes effect when it starts.
+func (d *DataFrame) PauseCleaner() {
+	d.cleaner.mutex.Lock()
+	defer d.cleaner.mutex.Unlock()
+	d.cleaner.paused = true
+}
+
+// ResumeCleaner resumes the removal of expired rows suspended by PauseCleaner.
+func (d *DataFrame) ResumeCleaner() {
+	d.cleaner.mutex.Lock()
+	defer d.cleaner.mutex.Unlock()
+	d.cleaner.paused = false
+}
+
+// CleanerStatus returns whether the cleaner is running or paused, when it last ran and how many rows it purged.
+func (d *DataFrame) CleanerStatus() CleanerStatus {
+	d.cleaner.mutex.Lock()
+	defer d.cleaner.mutex.Unlock()
+
+	return CleanerStatus{
+		Running:    d.cleaner.running,
+		Paused:     d.cleaner.paused,
+		LastRun:    d.cleaner.lastRun,
+		LastPurged: d.cleaner.last,
+		Purged:     d.cleaner.purged,
+	}
+}
+
+// cleanLoop removes expired rows every second until stop is closed, then closes done.
+func (d *DataFrame) cleanLoop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-d.stopCleaner:
+		case <-stop:
 			return
 		case <-ticker.C:
-			now := time.Now().UTC()
+			if d.cleaner.isPaused() {
+				continue
+			}
 
-			toRemove := make([]uuid.UUID, 0)
+			now := time.Now().UTC()
 
 			d.Locker.RLock()
-			for k, v := range d.ExpireAt {
-				if v.Before(now) {
-					toRemove = append(toRemove, k)
-				}
-			}
+			expired := d.hasExpiredUnlocked(now)
 			d.Locker.RUnlock()
 
-			for _, id := range toRemove {
-				d.RemoveElement(id)
+			purged := 0
+			if expired {
+				d.Locker.Lock()
+				purged = d.removeExpiredUnlocked(now)
+				d.unlockAndNotify()
 			}
+
+			d.cleaner.record(now, purged)
 		}
 	}
 }
 
+// start marks the cleaner as running and returns the channels of the new goroutine,
+// or false if it is already running.
+func (c *cleaner) start() (chan struct{}, chan struct{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.running {
+		return nil, nil, false
+	}
+
+	c.running = true
+	c.stopCh = make(chan struct{})
+	c.doneCh = make(chan struct{})
+
+	return c.stopCh, c.doneCh, true
+}
+
+// stop signals the cleaner goroutine to exit and waits for it, reporting whether it was running.
+func (c *cleaner) stop() bool {
+	c.mutex.Lock()
+	if !c.running {
+		c.mutex.Unlock()
+		return false
+	}
+	c.running = false
+	close(c.stopCh)
+	done := c.doneCh
+	c.mutex.Unlock()
+
+	<-done
+	return true
+}
+
+// isPaused reports whether the cleaner is paused.
+func (c *cleaner) isPaused() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.paused
+}
+
+// record stores the result of a tick.
+func (c *cleaner) record(at time.Time, purged int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.lastRun = at
+	c.last = purged
+	c.purged += purged
+}
+
 // RemoveElement removes the element with the specified UUID from all internal data structures in the DataFrame.
 func (d *DataFrame) RemoveElement(id uuid.UUID) {
 	d.Locker.Lock()
-	defer d.Locker.Unlock()
-
-	delete(d.ExpireAt, id)
-	delete(d.Data, id)
+	d.evictElementUnlocked(id, EvictRemoved)
+	d.unlockAndNotify()
+}
 
-	// Track which keys are still in use
-	keysInUse := make(map[KeyName]bool)
+// RemoveElements removes the elements with the specified UUIDs under a single lock and returns the
+// number of removed elements. Unknown IDs are ignored.
+func (d *DataFrame) RemoveElements(ids []uuid.UUID) int {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
 
-	for k1, v1 := range d.Strings {
-		for k2, v2 := range v1 {
-			delete(v2, id)
-			if len(v2) == 0 {
-				delete(v1, k2)
-			}
-		}
-		if len(v1) == 0 {
-			delete(d.Strings, k1)
-		} else {
-			keysInUse[k1] = true
+	removed := 0
+	for _, id := range ids {
+		if _, ok := d.Data[id]; !ok {
+			continue
 		}
+		d.evictElementUnlocked(id, EvictRemoved)
+		removed++
 	}
 
-	for k1, v1 := range d.Numerics {
-		for k2, v2 := range v1 {
-			delete(v2, id)
-			if len(v2) == 0 {
-				delete(v1, k2)
-			}
-		}
-		if len(v1) == 0 {
-			delete(d.Numerics, k1)
-		} else {
-			keysInUse[k1] = true
-		}
-	}
+	return removed
+}
 
-	for k1, v1 := range d.Booleans {
-		for k2, v2 := range v1 {
-			delete(v2, id)
-			if len(v2) == 0 {
-				delete(v1, k2)
-			}
-		}
-		if len(v1) == 0 {
-			delete(d.Booleans, k1)
-		} else {
-			keysInUse[k1] = true
-		}
-	}
+// DeleteWhere removes every row matching the filter, using the same arguments as Filter, and returns
+// the number of removed rows. The rows are found through the indexes and removed under a single lock.
+func (d *DataFrame) DeleteWhere(operator Operator, key KeyName, value any, options map[FilterOption]bool) int {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
 
-	for k1, v1 := range d.Times {
-		for k2, v2 := range v1 {
-			delete(v2, id)
-			if len(v2) == 0 {
-				delete(v1, k2)
-			}
-		}
-		if len(v1) == 0 {
-			delete(d.Times, k1)
-		} else {
-			keysInUse[k1] = true
-		}
+	ids := d.idsUnlocked(Condition{Operator: operator, Key: key, Value: value, Options: options})
+	for id := range ids {
+		d.evictElementUnlocked(id, EvictRemoved)
 	}
 
-	// Clean up Keys index for keys that are no longer in use
-	for key := range d.Keys {
-		if !keysInUse[key] {
-			delete(d.Keys, key)
-		}
+	return len(ids)
+}
+
+// removeElementUnlocked removes the element with the specified UUID without acquiring locks.
+// Only the index entries of the values held by the row are visited, and keys left without values are removed.
+func (d *DataFrame) removeElementUnlocked(id uuid.UUID) {
+	if _, ok := d.Data[id]; !ok {
+		return
 	}
+
+	d.unindexRowUnlocked(id)
+	d.forgetMismatchesUnlocked(id)
+	d.untagRowUnlocked(id)
+	d.order.remove(id)
+	d.recency.remove(id)
+	delete(d.ExpireAt, id)
+	delete(d.Data, id)
+	d.markChangedUnlocked(id)
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/threatwinds/mframe"
 )
 
@@ -25,3 +27,101 @@ func TestCleanExpired(t *testing.T) {
 		t.Errorf("expected 1 rows, but got %d", len(df.Data))
 	}
 }
+
+func TestDeleteWhere(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"seq": i, "kind": []string{"noise", "signal"}[i%2]})
+	}
+
+	removed := cache.DeleteWhere(mframe.Equals, "kind", "noise", nil)
+	if removed != 5 {
+		t.Errorf("expected 5 removed rows, but got %d", removed)
+	}
+	if cache.Count() != 5 {
+		t.Errorf("expected 5 rows left, but got %d", cache.Count())
+	}
+	if count := cache.CountWhere(mframe.Equals, "kind", "noise", nil); count != 0 {
+		t.Errorf("expected no noise rows in the index, but got %d", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "seq", 0.0, nil); count != 0 {
+		t.Errorf("expected the values of removed rows to leave the index, but got %d", count)
+	}
+
+	if removed := cache.DeleteWhere(mframe.Greater, "seq", 100.0, nil); removed != 0 {
+		t.Errorf("expected no removed rows, but got %d", removed)
+	}
+
+	cache.DeleteWhere(mframe.Equals, "kind", "signal", nil)
+	if len(cache.Keys) != 0 || len(cache.Strings) != 0 || len(cache.Numerics) != 0 {
+		t.Errorf("expected empty indexes, but got keys %v", cache.Keys)
+	}
+}
+
+func TestRemoveElements(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 5; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"seq": i})
+	}
+
+	ids := make([]uuid.UUID, 0)
+	for id, row := range cache.Data {
+		if row["seq"].(float64) < 3 {
+			ids = append(ids, id)
+		}
+	}
+	ids = append(ids, uuid.New())
+
+	if removed := cache.RemoveElements(ids); removed != 3 {
+		t.Errorf("expected 3 removed rows, but got %d", removed)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 rows left, but got %d", cache.Count())
+	}
+	if count := cache.CountWhere(mframe.Less, "seq", 3.0, nil); count != 0 {
+		t.Errorf("expected removed rows to leave the index, but got %d", count)
+	}
+}
+
+func TestPauseResumeCleaner(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(500 * time.Millisecond)
+
+	// Stopping a cleaner that was never started must not block.
+	cache.StopCleaner()
+	if status := cache.CleanerStatus(); status.Running {
+		t.Fatalf("expected the cleaner not to be running, but got %+v", status)
+	}
+
+	cache.StartCleaner()
+	cache.StartCleaner()
+	defer cache.StopCleaner()
+
+	cache.PauseCleaner()
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{{"seq": 1}, {"seq": 2}})
+	time.Sleep(1500 * time.Millisecond)
+
+	if cache.Count() != 2 {
+		t.Errorf("expected no rows purged while paused, but got %d rows", cache.Count())
+	}
+	if status := cache.CleanerStatus(); !status.Running || !status.Paused || !status.LastRun.IsZero() {
+		t.Errorf("expected a paused cleaner that never ran, but got %+v", status)
+	}
+
+	cache.ResumeCleaner()
+	time.Sleep(1100 * time.Millisecond)
+
+	status := cache.CleanerStatus()
+	if cache.Count() != 0 || status.Purged != 2 || status.Paused || status.LastRun.IsZero() {
+		t.Errorf("expected 2 rows purged after resuming, but got %d rows and %+v", cache.Count(), status)
+	}
+
+	cache.StopCleaner()
+	if status := cache.CleanerStatus(); status.Running {
+		t.Errorf("expected the cleaner to be stopped, but got %+v", status)
+	}
+}
@@ -0,0 +1,455 @@
+package mframe
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Codec identifies the serialization format of the files written by SaveToFileWithCodec.
+type Codec int
+
+const (
+	// CodecGob writes the same files as SaveToFile.
+	CodecGob Codec = 1
+	// CodecMsgpack writes MessagePack, readable from any language with a MessagePack library.
+	CodecMsgpack Codec = 2
+	// CodecCBOR writes CBOR (RFC 8949), readable from any language with a CBOR library.
+	CodecCBOR Codec = 3
+)
+
+// codecMaxDepth bounds the nesting of the values read from MessagePack and CBOR files.
+const codecMaxDepth = 64
+
+// String returns the name of the codec.
+func (c Codec) String() string {
+	switch c {
+	case CodecGob:
+		return "gob"
+	case CodecMsgpack:
+		return "msgpack"
+	case CodecCBOR:
+		return "cbor"
+	default:
+		return "unknown"
+	}
+}
+
+// codecEncoder writes the values of a document. Write errors are kept by the underlying bufio.Writer and
+// reported when it is flushed.
+type codecEncoder interface {
+	writeNil()
+	writeBool(v bool)
+	writeInt(v int64)
+	writeFloat(v float64)
+	writeString(v string)
+	writeBytes(v []byte)
+	writeTime(v time.Time)
+	writeArrayHeader(n int)
+	writeMapHeader(n int)
+}
+
+// codecTokenKind distinguishes the tokens read by a codecDecoder.
+type codecTokenKind int
+
+const (
+	codecScalar codecTokenKind = iota
+	codecArray
+	codecMap
+)
+
+// codecToken is a scalar value or the header of an array or map of n elements.
+type codecToken struct {
+	kind  codecTokenKind
+	n     int
+	value interface{}
+}
+
+// codecDecoder reads the tokens of a document. Integers are returned as int64, or float64 when they
+// overflow it.
+type codecDecoder interface {
+	token() (codecToken, error)
+}
+
+// newCodecEncoder returns the encoder of a codec writing to w.
+func newCodecEncoder(codec Codec, w *bufio.Writer) (codecEncoder, error) {
+	switch codec {
+	case CodecMsgpack:
+		return msgpackEncoder{w}, nil
+	case CodecCBOR:
+		return cborEncoder{w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported codec %s", codec)
+	}
+}
+
+// newCodecDecoder returns the decoder of a codec reading from r.
+func newCodecDecoder(codec Codec, r *bufio.Reader) (codecDecoder, error) {
+	switch codec {
+	case CodecMsgpack:
+		return msgpackDecoder{r}, nil
+	case CodecCBOR:
+		return cborDecoder{r}, nil
+	default:
+		return nil, fmt.Errorf("unsupported codec %s", codec)
+	}
+}
+
+// SaveToFileWithCodec saves the DataFrame to a file using the given codec, performing an atomic write.
+// MessagePack and CBOR files hold a map with the version, the TTL in nanoseconds, the type of each key and
+// the rows, each one an array of its ID, its expiration time or nil, and its data. Indexes are rebuilt on load.
+func (d *DataFrame) SaveToFileWithCodec(filename string, codec Codec) error {
+	if codec == CodecGob {
+		return d.SaveToFile(filename)
+	}
+
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	// Create a temporary file in the same directory for atomic writing
+	dir := filepath.Dir(filename)
+	tmpFile, err := os.CreateTemp(dir, ".tmp-mframe-*."+codec.String())
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	writer := bufio.NewWriter(tmpFile)
+	encoder, err := newCodecEncoder(codec, writer)
+	if err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err := d.encodeFrameUnlocked(encoder); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to encode dataframe: %w", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write to temporary file: %w", err)
+	}
+
+	// Close the temporary file
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	// Atomic rename
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadFromFileWithCodec loads a DataFrame from a file written with the given codec, replacing its rows.
+func (d *DataFrame) LoadFromFileWithCodec(filename string, codec Codec) error {
+	if codec == CodecGob {
+		return d.LoadFromFile(filename)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	decoder, err := newCodecDecoder(codec, bufio.NewReader(file))
+	if err != nil {
+		return err
+	}
+
+	// Stop the cleaner while loading, restarting it once the lock is released
+	if d.cleaner.stop() {
+		defer d.StartCleaner()
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	stage, err := d.decodeFrameUnlocked(decoder)
+	if err != nil {
+		return fmt.Errorf("failed to decode dataframe: %w", err)
+	}
+
+	d.commitImportUnlocked(stage)
+
+	return nil
+}
+
+// encodeFrameUnlocked writes the DataFrame as a document, without acquiring locks.
+func (d *DataFrame) encodeFrameUnlocked(e codecEncoder) error {
+	e.writeMapHeader(4)
+
+	e.writeString("version")
+	e.writeInt(int64(d.Version))
+
+	e.writeString("ttl")
+	e.writeInt(int64(d.TTL))
+
+	e.writeString("keys")
+	e.writeMapHeader(len(d.Keys))
+	for key, keyType := range d.Keys {
+		e.writeString(string(key))
+		e.writeInt(int64(keyType))
+	}
+
+	e.writeString("rows")
+	e.writeArrayHeader(len(d.Data))
+	for id, row := range d.Data {
+		e.writeArrayHeader(3)
+
+		idBytes := id
+		e.writeBytes(idBytes[:])
+
+		if expireAt, ok := d.ExpireAt[id]; ok {
+			e.writeTime(expireAt)
+		} else {
+			e.writeNil()
+		}
+
+		e.writeMapHeader(len(row))
+		for key, value := range row {
+			e.writeString(string(key))
+			if err := writeCodecValue(e, value); err != nil {
+				return fmt.Errorf("row %s, key '%s': %w", id, key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeCodecValue writes a value stored in a row.
+func writeCodecValue(e codecEncoder, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		e.writeNil()
+	case string:
+		e.writeString(v)
+	case float64:
+		e.writeFloat(v)
+	case bool:
+		e.writeBool(v)
+	case time.Time:
+		e.writeTime(v)
+	case uuid.UUID:
+		e.writeString(v.String())
+	default:
+		return fmt.Errorf("unsupported value type %T", value)
+	}
+	return nil
+}
+
+// decodeFrameUnlocked reads a document written by encodeFrameUnlocked into a DataFrame staged with
+// importStageUnlocked, without acquiring locks. The version, TTL and keys must come before the rows.
+func (d *DataFrame) decodeFrameUnlocked(dec codecDecoder) (*DataFrame, error) {
+	header, err := dec.token()
+	if err != nil {
+		return nil, err
+	}
+	if header.kind != codecMap {
+		return nil, fmt.Errorf("expected a map at the top level")
+	}
+
+	ttl := d.TTL
+	keys := make(map[string]int)
+	var stage *DataFrame
+
+	for i := 0; i < header.n; i++ {
+		name, err := readCodecValue(dec, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case "rows":
+			stage = d.importStageUnlocked(ttl, keys)
+			if err := stage.decodeRowsUnlocked(dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		value, err := readCodecValue(dec, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		switch name {
+		case "version":
+			version, ok := value.(int64)
+			if !ok {
+				return nil, fmt.Errorf("invalid version %v", value)
+			}
+			if int(version) > d.Version {
+				return nil, fmt.Errorf("unsupported file version %d (current version is %d)", version, d.Version)
+			}
+		case "ttl":
+			nanos, ok := value.(int64)
+			if !ok {
+				return nil, fmt.Errorf("invalid TTL %v", value)
+			}
+			ttl = time.Duration(nanos)
+		case "keys":
+			types, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid keys %v", value)
+			}
+			for key, keyType := range types {
+				n, ok := keyType.(int64)
+				if !ok {
+					return nil, fmt.Errorf("invalid type %v for key '%s'", keyType, key)
+				}
+				keys[key] = int(n)
+			}
+		}
+	}
+
+	if stage == nil {
+		stage = d.importStageUnlocked(ttl, keys)
+	}
+
+	return stage, nil
+}
+
+// decodeRowsUnlocked reads the array of rows of a document and indexes them, without acquiring locks.
+func (d *DataFrame) decodeRowsUnlocked(dec codecDecoder) error {
+	header, err := dec.token()
+	if err != nil {
+		return err
+	}
+	if header.kind != codecArray {
+		return fmt.Errorf("expected an array of rows")
+	}
+
+	for i := 0; i < header.n; i++ {
+		value, err := readCodecValue(dec, 0)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+
+		entry, ok := value.([]interface{})
+		if !ok || len(entry) != 3 {
+			return fmt.Errorf("row %d: expected an array of ID, expiration and data", i)
+		}
+
+		var id uuid.UUID
+		switch v := entry[0].(type) {
+		case []byte:
+			id, err = uuid.FromBytes(v)
+		case string:
+			id, err = uuid.Parse(v)
+		default:
+			err = fmt.Errorf("invalid ID %v", v)
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+
+		data, ok := entry[2].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("row %d: expected a map of data", i)
+		}
+
+		d.Data[id] = d.restoreRowUnlocked(id, data)
+
+		if expireAt, ok := entry[1].(time.Time); ok {
+			d.ExpireAt[id] = expireAt
+		}
+	}
+
+	return nil
+}
+
+// readCodecValue reads a whole value, building maps with string keys and slices for arrays.
+func readCodecValue(dec codecDecoder, depth int) (interface{}, error) {
+	if depth > codecMaxDepth {
+		return nil, fmt.Errorf("values nested deeper than %d levels", codecMaxDepth)
+	}
+
+	t, err := dec.token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.kind {
+	case codecArray:
+		values := make([]interface{}, 0, min(t.n, 1024))
+		for i := 0; i < t.n; i++ {
+			value, err := readCodecValue(dec, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case codecMap:
+		values := make(map[string]interface{}, min(t.n, 1024))
+		for i := 0; i < t.n; i++ {
+			key, err := readCodecValue(dec, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported map key %v", key)
+			}
+			value, err := readCodecValue(dec, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			values[name] = value
+		}
+		return values, nil
+	default:
+		return t.value, nil
+	}
+}
+
+// readCodecBytes reads n bytes, growing the buffer as the data arrives so corrupt lengths do not allocate
+// large buffers up front.
+func readCodecBytes(r io.Reader, n uint64) ([]byte, error) {
+	if n <= 64*1024 {
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return buf, nil
+	}
+
+	var buf bytes.Buffer
+	copied, err := io.CopyN(&buf, r, int64(min(n, 1<<62)))
+	if uint64(copied) != n {
+		return nil, unexpectedEOF(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// codecLength converts a length read from a file, rejecting lengths that do not fit in an int.
+func codecLength(n uint64) (int, error) {
+	if n > uint64(maxInt) {
+		return 0, fmt.Errorf("length %d out of range", n)
+	}
+	return int(n), nil
+}
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// unexpectedEOF reports a truncated file as io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == nil || err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
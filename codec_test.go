@@ -0,0 +1,148 @@
+package mframe_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestDataFrame_SaveLoadWithCodec(t *testing.T) {
+	codecs := []mframe.Codec{mframe.CodecGob, mframe.CodecMsgpack, mframe.CodecCBOR}
+
+	for _, codec := range codecs {
+		t.Run(codec.String(), func(t *testing.T) {
+			df := &mframe.DataFrame{}
+			df.Init(time.Hour)
+
+			created := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+			df.InsertBatch([]map[mframe.KeyName]interface{}{
+				{"name": "Alice", "age": float64(30), "active": true, "created": created},
+				{"name": "Bob", "age": -2.5, "nested": map[string]interface{}{"city": "New York"}},
+			})
+
+			filename := filepath.Join(t.TempDir(), "frame."+codec.String())
+			if err := df.SaveToFileWithCodec(filename, codec); err != nil {
+				t.Fatalf("Failed to save with %s: %v", codec, err)
+			}
+
+			df2 := &mframe.DataFrame{}
+			df2.Init(24 * time.Hour)
+			if err := df2.LoadFromFileWithCodec(filename, codec); err != nil {
+				t.Fatalf("Failed to load with %s: %v", codec, err)
+			}
+
+			if df2.TTL != time.Hour {
+				t.Errorf("expected TTL %v, but got %v", time.Hour, df2.TTL)
+			}
+			if len(df2.Data) != len(df.Data) {
+				t.Fatalf("expected %d rows, but got %d", len(df.Data), len(df2.Data))
+			}
+
+			for id, row := range df.Data {
+				for key, value := range row {
+					if df2.Data[id][key] != value {
+						t.Errorf("expected %s to be %v, but got %v", key, value, df2.Data[id][key])
+					}
+				}
+				if !df2.ExpireAt[id].Equal(df.ExpireAt[id]) {
+					t.Errorf("expected expiration %v, but got %v", df.ExpireAt[id], df2.ExpireAt[id])
+				}
+			}
+
+			if df2.Filter(mframe.Between, "created", []time.Time{created, created}, nil).Count() != 1 {
+				t.Error("expected the time index to be rebuilt")
+			}
+			if df2.Filter(mframe.Equals, "nested.city", "New York", nil).Count() != 1 {
+				t.Error("expected the string index to be rebuilt")
+			}
+		})
+	}
+}
+
+func TestDataFrame_LoadWithCodecForeignFile(t *testing.T) {
+	id := uuid.New()
+
+	// A CBOR map written by another library: keys before rows, a string ID, no expiration and a tag 1 time.
+	var data []byte
+	data = append(data, 0xa2)
+	data = append(data, 0x64)
+	data = append(data, "keys"...)
+	data = append(data, 0xa1, 0x64)
+	data = append(data, "seen"...)
+	data = append(data, 0x04)
+	data = append(data, 0x64)
+	data = append(data, "rows"...)
+	data = append(data, 0x81, 0x83, 0x78, 0x24)
+	data = append(data, id.String()...)
+	data = append(data, 0xf6, 0xa2, 0x64)
+	data = append(data, "seen"...)
+	data = append(data, 0xc1, 0x1a, 0x65, 0x92, 0x00, 0x80)
+	data = append(data, 0x64)
+	data = append(data, "size"...)
+	data = append(data, 0xf9, 0x3e, 0x00)
+
+	filename := filepath.Join(t.TempDir(), "frame.cbor")
+	if err := os.WriteFile(filename, data, 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+	if err := df.LoadFromFileWithCodec(filename, mframe.CodecCBOR); err != nil {
+		t.Fatalf("Failed to load CBOR: %v", err)
+	}
+
+	row, ok := df.Data[id]
+	if !ok {
+		t.Fatalf("expected row %s to be loaded", id)
+	}
+	if seen := row["seen"]; seen != time.Unix(1704067200, 0).UTC() {
+		t.Errorf("expected seen to be 2024-01-01, but got %v", seen)
+	}
+	if size := row["size"]; size != 1.5 {
+		t.Errorf("expected size to be 1.5, but got %v", size)
+	}
+	if df.TTL != time.Hour {
+		t.Errorf("expected the TTL to be kept, but got %v", df.TTL)
+	}
+}
+
+func TestDataFrame_LoadWithCodecErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+	df.Insert(map[mframe.KeyName]interface{}{"name": "Alice"})
+
+	if err := df.SaveToFileWithCodec(filepath.Join(dir, "frame.bin"), mframe.Codec(99)); err == nil {
+		t.Error("expected an error for an unsupported codec")
+	}
+
+	filename := filepath.Join(dir, "frame.msgpack")
+	if err := df.SaveToFileWithCodec(filename, mframe.CodecMsgpack); err != nil {
+		t.Fatalf("Failed to save with msgpack: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	truncated := filepath.Join(dir, "truncated.msgpack")
+	if err := os.WriteFile(truncated, content[:len(content)-3], 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	df2 := &mframe.DataFrame{}
+	df2.Init(time.Hour)
+	if err := df2.LoadFromFileWithCodec(truncated, mframe.CodecMsgpack); err == nil {
+		t.Error("expected an error for a truncated file")
+	}
+	if err := df2.LoadFromFileWithCodec(filename, mframe.CodecCBOR); err == nil {
+		t.Error("expected an error for a file written with another codec")
+	}
+}
@@ -0,0 +1,158 @@
+package mframe
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NumericSourceSuffix is appended to a key name to form the key holding the original string of a value
+// parsed as a number, when CoercionOptions.KeepNumericStrings is set.
+const NumericSourceSuffix = "_raw"
+
+// CoercionOptions selects which string values are converted to the type of their key before indexing.
+// Coercion only applies to keys already mapped to the target type, by a previous value or by SetKeyType;
+// other strings are indexed as strings.
+type CoercionOptions struct {
+	// Booleans converts "true"/"false", "t"/"f" and "1"/"0", in any case and ignoring surrounding
+	// spaces, for keys mapped as Boolean.
+	Booleans bool
+	// Numerics parses decimal strings such as "42", "1e3" or " 3.14 " for keys mapped as Numeric.
+	// Strings holding NaN or infinite values are not parsed.
+	Numerics bool
+	// KeepNumericStrings also stores the original string of every parsed number under the key
+	// with NumericSourceSuffix, e.g. "bytes_raw", indexed as a string.
+	KeepNumericStrings bool
+	// Times parses strings for keys mapped as Time: RFC 3339 timestamps, the layouts registered with
+	// RegisterTimeLayout, the layouts in TimeLayouts and epoch seconds or milliseconds, told apart by magnitude.
+	// Parsed times are stored in UTC.
+	Times bool
+	// DetectTimes also maps keys without a type as Time when their first value is a string matching
+	// RFC 3339 or one of the registered layouts or TimeLayouts. Epoch numbers are never detected.
+	DetectTimes bool
+	// TimeLayouts are additional layouts for this DataFrame, in the format of time.Parse, tried in order
+	// after the registered layouts.
+	TimeLayouts []string
+}
+
+// epochMillisThreshold is the magnitude from which an epoch number is read as milliseconds rather than seconds.
+// As seconds it would be a date after the year 5000, and as milliseconds it is a date after March 1973.
+const epochMillisThreshold = 1e11
+
+// SetCoercion enables the conversions selected in options for values inserted from now on.
+// Coercion is disabled by default, so a string for a key of another type is dropped with a mapping error.
+func (d *DataFrame) SetCoercion(options CoercionOptions) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+	d.coercion = options
+}
+
+// SetKeyType maps key to keyType before any value is inserted for it, so later values are coerced to it
+// when enabled with SetCoercion. The declaration outlives the values of the key, so it still applies after
+// every row holding the key is removed or expires. Returns an error if key is already mapped to another type.
+func (d *DataFrame) SetKeyType(key KeyName, keyType KeyType) error {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	if err := d.addMapping(key, keyType); err != nil {
+		return err
+	}
+
+	if d.declared == nil {
+		d.declared = make(map[KeyName]KeyType)
+	}
+	d.declared[key] = keyType
+
+	return nil
+}
+
+// keyTypeUnlocked returns the type of the key, mapped by its values or declared with SetKeyType, and whether
+// it has one, without acquiring locks.
+func (d *DataFrame) keyTypeUnlocked(key KeyName) (KeyType, bool) {
+	if keyType, ok := d.Keys[key]; ok {
+		return keyType, true
+	}
+	keyType, ok := d.declared[key]
+	return keyType, ok
+}
+
+// coerceUnlocked converts a string value to the type of its key when coercion is enabled for that type,
+// returning the value unchanged otherwise, without acquiring locks.
+func (d *DataFrame) coerceUnlocked(key KeyName, value string) interface{} {
+	keyType, mapped := d.keyTypeUnlocked(key)
+	if !mapped {
+		if d.coercion.DetectTimes {
+			if t, ok := parseTime(value, d.coercion.TimeLayouts, false); ok {
+				return t
+			}
+		}
+		return value
+	}
+
+	switch keyType {
+	case Boolean:
+		if !d.coercion.Booleans {
+			return value
+		}
+		if b, err := strconv.ParseBool(strings.ToLower(strings.TrimSpace(value))); err == nil {
+			return b
+		}
+	case Numeric:
+		if !d.coercion.Numerics {
+			return value
+		}
+		if f, ok := parseFinite(value); ok {
+			return f
+		}
+	case Time:
+		if !d.coercion.Times {
+			return value
+		}
+		if t, ok := parseTime(value, d.coercion.TimeLayouts, true); ok {
+			return t
+		}
+	}
+	return value
+}
+
+// parseFinite parses a decimal string, ignoring surrounding spaces, rejecting NaN and infinite values.
+func parseFinite(value string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// parseTime parses a timestamp string as RFC 3339, one of the registered layouts or one of layouts and,
+// when epochs is set, as epoch seconds or milliseconds. The result is in UTC.
+func parseTime(value string, layouts []string, epochs bool) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+
+	timeLayouts.mutex.RLock()
+	registered := timeLayouts.layouts
+	timeLayouts.mutex.RUnlock()
+
+	for _, group := range [][]string{{time.RFC3339Nano}, registered, layouts} {
+		for _, layout := range group {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.UTC(), true
+			}
+		}
+	}
+
+	if !epochs {
+		return time.Time{}, false
+	}
+
+	f, ok := parseFinite(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	if math.Abs(f) >= epochMillisThreshold {
+		return time.UnixMilli(int64(f)).UTC(), true
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+}
@@ -0,0 +1,181 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestBooleanCoercion(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	if err := cache.SetKeyType("enabled", mframe.Boolean); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"enabled": "true"})
+	if cache.Count() != 1 || cache.CountWhere(mframe.Equals, "enabled", true, nil) != 0 {
+		t.Fatal("expected strings to be dropped while coercion is disabled")
+	}
+
+	cache.SetCoercion(mframe.CoercionOptions{Booleans: true})
+
+	tests := []struct {
+		value    string
+		expected interface{}
+	}{
+		{"true", true},
+		{"FALSE", false},
+		{" 1 ", true},
+		{"0", false},
+		{"maybe", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"enabled": tt.value})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			row, _ := cache.Get(id)
+			if row["enabled"] != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, row["enabled"])
+			}
+		})
+	}
+
+	if count := cache.CountWhere(mframe.Equals, "enabled", true, nil); count != 2 {
+		t.Errorf("expected 2 coerced true values, but got %d", count)
+	}
+
+	if err := cache.SetKeyType("enabled", mframe.String); err == nil {
+		t.Error("expected an error when remapping a key")
+	}
+}
+
+func TestKeyTypeSurvivesRemoval(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetCoercion(mframe.CoercionOptions{Booleans: true})
+
+	if err := cache.SetKeyType("flag", mframe.Boolean); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"flag": "true"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		row, _ := cache.Get(id)
+		if row["flag"] != true {
+			t.Fatalf("insert %d: expected flag to be coerced to true, but got %#v", i, row["flag"])
+		}
+		cache.RemoveElement(id)
+	}
+
+	if err := cache.InsertWithError(map[mframe.KeyName]interface{}{"flag": "maybe"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cache.Filter(mframe.Equals, "flag", "maybe", nil).Count(); got != 0 {
+		t.Errorf("expected a string for a declared Boolean key to be rejected, but %d rows hold it", got)
+	}
+	if err := cache.SetKeyType("flag", mframe.String); err == nil {
+		t.Error("expected an error when declaring another type")
+	}
+}
+
+func TestNumericCoercion(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  mframe.CoercionOptions
+		value    string
+		expected interface{}
+		raw      interface{}
+	}{
+		{"disabled", mframe.CoercionOptions{}, "42", nil, nil},
+		{"integer", mframe.CoercionOptions{Numerics: true}, "42", 42.0, nil},
+		{"decimal with spaces", mframe.CoercionOptions{Numerics: true}, " 3.14 ", 3.14, nil},
+		{"exponent", mframe.CoercionOptions{Numerics: true}, "1e3", 1000.0, nil},
+		{"not a number", mframe.CoercionOptions{Numerics: true}, "NaN", nil, nil},
+		{"keep source", mframe.CoercionOptions{Numerics: true, KeepNumericStrings: true}, "0042", 42.0, "0042"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache mframe.DataFrame
+			cache.Init(24 * time.Hour)
+			cache.SetCoercion(tt.options)
+			cache.Insert(map[mframe.KeyName]interface{}{"bytes": 1})
+
+			id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"bytes": tt.value, "host": "a"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			row, _ := cache.Get(id)
+			if row["bytes"] != tt.expected {
+				t.Errorf("expected %v, but got %v", tt.expected, row["bytes"])
+			}
+			if row["bytes"+mframe.NumericSourceSuffix] != tt.raw {
+				t.Errorf("expected raw value %v, but got %v", tt.raw, row["bytes"+mframe.NumericSourceSuffix])
+			}
+			if tt.expected != nil && cache.CountWhere(mframe.Greater, "bytes", 1.0, nil) != 1 {
+				t.Error("expected the parsed value to be indexed as a number")
+			}
+		})
+	}
+}
+
+func TestTimeCoercion(t *testing.T) {
+	expected := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		options mframe.CoercionOptions
+		declare bool
+		value   string
+		parsed  bool
+	}{
+		{"disabled", mframe.CoercionOptions{}, true, "2024-03-01T12:30:00Z", false},
+		{"rfc3339", mframe.CoercionOptions{Times: true}, true, "2024-03-01T14:30:00+02:00", true},
+		{"epoch seconds", mframe.CoercionOptions{Times: true}, true, "1709296200", true},
+		{"epoch millis", mframe.CoercionOptions{Times: true}, true, "1709296200000", true},
+		{"layout", mframe.CoercionOptions{Times: true, TimeLayouts: []string{"2006-01-02 15:04:05"}}, true, "2024-03-01 12:30:00", true},
+		{"invalid", mframe.CoercionOptions{Times: true}, true, "yesterday", false},
+		{"detected", mframe.CoercionOptions{DetectTimes: true}, false, "2024-03-01T12:30:00Z", true},
+		{"epoch not detected", mframe.CoercionOptions{DetectTimes: true}, false, "1709296200", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache mframe.DataFrame
+			cache.Init(24 * time.Hour)
+			cache.SetCoercion(tt.options)
+			if tt.declare {
+				if err := cache.SetKeyType("timestamp", mframe.Time); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"timestamp": tt.value, "host": "a"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			row, _ := cache.Get(id)
+			value, ok := row["timestamp"].(time.Time)
+			if ok != tt.parsed {
+				t.Fatalf("expected parsed to be %v, but got %v", tt.parsed, row["timestamp"])
+			}
+			if ok && !value.Equal(expected) {
+				t.Errorf("expected %v, but got %v", expected, value)
+			}
+			if ok && cache.CountWhere(mframe.Between, "timestamp", []time.Time{expected, expected}, nil) != 1 {
+				t.Error("expected the parsed value to be indexed as a time")
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package mframe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxCompositeKeys is the number of keys a composite index combines at most.
+const MaxCompositeKeys = 4
+
+// compositeTuple holds the values of the keys of a composite index held by a row, in the order of the keys.
+// Values of missing keys are nil.
+type compositeTuple [MaxCompositeKeys]interface{}
+
+// compositeIndex maps the values of several keys to the rows holding every one of them. It is maintained
+// as values of its keys are indexed and unindexed, so it does not depend on which operation changed a row.
+type compositeIndex struct {
+	keys   []KeyName
+	tuples map[uuid.UUID]*compositeTuple
+	rows   map[compositeTuple]map[uuid.UUID]bool
+}
+
+// CompositeKey returns the name of the composite index of the keys, used as the key of the index by
+// DropIndex and AdaptiveIndexes. The order of the keys does not matter.
+func CompositeKey(keys ...KeyName) KeyName {
+	sorted := compositeKeys(keys)
+	names := make([]string, len(sorted))
+	for i, key := range sorted {
+		names[i] = string(key)
+	}
+	return KeyName(strings.Join(names, "+"))
+}
+
+// compositeKeys returns the keys sorted and without duplicates.
+func compositeKeys(keys []KeyName) []KeyName {
+	sorted := make([]KeyName, 0, len(keys))
+	seen := make(map[KeyName]bool, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			sorted = append(sorted, key)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// CreateCompositeIndex builds a composite index on the keys, so FilterSpecs with case-sensitive Equals
+// conditions on all of them read the matching rows with a single lookup. The index is maintained by
+// inserts, updates and removals from then on, and removed with DropIndex and CompositeKey.
+// Returns an error if fewer than two or more than MaxCompositeKeys distinct keys are given, or if a key
+// is not indexed or is mapped to a type other than Numeric, String or Boolean.
+func (d *DataFrame) CreateCompositeIndex(keys ...KeyName) error {
+	sorted := compositeKeys(keys)
+	if len(sorted) < 2 || len(sorted) > MaxCompositeKeys {
+		return fmt.Errorf("composite indexes combine 2 to %d keys, but got %d", MaxCompositeKeys, len(sorted))
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	for _, key := range sorted {
+		if isKeyPattern(key) || d.notIndexedUnlocked(key) {
+			return fmt.Errorf("key '%s' cannot be part of a composite index", key)
+		}
+		if mapped, exists := d.Keys[key]; exists && !compositeType(mapped) {
+			return fmt.Errorf("%s index requires Numeric, String or Boolean keys, but key '%s' is %s", CompositeIndex, key, keyTypeToString(mapped))
+		}
+	}
+
+	if !d.accel.has(CompositeKey(sorted...), CompositeIndex) {
+		d.buildCompositeUnlocked(sorted)
+	}
+
+	return nil
+}
+
+// compositeType reports whether keys of the type can be part of a composite index.
+func compositeType(keyType KeyType) bool {
+	return keyType == Numeric || keyType == String || keyType == Boolean
+}
+
+// buildCompositeUnlocked builds the composite index of the sorted keys from the rows, without acquiring locks.
+func (d *DataFrame) buildCompositeUnlocked(keys []KeyName) {
+	composite := &compositeIndex{
+		keys:   keys,
+		tuples: make(map[uuid.UUID]*compositeTuple),
+		rows:   make(map[compositeTuple]map[uuid.UUID]bool),
+	}
+	for id, row := range d.Data {
+		for _, key := range keys {
+			if d.notIndexedUnlocked(key) {
+				continue
+			}
+			if value, ok := row[key]; ok {
+				composite.set(id, key, value)
+			}
+		}
+	}
+
+	if d.accel.composites == nil {
+		d.accel.composites = make(map[KeyName]*compositeIndex)
+	}
+	d.accel.composites[CompositeKey(keys...)] = composite
+}
+
+// onRowValue updates the composite indexes of the key when a value of the key is indexed for a row.
+func (d *DataFrame) onRowValue(key KeyName, value interface{}, id uuid.UUID) {
+	for _, composite := range d.accel.composites {
+		composite.set(id, key, value)
+	}
+}
+
+// onRowValueGone updates the composite indexes of the key when a value of the key is unindexed for a row.
+func (d *DataFrame) onRowValueGone(key KeyName, value interface{}, id uuid.UUID) {
+	for _, composite := range d.accel.composites {
+		composite.unset(id, key, value)
+	}
+}
+
+// position returns the position of the key in the composite index, or -1 if it is not one of its keys.
+func (c *compositeIndex) position(key KeyName) int {
+	for i, k := range c.keys {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// complete reports whether the row holds a value for every key of the composite index.
+func (c *compositeIndex) complete(tuple *compositeTuple) bool {
+	for i := range c.keys {
+		if tuple[i] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// set records the value of the key for the row, moving the row to the entry of its new values.
+func (c *compositeIndex) set(id uuid.UUID, key KeyName, value interface{}) {
+	i := c.position(key)
+	if i < 0 {
+		return
+	}
+
+	tuple, ok := c.tuples[id]
+	if !ok {
+		tuple = new(compositeTuple)
+		c.tuples[id] = tuple
+	} else if c.complete(tuple) {
+		c.unlink(id, *tuple)
+	}
+
+	tuple[i] = value
+	if c.complete(tuple) {
+		if c.rows[*tuple] == nil {
+			c.rows[*tuple] = make(map[uuid.UUID]bool)
+		}
+		c.rows[*tuple][id] = true
+	}
+}
+
+// unset forgets the value of the key for the row.
+func (c *compositeIndex) unset(id uuid.UUID, key KeyName, value interface{}) {
+	i := c.position(key)
+	if i < 0 {
+		return
+	}
+
+	tuple, ok := c.tuples[id]
+	if !ok || tuple[i] != value {
+		return
+	}
+	if c.complete(tuple) {
+		c.unlink(id, *tuple)
+	}
+
+	tuple[i] = nil
+	for _, v := range tuple {
+		if v != nil {
+			return
+		}
+	}
+	delete(c.tuples, id)
+}
+
+// unlink removes the row from the entry of the values.
+func (c *compositeIndex) unlink(id uuid.UUID, tuple compositeTuple) {
+	delete(c.rows[tuple], id)
+	if len(c.rows[tuple]) == 0 {
+		delete(c.rows, tuple)
+	}
+}
+
+// size estimates the memory used by the composite index in bytes.
+func (c *compositeIndex) size() int {
+	size := 48 + len(c.tuples)*(40+16*MaxCompositeKeys)
+	for tuple, ids := range c.rows {
+		size += 64 + 16*MaxCompositeKeys + 24*len(ids)
+		for _, v := range tuple {
+			if s, ok := v.(string); ok {
+				size += len(s)
+			}
+		}
+	}
+	return size
+}
+
+// compositeConditionUnlocked reports whether a composite index can serve the condition, without acquiring
+// locks: a case-sensitive Equals on a Numeric, String or Boolean key with a value of the type of the key.
+func (d *DataFrame) compositeConditionUnlocked(c Condition) bool {
+	if c.Operator != Equals || isKeyPattern(c.Key) {
+		return false
+	}
+	if sensitive, set := c.Options[CaseSensitive]; set && !sensitive {
+		return false
+	}
+
+	switch d.Keys[c.Key] {
+	case Numeric:
+		_, ok := c.Value.(float64)
+		return ok
+	case String:
+		_, ok := c.Value.(string)
+		return ok
+	case Boolean:
+		_, ok := c.Value.(bool)
+		return ok
+	default:
+		return false
+	}
+}
+
+// compositeCandidateUnlocked returns the sorted keys of the conditions a composite index could serve, at most
+// MaxCompositeKeys of them, without acquiring locks. Returns nil when fewer than two keys qualify.
+func (d *DataFrame) compositeCandidateUnlocked(conditions []Condition) []KeyName {
+	var keys []KeyName
+	for _, c := range conditions {
+		if d.compositeConditionUnlocked(c) {
+			keys = append(keys, c.Key)
+		}
+	}
+
+	keys = compositeKeys(keys)
+	if len(keys) < 2 {
+		return nil
+	}
+	if len(keys) > MaxCompositeKeys {
+		keys = keys[:MaxCompositeKeys]
+	}
+	return keys
+}
+
+// compositeLookupUnlocked finds the composite index serving the most conditions, without acquiring locks.
+// Returns the rows matching those conditions and the positions of the conditions it served, or false when
+// no composite index applies.
+func (d *DataFrame) compositeLookupUnlocked(conditions []Condition) (map[uuid.UUID]bool, []int, bool) {
+	if len(d.accel.composites) == 0 || len(conditions) < 2 {
+		return nil, nil, false
+	}
+
+	var best *compositeIndex
+	var bestTuple compositeTuple
+	var bestPositions []int
+	for _, composite := range d.accel.composites {
+		if best != nil && len(composite.keys) <= len(best.keys) {
+			continue
+		}
+
+		var tuple compositeTuple
+		positions := make([]int, 0, len(composite.keys))
+		for i, key := range composite.keys {
+			for position, c := range conditions {
+				if c.Key == key && d.compositeConditionUnlocked(c) {
+					tuple[i] = c.Value
+					positions = append(positions, position)
+					break
+				}
+			}
+		}
+
+		if len(positions) == len(composite.keys) {
+			best, bestTuple, bestPositions = composite, tuple, positions
+		}
+	}
+
+	if best == nil {
+		return nil, nil, false
+	}
+	return best.rows[bestTuple], bestPositions, true
+}
@@ -0,0 +1,130 @@
+package mframe_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestCompositeIndex(t *testing.T) {
+	var plain, composite mframe.DataFrame
+	plain.Init(24 * time.Hour)
+	composite.Init(24 * time.Hour)
+
+	if err := composite.CreateCompositeIndex("host", "port", "host"); err != nil {
+		t.Fatalf("expected the index to be created, but got %v", err)
+	}
+	if err := composite.CreateCompositeIndex("host"); err == nil {
+		t.Error("expected an error for a single key")
+	}
+	if err := composite.CreateIndex("host", mframe.CompositeIndex); err == nil {
+		t.Error("expected CreateIndex to reject composite indexes")
+	}
+
+	for i := 0; i < 60; i++ {
+		row := map[mframe.KeyName]interface{}{"host": fmt.Sprintf("web-%d", i%4), "port": float64(80 + i%3), "up": i%2 == 0}
+		plain.Insert(row)
+		composite.Insert(row)
+	}
+
+	spec := func(host string, port float64, up ...bool) mframe.FilterSpec {
+		conditions := []mframe.Condition{
+			{Operator: mframe.Equals, Key: "port", Value: port},
+			{Operator: mframe.Equals, Key: "host", Value: host},
+		}
+		for _, u := range up {
+			conditions = append(conditions, mframe.Condition{Operator: mframe.Equals, Key: "up", Value: u})
+		}
+		return mframe.FilterSpec{Conditions: conditions}
+	}
+
+	check := func(t *testing.T) {
+		t.Helper()
+		for _, host := range []string{"web-0", "web-1", "web-3", "web-9"} {
+			for _, port := range []float64{80, 81, 82} {
+				queries := []mframe.QuerySpec{
+					{Spec: spec(host, port), Agg: mframe.AggSpec{Field: "port", Functions: []string{"count"}}},
+					{Spec: spec(host, port, true), Agg: mframe.AggSpec{Field: "port", Functions: []string{"count"}}},
+				}
+				expected := plain.ExecuteMany(queries, 1, 0)
+				for i, got := range composite.ExecuteMany(queries, 1, 0) {
+					if got.Count != expected[i].Count {
+						t.Errorf("%s:%v: expected %d rows, but got %d", host, port, expected[i].Count, got.Count)
+					}
+				}
+			}
+		}
+	}
+
+	check(t)
+
+	explain := composite.ExplainSpec(spec("web-1", 81, true))
+	served := 0
+	for _, step := range explain.Steps {
+		if step.Access == mframe.AccessComposite {
+			served++
+		}
+	}
+	if served != 2 {
+		t.Errorf("expected 2 conditions served by the composite index, but got %s", explain)
+	}
+
+	t.Run("maintained", func(t *testing.T) {
+		for _, frame := range []*mframe.DataFrame{&plain, &composite} {
+			frame.UpdateWhere(mframe.FilterSpec{Conditions: []mframe.Condition{
+				{Operator: mframe.Equals, Key: "host", Value: "web-1"},
+			}}, map[mframe.KeyName]interface{}{"port": 82.0})
+			ids := frame.FilterIDs(mframe.Equals, "host", "web-0", nil)
+			for id := range ids {
+				frame.RemoveElement(id)
+			}
+			frame.Insert(map[mframe.KeyName]interface{}{"host": "web-3", "port": 81.0, "up": true})
+		}
+		check(t)
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		composite.DropIndex(mframe.CompositeKey("port", "host"), mframe.CompositeIndex)
+		check(t)
+		for _, step := range composite.ExplainSpec(spec("web-1", 81)).Steps {
+			if step.Access == mframe.AccessComposite {
+				t.Error("expected the composite index to be dropped")
+			}
+		}
+	})
+}
+
+func TestAdaptiveCompositeIndex(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetAdaptiveIndexing(mframe.AdaptiveOptions{MinQueries: 2})
+
+	for i := 0; i < 30; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"user": fmt.Sprintf("user-%d", i%5), "action": fmt.Sprintf("action-%d", i%3)})
+	}
+
+	spec := mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "user", Value: "user-1"},
+		{Operator: mframe.Equals, Key: "action", Value: "action-1"},
+	}}
+	expected := countSpec(&cache, spec)
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Aggregate(spec, mframe.AggSpec{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	indexes := cache.AdaptiveIndexes()
+	if len(indexes) != 1 || indexes[0].Kind != mframe.CompositeIndex || indexes[0].Key != mframe.CompositeKey("user", "action") {
+		t.Fatalf("expected a composite index on action and user, but got %+v", indexes)
+	}
+	if len(indexes[0].Keys) != 2 || indexes[0].Bytes <= 0 || indexes[0].Queries != 2 {
+		t.Errorf("expected the keys, memory cost and query count of the index, but got %+v", indexes[0])
+	}
+
+	if got := countSpec(&cache, spec); got != expected {
+		t.Errorf("expected %d rows, but got %d", expected, got)
+	}
+}
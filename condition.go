@@ -0,0 +1,116 @@
+package mframe
+
+import (
+	"github.com/google/uuid"
+)
+
+// Condition describes a single filter predicate, using the same arguments accepted by Filter.
+type Condition struct {
+	Operator Operator
+	Key      KeyName
+	Value    any
+	Options  map[FilterOption]bool
+}
+
+// idsUnlocked returns the set of IDs of the rows matching the condition without acquiring locks.
+func (d *DataFrame) idsUnlocked(c Condition) map[uuid.UUID]struct{} {
+	return d.idsUntilUnlocked(c, nil)
+}
+
+// idsUntilUnlocked works like idsUnlocked, stopping the scan once poll is interrupted.
+func (d *DataFrame) idsUntilUnlocked(c Condition, poll *scanPoll) map[uuid.UUID]struct{} {
+	ids := make(map[uuid.UUID]struct{})
+	d.matchUnlocked(poll, c.Operator, c.Key, c.Value, c.Options, func(id uuid.UUID) bool {
+		ids[id] = struct{}{}
+		return true
+	})
+	return ids
+}
+
+// FilterSpec combines conditions: a row matches when it matches every condition.
+// An empty FilterSpec matches every row.
+type FilterSpec struct {
+	Conditions []Condition
+}
+
+// specIDsUnlocked returns the set of IDs of the rows matching the spec without acquiring locks.
+// The Equals conditions served by a composite index are read with a single lookup. The other conditions
+// are evaluated in the order of their plan, see planUnlocked, and their ID sets intersected as they come,
+// stopping once no ID is left.
+func (d *DataFrame) specIDsUnlocked(spec FilterSpec) map[uuid.UUID]struct{} {
+	ids, _ := d.specIDsUntilUnlocked(spec, nil)
+	return ids
+}
+
+// specIDsUntilUnlocked works like specIDsUnlocked, checking done before each condition and while scanning
+// the indexes. Returns false when done was closed before all the conditions were evaluated.
+func (d *DataFrame) specIDsUntilUnlocked(spec FilterSpec, done <-chan struct{}) (map[uuid.UUID]struct{}, bool) {
+	if len(spec.Conditions) == 0 {
+		ids := make(map[uuid.UUID]struct{}, len(d.Data))
+		for id := range d.Data {
+			ids[id] = struct{}{}
+		}
+		return ids, true
+	}
+
+	var result map[uuid.UUID]struct{}
+	conditions := spec.Conditions
+	if matches, served, ok := d.compositeLookupUnlocked(conditions); ok {
+		result = make(map[uuid.UUID]struct{}, len(matches))
+		for id := range matches {
+			result[id] = struct{}{}
+		}
+		if len(result) == 0 || len(served) == len(conditions) {
+			return result, true
+		}
+
+		skip := make(map[int]bool, len(served))
+		for _, position := range served {
+			skip[position] = true
+		}
+		rest := make([]Condition, 0, len(conditions)-len(served))
+		for position, c := range conditions {
+			if !skip[position] {
+				rest = append(rest, c)
+			}
+		}
+		conditions = rest
+	}
+
+	order := []int{0}
+	if len(conditions) > 1 {
+		order = d.planUnlocked(FilterSpec{Conditions: conditions})
+	}
+
+	poll := &scanPoll{done: done}
+	for _, position := range order {
+		select {
+		case <-done:
+			return nil, false
+		default:
+		}
+
+		ids := d.idsUntilUnlocked(conditions[position], poll)
+		if poll.stopped {
+			return nil, false
+		}
+		if result == nil {
+			result = ids
+		} else {
+			if len(ids) < len(result) {
+				result, ids = ids, result
+			}
+			for id := range result {
+				if _, ok := ids[id]; !ok {
+					delete(result, id)
+				}
+			}
+		}
+
+		if len(result) == 0 {
+			return result, true
+		}
+	}
+
+	return result, true
+}
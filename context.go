@@ -0,0 +1,91 @@
+package mframe
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetPartialResults configures what context-aware queries return when their context is done before
+// the scan completes. When enabled, they return the rows matched so far, flagged by IsPartial;
+// otherwise they return the error of the context. Disabled by default.
+func (d *DataFrame) SetPartialResults(enabled bool) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+	d.partialResults = enabled
+}
+
+// IsPartial reports whether the DataFrame is the result of a query interrupted by its context,
+// holding only the rows matched before the interruption.
+func (d *DataFrame) IsPartial() bool {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return d.partial
+}
+
+// FilterContext works like Filter but stops scanning the indexes as soon as ctx is done, so request
+// deadlines and cancellations of a server handler reach the query. See SetPartialResults for what
+// is returned when the query is interrupted.
+func (d *DataFrame) FilterContext(ctx context.Context, operator Operator, key KeyName, value any, options map[FilterOption]bool) (*DataFrame, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("query on key '%s' interrupted: %w", key, err)
+	}
+
+	query := Condition{Operator: operator, Key: key, Value: value, Options: options}
+
+	if err := d.runBeforeQuery(query); err != nil {
+		return nil, fmt.Errorf("query on key '%s' rejected: %w", key, err)
+	}
+
+	start := time.Now()
+	d.Locker.RLock()
+	results, err := d.filterUnlocked(query, ctx.Done())
+	partialResults := d.partialResults
+	d.Locker.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if results.partial && !partialResults {
+		return nil, fmt.Errorf("query on key '%s' interrupted: %w", key, ctx.Err())
+	}
+
+	d.recordQuery(query, time.Since(start), len(results.Data))
+	d.runAfterQuery(query, results)
+
+	return results, nil
+}
+
+// scanPollInterval is the number of index values a scan reads between two checks of its done channel.
+const scanPollInterval = 256
+
+// scanPoll checks the done channel of a scan every scanPollInterval index values, so scans reading many
+// values without a matching row, such as regular expressions or ranges, still stop once their context
+// is done. A nil scanPoll never interrupts.
+type scanPoll struct {
+	done    <-chan struct{}
+	read    int
+	stopped bool
+}
+
+// interrupted counts an index value read and reports whether done was closed, remembering it in stopped.
+func (p *scanPoll) interrupted() bool {
+	if p == nil || p.done == nil {
+		return false
+	}
+	if p.stopped {
+		return true
+	}
+
+	p.read++
+	if p.read%scanPollInterval != 0 {
+		return false
+	}
+	select {
+	case <-p.done:
+		p.stopped = true
+	default:
+	}
+	return p.stopped
+}
@@ -0,0 +1,102 @@
+package mframe_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestFilterContext(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"value": i})
+	}
+
+	results, err := cache.FilterContext(context.Background(), mframe.Less, "value", 10.0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results.Count() != 10 || results.IsPartial() {
+		t.Errorf("expected 10 complete rows, but got %d (partial %v)", results.Count(), results.IsPartial())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cache.FilterContext(ctx, mframe.Less, "value", 10.0, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, but got %v", err)
+	}
+}
+
+func TestFilterContextInterrupted(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"value": i})
+	}
+
+	var cancel context.CancelFunc
+	cache.OnBeforeQuery(func(mframe.Condition) error {
+		cancel()
+		return nil
+	})
+
+	var ctx context.Context
+	ctx, cancel = context.WithCancel(context.Background())
+	if _, err := cache.FilterContext(ctx, mframe.Less, "value", 50.0, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, but got %v", err)
+	}
+
+	cache.SetPartialResults(true)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	results, err := cache.FilterContext(ctx, mframe.Less, "value", 50.0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !results.IsPartial() {
+		t.Error("expected the results to be flagged as partial")
+	}
+	if results.Count() >= 50 {
+		t.Errorf("expected the scan to stop early, but got %d rows", results.Count())
+	}
+}
+
+func TestFilterContextInterruptedWithoutMatches(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		var cache mframe.DataFrame
+		cache.Init(24 * time.Hour)
+		if indexed {
+			if err := cache.CreateIndex("host", mframe.TrigramIndex); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for i := 0; i < 2000; i++ {
+			cache.Insert(map[mframe.KeyName]interface{}{"host": fmt.Sprintf("web-%04d", i)})
+		}
+
+		var cancel context.CancelFunc
+		cache.OnBeforeQuery(func(mframe.Condition) error {
+			cancel()
+			return nil
+		})
+
+		// Every value is read, as the trigrams of web- are in all of them, and none matches.
+		for operator, value := range map[mframe.Operator]string{mframe.RegExp: "web-.*x", mframe.Contains: "x"} {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			if _, err := cache.FilterContext(ctx, operator, "host", value, nil); !errors.Is(err, context.Canceled) {
+				t.Errorf("indexed %v, operator %v: expected context.Canceled, but got %v", indexed, operator, err)
+			}
+			cancel()
+		}
+	}
+}
@@ -221,12 +221,8 @@ func TestStats(t *testing.T) {
 		"boolean_field": true,
 	})
 
-	// Stats runs in a goroutine, so we can't test it directly
-	// But we can verify it doesn't panic
-	go df.Stats("test")
-
-	// Give it a moment to start
-	time.Sleep(10 * time.Millisecond)
+	// Stats logs a single summary and returns
+	df.Stats("test")
 }
 
 // Test addMapping error case
@@ -0,0 +1,232 @@
+package mframe
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CSVIDColumn is the name of the column holding the ID of the rows in CSV files.
+const CSVIDColumn = "_id"
+
+// CSVOptions configures ExportToCSV.
+type CSVOptions struct {
+	// Delimiter separates the fields. Zero means a comma.
+	Delimiter rune
+	// NoHeader omits the header line with the names of the columns.
+	NoHeader bool
+	// Columns selects the keys to export, in order. When empty, every key is exported in alphabetical order.
+	Columns []KeyName
+	// IncludeID adds the ID of each row as the first column, named CSVIDColumn.
+	IncludeID bool
+}
+
+// CSVSchema configures ImportFromCSV.
+type CSVSchema struct {
+	// Delimiter separates the fields. Zero means a comma.
+	Delimiter rune
+	// NoHeader reads the first line as data. Columns must then name the columns.
+	NoHeader bool
+	// Columns names the columns, overriding the header line when there is one.
+	Columns []KeyName
+	// Types hints the type of the values of a column. Columns without a hint are inserted as strings,
+	// subject to the coercion configured with SetCoercion.
+	Types map[KeyName]KeyType
+}
+
+// ExportToCSV writes the rows of the DataFrame to w as CSV, ordered by ID. Numbers are written in their
+// shortest form, times in RFC 3339 format and missing values as empty fields.
+func (d *DataFrame) ExportToCSV(w io.Writer, options CSVOptions) error {
+	d.Locker.RLock()
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for id := range d.Data {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	columns := options.Columns
+	if len(columns) == 0 {
+		stored := d.storedKeysUnlocked(ids)
+		columns = make([]KeyName, 0, len(stored))
+		for key := range stored {
+			columns = append(columns, key)
+		}
+		sort.Slice(columns, func(i, j int) bool { return columns[i] < columns[j] })
+	}
+
+	records := make([][]string, 0, len(ids)+1)
+	if !options.NoHeader {
+		header := make([]string, 0, len(columns)+1)
+		if options.IncludeID {
+			header = append(header, CSVIDColumn)
+		}
+		for _, column := range columns {
+			header = append(header, string(column))
+		}
+		records = append(records, header)
+	}
+
+	for _, id := range ids {
+		row := d.Data[id]
+		record := make([]string, 0, len(columns)+1)
+		if options.IncludeID {
+			record = append(record, id.String())
+		}
+		for _, column := range columns {
+			value, ok := row[column]
+			if !ok {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, formatCSVValue(value))
+		}
+		records = append(records, record)
+	}
+	d.Locker.RUnlock()
+
+	writer := csv.NewWriter(w)
+	if options.Delimiter != 0 {
+		writer.Comma = options.Delimiter
+	}
+
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return nil
+}
+
+// ImportFromCSV inserts the records read from r as new rows and returns the number of inserted rows.
+// Empty fields are left out of the rows. A column named CSVIDColumn holding UUIDs sets the ID of the rows,
+// replacing existing rows with the same ID. Values not matching the type hinted for their column fail the
+// import before any row is inserted.
+func (d *DataFrame) ImportFromCSV(r io.Reader, schema CSVSchema) (int, error) {
+	reader := csv.NewReader(r)
+	if schema.Delimiter != 0 {
+		reader.Comma = schema.Delimiter
+	}
+	reader.FieldsPerRecord = -1
+
+	columns := schema.Columns
+	if !schema.NoHeader {
+		header, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		if len(columns) == 0 {
+			columns = make([]KeyName, len(header))
+			for i, name := range header {
+				columns[i] = KeyName(name)
+			}
+		}
+	}
+
+	if len(columns) == 0 {
+		return 0, fmt.Errorf("CSV columns must be named when there is no header")
+	}
+
+	d.Locker.RLock()
+	newID := d.idGeneratorUnlocked()
+	d.Locker.RUnlock()
+
+	var entries []batchEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) > len(columns) {
+			return 0, fmt.Errorf("line %d: %d fields for %d columns", line, len(record), len(columns))
+		}
+
+		id := uuid.Nil
+		data := make(map[KeyName]interface{}, len(record))
+		for i, field := range record {
+			if field == "" {
+				continue
+			}
+
+			column := columns[i]
+			if column == CSVIDColumn {
+				if id, err = uuid.Parse(field); err != nil {
+					return 0, fmt.Errorf("line %d: invalid ID '%s': %w", line, field, err)
+				}
+				continue
+			}
+
+			value, err := parseCSVValue(field, schema.Types[column])
+			if err != nil {
+				return 0, fmt.Errorf("line %d: column '%s': %w", line, column, err)
+			}
+			data[column] = value
+		}
+
+		if id == uuid.Nil {
+			id = newID()
+		}
+		entries = append(entries, batchEntry{id: id, data: data})
+	}
+
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	return d.insertEntriesWithOptions(entries, batchOptions{})
+}
+
+// formatCSVValue formats a value as a CSV field.
+func formatCSVValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// parseCSVValue parses a CSV field as a value of the hinted type, keeping it as a string without a hint.
+func parseCSVValue(field string, keyType KeyType) (interface{}, error) {
+	switch keyType {
+	case Numeric:
+		value, ok := parseFinite(field)
+		if !ok {
+			return nil, fmt.Errorf("invalid number '%s'", field)
+		}
+		return value, nil
+	case Boolean:
+		value, err := strconv.ParseBool(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean '%s'", field)
+		}
+		return value, nil
+	case Time:
+		value, ok := parseTime(field, nil, false)
+		if !ok {
+			return nil, fmt.Errorf("invalid time '%s'", field)
+		}
+		return value, nil
+	default:
+		return field, nil
+	}
+}
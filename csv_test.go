@@ -0,0 +1,95 @@
+package mframe_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestExportImportCSV(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "bytes": 1.5, "blocked": true, "seen": seen},
+		{"host": "web;2", "bytes": 100},
+	})
+
+	var out bytes.Buffer
+	if err := cache.ExportToCSV(&out, mframe.CSVOptions{Delimiter: ';', IncludeID: true}); err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || lines[0] != "_id;blocked;bytes;host;seen" {
+		t.Fatalf("expected a header and 2 rows, but got %q", lines)
+	}
+	if !strings.Contains(out.String(), `;;100;"web;2";`) {
+		t.Errorf("expected the delimiter to be quoted and missing values to be empty, but got %q", out.String())
+	}
+
+	var imported mframe.DataFrame
+	imported.Init(24 * time.Hour)
+	n, err := imported.ImportFromCSV(&out, mframe.CSVSchema{
+		Delimiter: ';',
+		Types:     map[mframe.KeyName]mframe.KeyType{"bytes": mframe.Numeric, "blocked": mframe.Boolean, "seen": mframe.Time},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 imported rows, but got %d", n)
+	}
+
+	for id, row := range cache.Data {
+		copied, ok := imported.Data[id]
+		if !ok {
+			t.Fatalf("expected row %s to keep its ID", id)
+		}
+		for key, value := range row {
+			if copied[key] != value {
+				t.Errorf("expected %s to be %v, but got %v", key, value, copied[key])
+			}
+		}
+		if len(copied) != len(row) {
+			t.Errorf("expected %d keys, but got %v", len(row), copied)
+		}
+	}
+}
+
+func TestImportFromCSV(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		schema   mframe.CSVSchema
+		expected int
+		wantErr  bool
+	}{
+		{"header", "host,port\nweb-1,22\nweb-2,\n", mframe.CSVSchema{}, 2, false},
+		{"no header", "web-1,22\n", mframe.CSVSchema{NoHeader: true, Columns: []mframe.KeyName{"host", "port"}}, 1, false},
+		{"empty", "", mframe.CSVSchema{}, 0, false},
+		{"no columns", "web-1,22\n", mframe.CSVSchema{NoHeader: true}, 0, true},
+		{"bad number", "host,port\nweb-1,ssh\n", mframe.CSVSchema{Types: map[mframe.KeyName]mframe.KeyType{"port": mframe.Numeric}}, 0, true},
+		{"too many fields", "host\nweb-1,22\n", mframe.CSVSchema{}, 0, true},
+		{"bad id", "_id,host\nnope,web-1\n", mframe.CSVSchema{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache mframe.DataFrame
+			cache.Init(24 * time.Hour)
+
+			n, err := cache.ImportFromCSV(strings.NewReader(tt.input), tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, but got %v", tt.wantErr, err)
+			}
+			if n != tt.expected || cache.Count() != tt.expected {
+				t.Errorf("expected %d rows, but got %d", tt.expected, n)
+			}
+		})
+	}
+}
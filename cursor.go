@@ -0,0 +1,94 @@
+package mframe
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Cursor walks the rows that matched a query when the cursor was created. The set of IDs is fixed at creation,
+// while rows are read lazily, so rows removed afterwards, e.g. by the cleaner, are skipped and the cursor
+// stays valid. Rows inserted afterwards are not visited. A Cursor is safe for concurrent use.
+type Cursor struct {
+	frame    *DataFrame
+	mutex    sync.Mutex
+	ids      []uuid.UUID
+	position int
+}
+
+// NewCursor returns a cursor over the rows matching every condition of spec, in ID order.
+func (d *DataFrame) NewCursor(spec FilterSpec) *Cursor {
+	d.Locker.RLock()
+	matches := d.specIDsUnlocked(spec)
+	d.Locker.RUnlock()
+	d.recordSpec(spec)
+
+	ids := make([]uuid.UUID, 0, len(matches))
+	for id := range matches {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	return &Cursor{frame: d, ids: ids}
+}
+
+// FilterCursor returns a cursor over the rows matching the filter, using the same arguments as Filter.
+func (d *DataFrame) FilterCursor(operator Operator, key KeyName, value any, options map[FilterOption]bool) *Cursor {
+	return d.NewCursor(FilterSpec{Conditions: []Condition{{Operator: operator, Key: key, Value: value, Options: options}}})
+}
+
+// Next returns the ID and a copy of the next row that still exists, or false when the cursor is exhausted.
+func (c *Cursor) Next() (uuid.UUID, Row, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for c.position < len(c.ids) {
+		id := c.ids[c.position]
+		c.position++
+
+		c.frame.Locker.RLock()
+		row, ok := c.frame.Data[id]
+		if ok {
+			row = c.frame.copyRowUnlocked(row)
+		}
+		c.frame.Locker.RUnlock()
+
+		if ok {
+			return id, row, true
+		}
+	}
+
+	return uuid.Nil, nil, false
+}
+
+// Len returns the number of rows that matched when the cursor was created, including those removed since.
+func (c *Cursor) Len() int {
+	return len(c.ids)
+}
+
+// Remaining returns the number of captured IDs not visited yet, including those of rows removed since.
+func (c *Cursor) Remaining() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.ids) - c.position
+}
+
+// Reset moves the cursor back to the first captured row.
+func (c *Cursor) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.position = 0
+}
+
+// All returns an iterator over the IDs and copies of the remaining rows, advancing the cursor.
+func (c *Cursor) All() iter.Seq2[uuid.UUID, Row] {
+	return func(yield func(uuid.UUID, Row) bool) {
+		for {
+			id, row, ok := c.Next()
+			if !ok || !yield(id, row) {
+				return
+			}
+		}
+	}
+}
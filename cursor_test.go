@@ -0,0 +1,57 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestCursor(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	for i := 0; i < 6; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"seq": i, "kind": []string{"a", "b"}[i%2]})
+	}
+
+	cursor := cache.FilterCursor(mframe.Equals, "kind", "a", nil)
+	if cursor.Len() != 3 {
+		t.Fatalf("expected 3 captured rows, but got %d", cursor.Len())
+	}
+
+	id, row, ok := cursor.Next()
+	if !ok || row["kind"] != "a" {
+		t.Fatalf("expected a row of kind a, but got %v, %v", row, ok)
+	}
+	if got, _ := cache.Get(id); got["seq"] != row["seq"] {
+		t.Errorf("expected the id to reference the row, but got %v", got)
+	}
+
+	// Rows removed and inserted after the cursor was created are skipped.
+	cache.DeleteWhere(mframe.Equals, "kind", "a", nil)
+	cache.Insert(map[mframe.KeyName]interface{}{"seq": 6, "kind": "a"})
+
+	if _, _, ok := cursor.Next(); ok {
+		t.Error("expected removed rows to be skipped")
+	}
+	if cursor.Remaining() != 0 {
+		t.Errorf("expected no remaining ids, but got %d", cursor.Remaining())
+	}
+
+	cursor = cache.NewCursor(mframe.FilterSpec{})
+	visited := 0
+	for id, row := range cursor.All() {
+		if id.String() == "" || row == nil {
+			t.Errorf("unexpected row %v, %v", id, row)
+		}
+		visited++
+	}
+	if visited != 4 {
+		t.Errorf("expected 4 rows, but got %d", visited)
+	}
+
+	cursor.Reset()
+	if cursor.Remaining() != 4 {
+		t.Errorf("expected 4 remaining ids after reset, but got %d", cursor.Remaining())
+	}
+}
@@ -3,6 +3,7 @@ package mframe
 import (
 	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,6 +20,8 @@ type FilterOption int
 
 const (
 	CaseSensitive FilterOption = 1
+	// AllowFullScan exempts a query from the scan guard set with SetScanGuard.
+	AllowFullScan FilterOption = 2
 )
 
 const (
@@ -51,21 +54,60 @@ type Row map[KeyName]interface{}
 
 // DataFrame represents a structure for managing indexed data with TTL and thread-safe operations.
 type DataFrame struct {
-	Data           map[uuid.UUID]Row
-	Keys           KeysIndex
-	Strings        StringsIndex
-	Numerics       NumericsIndex
-	Booleans       BooleansIndex
-	Times          TimesIndex
-	ExpireAt       ExpireAtIndex
-	Locker         sync.RWMutex
-	TTL            time.Duration
-	regexCache     map[string]*regexp.Regexp
-	regexMutex     sync.RWMutex
-	regexCacheSize int
-	maxRegexCache  int
-	stopCleaner    chan bool
-	Version        int // For persistence format versioning
+	Data               map[uuid.UUID]Row
+	Keys               KeysIndex
+	Strings            StringsIndex
+	Numerics           NumericsIndex
+	Booleans           BooleansIndex
+	Times              TimesIndex
+	ExpireAt           ExpireAtIndex
+	Locker             sync.RWMutex
+	TTL                time.Duration
+	regexCache         map[string]*regexp.Regexp
+	regexMutex         sync.RWMutex
+	regexCacheSize     int
+	maxRegexCache      int
+	cleaner            cleaner
+	hooks              hooks
+	quality            quality
+	entropyKeys        map[KeyName]bool
+	name               string
+	noProvenance       bool
+	partialResults     bool
+	partial            bool
+	queryMemoryLimit   int
+	scanGuard          ScanGuard
+	accel              accelerators
+	adaptive           adaptive
+	queryHistory       queryHistory
+	tags               tagIndex
+	changes            changeLog
+	idGenerator        IDGenerator
+	order              insertionOrder
+	defaults           map[KeyName]interface{}
+	coercion           CoercionOptions
+	declared           map[KeyName]KeyType
+	maxRows            int
+	eviction           EvictionPolicy
+	recency            recency
+	evictions          []eviction
+	expiry             expiryHeap
+	watchers           watchers
+	subscriptions      []*subscription
+	triggers           triggers
+	activity           activity
+	metrics            metrics
+	plans              planCache
+	seq                atomic.Uint64
+	mismatched         map[KeyName]map[uuid.UUID]bool
+	compactPersistence bool
+	schema             map[KeyName]compiledFieldSchema
+	strict             bool
+	memoryLimit        int
+	memoryPolicy       MemoryPolicy
+	snapshots          readSnapshots
+	notIndexed         map[KeyName]bool
+	Version            int // For persistence format versioning
 }
 
 // Init initializes the DataFrame with default indexes, an empty data map, and sets the TTL for data expiration.
@@ -77,11 +119,51 @@ func (d *DataFrame) Init(ttl time.Duration) {
 	d.Booleans = make(BooleansIndex)
 	d.Times = make(TimesIndex)
 	d.ExpireAt = make(ExpireAtIndex)
+	d.expiry = nil
+	d.mismatched = nil
+	d.accel = accelerators{}
+	d.tags = tagIndex{}
+	d.order = insertionOrder{enabled: d.order.enabled}
 	d.TTL = ttl
 	d.regexCache = make(map[string]*regexp.Regexp)
 	d.maxRegexCache = 1000 // Default cache size
-	d.stopCleaner = make(chan bool)
-	d.Version = 1 // Current persistence format version
+	d.Version = 1          // Current persistence format version
+	frameOrder(d)
+}
+
+// resetDerivedUnlocked rebuilds the acceleration structures, the expiry heap and the insertion order, clears the tags
+// and forgets the change log and the query plans after the rows of the DataFrame are replaced wholesale, without acquiring locks.
+func (d *DataFrame) resetDerivedUnlocked() {
+	d.forgetChangesUnlocked()
+	d.plans.clear()
+	d.mismatched = nil
+
+	previous := d.accel
+	d.accel = accelerators{}
+	for key := range previous.sortedNumerics {
+		d.buildAcceleratorUnlocked(key, SortedNumericIndex)
+	}
+	for key := range previous.lowercase {
+		d.buildAcceleratorUnlocked(key, LowercaseIndex)
+	}
+	for key := range previous.sortedStrings {
+		d.buildAcceleratorUnlocked(key, PrefixIndex)
+	}
+	for key := range previous.sortedTimes {
+		d.buildAcceleratorUnlocked(key, SortedTimeIndex)
+	}
+	for key := range previous.trigrams {
+		d.buildAcceleratorUnlocked(key, TrigramIndex)
+	}
+	for _, composite := range previous.composites {
+		d.buildCompositeUnlocked(composite.keys)
+	}
+
+	d.tags = tagIndex{}
+	d.rebuildExpiryUnlocked()
+
+	d.order = insertionOrder{enabled: d.order.enabled}
+	d.seedOrderUnlocked()
 }
 
 // InitWithOptions initializes the DataFrame with custom options.
@@ -92,16 +174,18 @@ func (d *DataFrame) InitWithOptions(ttl time.Duration, maxRegexCache int) {
 	}
 }
 
-// StartCleaner starts the background goroutine for cleaning expired entries
+// StartCleaner starts the background goroutine for cleaning expired entries.
+// It does nothing if the cleaner is already running.
 func (d *DataFrame) StartCleaner() {
-	go d.CleanExpired()
+	if stop, done, ok := d.cleaner.start(); ok {
+		go d.cleanLoop(stop, done)
+	}
 }
 
-// StopCleaner stops the background cleaner goroutine
+// StopCleaner stops the background cleaner goroutine and waits for it to exit.
+// It returns immediately if the cleaner is not running.
 func (d *DataFrame) StopCleaner() {
-	if d.stopCleaner != nil {
-		d.stopCleaner <- true
-	}
+	d.cleaner.stop()
 }
 
 // getCompiledRegex returns a compiled regular expression from cache or compiles and caches it
@@ -111,8 +195,10 @@ func (d *DataFrame) getCompiledRegex(pattern string) (*regexp.Regexp, error) {
 	d.regexMutex.RUnlock()
 
 	if exists {
+		d.metrics.regexHits.Add(1)
 		return re, nil
 	}
+	d.metrics.regexMisses.Add(1)
 
 	// Compile the regex
 	compiled, err := regexp.Compile(pattern)
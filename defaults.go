@@ -0,0 +1,83 @@
+package mframe
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetDefault declares the value read for key in rows that do not have it, e.g. severity defaulting to "info".
+// Defaults are applied to the copies returned by Get, Rows, Latest and Oldest, to sorting and to the
+// aggregations over a field, such as CountUnique or Mean. They are not stored in the rows nor indexed,
+// so filters only match the values actually present, and exports hold only the stored values. Passing a nil value removes the default of key.
+// Returns an error if the value is not a string, number, boolean or time, or if its type differs
+// from the type already indexed for key.
+func (d *DataFrame) SetDefault(key KeyName, value interface{}) error {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	if value == nil {
+		delete(d.defaults, key)
+		return nil
+	}
+
+	value = normalizeValue(value)
+
+	var keyType KeyType
+	switch value.(type) {
+	case string:
+		keyType = String
+	case float64:
+		keyType = Numeric
+	case bool:
+		keyType = Boolean
+	case time.Time:
+		keyType = Time
+	default:
+		return fmt.Errorf("unsupported default value type %T for key '%s'", value, key)
+	}
+
+	if current, ok := d.keyTypeUnlocked(key); ok && current != keyType {
+		return fmt.Errorf("default value type %T does not match the type of key '%s'", value, key)
+	}
+
+	if d.defaults == nil {
+		d.defaults = make(map[KeyName]interface{})
+	}
+	d.defaults[key] = value
+
+	return nil
+}
+
+// Defaults returns a copy of the declared default values by key.
+func (d *DataFrame) Defaults() map[KeyName]interface{} {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	result := make(map[KeyName]interface{}, len(d.defaults))
+	for k, v := range d.defaults {
+		result[k] = v
+	}
+
+	return result
+}
+
+// fieldUnlocked returns the value of key in the row, or its default when the row does not have it,
+// and whether a value was found, without acquiring locks.
+func (d *DataFrame) fieldUnlocked(row Row, key KeyName) (interface{}, bool) {
+	if value, ok := row[key]; ok {
+		return value, true
+	}
+	value, ok := d.defaults[key]
+	return value, ok
+}
+
+// copyRowUnlocked returns a copy of the row with the defaults of its missing keys, without acquiring locks.
+func (d *DataFrame) copyRowUnlocked(row Row) Row {
+	result := copyRow(row)
+	for k, v := range d.defaults {
+		if _, ok := result[k]; !ok {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package mframe_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSetDefault(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "a", "severity": "high", "score": 10},
+		{"host": "b"},
+		{"host": "c"},
+	})
+
+	if err := cache.SetDefault("severity", "info"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cache.SetDefault("score", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, row := range cache.Rows() {
+		if row["severity"] == nil {
+			t.Errorf("expected every row to have a severity, but got %v", row)
+		}
+	}
+
+	counts := cache.CountUnique("severity")
+	if counts["info"] != 2 || counts["high"] != 1 {
+		t.Errorf("expected 2 info and 1 high, but got %v", counts)
+	}
+	if sum, _ := cache.Sum("score"); sum != 12 {
+		t.Errorf("expected sum 12, but got %v", sum)
+	}
+	if count := cache.CountWhere(mframe.Equals, "severity", "info", nil); count != 0 {
+		t.Errorf("expected defaults not to be indexed, but got %d", count)
+	}
+
+	if err := cache.SetDefault("severity", 3); err == nil {
+		t.Error("expected an error for a default of a different type")
+	}
+	if err := cache.SetDefault("tags", []string{"a"}); err == nil {
+		t.Error("expected an error for an unsupported default")
+	}
+
+	if err := cache.SetDefault("severity", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if counts := cache.CountUnique("severity"); counts["info"] != 0 {
+		t.Errorf("expected the default to be removed, but got %v", counts)
+	}
+}
+
+func TestDefaultsNotExported(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1", "severity": "high"})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-2"})
+	if err := cache.SetDefault("severity", "info"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var csv bytes.Buffer
+	if err := cache.ExportToCSV(&csv, mframe.CSVOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(csv.String(), "info") {
+		t.Errorf("expected the default not to be exported to CSV, but got %q", csv.String())
+	}
+
+	for _, column := range cache.ToArrowRecord().Columns {
+		if column.Name == "severity" && column.NullCount != 1 {
+			t.Errorf("expected the missing Arrow value to be null, but got %d nulls", column.NullCount)
+		}
+	}
+
+	filename := filepath.Join(t.TempDir(), "frame.parquet")
+	if err := cache.ExportToParquet(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var restored mframe.DataFrame
+	restored.Init(24 * time.Hour)
+	if _, err := restored.ImportFromParquet(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := restored.Filter(mframe.Equals, "severity", "info", nil).Count(); got != 0 {
+		t.Errorf("expected the default not to be exported to Parquet, but got %d rows", got)
+	}
+}
@@ -0,0 +1,117 @@
+package mframe
+
+import (
+	"github.com/google/uuid"
+)
+
+// KeepPolicy selects which row survives when several rows share the same value.
+type KeepPolicy int
+
+const (
+	// KeepFirst keeps the row that expires first. With a uniform TTL this is the oldest inserted row.
+	KeepFirst KeepPolicy = 1
+	// KeepLast keeps the row that expires last. With a uniform TTL this is the newest inserted row.
+	KeepLast KeepPolicy = 2
+	// KeepFirstInserted keeps the row inserted first, whatever its TTL. It requires insertion order,
+	// enabled with SetInsertionOrder, and falls back to KeepFirst without it.
+	KeepFirstInserted KeepPolicy = 3
+	// KeepLastInserted keeps the row inserted last, whatever its TTL. It requires insertion order,
+	// enabled with SetInsertionOrder, and falls back to KeepLast without it.
+	KeepLastInserted KeepPolicy = 4
+)
+
+// DistinctBy returns a new DataFrame keeping one row per unique value of the specified field.
+// Rows that do not contain the field are kept as they are not duplicates of anything.
+func (d *DataFrame) DistinctBy(field KeyName, keep KeepPolicy) *DataFrame {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	var results = new(DataFrame)
+	results.Init(d.TTL)
+
+	survivors, _ := d.distinctUnlocked(field, keep)
+	for _, id := range survivors {
+		results.Insert(d.Data[id])
+	}
+
+	return results
+}
+
+// Deduplicate removes in place every row sharing a value of the specified field with
+// another row, keeping one row per value according to keep. Returns the number of removed rows.
+func (d *DataFrame) Deduplicate(field KeyName, keep KeepPolicy) int {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	_, duplicates := d.distinctUnlocked(field, keep)
+	for _, id := range duplicates {
+		d.evictElementUnlocked(id, EvictRemoved)
+	}
+
+	return len(duplicates)
+}
+
+// distinctUnlocked splits the rows into survivors and duplicates for the specified field.
+func (d *DataFrame) distinctUnlocked(field KeyName, keep KeepPolicy) ([]uuid.UUID, []uuid.UUID) {
+	var arrival map[uuid.UUID]int
+	if (keep == KeepFirstInserted || keep == KeepLastInserted) && d.order.enabled && d.order.rows != nil {
+		arrival = make(map[uuid.UUID]int, d.order.rows.Len())
+		for e := d.order.rows.Front(); e != nil; e = e.Next() {
+			arrival[e.Value.(uuid.UUID)] = len(arrival)
+		}
+	}
+
+	chosen := make(map[interface{}]uuid.UUID)
+	survivors := make([]uuid.UUID, 0)
+	duplicates := make([]uuid.UUID, 0)
+
+	for id, row := range d.Data {
+		value, ok := row[field]
+		if !ok {
+			survivors = append(survivors, id)
+			continue
+		}
+
+		current, exists := chosen[value]
+		if !exists {
+			chosen[value] = id
+			continue
+		}
+
+		if d.prefers(id, current, keep, arrival) {
+			chosen[value] = id
+			duplicates = append(duplicates, current)
+		} else {
+			duplicates = append(duplicates, id)
+		}
+	}
+
+	for _, id := range chosen {
+		survivors = append(survivors, id)
+	}
+
+	return survivors, duplicates
+}
+
+// prefers reports whether the candidate row should replace the current one under the keep policy,
+// comparing the positions in arrival, when given, or the expiration times otherwise.
+// Ties are broken by ID so the result does not depend on map iteration order.
+func (d *DataFrame) prefers(candidate, current uuid.UUID, keep KeepPolicy, arrival map[uuid.UUID]int) bool {
+	latest := keep == KeepLast || keep == KeepLastInserted
+
+	if arrival != nil {
+		if latest {
+			return arrival[candidate] > arrival[current]
+		}
+		return arrival[candidate] < arrival[current]
+	}
+
+	candidateAt, currentAt := d.ExpireAt[candidate], d.ExpireAt[current]
+	if candidateAt.Equal(currentAt) {
+		return candidate.String() < current.String()
+	}
+	if latest {
+		return candidateAt.After(currentAt)
+	}
+	return candidateAt.Before(currentAt)
+}
@@ -0,0 +1,118 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/threatwinds/mframe"
+)
+
+func TestDistinctBy(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"fingerprint": "a", "seq": 1.0},
+		{"fingerprint": "b", "seq": 2.0},
+		{"fingerprint": "a", "seq": 3.0},
+		{"fingerprint": "a", "seq": 4.0},
+		{"seq": 5.0},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+		time.Sleep(time.Millisecond)
+	}
+
+	first := cache.DistinctBy("fingerprint", mframe.KeepFirst)
+	if first.Count() != 3 {
+		t.Fatalf("expected 3 rows, but got %d", first.Count())
+	}
+	if rows := first.Filter(mframe.Equals, "fingerprint", "a", nil).ToSlice(); len(rows) != 1 || rows[0]["seq"] != 1.0 {
+		t.Errorf("expected first 'a' row to be kept, but got %v", rows)
+	}
+
+	last := cache.DistinctBy("fingerprint", mframe.KeepLast)
+	if rows := last.Filter(mframe.Equals, "fingerprint", "a", nil).ToSlice(); len(rows) != 1 || rows[0]["seq"] != 4.0 {
+		t.Errorf("expected last 'a' row to be kept, but got %v", rows)
+	}
+
+	if cache.Count() != 5 {
+		t.Errorf("DistinctBy should not modify the source, but count is %d", cache.Count())
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"fingerprint": i % 3, "seq": float64(i)})
+		time.Sleep(time.Millisecond)
+	}
+
+	removed := cache.Deduplicate("fingerprint", mframe.KeepLast)
+	if removed != 7 {
+		t.Errorf("expected 7 removed rows, but got %d", removed)
+	}
+	if cache.Count() != 3 {
+		t.Errorf("expected 3 rows, but got %d", cache.Count())
+	}
+
+	seq, err := cache.Sum("seq")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seq != 7+8+9 {
+		t.Errorf("expected the newest rows to be kept, but got seq sum %v", seq)
+	}
+}
+
+func TestDistinctByInsertTime(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetInsertionOrder(true)
+
+	ids := make([]uuid.UUID, 0, 3)
+	for i := 1; i <= 3; i++ {
+		id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"fingerprint": "a", "seq": float64(i)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, id)
+		time.Sleep(time.Millisecond)
+	}
+
+	// Touching the first row makes it expire last, while it is still the first inserted.
+	if err := cache.Touch(ids[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		keep     mframe.KeepPolicy
+		expected float64
+	}{
+		{"first expiring", mframe.KeepFirst, 2},
+		{"last expiring", mframe.KeepLast, 1},
+		{"first inserted", mframe.KeepFirstInserted, 1},
+		{"last inserted", mframe.KeepLastInserted, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := cache.DistinctBy("fingerprint", tt.keep).ToSlice()
+			if len(rows) != 1 || rows[0]["seq"] != tt.expected {
+				t.Errorf("expected the row with seq %v, but got %v", tt.expected, rows)
+			}
+		})
+	}
+
+	if removed := cache.Deduplicate("fingerprint", mframe.KeepLastInserted); removed != 2 {
+		t.Errorf("expected 2 removed rows, but got %d", removed)
+	}
+	if _, ok := cache.Get(ids[2]); !ok {
+		t.Errorf("expected the last inserted row to be kept")
+	}
+}
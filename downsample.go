@@ -0,0 +1,135 @@
+package mframe
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DownsampleSamplesKey is the key of the rolled-up rows holding the number of values aggregated into them.
+const DownsampleSamplesKey KeyName = "samples"
+
+// Downsampler rolls the detailed rows of a DataFrame up into coarser aggregate rows stored in a target
+// DataFrame, usually with a longer TTL, implementing hot/warm retention.
+type Downsampler struct {
+	source     *DataFrame
+	target     *DataFrame
+	timeKey    KeyName
+	valueKey   KeyName
+	resolution time.Duration
+	agg        string
+	fn         AggregateFunc
+}
+
+// Downsample returns a Downsampler rolling the values of valueKey up into buckets of the given resolution
+// by the time held in timeKey, using the aggregation registered under agg. Each bucket becomes a row of the
+// target holding its start time in timeKey, the aggregated value in valueKey and the number of values in
+// DownsampleSamplesKey. Call Rollup or Run to roll the rows up.
+func (d *DataFrame) Downsample(timeKey, valueKey KeyName, resolution time.Duration, agg string, target *DataFrame) (*Downsampler, error) {
+	if resolution <= 0 {
+		return nil, fmt.Errorf("resolution must be greater than zero")
+	}
+	if target == nil || target == d {
+		return nil, fmt.Errorf("target must be another DataFrame")
+	}
+
+	fn, ok := LookupAggregate(agg)
+	if !ok {
+		return nil, fmt.Errorf("unknown aggregate '%s'", agg)
+	}
+
+	return &Downsampler{
+		source:     d,
+		target:     target,
+		timeKey:    timeKey,
+		valueKey:   valueKey,
+		resolution: resolution,
+		agg:        agg,
+		fn:         fn,
+	}, nil
+}
+
+// Rollup aggregates every bucket closed at now that is not yet in the target, and returns the number of rows
+// added to the target. Buckets are aligned to multiples of the resolution and rolled up once: rows arriving
+// for a bucket after it was rolled up are not added to it. Buckets whose aggregation fails, such as the
+// average of a bucket without numeric values, are skipped.
+func (s *Downsampler) Rollup(now time.Time) int {
+	buckets := make(map[time.Time][]interface{})
+
+	s.source.Locker.RLock()
+	for _, row := range s.source.Data {
+		at, ok := s.source.timeFieldUnlocked(row, s.timeKey)
+		if !ok {
+			continue
+		}
+
+		start := at.UTC().Truncate(s.resolution)
+		if start.Add(s.resolution).After(now) {
+			continue
+		}
+
+		if value, ok := s.source.fieldUnlocked(row, s.valueKey); ok {
+			buckets[start] = append(buckets[start], value)
+		}
+	}
+	s.source.Locker.RUnlock()
+
+	starts := make([]time.Time, 0, len(buckets))
+	for start := range buckets {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	entries := make([]batchEntry, 0, len(buckets))
+	for _, start := range starts {
+		values := buckets[start]
+		value, err := s.fn(values)
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, batchEntry{id: s.bucketID(start), data: map[KeyName]interface{}{
+			s.timeKey:            start,
+			s.valueKey:           value,
+			DownsampleSamplesKey: len(values),
+		}})
+	}
+
+	if len(entries) == 0 {
+		return 0
+	}
+
+	count, err := s.target.insertEntriesWithOptions(entries, batchOptions{skipExisting: true})
+	if err != nil {
+		log.Printf("error inserting rollups: %s", err.Error())
+	}
+
+	return count
+}
+
+// Run rolls the closed buckets up every interval until ctx is done.
+func (s *Downsampler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Rollup(time.Now().UTC())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// bucketID returns the ID of the target row of the bucket starting at start, derived from the
+// settings of the Downsampler so a bucket is only rolled up once.
+func (s *Downsampler) bucketID(start time.Time) uuid.UUID {
+	name := fmt.Sprintf("mframe:downsample:%s:%s:%s:%d:%d", s.timeKey, s.valueKey, s.agg, s.resolution, start.UnixNano())
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(name))
+}
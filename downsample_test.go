@@ -0,0 +1,65 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestDownsample(t *testing.T) {
+	var hot mframe.DataFrame
+	hot.Init(time.Hour)
+
+	var warm mframe.DataFrame
+	warm.Init(30 * 24 * time.Hour)
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		hot.Insert(map[mframe.KeyName]interface{}{"at": start.Add(time.Duration(i) * 10 * time.Minute), "bytes": i})
+	}
+	hot.Insert(map[mframe.KeyName]interface{}{"bytes": 1000})
+
+	downsampler, err := hot.Downsample("at", "bytes", time.Hour, "sum", &warm)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	if added := downsampler.Rollup(start.Add(90 * time.Minute)); added != 1 {
+		t.Fatalf("expected only the closed bucket to be rolled up, but got %d rows", added)
+	}
+	if added := downsampler.Rollup(start.Add(2 * time.Hour)); added != 1 {
+		t.Fatalf("expected the second bucket to be rolled up once, but got %d rows", added)
+	}
+	if added := downsampler.Rollup(start.Add(3 * time.Hour)); added != 0 {
+		t.Errorf("expected no rows for buckets already rolled up, but got %d", added)
+	}
+
+	rows := warm.Filter(mframe.Between, "at", []time.Time{start, start}, nil).ToSlice()
+	if len(rows) != 1 || rows[0]["bytes"] != 15.0 || rows[0][mframe.DownsampleSamplesKey] != 6.0 {
+		t.Errorf("expected the first bucket to sum 15 over 6 samples, but got %v", rows)
+	}
+	if sum, _ := warm.Sum("bytes"); sum != 66 {
+		t.Errorf("expected the buckets to sum 66, but got %v", sum)
+	}
+
+	tests := []struct {
+		name       string
+		resolution time.Duration
+		agg        string
+		target     *mframe.DataFrame
+	}{
+		{"no resolution", 0, "sum", &warm},
+		{"unknown aggregate", time.Hour, "nope", &warm},
+		{"no target", time.Hour, "sum", nil},
+		{"same frame", time.Hour, "sum", &hot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := hot.Downsample("at", "bytes", tt.resolution, tt.agg, tt.target); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package mframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// PSISignificant is the population stability index above which a distribution is usually considered to have
+// shifted significantly. Values between 0.1 and PSISignificant indicate a moderate shift.
+const PSISignificant = 0.25
+
+// psiSmoothing replaces the share of values missing from one of the distributions, so the
+// population stability index stays finite.
+const psiSmoothing = 1e-4
+
+// DriftValue holds the counts of a value in the baseline and current distributions.
+type DriftValue struct {
+	Value         interface{}
+	Baseline      int
+	Current       int
+	BaselineShare float64
+	CurrentShare  float64
+	// Contribution is the part of the population stability index due to this value.
+	Contribution float64
+}
+
+// Drift compares the distribution of the values of a key between a baseline and a current set of rows.
+type Drift struct {
+	Key      KeyName
+	Baseline int // Rows of the baseline holding the key
+	Current  int // Rows of the current set holding the key
+	// PSI is the population stability index, 0 for identical distributions. See PSISignificant.
+	PSI float64
+	// ChiSquare is the statistic of the chi-square test of homogeneity of both distributions, with
+	// DegreesOfFreedom degrees of freedom and PValue the probability of a statistic as large by chance.
+	ChiSquare        float64
+	DegreesOfFreedom int
+	PValue           float64
+	// Values holds every value seen in either distribution, by decreasing contribution to the PSI.
+	Values []DriftValue
+}
+
+// Significant reports whether the population stability index reaches PSISignificant.
+func (d Drift) Significant() bool {
+	return d.PSI >= PSISignificant
+}
+
+// CompareDistributions compares the distribution of the values of key in the baseline frame with
+// the one in the current frame, to flag sudden changes in categorical telemetry. Rows without the key are ignored.
+func CompareDistributions(baseline, current *DataFrame, key KeyName) (Drift, error) {
+	baseline.Locker.RLock()
+	expected := baseline.valueCountsUnlocked(key, nil)
+	baseline.Locker.RUnlock()
+
+	current.Locker.RLock()
+	actual := current.valueCountsUnlocked(key, nil)
+	current.Locker.RUnlock()
+
+	return computeDrift(key, expected, actual)
+}
+
+// WindowDrift compares the distribution of the values of key in the rows whose timeKey falls in
+// [baselineStart, split) with the one in the rows whose timeKey falls in [split, end).
+// Rows without a time value for timeKey are ignored.
+func (d *DataFrame) WindowDrift(key, timeKey KeyName, baselineStart, split, end time.Time) (Drift, error) {
+	if !baselineStart.Before(split) || !split.Before(end) {
+		return Drift{}, fmt.Errorf("windows must satisfy baselineStart < split < end")
+	}
+
+	d.Locker.RLock()
+	expected := d.valueCountsUnlocked(key, func(row Row) bool {
+		at, ok := d.timeFieldUnlocked(row, timeKey)
+		return ok && !at.Before(baselineStart) && at.Before(split)
+	})
+	actual := d.valueCountsUnlocked(key, func(row Row) bool {
+		at, ok := d.timeFieldUnlocked(row, timeKey)
+		return ok && !at.Before(split) && at.Before(end)
+	})
+	d.Locker.RUnlock()
+
+	return computeDrift(key, expected, actual)
+}
+
+// valueCountsUnlocked counts the rows holding each value of key among the rows accepted by include,
+// or all rows when include is nil, without acquiring locks.
+func (d *DataFrame) valueCountsUnlocked(key KeyName, include func(Row) bool) map[interface{}]int {
+	counts := make(map[interface{}]int)
+	for _, row := range d.Data {
+		if include != nil && !include(row) {
+			continue
+		}
+		if value, ok := d.fieldUnlocked(row, key); ok {
+			counts[value]++
+		}
+	}
+	return counts
+}
+
+// timeFieldUnlocked returns the time value of key in the row, without acquiring locks.
+func (d *DataFrame) timeFieldUnlocked(row Row, key KeyName) (time.Time, bool) {
+	value, ok := d.fieldUnlocked(row, key)
+	if !ok {
+		return time.Time{}, false
+	}
+	at, ok := value.(time.Time)
+	return at, ok
+}
+
+// computeDrift compares two value distributions.
+func computeDrift(key KeyName, expected, actual map[interface{}]int) (Drift, error) {
+	result := Drift{Key: key}
+	for _, c := range expected {
+		result.Baseline += c
+	}
+	for _, c := range actual {
+		result.Current += c
+	}
+
+	if result.Baseline == 0 || result.Current == 0 {
+		return result, stats.EmptyInputErr
+	}
+
+	values := make(map[interface{}]bool, len(expected)+len(actual))
+	for value := range expected {
+		values[value] = true
+	}
+	for value := range actual {
+		values[value] = true
+	}
+
+	total := float64(result.Baseline + result.Current)
+	for value := range values {
+		v := DriftValue{
+			Value:         value,
+			Baseline:      expected[value],
+			Current:       actual[value],
+			BaselineShare: float64(expected[value]) / float64(result.Baseline),
+			CurrentShare:  float64(actual[value]) / float64(result.Current),
+		}
+
+		e := math.Max(v.BaselineShare, psiSmoothing)
+		a := math.Max(v.CurrentShare, psiSmoothing)
+		v.Contribution = (a - e) * math.Log(a/e)
+		result.PSI += v.Contribution
+
+		column := float64(v.Baseline + v.Current)
+		for _, observed := range [2]struct{ count, rows int }{{v.Baseline, result.Baseline}, {v.Current, result.Current}} {
+			want := float64(observed.rows) * column / total
+			result.ChiSquare += (float64(observed.count) - want) * (float64(observed.count) - want) / want
+		}
+
+		result.Values = append(result.Values, v)
+	}
+
+	result.DegreesOfFreedom = len(values) - 1
+	result.PValue = chiSquareSurvival(result.ChiSquare, result.DegreesOfFreedom)
+
+	sort.Slice(result.Values, func(i, j int) bool {
+		if result.Values[i].Contribution != result.Values[j].Contribution {
+			return result.Values[i].Contribution > result.Values[j].Contribution
+		}
+		return fmt.Sprint(result.Values[i].Value) < fmt.Sprint(result.Values[j].Value)
+	})
+
+	return result, nil
+}
+
+// chiSquareSurvival returns the probability that a chi-square variable with df degrees of freedom exceeds x.
+func chiSquareSurvival(x float64, df int) float64 {
+	if df <= 0 || x <= 0 {
+		return 1
+	}
+	return upperGamma(float64(df)/2, x/2)
+}
+
+// upperGamma returns the regularized upper incomplete gamma function Q(a, x), using its series
+// expansion for x < a+1 and its continued fraction otherwise.
+func upperGamma(a, x float64) float64 {
+	const (
+		iterations = 200
+		epsilon    = 1e-14
+		tiny       = 1e-300
+	)
+
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1; n < iterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < iterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h * prefix
+}
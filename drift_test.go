@@ -0,0 +1,93 @@
+package mframe_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func protocolFrame(counts map[string]int) *mframe.DataFrame {
+	frame := new(mframe.DataFrame)
+	frame.Init(24 * time.Hour)
+	for protocol, n := range counts {
+		for i := 0; i < n; i++ {
+			frame.Insert(map[mframe.KeyName]interface{}{"protocol": protocol})
+		}
+	}
+	return frame
+}
+
+func TestCompareDistributions(t *testing.T) {
+	baseline := protocolFrame(map[string]int{"tcp": 80, "udp": 20})
+
+	tests := []struct {
+		name        string
+		current     map[string]int
+		significant bool
+	}{
+		{"same distribution", map[string]int{"tcp": 40, "udp": 10}, false},
+		{"shifted distribution", map[string]int{"tcp": 20, "udp": 80}, true},
+		{"new value", map[string]int{"tcp": 50, "udp": 10, "icmp": 40}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drift, err := mframe.CompareDistributions(baseline, protocolFrame(tt.current), "protocol")
+			if err != nil {
+				t.Fatalf("expected no error, but got %v", err)
+			}
+			if drift.Significant() != tt.significant {
+				t.Errorf("expected significant %v, but got PSI %f", tt.significant, drift.PSI)
+			}
+			if tt.significant && drift.PValue > 0.001 {
+				t.Errorf("expected a small p-value, but got %f", drift.PValue)
+			}
+			if !tt.significant && (drift.PSI > 1e-9 || math.Abs(drift.PValue-1) > 1e-9) {
+				t.Errorf("expected no drift, but got PSI %f and p-value %f", drift.PSI, drift.PValue)
+			}
+		})
+	}
+
+	drift, _ := mframe.CompareDistributions(baseline, protocolFrame(map[string]int{"tcp": 50, "udp": 10, "icmp": 40}), "protocol")
+	if drift.Values[0].Value != "icmp" || drift.Values[0].Baseline != 0 || drift.Values[0].Current != 40 {
+		t.Errorf("expected icmp to contribute the most, but got %+v", drift.Values[0])
+	}
+	if drift.DegreesOfFreedom != 2 {
+		t.Errorf("expected 2 degrees of freedom, but got %d", drift.DegreesOfFreedom)
+	}
+
+	if _, err := mframe.CompareDistributions(baseline, protocolFrame(nil), "protocol"); err == nil {
+		t.Error("expected an error for an empty distribution")
+	}
+}
+
+func TestWindowDrift(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		protocol := "tcp"
+		if i%10 == 0 || i >= 50 && i%2 == 0 {
+			protocol = "dns"
+		}
+		cache.Insert(map[mframe.KeyName]interface{}{"protocol": protocol, "at": start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	drift, err := cache.WindowDrift("protocol", "at", start, start.Add(50*time.Minute), start.Add(100*time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if drift.Baseline != 50 || drift.Current != 50 {
+		t.Errorf("expected 50 rows in each window, but got %d and %d", drift.Baseline, drift.Current)
+	}
+	if !drift.Significant() {
+		t.Errorf("expected a significant drift, but got PSI %f", drift.PSI)
+	}
+
+	if _, err := cache.WindowDrift("protocol", "at", start, start, start); err == nil {
+		t.Error("expected an error for empty windows")
+	}
+}
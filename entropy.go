@@ -0,0 +1,68 @@
+package mframe
+
+import (
+	"math"
+)
+
+// EntropySuffix is appended to a key name to form the derived key holding the entropy of its values.
+const EntropySuffix = "_entropy"
+
+// ShannonEntropy returns the Shannon entropy of the characters in s, in bits per character.
+// Random-looking strings such as DGA domains or encoded payloads score higher than natural words.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	return entropyOfCounts(counts, total)
+}
+
+// Entropy returns the Shannon entropy, in bits, of the distribution of values in the specified field.
+// A field where every row has the same value has an entropy of 0.
+func (d *DataFrame) Entropy(field KeyName) (float64, error) {
+	return d.AggregateField("entropy", field)
+}
+
+// SetEntropyKeys configures string keys for which a derived numeric key named after the key
+// plus EntropySuffix is indexed on insert, holding the ShannonEntropy of the value.
+// Only rows inserted after the call are affected.
+func (d *DataFrame) SetEntropyKeys(keys ...KeyName) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.entropyKeys = make(map[KeyName]bool, len(keys))
+	for _, key := range keys {
+		d.entropyKeys[key] = true
+	}
+}
+
+// entropyAggregate returns the Shannon entropy of the distribution of values.
+func entropyAggregate(values []interface{}) (float64, error) {
+	counts := make(map[interface{}]int)
+	for _, v := range values {
+		counts[v]++
+	}
+	return entropyOfCounts(counts, len(values)), nil
+}
+
+// entropyOfCounts computes the Shannon entropy in bits from occurrence counts.
+func entropyOfCounts[K comparable](counts map[K]int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
@@ -0,0 +1,77 @@
+package mframe_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestShannonEntropy(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"ab", 1},
+		{"abcd", 2},
+	}
+
+	for _, tt := range tests {
+		if result := mframe.ShannonEntropy(tt.value); math.Abs(result-tt.expected) > 1e-9 {
+			t.Errorf("expected entropy %v for '%s', but got %v", tt.expected, tt.value, result)
+		}
+	}
+
+	if mframe.ShannonEntropy("x7kq9zj2vw4p.com") <= mframe.ShannonEntropy("google.com") {
+		t.Error("expected a random-looking domain to have a higher entropy")
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for _, v := range []string{"a", "b", "c", "d"} {
+		cache.Insert(map[mframe.KeyName]interface{}{"name": v, "same": "x"})
+	}
+
+	result, err := cache.Entropy("name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(result-2) > 1e-9 {
+		t.Errorf("expected entropy 2, but got %v", result)
+	}
+
+	result, err = cache.Entropy("same")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != 0 {
+		t.Errorf("expected entropy 0, but got %v", result)
+	}
+}
+
+func TestSetEntropyKeys(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetEntropyKeys("domain")
+
+	cache.Insert(map[mframe.KeyName]interface{}{"domain": "abcd", "other": "abcd"})
+	cache.Insert(map[mframe.KeyName]interface{}{"domain": "aaaa"})
+
+	if cache.Keys["domain"+mframe.EntropySuffix] != mframe.Numeric {
+		t.Fatalf("expected derived entropy key to be numeric")
+	}
+	if _, ok := cache.Keys["other"+mframe.EntropySuffix]; ok {
+		t.Error("expected no derived entropy key for unconfigured keys")
+	}
+
+	high := cache.Filter(mframe.Greater, "domain"+mframe.EntropySuffix, 1.5, nil)
+	if high.Count() != 1 {
+		t.Errorf("expected 1 high entropy row, but got %d", high.Count())
+	}
+}
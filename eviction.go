@@ -0,0 +1,241 @@
+package mframe
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EvictionPolicy selects which row is removed when an insert exceeds the maximum number of rows.
+type EvictionPolicy int
+
+const (
+	// EvictOldestExpiry removes the row that would expire first.
+	EvictOldestExpiry EvictionPolicy = 1
+	// EvictLRU removes the least recently used row. Rows are used when they are inserted, updated,
+	// read with Get or touched with Touch.
+	EvictLRU EvictionPolicy = 2
+	// EvictRandom removes an arbitrary row.
+	EvictRandom EvictionPolicy = 3
+)
+
+// EvictReason tells why a row was removed from the DataFrame.
+type EvictReason int
+
+const (
+	// EvictExpired is reported for rows removed by the cleaner after their expiration.
+	EvictExpired EvictReason = 1
+	// EvictRemoved is reported for rows removed explicitly, e.g. by RemoveElement or DeleteWhere.
+	EvictRemoved EvictReason = 2
+	// EvictCapacity is reported for rows removed to respect the maximum number of rows.
+	EvictCapacity EvictReason = 3
+)
+
+// String returns the name of the reason.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExpired:
+		return "expired"
+	case EvictRemoved:
+		return "removed"
+	case EvictCapacity:
+		return "capacity"
+	default:
+		return "unknown"
+	}
+}
+
+// eviction is a removed row waiting to be reported to the evict hooks once the lock is released.
+type eviction struct {
+	id     uuid.UUID
+	row    Row
+	reason EvictReason
+}
+
+// recency is the list of row IDs from least to most recently used, kept only for EvictLRU.
+// It has its own lock because reads by ID update it under the read lock of the DataFrame.
+type recency struct {
+	mu       sync.Mutex
+	enabled  bool
+	rows     *list.List
+	elements map[uuid.UUID]*list.Element
+}
+
+// SetMaxRows caps the number of rows of the DataFrame at maxRows. When an insert exceeds it, rows are
+// removed according to policy until the DataFrame fits, so a burst of events cannot exhaust memory before
+// the TTL removes them. The row being inserted is never evicted. A maxRows of 0 or less removes the cap.
+// Rows above a new cap are only evicted by the next insert.
+func (d *DataFrame) SetMaxRows(maxRows int, policy EvictionPolicy) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.maxRows = max(maxRows, 0)
+	d.eviction = policy
+
+	d.recency.mu.Lock()
+	defer d.recency.mu.Unlock()
+
+	d.recency.enabled = d.maxRows > 0 && policy == EvictLRU
+	d.recency.rows = nil
+	d.recency.elements = nil
+	if !d.recency.enabled {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for id := range d.Data {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+	for _, id := range ids {
+		d.recency.touchLocked(id)
+	}
+}
+
+// evictUnlocked removes rows according to the eviction policy until the DataFrame fits its maximum number
+// of rows, and then its memory limit, never evicting keep, without acquiring locks.
+func (d *DataFrame) evictUnlocked(keep uuid.UUID) {
+	for d.maxRows > 0 && len(d.Data) > d.maxRows {
+		victim, ok := d.evictionVictimUnlocked(keep)
+		if !ok {
+			break
+		}
+		d.evictElementUnlocked(victim, EvictCapacity)
+	}
+
+	d.evictMemoryUnlocked(keep)
+}
+
+// evictElementUnlocked removes the row like removeElementUnlocked, queueing it for the evict hooks
+// when any is registered, without acquiring locks. It does nothing if the row does not exist.
+func (d *DataFrame) evictElementUnlocked(id uuid.UUID, reason EvictReason) {
+	row, ok := d.Data[id]
+	if !ok {
+		return
+	}
+
+	if d.hooks.hasEvict() {
+		d.evictions = append(d.evictions, eviction{id: id, row: row, reason: reason})
+	}
+	d.removeElementUnlocked(id)
+
+	change := ChangeDelete
+	if reason == EvictExpired {
+		change = ChangeExpire
+		d.activity.expired++
+	} else {
+		d.activity.removed++
+	}
+	d.publishUnlocked(change, id, row, nil)
+}
+
+// unlockAndNotify checks the watches, releases the write lock and then reports the queued evictions
+// to the evict hooks, the watch events to their callbacks and the matched rows to the trigger actions.
+func (d *DataFrame) unlockAndNotify() {
+	d.checkWatchesUnlocked()
+
+	evictions := d.evictions
+	notices := d.watchers.notices
+	runs := d.triggers.pending
+	d.evictions = nil
+	d.watchers.notices = nil
+	d.triggers.pending = nil
+	d.Locker.Unlock()
+
+	d.runEvict(evictions)
+	for _, notice := range notices {
+		notice.callback(notice.event)
+	}
+	d.runTriggers(runs)
+}
+
+// evictionVictimUnlocked returns the next row to evict other than keep, without acquiring locks.
+func (d *DataFrame) evictionVictimUnlocked(keep uuid.UUID) (uuid.UUID, bool) {
+	switch d.eviction {
+	case EvictLRU:
+		if id, ok := d.recency.oldest(keep); ok {
+			return id, true
+		}
+	case EvictOldestExpiry:
+		if id, ok := d.firstExpiringUnlocked(keep); ok {
+			return id, true
+		}
+	}
+
+	for id := range d.Data {
+		if id != keep {
+			return id, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// firstExpiringUnlocked returns the row other than keep that expires first, without acquiring locks.
+func (d *DataFrame) firstExpiringUnlocked(keep uuid.UUID) (uuid.UUID, bool) {
+	if next, ok := d.nextExpiryUnlocked(); ok && next.id != keep {
+		return next.id, true
+	}
+
+	var victim uuid.UUID
+	var first time.Time
+	found := false
+	for id, expireAt := range d.ExpireAt {
+		if id == keep {
+			continue
+		}
+		if !found || expireAt.Before(first) {
+			victim, first, found = id, expireAt, true
+		}
+	}
+	return victim, found
+}
+
+// touch marks the row as the most recently used one.
+func (r *recency) touch(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.touchLocked(id)
+}
+
+// touchLocked marks the row as the most recently used one, with r.mu held.
+func (r *recency) touchLocked(id uuid.UUID) {
+	if !r.enabled {
+		return
+	}
+	if r.rows == nil {
+		r.rows = list.New()
+		r.elements = make(map[uuid.UUID]*list.Element)
+	}
+	if e, ok := r.elements[id]; ok {
+		r.rows.MoveToBack(e)
+		return
+	}
+	r.elements[id] = r.rows.PushBack(id)
+}
+
+// remove drops the row from the list.
+func (r *recency) remove(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.elements[id]; ok {
+		r.rows.Remove(e)
+		delete(r.elements, id)
+	}
+}
+
+// oldest returns the least recently used row other than keep.
+func (r *recency) oldest(keep uuid.UUID) (uuid.UUID, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rows == nil {
+		return uuid.Nil, false
+	}
+	for e := r.rows.Front(); e != nil; e = e.Next() {
+		if id := e.Value.(uuid.UUID); id != keep {
+			return id, true
+		}
+	}
+	return uuid.Nil, false
+}
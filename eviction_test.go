@@ -0,0 +1,72 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSetMaxRows(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   mframe.EvictionPolicy
+		expected []float64
+	}{
+		{"oldest expiry", mframe.EvictOldestExpiry, []float64{0, 3, 4}},
+		{"lru", mframe.EvictLRU, []float64{0, 3, 4}},
+		{"random", mframe.EvictRandom, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache mframe.DataFrame
+			cache.Init(24 * time.Hour)
+			cache.SetMaxRows(3, tt.policy)
+
+			ids := make([]uuid.UUID, 0)
+			for i := 0; i < 3; i++ {
+				id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"seq": i})
+				ids = append(ids, id)
+				time.Sleep(time.Millisecond)
+			}
+
+			// Row 0 is touched, becoming the most recently used and the last to expire.
+			if err := cache.Touch(ids[0]); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var last uuid.UUID
+			for i := 3; i < 5; i++ {
+				last, _ = cache.InsertReturningID(map[mframe.KeyName]interface{}{"seq": i})
+			}
+
+			if cache.Count() != 3 {
+				t.Fatalf("expected 3 rows, but got %d", cache.Count())
+			}
+			if _, ok := cache.Get(last); !ok {
+				t.Error("expected the inserted row not to be evicted")
+			}
+
+			for _, seq := range tt.expected {
+				if cache.CountWhere(mframe.Equals, "seq", seq, nil) != 1 {
+					t.Errorf("expected row %v to be kept", seq)
+				}
+			}
+		})
+	}
+}
+
+func TestSetMaxRowsDisabled(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetMaxRows(1, mframe.EvictRandom)
+	cache.SetMaxRows(0, mframe.EvictRandom)
+
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{{"seq": 1}, {"seq": 2}})
+	if cache.Count() != 2 {
+		t.Errorf("expected no cap, but got %d rows", cache.Count())
+	}
+}
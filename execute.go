@@ -0,0 +1,133 @@
+package mframe
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// QuerySpec is a query run by ExecuteMany. When Agg has functions, the query computes them over
+// the matching rows instead of returning the rows.
+type QuerySpec struct {
+	Name string
+	Spec FilterSpec
+	Agg  AggSpec
+}
+
+// QueryResult holds the outcome of a query run by ExecuteMany. Rows holds the matching rows of queries without
+// aggregations and Aggregates the result of each aggregation otherwise. Count is the number of matching rows.
+type QueryResult struct {
+	Name       string
+	Rows       *DataFrame
+	Aggregates map[string]float64
+	Count      int
+	Duration   time.Duration
+	Err        error
+}
+
+// ExecuteMany runs a dashboard's worth of queries concurrently, at most maxParallel at a time, under a single
+// read lock so every query sees the same snapshot of the DataFrame. Queries not finished totalDeadline after
+// the call are interrupted, failing with an error wrapping context.DeadlineExceeded. A maxParallel of zero
+// runs up to GOMAXPROCS queries at a time, and a totalDeadline of zero disables the deadline.
+// Returns the result of each query in the order of the queries.
+func (d *DataFrame) ExecuteMany(queries []QuerySpec, maxParallel int, totalDeadline time.Duration) []QueryResult {
+	results := make([]QueryResult, len(queries))
+	if len(queries) == 0 {
+		return results
+	}
+
+	if maxParallel <= 0 {
+		maxParallel = runtime.GOMAXPROCS(0)
+	}
+
+	ctx := context.Background()
+	if totalDeadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, totalDeadline)
+		defer cancel()
+	}
+
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	slots := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = QueryResult{Name: query.Name, Err: interruptedQuery(query, ctx)}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, query QuerySpec) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = d.executeUnlocked(ctx, query)
+		}(i, query)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// executeUnlocked runs a query of ExecuteMany without acquiring locks, stopping when ctx is done.
+func (d *DataFrame) executeUnlocked(ctx context.Context, query QuerySpec) (result QueryResult) {
+	result.Name = query.Name
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	if ctx.Err() != nil {
+		result.Err = interruptedQuery(query, ctx)
+		return result
+	}
+
+	functions, err := lookupAggregates(query.Agg.Functions)
+	if err != nil {
+		result.Err = fmt.Errorf("query '%s' failed: %w", query.Name, err)
+		return result
+	}
+
+	ids, ok := d.specIDsUntilUnlocked(query.Spec, ctx.Done())
+	if !ok {
+		result.Err = interruptedQuery(query, ctx)
+		return result
+	}
+	result.Count = len(ids)
+
+	if len(functions) > 0 {
+		values := make([]interface{}, 0, len(ids))
+		for id := range ids {
+			if value, ok := d.Data[id][query.Agg.Field]; ok {
+				values = append(values, value)
+			}
+		}
+
+		result.Aggregates, err = applyAggregates(functions, values)
+		if err != nil {
+			result.Err = fmt.Errorf("query '%s' failed: %w", query.Name, err)
+		}
+		return result
+	}
+
+	rows := new(DataFrame)
+	rows.Init(d.TTL)
+	for id := range ids {
+		if ctx.Err() != nil {
+			result.Err = interruptedQuery(query, ctx)
+			return result
+		}
+		rows.insertWithIDUnlocked(id, d.Data[id])
+	}
+	result.Rows = rows
+
+	return result
+}
+
+// interruptedQuery returns the error of a query of ExecuteMany interrupted by the deadline.
+func interruptedQuery(query QuerySpec, ctx context.Context) error {
+	return fmt.Errorf("query '%s' interrupted: %w", query.Name, ctx.Err())
+}
@@ -0,0 +1,60 @@
+package mframe_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestExecuteMany(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"action": "login", "bytes": 100},
+		{"action": "login", "bytes": 300},
+		{"action": "logout", "bytes": 50},
+	})
+
+	logins := mframe.FilterSpec{Conditions: []mframe.Condition{{Operator: mframe.Equals, Key: "action", Value: "login"}}}
+	queries := []mframe.QuerySpec{
+		{Name: "logins", Spec: logins},
+		{Name: "login bytes", Spec: logins, Agg: mframe.AggSpec{Field: "bytes", Functions: []string{"sum", "max"}}},
+		{Name: "all", Spec: mframe.FilterSpec{}},
+		{Name: "unknown", Agg: mframe.AggSpec{Field: "bytes", Functions: []string{"nope"}}},
+	}
+
+	results := cache.ExecuteMany(queries, 2, time.Minute)
+	if len(results) != len(queries) {
+		t.Fatalf("expected %d results, but got %d", len(queries), len(results))
+	}
+
+	if r := results[0]; r.Name != "logins" || r.Err != nil || r.Count != 2 || r.Rows.Count() != 2 {
+		t.Errorf("expected 2 login rows, but got %+v", r)
+	}
+	if r := results[1]; r.Err != nil || r.Rows != nil || r.Aggregates["sum"] != 400 || r.Aggregates["max"] != 300 {
+		t.Errorf("expected login bytes aggregates, but got %+v", r)
+	}
+	if r := results[2]; r.Err != nil || r.Count != 3 {
+		t.Errorf("expected 3 rows, but got %+v", r)
+	}
+	if r := results[3]; r.Err == nil {
+		t.Error("expected an error for an unknown aggregate")
+	}
+}
+
+func TestExecuteManyDeadline(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	for i := 0; i < 1000; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"action": "login"})
+	}
+
+	results := cache.ExecuteMany([]mframe.QuerySpec{{Name: "logins"}}, 1, time.Nanosecond)
+
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, but got %v", results[0].Err)
+	}
+}
@@ -0,0 +1,86 @@
+package mframe
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// expiryEntry is an expiration time of a row in the expiry heap.
+type expiryEntry struct {
+	at time.Time
+	id uuid.UUID
+}
+
+// expiryHeap is a min-heap of expiration times, so the cleaner only visits expired rows.
+// Entries are never updated in place: changing an expiration pushes a new entry, and entries that no
+// longer match ExpireAt are discarded when they reach the top.
+type expiryHeap []expiryEntry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h expiryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *expiryHeap) Push(x any) { *h = append(*h, x.(expiryEntry)) }
+
+func (h *expiryHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// setExpireAtUnlocked sets the expiration time of a row, without acquiring locks.
+// The heap is rebuilt when stale entries outnumber the live ones.
+func (d *DataFrame) setExpireAtUnlocked(id uuid.UUID, at time.Time) {
+	d.ExpireAt[id] = at
+	heap.Push(&d.expiry, expiryEntry{at: at, id: id})
+
+	if len(d.expiry) > 2*len(d.ExpireAt)+64 {
+		d.rebuildExpiryUnlocked()
+	}
+}
+
+// rebuildExpiryUnlocked rebuilds the expiry heap from ExpireAt, without acquiring locks.
+func (d *DataFrame) rebuildExpiryUnlocked() {
+	d.expiry = make(expiryHeap, 0, len(d.ExpireAt))
+	for id, at := range d.ExpireAt {
+		d.expiry = append(d.expiry, expiryEntry{at: at, id: id})
+	}
+	heap.Init(&d.expiry)
+}
+
+// nextExpiryUnlocked discards stale entries from the top of the heap and returns the row expiring first,
+// without acquiring locks. Returns false if no row has an expiration time.
+func (d *DataFrame) nextExpiryUnlocked() (expiryEntry, bool) {
+	for len(d.expiry) > 0 {
+		top := d.expiry[0]
+		if at, ok := d.ExpireAt[top.id]; ok && at.Equal(top.at) {
+			return top, true
+		}
+		heap.Pop(&d.expiry)
+	}
+	return expiryEntry{}, false
+}
+
+// hasExpiredUnlocked reports whether the top of the heap is before now, without acquiring locks.
+// The entry may be stale, so the caller must still check it under the write lock.
+func (d *DataFrame) hasExpiredUnlocked(now time.Time) bool {
+	return len(d.expiry) > 0 && d.expiry[0].at.Before(now)
+}
+
+// removeExpiredUnlocked removes every row whose expiration is before now, visiting only the expired
+// entries of the heap, and returns the number of removed rows, without acquiring locks.
+func (d *DataFrame) removeExpiredUnlocked(now time.Time) int {
+	removed := 0
+	for {
+		next, ok := d.nextExpiryUnlocked()
+		if !ok || !next.at.Before(now) {
+			return removed
+		}
+		heap.Pop(&d.expiry)
+		d.evictElementUnlocked(next.id, EvictExpired)
+		removed++
+	}
+}
@@ -0,0 +1,35 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestCleanExpiredAfterTouch(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(1500 * time.Millisecond)
+
+	touched, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"host": "touched"})
+	expired, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"host": "expired"})
+
+	cache.StartCleaner()
+	defer cache.StopCleaner()
+
+	time.Sleep(1200 * time.Millisecond)
+	for i := 0; i < 1000; i++ {
+		if err := cache.Touch(touched); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+
+	if _, ok := cache.Get(expired); ok {
+		t.Error("expected the untouched row to expire")
+	}
+	if _, ok := cache.Get(touched); !ok {
+		t.Error("expected the touched row to be kept")
+	}
+}
@@ -24,6 +24,11 @@ func (d *DataFrame) Explain(operator Operator, key KeyName, value any) ExplainRe
 	d.Locker.RLock()
 	defer d.Locker.RUnlock()
 
+	return d.explainUnlocked(operator, key, value)
+}
+
+// explainUnlocked analyzes a filter operation like Explain, without acquiring locks.
+func (d *DataFrame) explainUnlocked(operator Operator, key KeyName, value any) ExplainResult {
 	result := ExplainResult{
 		Operator:  operatorToString(operator),
 		Key:       string(key),
@@ -57,6 +62,13 @@ func (d *DataFrame) Explain(operator Operator, key KeyName, value any) ExplainRe
 	result.KeyType = keyTypeToString(keyType)
 	result.IndexUsed = true
 
+	if target, ok := d.accelTargetUnlocked(Condition{Operator: operator, Key: key, Value: value}); ok && d.accel.has(target.key, target.kind) {
+		result.Details = append(result.Details, fmt.Sprintf("%s index seeks the matching values", target.kind))
+	}
+	if keyType == String && d.accel.has(key, TrigramIndex) && (operator == Contains || operator == NotContains || operator == RegExp) {
+		result.Details = append(result.Details, "Trigram index pre-filters the candidate values")
+	}
+
 	// Estimate row count based on index
 	switch keyType {
 	case Numeric:
@@ -81,6 +93,15 @@ func (d *DataFrame) Explain(operator Operator, key KeyName, value any) ExplainRe
 		}
 	}
 
+	// Add value statistics
+	stats := d.keyStatsUnlocked(key)
+	if stats.Min != nil {
+		result.Details = append(result.Details, fmt.Sprintf("Values range from %v to %v", stats.Min, stats.Max))
+	}
+	if len(stats.TopValues) > 0 {
+		result.Details = append(result.Details, fmt.Sprintf("Most frequent value '%v' holds %d rows", stats.TopValues[0].Value, stats.TopValues[0].Count))
+	}
+
 	// Add selectivity information
 	if result.TotalRows > 0 {
 		selectivity := float64(result.EstimatedRows) / float64(result.TotalRows) * 100
@@ -0,0 +1,151 @@
+package mframe
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// misestimateFactor is the ratio between actual and estimated rows from which ExplainAnalyze reports
+// a condition as misestimated.
+const misestimateFactor = 2
+
+// AnalyzedStep is a step of the plan of a FilterSpec along with what happened when it ran.
+type AnalyzedStep struct {
+	PlanStep
+	// ActualRows is the number of rows matching the condition on its own and ActualRemaining the number
+	// of rows left after intersecting it with the previous steps.
+	ActualRows      int
+	ActualRemaining int
+	// Duration is the time spent evaluating the condition, without the intersection.
+	Duration time.Duration
+	// IndexHits is the number of index entries the condition read.
+	IndexHits int
+}
+
+// AnalyzeResult is the plan of a FilterSpec with the statistics of its execution, returned by ExplainAnalyze.
+type AnalyzeResult struct {
+	Plan  SpecExplainResult
+	Steps []AnalyzedStep
+	// ActualRows is the number of rows matching the FilterSpec and Duration the wall time of the execution.
+	ActualRows int
+	Duration   time.Duration
+	// IndexHits is the number of index entries read by all the conditions.
+	IndexHits int
+	// RegexCacheHits and RegexCacheMisses count the lookups of compiled regular expressions during the
+	// execution, including those of queries running concurrently on the DataFrame.
+	RegexCacheHits   uint64
+	RegexCacheMisses uint64
+	Details          []string
+}
+
+// ExplainAnalyze runs the FilterSpec and returns its plan, as reported by ExplainSpec, along with the
+// actual number of rows matched by every condition and left after every intersection, the time spent,
+// the index entries read and the regex cache lookups, to check the estimates against real data.
+// Conditions whose actual rows differ from the estimate by more than a factor of two are listed in Details.
+// The matching rows themselves are not returned.
+func (d *DataFrame) ExplainAnalyze(spec FilterSpec) AnalyzeResult {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	plan := d.explainSpecUnlocked(spec)
+
+	result := AnalyzeResult{
+		Plan:    plan,
+		Steps:   make([]AnalyzedStep, len(plan.Steps)),
+		Details: make([]string, 0),
+	}
+
+	hits, misses := d.metrics.regexHits.Load(), d.metrics.regexMisses.Load()
+	start := time.Now()
+
+	if len(spec.Conditions) == 0 {
+		result.ActualRows = len(d.Data)
+	}
+
+	var remaining map[uuid.UUID]struct{}
+	for i, step := range plan.Steps {
+		c := spec.Conditions[step.Position]
+		analyzed := AnalyzedStep{PlanStep: step}
+
+		conditionStart := time.Now()
+		ids := make(map[uuid.UUID]struct{})
+		d.matchUnlocked(nil, c.Operator, c.Key, c.Value, c.Options, func(id uuid.UUID) bool {
+			analyzed.IndexHits++
+			ids[id] = struct{}{}
+			return true
+		})
+		analyzed.Duration = time.Since(conditionStart)
+		analyzed.ActualRows = len(ids)
+
+		if remaining == nil {
+			remaining = ids
+		} else {
+			for id := range remaining {
+				if _, ok := ids[id]; !ok {
+					delete(remaining, id)
+				}
+			}
+		}
+		analyzed.ActualRemaining = len(remaining)
+
+		result.Steps[i] = analyzed
+		result.IndexHits += analyzed.IndexHits
+		result.ActualRows = len(remaining)
+	}
+
+	result.Duration = time.Since(start)
+	result.RegexCacheHits = d.metrics.regexHits.Load() - hits
+	result.RegexCacheMisses = d.metrics.regexMisses.Load() - misses
+
+	for _, step := range result.Steps {
+		if misestimated(step.Condition.EstimatedRows, step.ActualRows) {
+			result.Details = append(result.Details, fmt.Sprintf("Condition %d on '%s' was estimated at %d rows but matched %d", step.Position, step.Condition.Key, step.Condition.EstimatedRows, step.ActualRows))
+		}
+	}
+	if misestimated(plan.EstimatedRows, result.ActualRows) {
+		result.Details = append(result.Details, fmt.Sprintf("FilterSpec was estimated at %d rows but matched %d", plan.EstimatedRows, result.ActualRows))
+	}
+
+	return result
+}
+
+// misestimated reports whether the actual rows differ from the estimate by more than misestimateFactor.
+func misestimated(estimated, actual int) bool {
+	if estimated == actual {
+		return false
+	}
+	return actual > estimated*misestimateFactor || estimated > actual*misestimateFactor
+}
+
+// String returns a formatted string representation of the analysis.
+func (a AnalyzeResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("EXPLAIN ANALYZE: FilterSpec(%d conditions)\n", len(a.Steps)))
+	sb.WriteString(fmt.Sprintf("  Strategy: %s\n", a.Plan.Strategy))
+	sb.WriteString(fmt.Sprintf("  Total Rows: %d\n", a.Plan.TotalRows))
+	sb.WriteString(fmt.Sprintf("  Rows: %d estimated, %d actual\n", a.Plan.EstimatedRows, a.ActualRows))
+	sb.WriteString(fmt.Sprintf("  Duration: %s\n", a.Duration))
+	sb.WriteString(fmt.Sprintf("  Index Hits: %d\n", a.IndexHits))
+	sb.WriteString(fmt.Sprintf("  Regex Cache: %d hits, %d misses\n", a.RegexCacheHits, a.RegexCacheMisses))
+
+	if len(a.Steps) > 0 {
+		sb.WriteString("  Steps:\n")
+		for i, step := range a.Steps {
+			sb.WriteString(fmt.Sprintf("    %d. #%d %s(%s) via %s: %d/%d rows, %d/%d after intersection, %s\n",
+				i+1, step.Position, step.Condition.Operator, step.Condition.Key, step.Access,
+				step.Condition.EstimatedRows, step.ActualRows, step.EstimatedRows, step.ActualRemaining, step.Duration))
+		}
+	}
+
+	if len(a.Details) > 0 {
+		sb.WriteString("  Details:\n")
+		for _, detail := range a.Details {
+			sb.WriteString(fmt.Sprintf("    - %s\n", detail))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package mframe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestExplainAnalyze(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{
+			"host":  []string{"web-1", "web-2", "web-3", "web-4"}[i%4],
+			"bytes": i,
+		})
+	}
+
+	spec := mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.RegExp, Key: "host", Value: "^web-1$"},
+		{Operator: mframe.Major, Key: "bytes", Value: 89.0},
+	}}
+
+	result := cache.ExplainAnalyze(spec)
+	if len(result.Steps) != 2 {
+		t.Fatalf("expected 2 steps, but got %d", len(result.Steps))
+	}
+	if result.ActualRows != 2 {
+		t.Errorf("expected 2 matching rows, but got %d", result.ActualRows)
+	}
+
+	bytes, host := result.Steps[0], result.Steps[1]
+	if bytes.Position != 1 || bytes.ActualRows != 10 || bytes.ActualRemaining != 10 {
+		t.Errorf("expected bytes first with 10 rows, but got %+v", bytes)
+	}
+	if host.ActualRows != 25 || host.ActualRemaining != 2 {
+		t.Errorf("expected host with 25 rows leaving 2, but got %+v", host)
+	}
+	if result.IndexHits != 35 {
+		t.Errorf("expected 35 index hits, but got %d", result.IndexHits)
+	}
+	if result.RegexCacheHits+result.RegexCacheMisses == 0 {
+		t.Errorf("expected regex cache lookups, but got none")
+	}
+
+	// The regular expression is estimated at every row of the key but matches a quarter of them.
+	if !strings.Contains(strings.Join(result.Details, "\n"), "Condition 0 on 'host' was estimated at 100 rows but matched 25") {
+		t.Errorf("expected the misestimate in %v", result.Details)
+	}
+	if !strings.Contains(result.String(), "EXPLAIN ANALYZE") {
+		t.Errorf("expected the header in %s", result.String())
+	}
+
+	if all := cache.ExplainAnalyze(mframe.FilterSpec{}); all.ActualRows != 100 {
+		t.Errorf("expected every row for an empty spec, but got %d", all.ActualRows)
+	}
+}
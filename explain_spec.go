@@ -0,0 +1,184 @@
+package mframe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Access methods of the conditions of a plan, as reported by ExplainSpec.
+const (
+	// AccessSeek locates the matching values with an acceleration structure.
+	AccessSeek = "index seek"
+	// AccessPrefilter checks only the values sharing the trigrams of the query.
+	AccessPrefilter = "trigram pre-filter"
+	// AccessComposite reads the rows matching several Equals conditions at once from a composite index.
+	AccessComposite = "composite lookup"
+	// AccessLookup reads the IDs of a single value from the index, for Equals on Numeric and Boolean keys.
+	AccessLookup = "index lookup"
+	// AccessScan checks every value of the index of the key.
+	AccessScan = "value scan"
+	// AccessNone finds no index for the key, so the condition matches no rows.
+	AccessNone = "no index"
+)
+
+// Strategies combining the conditions of a plan, as reported by ExplainSpec.
+const (
+	// StrategyFullScan reads every row, for a FilterSpec without conditions.
+	StrategyFullScan = "full scan"
+	// StrategySingle reads the rows matching the only condition.
+	StrategySingle = "single condition"
+	// StrategyIntersection intersects the rows matching every condition, starting from the smallest set.
+	StrategyIntersection = "intersection"
+)
+
+// PlanStep is a condition of a FilterSpec in the order its rows are intersected.
+type PlanStep struct {
+	// Position is the position of the condition in the FilterSpec.
+	Position  int
+	Condition ExplainResult
+	// Access is one of the Access constants.
+	Access string
+	// EstimatedRows is the estimated number of rows left after intersecting this condition with
+	// the previous steps, assuming the conditions are independent.
+	EstimatedRows int
+}
+
+// SpecExplainResult describes how a FilterSpec would be executed, as returned by ExplainSpec.
+type SpecExplainResult struct {
+	// Strategy is one of the Strategy constants.
+	Strategy      string
+	Steps         []PlanStep
+	EstimatedRows int
+	TotalRows     int
+	Details       []string
+}
+
+// ExplainSpec analyzes how a FilterSpec would be executed without running it, like Explain does for a
+// single condition. Every condition is evaluated on its own, through the index of its key, and the sets of
+// rows are then intersected from the smallest one, so Steps are ordered by their estimated number of rows.
+// The estimated intermediate sizes assume the conditions are independent.
+func (d *DataFrame) ExplainSpec(spec FilterSpec) SpecExplainResult {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	return d.explainSpecUnlocked(spec)
+}
+
+// explainSpecUnlocked analyzes a FilterSpec like ExplainSpec, without acquiring locks.
+func (d *DataFrame) explainSpecUnlocked(spec FilterSpec) SpecExplainResult {
+	result := SpecExplainResult{
+		TotalRows: len(d.Data),
+		Details:   make([]string, 0),
+	}
+
+	switch len(spec.Conditions) {
+	case 0:
+		result.Strategy = StrategyFullScan
+		result.EstimatedRows = result.TotalRows
+		result.Details = append(result.Details, "No conditions, every row matches")
+		return result
+	case 1:
+		result.Strategy = StrategySingle
+	default:
+		result.Strategy = StrategyIntersection
+	}
+
+	for i, c := range spec.Conditions {
+		result.Steps = append(result.Steps, PlanStep{
+			Position:  i,
+			Condition: d.explainUnlocked(c.Operator, c.Key, c.Value),
+			Access:    d.accessUnlocked(c),
+		})
+	}
+
+	if _, served, ok := d.compositeLookupUnlocked(spec.Conditions); ok {
+		keys := make([]KeyName, len(served))
+		for i, position := range served {
+			result.Steps[position].Access = AccessComposite
+			keys[i] = spec.Conditions[position].Key
+		}
+		result.Details = append(result.Details, fmt.Sprintf("Conditions on %v are read together from the composite index '%s'", keys, CompositeKey(keys...)))
+	}
+
+	sort.SliceStable(result.Steps, func(i, j int) bool {
+		return result.Steps[i].Condition.EstimatedRows < result.Steps[j].Condition.EstimatedRows
+	})
+
+	estimate := float64(result.TotalRows)
+	for i := range result.Steps {
+		step := &result.Steps[i]
+		if result.TotalRows > 0 {
+			estimate *= float64(step.Condition.EstimatedRows) / float64(result.TotalRows)
+		}
+		step.EstimatedRows = int(estimate + 0.5)
+	}
+	result.EstimatedRows = result.Steps[len(result.Steps)-1].EstimatedRows
+
+	for _, step := range result.Steps {
+		if step.Condition.EstimatedRows == 0 {
+			result.Details = append(result.Details, fmt.Sprintf("Condition %d on '%s' is expected to match no rows, ending the evaluation early", step.Position, step.Condition.Key))
+			break
+		}
+	}
+	for _, step := range result.Steps {
+		if step.Access == AccessScan && step.Condition.EstimatedRows > 0 {
+			result.Details = append(result.Details, fmt.Sprintf("Condition %d on '%s' checks every value of the key", step.Position, step.Condition.Key))
+		}
+	}
+	if result.TotalRows > 0 {
+		selectivity := float64(result.EstimatedRows) / float64(result.TotalRows) * 100
+		result.Details = append(result.Details, fmt.Sprintf("Estimated selectivity: %.2f%%", selectivity))
+	}
+
+	return result
+}
+
+// accessUnlocked returns the access method of a condition, without acquiring locks.
+func (d *DataFrame) accessUnlocked(c Condition) string {
+	keyType, exists := d.Keys[c.Key]
+	if !exists {
+		if isKeyPattern(c.Key) {
+			return AccessScan
+		}
+		return AccessNone
+	}
+
+	if target, ok := d.accelTargetUnlocked(c); ok && d.accel.has(target.key, target.kind) {
+		return AccessSeek
+	}
+	if keyType == String && d.accel.has(c.Key, TrigramIndex) && (c.Operator == Contains || c.Operator == NotContains || c.Operator == RegExp) {
+		return AccessPrefilter
+	}
+	if c.Operator == Equals && (keyType == Numeric || keyType == Boolean) {
+		return AccessLookup
+	}
+	return AccessScan
+}
+
+// String returns a formatted string representation of the plan.
+func (e SpecExplainResult) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("EXPLAIN: FilterSpec(%d conditions)\n", len(e.Steps)))
+	sb.WriteString(fmt.Sprintf("  Strategy: %s\n", e.Strategy))
+	sb.WriteString(fmt.Sprintf("  Total Rows: %d\n", e.TotalRows))
+	sb.WriteString(fmt.Sprintf("  Estimated Rows: %d\n", e.EstimatedRows))
+
+	if len(e.Steps) > 0 {
+		sb.WriteString("  Steps:\n")
+		for i, step := range e.Steps {
+			sb.WriteString(fmt.Sprintf("    %d. #%d %s(%s) via %s: %d rows, %d after intersection\n",
+				i+1, step.Position, step.Condition.Operator, step.Condition.Key, step.Access,
+				step.Condition.EstimatedRows, step.EstimatedRows))
+		}
+	}
+
+	if len(e.Details) > 0 {
+		sb.WriteString("  Details:\n")
+		for _, detail := range e.Details {
+			sb.WriteString(fmt.Sprintf("    - %s\n", detail))
+		}
+	}
+
+	return sb.String()
+}
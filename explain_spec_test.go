@@ -0,0 +1,71 @@
+package mframe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestExplainSpec(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{
+			"status": i % 2,
+			"host":   []string{"web-1", "web-2", "web-3", "web-4"}[i%4],
+			"bytes":  i,
+		})
+	}
+	if err := cache.CreateIndex("bytes", mframe.SortedNumericIndex); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec := mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "status", Value: 0.0},
+		{Operator: mframe.Equals, Key: "host", Value: "web-1"},
+		{Operator: mframe.Major, Key: "bytes", Value: 89.0},
+	}}
+
+	plan := cache.ExplainSpec(spec)
+	if plan.Strategy != mframe.StrategyIntersection || plan.TotalRows != 100 {
+		t.Fatalf("expected an intersection over 100 rows, but got %s over %d", plan.Strategy, plan.TotalRows)
+	}
+	if len(plan.Steps) != 3 {
+		t.Fatalf("expected 3 steps, but got %d", len(plan.Steps))
+	}
+
+	first, second, third := plan.Steps[0], plan.Steps[1], plan.Steps[2]
+	if first.Position != 2 || first.Access != mframe.AccessSeek || first.Condition.EstimatedRows != 10 {
+		t.Errorf("expected the bytes seek first with 10 rows, but got %+v", first)
+	}
+	if second.Position != 1 || second.Access != mframe.AccessScan || second.EstimatedRows != 3 {
+		t.Errorf("expected the host scan second leaving 3 rows, but got %+v", second)
+	}
+	if third.Position != 0 || third.Access != mframe.AccessLookup || third.EstimatedRows != 1 {
+		t.Errorf("expected the status lookup last leaving 1 row, but got %+v", third)
+	}
+	if plan.EstimatedRows != 1 {
+		t.Errorf("expected 1 estimated row, but got %d", plan.EstimatedRows)
+	}
+	if !strings.Contains(plan.String(), "Strategy: intersection") {
+		t.Errorf("expected the strategy in %s", plan.String())
+	}
+
+	if plan := cache.ExplainSpec(mframe.FilterSpec{}); plan.Strategy != mframe.StrategyFullScan || plan.EstimatedRows != 100 {
+		t.Errorf("expected a full scan of 100 rows, but got %s with %d", plan.Strategy, plan.EstimatedRows)
+	}
+
+	missing := cache.ExplainSpec(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "host", Value: "web-1"},
+		{Operator: mframe.Equals, Key: "missing", Value: "x"},
+	}})
+	if missing.Steps[0].Access != mframe.AccessNone || missing.EstimatedRows != 0 {
+		t.Errorf("expected the missing key first and no rows, but got %+v", missing)
+	}
+	if !strings.Contains(strings.Join(missing.Details, "\n"), "ending the evaluation early") {
+		t.Errorf("expected an early end in %v", missing.Details)
+	}
+}
@@ -1,6 +1,7 @@
 package mframe
 
 import (
+	"fmt"
 	"github.com/google/uuid"
 	"log"
 	"net"
@@ -46,7 +47,8 @@ const (
 // key indicates the column to filter on.
 // value represents the target value(s) used for filtering.
 // options is an optional map to specify additional filter settings (e.g., case sensitivity).
-// Returns a new DataFrame containing the filtered rows.
+// Returns a new DataFrame containing the filtered rows, which keep their IDs so results of several
+// filters can be combined with Union, Intersect and Difference.
 //
 // Available Operators:
 // - Equals: Available for numeric, string and bool types.
@@ -76,9 +78,118 @@ const (
 // - EndsWith Available for string types.
 // - NotEndsWith Available for string types.
 func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options map[FilterOption]bool) *DataFrame {
+	query := Condition{Operator: operator, Key: key, Value: value, Options: options}
+
+	if err := d.runBeforeQuery(query); err != nil {
+		log.Printf("query on key '%s' rejected: %s", key, err.Error())
+		d.Locker.RLock()
+		defer d.Locker.RUnlock()
+		var results = new(DataFrame)
+		results.Init(d.TTL)
+		return results
+	}
+
+	start := time.Now()
 	d.Locker.RLock()
-	defer d.Locker.RUnlock()
+	results, err := d.filterUnlocked(query, nil)
+	d.Locker.RUnlock()
+
+	if err != nil {
+		log.Printf("query on key '%s' aborted: %s", key, err.Error())
+		results = new(DataFrame)
+		results.Init(d.TTL)
+		return results
+	}
+
+	d.recordQuery(query, time.Since(start), len(results.Data))
+	d.runAfterQuery(query, results)
+
+	return results
+}
+
+// FilterIDs returns the IDs of the rows matching the filter, using the same arguments as Filter, without
+// copying the rows into a new DataFrame. Like Filter, it runs the before-query hooks and returns an empty set
+// when one rejects the query, and it is counted in the query statistics. The after-query hooks, which receive
+// the result DataFrame, are not run.
+func (d *DataFrame) FilterIDs(operator Operator, key KeyName, value any, options map[FilterOption]bool) map[uuid.UUID]struct{} {
+	query := Condition{Operator: operator, Key: key, Value: value, Options: options}
+
+	if err := d.runBeforeQuery(query); err != nil {
+		log.Printf("query on key '%s' rejected: %s", key, err.Error())
+		return make(map[uuid.UUID]struct{})
+	}
+
+	start := time.Now()
+	d.Locker.RLock()
+	ids := d.idsUnlocked(query)
+	d.Locker.RUnlock()
+
+	d.recordQuery(query, time.Since(start), len(ids))
+
+	return ids
+}
+
+// filterUnlocked applies a filtering operation without acquiring locks. When done is closed
+// before the walk completes, the rows matched so far are returned flagged as partial.
+// Returns an error if the result would exceed the query memory limit or a rejecting scan guard.
+func (d *DataFrame) filterUnlocked(query Condition, done <-chan struct{}) (*DataFrame, error) {
+	var results = new(DataFrame)
+	results.Init(d.TTL)
+
+	var rowSize, matched int
+	if d.queryMemoryLimit > 0 {
+		rowSize = d.averageRowSizeUnlocked()
+	}
+
+	guard := d.newScanCheckUnlocked(query)
+
+	var err error
+	poll := &scanPoll{done: done}
+	d.matchUnlocked(poll, query.Operator, query.Key, query.Value, query.Options, func(id uuid.UUID) bool {
+		select {
+		case <-done:
+			results.partial = true
+			return false
+		default:
+		}
 
+		if _, exists := results.Data[id]; exists {
+			return true
+		}
+
+		matched++
+		if d.queryMemoryLimit > 0 && matched*rowSize > d.queryMemoryLimit {
+			err = fmt.Errorf("%w: query on key '%s' needs more than %d bytes", ErrQueryMemoryLimit, query.Key, d.queryMemoryLimit)
+			return false
+		}
+
+		if guard != nil && !guard.observe(d.Data[id]) {
+			err = guard.err()
+			return false
+		}
+
+		results.insertWithIDUnlocked(id, d.Data[id])
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	if poll.stopped {
+		results.partial = true
+	}
+
+	if guard != nil && guard.violation != "" {
+		log.Printf("query on key '%s' exceeds the scan guard: %s", query.Key, guard.violation)
+	}
+
+	return results, nil
+}
+
+// matchUnlocked walks the indexes and calls visit with the ID of every row matching the filter,
+// without acquiring locks. A row matching through several keys is visited once per key.
+// Returning false from visit stops the walk, and so does poll once its done channel is closed.
+func (d *DataFrame) matchUnlocked(poll *scanPoll, operator Operator, key KeyName, value any, options map[FilterOption]bool, visit func(id uuid.UUID) bool) {
 	var keys = make(map[KeyName]KeyType)
 
 	if ContainsF(string(key), "^") || ContainsF(string(key), "[") || ContainsF(string(key), "(") {
@@ -94,139 +205,180 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 		keys[key] = d.Keys[key]
 	}
 
-	var results = new(DataFrame)
-	results.Init(d.TTL)
-
 	for dataFrameKey, keyType := range keys {
+		if handled, stopped := d.matchAcceleratedUnlocked(poll, dataFrameKey, keyType, operator, value, options, visit); handled {
+			if stopped {
+				return
+			}
+			continue
+		}
+
 		switch keyType {
 		case Numeric:
 			switch operator {
 			case Equals:
 				floatValue, ok := value.(float64)
 				if !ok {
-					return results
+					return
 				}
 				if ids, ok := d.Numerics[dataFrameKey][floatValue]; ok {
 					for id := range ids {
-						results.Insert(d.Data[id])
+						if !visit(id) {
+							return
+						}
 					}
 				}
 			case NotEquals:
 				floatValue, ok := value.(float64)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if EqualsF(keyValue, floatValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case Major:
 				floatValue, ok := value.(float64)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if !GreaterThanF(keyValue, floatValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case Minor:
 				floatValue, ok := value.(float64)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if !GreaterThanF(floatValue, keyValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case MajorEquals:
 				floatValue, ok := value.(float64)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if !EqualsF(keyValue, floatValue) && !GreaterThanF(keyValue, floatValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case MinorEquals:
 				floatValue, ok := value.(float64)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if !EqualsF(keyValue, floatValue) && GreaterThanF(keyValue, floatValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case InList:
 				floatValues, ok := value.([]float64)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if !InListF(keyValue, floatValues) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotInList:
 				floatValues, ok := value.([]float64)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if InListF(keyValue, floatValues) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case Between:
 				rangeValues, ok := value.([]float64)
 				if !ok || len(rangeValues) != 2 {
-					return results
+					return
 				}
 				min, max := rangeValues[0], rangeValues[1]
 				if min > max {
@@ -234,19 +386,24 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if keyValue < min || keyValue > max {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotBetween:
 				rangeValues, ok := value.([]float64)
 				if !ok || len(rangeValues) != 2 {
-					return results
+					return
 				}
 				min, max := rangeValues[0], rangeValues[1]
 				if min > max {
@@ -254,12 +411,17 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 				}
 				if keyValues, ok := d.Numerics[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if keyValue >= min && keyValue <= max {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
@@ -271,10 +433,13 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 			case Equals:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -285,17 +450,22 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotEquals:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -306,14 +476,16 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case RegExp:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					re, err := d.getCompiledRegex(stringValue)
@@ -321,19 +493,24 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						continue
 					}
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if !re.MatchString(keyValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotRegExp:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					re, err := d.getCompiledRegex(stringValue)
@@ -341,22 +518,30 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						continue
 					}
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if re.MatchString(keyValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case InList:
 				stringValues, ok := value.([]string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 
@@ -372,17 +557,22 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotInList:
 				stringValues, ok := value.([]string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 
@@ -398,49 +588,64 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case InCIDR:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if m, e := InCIDRF(keyValue, stringValue); e != nil || !m {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotInCIDR:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if m, e := InCIDRF(keyValue, stringValue); e != nil || m {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case Contains:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -451,17 +656,22 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotContains:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -472,17 +682,22 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case StartsWith:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -493,17 +708,22 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotStartsWith:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -514,17 +734,22 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case EndsWith:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -535,17 +760,22 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotEndsWith:
 				stringValue, ok := value.(string)
 				if !ok {
-					return results
+					return
 				}
 				if keyValues, ok := d.Strings[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if sensitive, ok := options[CaseSensitive]; ok && !sensitive {
 							keyValue = strings.ToLower(keyValue)
 							stringValue = strings.ToLower(stringValue)
@@ -556,7 +786,9 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
@@ -566,24 +798,31 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 		case Boolean:
 			boolValue, ok := value.(bool)
 			if !ok {
-				return results
+				return
 			}
 			switch operator {
 			case Equals:
 				if ids, ok := d.Booleans[dataFrameKey][boolValue]; ok {
 					for id := range ids {
-						results.Insert(d.Data[id])
+						if !visit(id) {
+							return
+						}
 					}
 				}
 			case NotEquals:
 				if keyValues, ok := d.Booleans[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if EqualsF(boolValue, keyValue) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
@@ -595,7 +834,7 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 			case Between:
 				timeValues, ok := value.([]time.Time)
 				if !ok || len(timeValues) != 2 {
-					return results
+					return
 				}
 				startTime, endTime := timeValues[0], timeValues[1]
 				if startTime.After(endTime) {
@@ -603,19 +842,24 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 				}
 				if keyValues, ok := d.Times[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if keyValue.Before(startTime) || keyValue.After(endTime) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
 			case NotBetween:
 				timeValues, ok := value.([]time.Time)
 				if !ok || len(timeValues) != 2 {
-					return results
+					return
 				}
 				startTime, endTime := timeValues[0], timeValues[1]
 				if startTime.After(endTime) {
@@ -623,12 +867,17 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 				}
 				if keyValues, ok := d.Times[dataFrameKey]; ok {
 					for keyValue, ids := range keyValues {
+						if poll.interrupted() {
+							return
+						}
 						if !keyValue.Before(startTime) && !keyValue.After(endTime) {
 							continue
 						}
 
 						for id := range ids {
-							results.Insert(d.Data[id])
+							if !visit(id) {
+								return
+							}
 						}
 					}
 				}
@@ -638,7 +887,6 @@ func (d *DataFrame) Filter(operator Operator, key KeyName, value any, options ma
 		}
 	}
 
-	return results
 }
 
 // FindFirstByKey retrieves the first occurrence of a key within a DataFrame and returns its UUID, key name, and value.
@@ -410,3 +410,34 @@ func TestTimeRangeFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterIDs(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	ids := make(map[string]bool)
+	for _, host := range []string{"web-1", "web-2", "db-1"} {
+		id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"host": host})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids[id.String()] = host[:3] == "web"
+	}
+
+	matched := cache.FilterIDs(mframe.StartsWith, "host", "web", nil)
+	if len(matched) != 2 {
+		t.Fatalf("expected 2 IDs, but got %d", len(matched))
+	}
+	for id := range matched {
+		if !ids[id.String()] {
+			t.Errorf("expected only web hosts, but got %s", id)
+		}
+	}
+
+	if n := cache.FilterCount(mframe.StartsWith, "host", "web", nil); n != 2 {
+		t.Errorf("expected a count of 2, but got %d", n)
+	}
+	if n := len(cache.FilterIDs(mframe.Equals, "host", "missing", nil)); n != 0 {
+		t.Errorf("expected no IDs, but got %d", n)
+	}
+}
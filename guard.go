@@ -0,0 +1,102 @@
+package mframe
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrScanGuard is returned when a query exceeds a rejecting scan guard.
+var ErrScanGuard = errors.New("query exceeds the scan guard")
+
+// ScanGuard protects shared deployments from accidentally expensive filters. Queries matching more than
+// MaxValueRatio of the unique values of their key, or returning more than MaxRows rows, are logged or,
+// when Reject is set, aborted with ErrScanGuard. Queries with the AllowFullScan option are exempt.
+// The value ratio is only checked for plain keys, not key patterns. A zero limit disables its check.
+type ScanGuard struct {
+	MaxValueRatio float64
+	MaxRows       int
+	Reject        bool
+}
+
+// SetScanGuard configures the scan guard applied to Filter and FilterContext.
+// The zero ScanGuard disables it.
+func (d *DataFrame) SetScanGuard(guard ScanGuard) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+	d.scanGuard = guard
+}
+
+// scanCheck tracks a single query against the scan guard.
+type scanCheck struct {
+	guard     ScanGuard
+	key       KeyName
+	maxValues int
+	values    map[interface{}]struct{}
+	rows      int
+	violation string
+}
+
+// newScanCheckUnlocked returns the check of the query against the scan guard, or nil if the guard is
+// disabled or the query is exempt.
+func (d *DataFrame) newScanCheckUnlocked(query Condition) *scanCheck {
+	if d.scanGuard.MaxValueRatio <= 0 && d.scanGuard.MaxRows <= 0 {
+		return nil
+	}
+	if query.Options[AllowFullScan] {
+		return nil
+	}
+
+	check := &scanCheck{guard: d.scanGuard, key: query.Key}
+
+	if _, plain := d.Keys[query.Key]; plain && d.scanGuard.MaxValueRatio > 0 {
+		check.maxValues = int(math.Ceil(d.scanGuard.MaxValueRatio * float64(d.uniqueValuesUnlocked(query.Key))))
+		check.values = make(map[interface{}]struct{})
+	}
+
+	return check
+}
+
+// observe records a matched row. Returns false when the query must be aborted.
+func (c *scanCheck) observe(row Row) bool {
+	if c.violation != "" {
+		return true
+	}
+
+	c.rows++
+	if c.guard.MaxRows > 0 && c.rows > c.guard.MaxRows {
+		c.violation = fmt.Sprintf("more than %d rows", c.guard.MaxRows)
+		return !c.guard.Reject
+	}
+
+	if c.values != nil {
+		c.values[row[c.key]] = struct{}{}
+		if len(c.values) > c.maxValues {
+			c.violation = fmt.Sprintf("more than %.0f%% of the unique values of '%s'", c.guard.MaxValueRatio*100, c.key)
+			return !c.guard.Reject
+		}
+	}
+
+	return true
+}
+
+// err returns the error reported when the query is aborted.
+func (c *scanCheck) err() error {
+	return fmt.Errorf("%w: query on key '%s' matches %s", ErrScanGuard, c.key, c.violation)
+}
+
+// uniqueValuesUnlocked returns the number of unique values of the key, without acquiring locks.
+func (d *DataFrame) uniqueValuesUnlocked(key KeyName) int {
+	switch d.Keys[key] {
+	case String:
+		return len(d.Strings[key])
+	case Numeric:
+		return len(d.Numerics[key])
+	case Boolean:
+		return len(d.Booleans[key])
+	case Time:
+		return len(d.Times[key])
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,53 @@
+package mframe_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestScanGuard(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"id": i, "severity": []string{"low", "high"}[i%2]})
+	}
+
+	cache.SetScanGuard(mframe.ScanGuard{MaxValueRatio: 0.5, MaxRows: 60, Reject: true})
+
+	tests := []struct {
+		name     string
+		operator mframe.Operator
+		key      mframe.KeyName
+		value    any
+		options  map[mframe.FilterOption]bool
+		rejected bool
+	}{
+		{"selective", mframe.Less, "id", 10.0, nil, false},
+		{"too many values", mframe.NotEquals, "id", 0.0, nil, true},
+		{"too many rows", mframe.Contains, "severity", "h", nil, false},
+		{"all rows", mframe.NotEquals, "severity", "none", nil, true},
+		{"allowed", mframe.NotEquals, "id", 0.0, map[mframe.FilterOption]bool{mframe.AllowFullScan: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cache.FilterContext(context.Background(), tt.operator, tt.key, tt.value, tt.options)
+			if tt.rejected && !errors.Is(err, mframe.ErrScanGuard) {
+				t.Errorf("expected ErrScanGuard, but got %v", err)
+			}
+			if !tt.rejected && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	cache.SetScanGuard(mframe.ScanGuard{MaxRows: 10})
+	if results := cache.Filter(mframe.NotEquals, "id", 0.0, nil); results.Count() != 99 {
+		t.Errorf("expected a warning guard to keep all 99 rows, but got %d", results.Count())
+	}
+}
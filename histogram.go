@@ -0,0 +1,66 @@
+package mframe
+
+import (
+	"fmt"
+
+	"github.com/montanaflynn/stats"
+)
+
+// Histogram holds equal-width buckets over a numeric field. Bucket i covers the values in
+// [Bounds[i], Bounds[i+1]), except the last bucket which also includes its upper bound.
+type Histogram struct {
+	Bounds []float64
+	Counts []int
+}
+
+// Histogram splits the range of the numeric values in the specified field into the given
+// number of equal-width buckets and counts the rows falling into each one.
+func (d *DataFrame) Histogram(field KeyName, bins int) (Histogram, error) {
+	if bins <= 0 {
+		return Histogram{}, fmt.Errorf("number of bins must be greater than zero")
+	}
+
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	index := d.Numerics[field]
+	if len(index) == 0 {
+		return Histogram{}, stats.EmptyInputErr
+	}
+
+	first := true
+	var minVal, maxVal float64
+	for value := range index {
+		if first || value < minVal {
+			minVal = value
+		}
+		if first || value > maxVal {
+			maxVal = value
+		}
+		first = false
+	}
+
+	width := (maxVal - minVal) / float64(bins)
+
+	result := Histogram{
+		Bounds: make([]float64, bins+1),
+		Counts: make([]int, bins),
+	}
+	for i := range result.Bounds {
+		result.Bounds[i] = minVal + width*float64(i)
+	}
+	result.Bounds[bins] = maxVal
+
+	for value, ids := range index {
+		bucket := bins - 1
+		if width > 0 {
+			bucket = int((value - minVal) / width)
+			if bucket >= bins {
+				bucket = bins - 1
+			}
+		}
+		result.Counts[bucket] += len(ids)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestHistogram(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i <= 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"value": float64(i)})
+	}
+	cache.Insert(map[mframe.KeyName]interface{}{"value": 10.0})
+
+	histogram, err := cache.Histogram("value", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedBounds := []float64{0, 2, 4, 6, 8, 10}
+	for i, b := range expectedBounds {
+		if histogram.Bounds[i] != b {
+			t.Errorf("expected bound %d to be %v, but got %v", i, b, histogram.Bounds[i])
+		}
+	}
+
+	expectedCounts := []int{2, 2, 2, 2, 4}
+	for i, c := range expectedCounts {
+		if histogram.Counts[i] != c {
+			t.Errorf("expected count %d to be %v, but got %v", i, c, histogram.Counts[i])
+		}
+	}
+
+	if _, err := cache.Histogram("value", 0); err == nil {
+		t.Error("expected error for zero bins")
+	}
+	if _, err := cache.Histogram("missing", 5); err == nil {
+		t.Error("expected error for a missing field")
+	}
+}
+
+func TestHistogramSingleValue(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 3; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"value": 7.0})
+	}
+
+	histogram, err := cache.Histogram("value", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if histogram.Counts[2] != 3 {
+		t.Errorf("expected all rows in the last bucket, but got %v", histogram.Counts)
+	}
+}
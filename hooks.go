@@ -0,0 +1,159 @@
+package mframe
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// BeforeInsertHook is called with the data of every row before it is inserted. It may return
+// modified data, or an error to reject the row.
+type BeforeInsertHook func(data map[KeyName]interface{}) (map[KeyName]interface{}, error)
+
+// AfterInsertHook is called with a copy of every row after it has been inserted and indexed.
+type AfterInsertHook func(id uuid.UUID, row Row)
+
+// BeforeQueryHook is called before every Filter. Returning an error rejects the query,
+// making Filter return an empty DataFrame.
+type BeforeQueryHook func(query Condition) error
+
+// AfterQueryHook is called after every Filter with the query and its results.
+type AfterQueryHook func(query Condition, results *DataFrame)
+
+// EvictHook is called with the last content of every row removed from the DataFrame and the reason of the removal.
+type EvictHook func(id uuid.UUID, row Row, reason EvictReason)
+
+// hooks holds the middleware chains registered on a DataFrame.
+type hooks struct {
+	mutex        sync.RWMutex
+	beforeInsert []BeforeInsertHook
+	afterInsert  []AfterInsertHook
+	beforeQuery  []BeforeQueryHook
+	afterQuery   []AfterQueryHook
+	evict        []EvictHook
+}
+
+// OnBeforeInsert registers a hook called before each row is inserted. Hooks run in registration order,
+// each receiving the data returned by the previous one.
+func (d *DataFrame) OnBeforeInsert(hook BeforeInsertHook) {
+	d.hooks.mutex.Lock()
+	defer d.hooks.mutex.Unlock()
+	d.hooks.beforeInsert = append(d.hooks.beforeInsert, hook)
+}
+
+// OnAfterInsert registers a hook called after each row is inserted.
+// Hooks run without holding the DataFrame lock, so they may safely query the DataFrame.
+func (d *DataFrame) OnAfterInsert(hook AfterInsertHook) {
+	d.hooks.mutex.Lock()
+	defer d.hooks.mutex.Unlock()
+	d.hooks.afterInsert = append(d.hooks.afterInsert, hook)
+}
+
+// OnBeforeQuery registers a hook called before each Filter.
+func (d *DataFrame) OnBeforeQuery(hook BeforeQueryHook) {
+	d.hooks.mutex.Lock()
+	defer d.hooks.mutex.Unlock()
+	d.hooks.beforeQuery = append(d.hooks.beforeQuery, hook)
+}
+
+// OnAfterQuery registers a hook called after each Filter.
+func (d *DataFrame) OnAfterQuery(hook AfterQueryHook) {
+	d.hooks.mutex.Lock()
+	defer d.hooks.mutex.Unlock()
+	d.hooks.afterQuery = append(d.hooks.afterQuery, hook)
+}
+
+// OnEvict registers a hook called after each row is removed by the cleaner, by RemoveElement, RemoveElements,
+// DeleteWhere, Deduplicate or Upsert, or to respect the maximum number of rows, so removed rows can be flushed
+// to long-term storage. Rows replaced by a batch insert with the same ID are not reported.
+// Hooks run without holding the DataFrame lock, so they may safely query the DataFrame.
+func (d *DataFrame) OnEvict(hook EvictHook) {
+	d.hooks.mutex.Lock()
+	defer d.hooks.mutex.Unlock()
+	d.hooks.evict = append(d.hooks.evict, hook)
+}
+
+// hasEvict reports whether any evict hook is registered.
+func (h *hooks) hasEvict() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.evict) > 0
+}
+
+// hasAfterInsert reports whether any after-insert hook is registered.
+func (h *hooks) hasAfterInsert() bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return len(h.afterInsert) > 0
+}
+
+// runBeforeInsert passes the data through the before-insert chain.
+func (d *DataFrame) runBeforeInsert(data map[KeyName]interface{}) (map[KeyName]interface{}, error) {
+	d.hooks.mutex.RLock()
+	chain := d.hooks.beforeInsert
+	d.hooks.mutex.RUnlock()
+
+	for _, hook := range chain {
+		var err error
+		data, err = hook(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// runAfterInsert calls the after-insert chain.
+func (d *DataFrame) runAfterInsert(id uuid.UUID, row Row) {
+	d.hooks.mutex.RLock()
+	chain := d.hooks.afterInsert
+	d.hooks.mutex.RUnlock()
+
+	for _, hook := range chain {
+		hook(id, row)
+	}
+}
+
+// runBeforeQuery calls the before-query chain, stopping at the first error.
+func (d *DataFrame) runBeforeQuery(query Condition) error {
+	d.hooks.mutex.RLock()
+	chain := d.hooks.beforeQuery
+	d.hooks.mutex.RUnlock()
+
+	for _, hook := range chain {
+		if err := hook(query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runAfterQuery calls the after-query chain.
+func (d *DataFrame) runAfterQuery(query Condition, results *DataFrame) {
+	d.hooks.mutex.RLock()
+	chain := d.hooks.afterQuery
+	d.hooks.mutex.RUnlock()
+
+	for _, hook := range chain {
+		hook(query, results)
+	}
+}
+
+// runEvict calls the evict chain for each eviction.
+func (d *DataFrame) runEvict(evictions []eviction) {
+	if len(evictions) == 0 {
+		return
+	}
+
+	d.hooks.mutex.RLock()
+	chain := d.hooks.evict
+	d.hooks.mutex.RUnlock()
+
+	for _, e := range evictions {
+		for _, hook := range chain {
+			hook(e.id, e.row, e.reason)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package mframe_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestInsertHooks(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.OnBeforeInsert(func(data map[mframe.KeyName]interface{}) (map[mframe.KeyName]interface{}, error) {
+		if _, ok := data["host"]; !ok {
+			return nil, fmt.Errorf("missing host")
+		}
+		return data, nil
+	})
+	cache.OnBeforeInsert(func(data map[mframe.KeyName]interface{}) (map[mframe.KeyName]interface{}, error) {
+		data["host"] = strings.ToLower(data["host"].(string))
+		return data, nil
+	})
+
+	var mutex sync.Mutex
+	inserted := make(map[uuid.UUID]mframe.Row)
+	cache.OnAfterInsert(func(id uuid.UUID, row mframe.Row) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		inserted[id] = row
+	})
+
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "WEB-01"})
+	cache.Insert(map[mframe.KeyName]interface{}{"name": "no host"})
+
+	if err := cache.InsertWithError(map[mframe.KeyName]interface{}{"name": "no host"}); err == nil {
+		t.Error("expected error from rejecting hook")
+	}
+
+	if err := cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "DB-01"},
+		{"name": "no host"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 rows, but got %d", cache.Count())
+	}
+
+	if cache.Filter(mframe.Equals, "host", "web-01", nil).Count() != 1 {
+		t.Error("expected the host to be normalized by the hook")
+	}
+
+	if len(inserted) != 2 {
+		t.Errorf("expected 2 after-insert calls, but got %d", len(inserted))
+	}
+	for id, row := range inserted {
+		if _, ok := cache.Data[id]; !ok {
+			t.Errorf("after-insert hook received unknown id %s", id)
+		}
+		if _, ok := row["host"]; !ok {
+			t.Errorf("after-insert hook received incomplete row %v", row)
+		}
+	}
+}
+
+func TestQueryHooks(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"name": "John", "secret": "x"})
+
+	cache.OnBeforeQuery(func(query mframe.Condition) error {
+		if query.Key == "secret" {
+			return fmt.Errorf("key '%s' cannot be queried", query.Key)
+		}
+		return nil
+	})
+
+	var queries []mframe.Condition
+	var counts []int
+	cache.OnAfterQuery(func(query mframe.Condition, results *mframe.DataFrame) {
+		queries = append(queries, query)
+		counts = append(counts, results.Count())
+	})
+
+	if cache.Filter(mframe.Equals, "name", "John", nil).Count() != 1 {
+		t.Error("expected 1 result")
+	}
+
+	if cache.Filter(mframe.Equals, "secret", "x", nil).Count() != 0 {
+		t.Error("expected rejected query to return no results")
+	}
+
+	if len(queries) != 1 || queries[0].Key != "name" || counts[0] != 1 {
+		t.Errorf("expected one after-query call for 'name', but got %v %v", queries, counts)
+	}
+}
+
+func TestEvictHook(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(time.Second)
+	cache.SetMaxRows(2, mframe.EvictOldestExpiry)
+
+	var mu sync.Mutex
+	reasons := make(map[mframe.EvictReason][]mframe.Row)
+	cache.OnEvict(func(id uuid.UUID, row mframe.Row, reason mframe.EvictReason) {
+		mu.Lock()
+		defer mu.Unlock()
+		reasons[reason] = append(reasons[reason], row)
+		if count := cache.Count(); count > 2 {
+			t.Errorf("expected the hook to run after the removal, but got %d rows", count)
+		}
+	})
+
+	removed, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"host": "removed"})
+	cache.RemoveElement(removed)
+	cache.RemoveElement(removed)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "evicted"})
+	time.Sleep(time.Millisecond)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "a"})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "b"})
+
+	cache.StartCleaner()
+	defer cache.StopCleaner()
+	time.Sleep(2500 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	tests := []struct {
+		reason mframe.EvictReason
+		hosts  int
+		host   string
+	}{
+		{mframe.EvictRemoved, 1, "removed"},
+		{mframe.EvictCapacity, 1, "evicted"},
+		{mframe.EvictExpired, 2, ""},
+	}
+
+	for _, tt := range tests {
+		rows := reasons[tt.reason]
+		if len(rows) != tt.hosts {
+			t.Errorf("expected %d rows %s, but got %d", tt.hosts, tt.reason, len(rows))
+			continue
+		}
+		if tt.host != "" && rows[0]["host"] != tt.host {
+			t.Errorf("expected row %s to be %s, but got %v", tt.host, tt.reason, rows[0])
+		}
+	}
+}
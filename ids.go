@@ -0,0 +1,52 @@
+package mframe
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDGenerator returns the ID of a new row.
+type IDGenerator func() uuid.UUID
+
+// RandomIDs generates random version 4 UUIDs. It is the default IDGenerator.
+func RandomIDs() uuid.UUID {
+	return uuid.New()
+}
+
+// TimeOrderedIDs generates version 7 UUIDs, which embed the creation time in milliseconds and
+// sort by it, including between IDs generated within the same millisecond. Like ULIDs, their string
+// form sorts in the same order, so rows ordered by ID are ordered by insertion time.
+func TimeOrderedIDs() uuid.UUID {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.New()
+	}
+	return id
+}
+
+// SetIDGenerator sets the function generating the IDs of new rows. Passing nil restores RandomIDs.
+// Rows inserted before the change keep their IDs.
+func (d *DataFrame) SetIDGenerator(generator IDGenerator) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+	d.idGenerator = generator
+}
+
+// IDTime returns the creation time embedded in a time-ordered ID and whether the ID embeds one.
+func IDTime(id uuid.UUID) (time.Time, bool) {
+	switch id.Version() {
+	case 1, 2, 6, 7:
+		sec, nsec := id.Time().UnixTime()
+		return time.Unix(sec, nsec).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// idGeneratorUnlocked returns the ID generator of the DataFrame without acquiring locks.
+func (d *DataFrame) idGeneratorUnlocked() IDGenerator {
+	if d.idGenerator == nil {
+		return RandomIDs
+	}
+	return d.idGenerator
+}
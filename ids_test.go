@@ -0,0 +1,47 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestTimeOrderedIDs(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetIDGenerator(mframe.TimeOrderedIDs)
+
+	start := time.Now().UTC().Truncate(time.Millisecond)
+
+	ids := make([]uuid.UUID, 0)
+	for i := 0; i < 100; i++ {
+		id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"seq": i})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	for i := 1; i < len(ids); i++ {
+		if ids[i-1].String() >= ids[i].String() {
+			t.Fatalf("expected ids to sort by insertion, but %s came before %s", ids[i-1], ids[i])
+		}
+	}
+
+	created, ok := mframe.IDTime(ids[0])
+	if !ok || created.Before(start) || created.After(time.Now().UTC()) {
+		t.Errorf("expected the id to embed its creation time, but got %v, %v", created, ok)
+	}
+
+	cache.SetIDGenerator(nil)
+	id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"seq": 100})
+	if id.Version() != 4 {
+		t.Errorf("expected a random id after resetting the generator, but got version %d", id.Version())
+	}
+	if _, ok := mframe.IDTime(id); ok {
+		t.Error("expected a random id not to embed a time")
+	}
+}
@@ -0,0 +1,147 @@
+package mframe
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IndexPolicy selects the keys whose values are stored in the rows without being indexed.
+type IndexPolicy struct {
+	// NotIndexed lists the keys, by their dotted name for nested values, kept in the rows but left out of
+	// the indexes, such as free-text messages that are never filtered on. A key also covers the keys nested
+	// under it. Filters on these keys match no rows, while reading rows, the math functions and the CSV, Arrow
+	// and Parquet exports still see them. Upsert, Merge, Join and the set operations match them by scanning
+	// the rows.
+	NotIndexed []KeyName
+}
+
+// SetIndexPolicy sets the keys that are stored but not indexed, saving the maps of their values.
+// The index entries of the rows already holding newly excluded keys are dropped, along with their
+// acceleration structures and the composite indexes including them, and the values of keys no longer
+// excluded are indexed.
+func (d *DataFrame) SetIndexPolicy(policy IndexPolicy) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.notIndexed = nil
+	if len(policy.NotIndexed) > 0 {
+		d.notIndexed = make(map[KeyName]bool, len(policy.NotIndexed))
+		for _, key := range policy.NotIndexed {
+			d.notIndexed[key] = true
+		}
+	}
+
+	for key := range d.Keys {
+		if !d.notIndexedUnlocked(key) {
+			continue
+		}
+		delete(d.Keys, key)
+		delete(d.Strings, key)
+		delete(d.Numerics, key)
+		delete(d.Booleans, key)
+		delete(d.Times, key)
+		for kind := SortedNumericIndex; kind <= TrigramIndex; kind++ {
+			d.dropAcceleratorUnlocked(key, kind)
+		}
+	}
+
+	mapped := make(map[KeyName]bool, len(d.Keys))
+	for key := range d.Keys {
+		mapped[key] = true
+	}
+
+	for id, row := range d.Data {
+		for key, value := range row {
+			if mapped[key] || d.notIndexedUnlocked(key) {
+				continue
+			}
+
+			var err error
+			switch v := value.(type) {
+			case string:
+				if err = d.addMapping(key, String); err == nil {
+					indexValue(d.Strings, key, v, id)
+				}
+			case float64:
+				if err = d.addMapping(key, Numeric); err == nil {
+					indexValue(d.Numerics, key, v, id)
+				}
+			case bool:
+				if err = d.addMapping(key, Boolean); err == nil {
+					indexValue(d.Booleans, key, v, id)
+				}
+			case time.Time:
+				if err = d.addMapping(key, Time); err == nil {
+					indexValue(d.Times, key, v, id)
+				}
+			}
+			if err != nil {
+				log.Printf("error adding mapping for key '%s': %s", key, err.Error())
+			}
+		}
+	}
+
+	composites := d.accel.composites
+	d.accel.composites = nil
+	for _, composite := range composites {
+		excluded := false
+		for _, key := range composite.keys {
+			excluded = excluded || d.notIndexedUnlocked(key)
+		}
+		if !excluded {
+			d.buildCompositeUnlocked(composite.keys)
+		}
+	}
+
+	d.snapshots.current.Store(nil)
+}
+
+// notIndexedUnlocked reports whether the key, or a key it is nested under, is stored but not indexed,
+// without acquiring locks.
+func (d *DataFrame) notIndexedUnlocked(key KeyName) bool {
+	if d.notIndexed == nil {
+		return false
+	}
+	if d.notIndexed[key] {
+		return true
+	}
+	for i := strings.IndexByte(string(key), '.'); i >= 0; {
+		if d.notIndexed[key[:i]] {
+			return true
+		}
+		next := strings.IndexByte(string(key[i+1:]), '.')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return false
+}
+
+// storedKeysUnlocked returns the type of every key stored in the rows of ids, including the keys that are not
+// indexed, without acquiring locks. A key that is not indexed takes the type of its first value in the order
+// of ids. Exports use it so keys left out of the indexes are not left out of the files.
+func (d *DataFrame) storedKeysUnlocked(ids []uuid.UUID) map[KeyName]KeyType {
+	keys := make(map[KeyName]KeyType, len(d.Keys))
+	for key, keyType := range d.Keys {
+		keys[key] = keyType
+	}
+	if d.notIndexed == nil {
+		return keys
+	}
+
+	for _, id := range ids {
+		for key, value := range d.Data[id] {
+			if _, known := keys[key]; known || !d.notIndexedUnlocked(key) {
+				continue
+			}
+			if keyType, ok := keyTypeOf(value); ok {
+				keys[key] = keyType
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,145 @@
+package mframe_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSetIndexPolicy(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetIndexPolicy(mframe.IndexPolicy{NotIndexed: []mframe.KeyName{"message", "raw"}})
+
+	cache.Insert(map[mframe.KeyName]interface{}{
+		"host":    "web-1",
+		"message": "Failed password for root",
+		"raw":     map[string]interface{}{"size": 10, "body": "..."},
+	})
+	cache.Insert(map[mframe.KeyName]interface{}{
+		"host":    "web-2",
+		"message": "Accepted password for admin",
+		"raw":     map[string]interface{}{"size": 20, "body": "..."},
+	})
+
+	if n := cache.Filter(mframe.Contains, "message", "password", nil).Count(); n != 0 {
+		t.Errorf("expected no rows from a key that is not indexed, but got %d", n)
+	}
+	if _, ok := cache.Strings["message"]; ok {
+		t.Errorf("expected no index for 'message'")
+	}
+	if _, ok := cache.Numerics["raw.size"]; ok {
+		t.Errorf("expected no index for the nested 'raw.size'")
+	}
+	if sum, err := cache.Sum("raw.size"); err != nil || sum != 30 {
+		t.Errorf("expected the stored values to sum to 30, but got %f (%v)", sum, err)
+	}
+
+	rows := cache.Filter(mframe.Equals, "host", "web-1", nil).Rows()
+	if len(rows) != 1 || rows[0]["message"] != "Failed password for root" || rows[0]["raw.size"] != 10.0 {
+		t.Errorf("expected the stored values in the row, but got %v", rows)
+	}
+
+	cache.SetIndexPolicy(mframe.IndexPolicy{NotIndexed: []mframe.KeyName{"host"}})
+	if n := cache.Filter(mframe.Contains, "message", "password", nil).Count(); n != 2 {
+		t.Errorf("expected 2 rows once 'message' is indexed, but got %d", n)
+	}
+	if n := cache.Filter(mframe.Equals, "host", "web-1", nil).Count(); n != 0 {
+		t.Errorf("expected no rows once 'host' is not indexed, but got %d", n)
+	}
+
+	cache.DeleteWhere(mframe.Contains, "message", "Failed", nil)
+	if cache.Count() != 1 {
+		t.Errorf("expected 1 row, but got %d", cache.Count())
+	}
+}
+
+func TestIndexPolicyJSONRoundTrip(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetIndexPolicy(mframe.IndexPolicy{NotIndexed: []mframe.KeyName{"message"}})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1", "message": "disk full"})
+
+	filename := filepath.Join(t.TempDir(), "frame.json")
+	if err := cache.ExportToJSON(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var restored mframe.DataFrame
+	restored.Init(24 * time.Hour)
+	restored.SetIndexPolicy(mframe.IndexPolicy{NotIndexed: []mframe.KeyName{"message"}})
+	if err := restored.ImportFromJSON(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := restored.Filter(mframe.Equals, "message", "disk full", nil).Count(); got != 0 {
+		t.Errorf("expected the excluded key to stay out of the indexes, but got %d rows", got)
+	}
+	if _, ok := restored.Keys["message"]; ok {
+		t.Errorf("expected the excluded key to be unmapped")
+	}
+	for _, row := range restored.Rows() {
+		if row["message"] != "disk full" {
+			t.Errorf("expected the excluded value in the row, but got %v", row["message"])
+		}
+	}
+	if got := restored.Filter(mframe.Equals, "host", "web-1", nil).Count(); got != 1 {
+		t.Errorf("expected 1 row for the indexed key, but got %d", got)
+	}
+}
+
+func TestIndexPolicyExportsAndMatches(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetIndexPolicy(mframe.IndexPolicy{NotIndexed: []mframe.KeyName{"message"}})
+	cache.Insert(map[mframe.KeyName]interface{}{"n": 1, "message": "disk full"})
+
+	var csv bytes.Buffer
+	if err := cache.ExportToCSV(&csv, mframe.CSVOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if header, _, _ := strings.Cut(csv.String(), "\n"); header != "message,n" {
+		t.Errorf("expected the excluded key to be exported, but got the header %q", header)
+	}
+
+	record := cache.ToArrowRecord()
+	if len(record.Columns) != 3 {
+		t.Errorf("expected an Arrow column for the excluded key, but got %d columns", len(record.Columns))
+	}
+
+	filename := filepath.Join(t.TempDir(), "frame.parquet")
+	if err := cache.ExportToParquet(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var restored mframe.DataFrame
+	restored.Init(24 * time.Hour)
+	if _, err := restored.ImportFromParquet(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := restored.Filter(mframe.Equals, "message", "disk full", nil).Count(); got != 1 {
+		t.Errorf("expected the excluded key in the Parquet file, but got %d rows", got)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Upsert("message", map[mframe.KeyName]interface{}{"n": 2, "message": "disk full"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if cache.Count() != 1 {
+		t.Errorf("expected Upsert to match the excluded key, but got %d rows", cache.Count())
+	}
+
+	var other mframe.DataFrame
+	other.Init(24 * time.Hour)
+	other.Insert(map[mframe.KeyName]interface{}{"n": 3, "message": "disk full"})
+	if updated, inserted := cache.Merge(&other, "message"); updated != 1 || inserted != 0 {
+		t.Errorf("expected Merge to update the matching row, but got %d updated and %d inserted", updated, inserted)
+	}
+	if joined := other.Join(&cache, "message", mframe.JoinInner); joined.Count() != 1 {
+		t.Errorf("expected Join to match the excluded key, but got %d rows", joined.Count())
+	}
+}
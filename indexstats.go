@@ -0,0 +1,108 @@
+package mframe
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// indexStatsTopValues is the number of most frequent values reported by IndexStats.
+const indexStatsTopValues = 5
+
+// KeyStats describes the index of a key, as reported by IndexStats.
+type KeyStats struct {
+	Key  KeyName
+	Type KeyType
+	// Cardinality is the number of distinct values and Rows the number of rows holding the key.
+	Cardinality int
+	Rows        int
+	// Min and Max are the smallest and largest values of Numeric and Time keys, as float64 or time.Time,
+	// and nil for other keys.
+	Min interface{}
+	Max interface{}
+	// TopValues holds the most frequent values by decreasing count, ties ordered by value.
+	TopValues []ValueCount
+}
+
+// IndexStats returns the statistics of the index of every key, ordered by key: the number of distinct
+// values and of rows holding the key, the range of numeric and time keys and the most frequent values.
+// They are read from the indexes, without scanning the rows.
+func (d *DataFrame) IndexStats() []KeyStats {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	result := make([]KeyStats, 0, len(d.Keys))
+	for key := range d.Keys {
+		result = append(result, d.keyStatsUnlocked(key))
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+
+	return result
+}
+
+// keyStatsUnlocked returns the statistics of the index of a key, without acquiring locks.
+func (d *DataFrame) keyStatsUnlocked(key KeyName) KeyStats {
+	stats := KeyStats{Key: key, Type: d.Keys[key]}
+
+	switch stats.Type {
+	case String:
+		valueStats(&stats, d.Strings[key], nil)
+	case Numeric:
+		valueStats(&stats, d.Numerics[key], func(a, b float64) bool { return a < b })
+	case Boolean:
+		valueStats(&stats, d.Booleans[key], nil)
+	case Time:
+		valueStats(&stats, d.Times[key], func(a, b time.Time) bool { return a.Before(b) })
+	}
+
+	return stats
+}
+
+// valueStats fills the statistics of the values of an index, with their range when less is given.
+func valueStats[V comparable](stats *KeyStats, index map[V]map[uuid.UUID]bool, less func(a, b V) bool) {
+	stats.Cardinality = len(index)
+
+	var min, max V
+	first := true
+	top := make([]ValueCount, 0, indexStatsTopValues+1)
+	for value, ids := range index {
+		stats.Rows += len(ids)
+
+		if less != nil {
+			if first || less(value, min) {
+				min = value
+			}
+			if first || less(max, value) {
+				max = value
+			}
+			first = false
+		}
+
+		count := ValueCount{Value: value, Count: len(ids), Weight: float64(len(ids))}
+		i := sort.Search(len(top), func(i int) bool { return rankedBefore(count, top[i]) })
+		if i == indexStatsTopValues {
+			continue
+		}
+		top = append(top, ValueCount{})
+		copy(top[i+1:], top[i:])
+		top[i] = count
+		if len(top) > indexStatsTopValues {
+			top = top[:indexStatsTopValues]
+		}
+	}
+
+	if less != nil && !first {
+		stats.Min, stats.Max = min, max
+	}
+	stats.TopValues = top
+}
+
+// rankedBefore reports whether a ranks before b among the most frequent values.
+func rankedBefore(a, b ValueCount) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return valueLess(a.Value, b.Value)
+}
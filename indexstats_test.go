@@ -0,0 +1,56 @@
+package mframe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestIndexStats(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hosts := []string{"web-1", "web-1", "web-1", "web-2", "web-2", "db-1", "a", "b", "c"}
+	for i, host := range hosts {
+		cache.Insert(map[mframe.KeyName]interface{}{"host": host, "bytes": i * 10, "seen": base.Add(time.Duration(i) * time.Minute)})
+	}
+	cache.Insert(map[mframe.KeyName]interface{}{"up": true})
+
+	stats := cache.IndexStats()
+	if len(stats) != 4 {
+		t.Fatalf("expected 4 keys, but got %d", len(stats))
+	}
+	byKey := make(map[mframe.KeyName]mframe.KeyStats)
+	for _, s := range stats {
+		byKey[s.Key] = s
+	}
+	if stats[0].Key != "bytes" || stats[3].Key != "up" {
+		t.Errorf("expected keys in order, but got %v", stats)
+	}
+
+	host := byKey["host"]
+	if host.Type != mframe.String || host.Cardinality != 6 || host.Rows != 9 || host.Min != nil {
+		t.Errorf("expected 6 hosts in 9 rows without range, but got %+v", host)
+	}
+	if len(host.TopValues) != 5 || host.TopValues[0].Value != "web-1" || host.TopValues[0].Count != 3 ||
+		host.TopValues[1].Value != "web-2" || host.TopValues[2].Value != "a" {
+		t.Errorf("expected the most frequent hosts, but got %+v", host.TopValues)
+	}
+
+	bytes := byKey["bytes"]
+	if bytes.Min != 0.0 || bytes.Max != 80.0 {
+		t.Errorf("expected bytes from 0 to 80, but got %v to %v", bytes.Min, bytes.Max)
+	}
+	seen := byKey["seen"]
+	if seen.Min != base || seen.Max != base.Add(8*time.Minute) {
+		t.Errorf("expected times from %v, but got %v to %v", base, seen.Min, seen.Max)
+	}
+
+	explain := cache.Explain(mframe.Equals, "host", "web-1")
+	if !strings.Contains(strings.Join(explain.Details, "\n"), "Most frequent value 'web-1' holds 3 rows") {
+		t.Errorf("expected the most frequent value in %v", explain.Details)
+	}
+}
@@ -1,6 +1,7 @@
 package mframe
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
@@ -11,13 +12,32 @@ import (
 
 // index processes key-value pairs recursively to index data into the DataFrame,
 // handling various data types and nested structures.
-// Note: Errors during indexing are logged but do not stop the indexing process.
+// Note: Errors during indexing are logged but do not stop the indexing process. In strict mode,
+// rows are checked with checkIndexableUnlocked beforehand and rejected instead.
 func (d *DataFrame) index(kv map[KeyName]interface{}, wrapKey KeyName, id uuid.UUID, row *Row) {
 	for kvKey, kvValue := range kv {
 		if wrapKey != "" {
 			kvKey = KeyName(fmt.Sprintf("%s.%s", wrapKey, kvKey))
 		}
 
+		if d.notIndexedUnlocked(kvKey) {
+			switch kvValue.(type) {
+			case map[string]interface{}, []interface{}:
+			case nil:
+				continue
+			default:
+				(*row)[kvKey] = normalizeValue(kvValue)
+				continue
+			}
+		}
+
+		if s, ok := kvValue.(string); ok {
+			kvValue = d.coerceUnlocked(kvKey, s)
+			if _, parsed := kvValue.(float64); parsed && d.coercion.KeepNumericStrings {
+				d.index(map[KeyName]interface{}{kvKey + NumericSourceSuffix: s}, "", id, row)
+			}
+		}
+
 		kvValueType := reflect.TypeOf(kvValue)
 		if kvValueType == nil {
 			continue
@@ -40,6 +60,7 @@ func (d *DataFrame) index(kv map[KeyName]interface{}, wrapKey KeyName, id uuid.U
 			err := d.addMapping(kvKey, String)
 			if err != nil {
 				log.Printf("error adding mapping for key '%s': %s", kvKey, err.Error())
+				d.markMismatchUnlocked(kvKey, id)
 				continue
 			}
 
@@ -51,9 +72,15 @@ func (d *DataFrame) index(kv map[KeyName]interface{}, wrapKey KeyName, id uuid.U
 
 			if len(d.Strings[kvKey][kvValue.(string)]) == 0 {
 				d.Strings[kvKey][kvValue.(string)] = make(map[uuid.UUID]bool)
+				d.onNewValue(kvKey, kvValue)
 			}
 
 			d.Strings[kvKey][kvValue.(string)][id] = true
+			d.onRowValue(kvKey, kvValue, id)
+
+			if d.entropyKeys[kvKey] {
+				d.num(kvKey+EntropySuffix, ShannonEntropy(kvValue.(string)), id, row)
+			}
 		case "float64":
 			d.num(kvKey, kvValue.(float64), id, row)
 		case "int64":
@@ -82,6 +109,7 @@ func (d *DataFrame) index(kv map[KeyName]interface{}, wrapKey KeyName, id uuid.U
 			err := d.addMapping(kvKey, Boolean)
 			if err != nil {
 				log.Printf("error adding mapping for key '%s': %s", kvKey, err.Error())
+				d.markMismatchUnlocked(kvKey, id)
 				continue
 			}
 
@@ -96,10 +124,12 @@ func (d *DataFrame) index(kv map[KeyName]interface{}, wrapKey KeyName, id uuid.U
 			}
 
 			d.Booleans[kvKey][kvValue.(bool)][id] = true
+			d.onRowValue(kvKey, kvValue, id)
 		case "uuid.UUID":
 			err := d.addMapping(kvKey, String)
 			if err != nil {
 				log.Printf("error adding mapping for key '%s': %s", kvKey, err.Error())
+				d.markMismatchUnlocked(kvKey, id)
 				continue
 			}
 
@@ -112,13 +142,16 @@ func (d *DataFrame) index(kv map[KeyName]interface{}, wrapKey KeyName, id uuid.U
 
 			if len(d.Strings[kvKey][uuidValue]) == 0 {
 				d.Strings[kvKey][uuidValue] = make(map[uuid.UUID]bool)
+				d.onNewValue(kvKey, uuidValue)
 			}
 
 			d.Strings[kvKey][uuidValue][id] = true
+			d.onRowValue(kvKey, uuidValue, id)
 		case "time.Time":
 			err := d.addMapping(kvKey, Time)
 			if err != nil {
 				log.Printf("error adding mapping for key '%s': %s", kvKey, err.Error())
+				d.markMismatchUnlocked(kvKey, id)
 				continue
 			}
 
@@ -131,9 +164,11 @@ func (d *DataFrame) index(kv map[KeyName]interface{}, wrapKey KeyName, id uuid.U
 
 			if len(d.Times[kvKey][timeValue]) == 0 {
 				d.Times[kvKey][timeValue] = make(map[uuid.UUID]bool)
+				d.onNewValue(kvKey, timeValue)
 			}
 
 			d.Times[kvKey][timeValue][id] = true
+			d.onRowValue(kvKey, timeValue, id)
 		default:
 			log.Printf("unknown field type: %s", kvValueType.String())
 		}
@@ -145,6 +180,7 @@ func (d *DataFrame) num(keyName KeyName, value float64, id uuid.UUID, row *Row)
 	err := d.addMapping(keyName, Numeric)
 	if err != nil {
 		log.Printf("error adding mapping for key '%s': %s", keyName, err.Error())
+		d.markMismatchUnlocked(keyName, id)
 		return
 	}
 
@@ -156,22 +192,52 @@ func (d *DataFrame) num(keyName KeyName, value float64, id uuid.UUID, row *Row)
 
 	if len(d.Numerics[keyName][value]) == 0 {
 		d.Numerics[keyName][value] = make(map[uuid.UUID]bool)
+		d.onNewValue(keyName, value)
 	}
 
 	d.Numerics[keyName][value][id] = true
+	d.onRowValue(keyName, value, id)
 }
 
 // Insert adds a new row to the DataFrame using the provided data,
 // generating a unique ID and applying the configured TTL.
 func (d *DataFrame) Insert(data map[KeyName]interface{}) {
+	if _, err := d.insert(data); err != nil {
+		log.Printf("error inserting row: %s", err.Error())
+	}
+}
+
+// insert runs the insert hooks around indexing the data as a new row and returns the generated ID.
+func (d *DataFrame) insert(data map[KeyName]interface{}) (uuid.UUID, error) {
+	data, err := d.runBeforeInsert(data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
 	d.Locker.Lock()
-	defer d.Locker.Unlock()
+	if err := d.validateUnlocked(map[uuid.UUID]map[KeyName]interface{}{uuid.Nil: data}); err != nil {
+		d.Locker.Unlock()
+		return uuid.Nil, err
+	}
+	id := d.insertUnlocked(data)
+	var row Row
+	if d.hooks.hasAfterInsert() {
+		row = copyRow(d.Data[id])
+	}
+	d.unlockAndNotify()
 
-	id := uuid.New()
-	var row = make(Row)
-	d.index(data, "", id, &row)
-	d.Data[id] = row
-	d.ExpireAt[id] = time.Now().UTC().Add(d.TTL)
+	if row != nil {
+		d.runAfterInsert(id, row)
+	}
+
+	return id, nil
+}
+
+// insertUnlocked indexes the data as a new row without acquiring locks and returns the generated ID.
+func (d *DataFrame) insertUnlocked(data map[KeyName]interface{}) uuid.UUID {
+	id := d.idGeneratorUnlocked()()
+	d.insertWithIDUnlocked(id, data)
+	return id
 }
 
 // InsertWithError adds a new row to the DataFrame and returns an error if the data is invalid
@@ -183,76 +249,224 @@ func (d *DataFrame) InsertWithError(data map[KeyName]interface{}) error {
 		return fmt.Errorf("cannot insert empty data")
 	}
 
-	d.Insert(data)
-	return nil
+	_, err := d.insert(data)
+	return err
+}
+
+// InsertReturningID adds a new row to the DataFrame like InsertWithError and returns its generated ID,
+// so the row can later be read, updated or removed without searching for it.
+func (d *DataFrame) InsertReturningID(data map[KeyName]interface{}) (uuid.UUID, error) {
+	if data == nil {
+		return uuid.Nil, fmt.Errorf("cannot insert nil data")
+	}
+	if len(data) == 0 {
+		return uuid.Nil, fmt.Errorf("cannot insert empty data")
+	}
+
+	return d.insert(data)
 }
 
-// InsertWithID adds a row to the DataFrame with a specific ID.
+// insertWithIDUnlocked indexes the data as a row with a specific ID.
 // This method assumes the caller already holds the necessary locks.
-func (d *DataFrame) insertWithIDUnlocked(id uuid.UUID, data Row) {
-	d.Data[id] = data
-	d.ExpireAt[id] = time.Now().UTC().Add(d.TTL)
+func (d *DataFrame) insertWithIDUnlocked(id uuid.UUID, data map[KeyName]interface{}) {
+	var row = make(Row)
+	d.index(data, "", id, &row)
+	d.Data[id] = row
+	d.setExpireAtUnlocked(id, time.Now().UTC().Add(d.TTL))
+	d.order.push(id)
+	d.recency.touch(id)
+	d.markChangedUnlocked(id)
+	d.activity.inserted++
+	d.activity.inserts.add(time.Now())
+	d.publishUnlocked(ChangeInsert, id, nil, row)
+	d.matchTriggersUnlocked(id, row)
+	d.evictUnlocked(id)
 }
 
 // InsertBatch adds multiple rows to the DataFrame in a single operation,
-// reducing lock contention for bulk inserts.
+// reducing lock contention for bulk inserts. Rows are inserted in the order of the slice.
+// If a row breaks the schema, none are inserted and a *SchemaError is returned.
 func (d *DataFrame) InsertBatch(rows []map[KeyName]interface{}) error {
 	if len(rows) == 0 {
 		return fmt.Errorf("cannot insert empty batch")
 	}
 
-	d.Locker.Lock()
-	defer d.Locker.Unlock()
+	d.Locker.RLock()
+	newID := d.idGeneratorUnlocked()
+	d.Locker.RUnlock()
 
+	entries := make([]batchEntry, 0, len(rows))
 	for _, data := range rows {
-		if data == nil {
-			continue
-		}
-		if len(data) == 0 {
-			continue
-		}
-
-		id := uuid.New()
-		var row = make(Row)
-		d.index(data, "", id, &row)
-		d.Data[id] = row
-		d.ExpireAt[id] = time.Now().UTC().Add(d.TTL)
+		entries = append(entries, batchEntry{id: newID(), data: data})
 	}
 
-	return nil
+	return d.insertEntries(entries)
 }
 
-// InsertBatchWithIDs adds multiple rows with specific IDs to the DataFrame.
+// InsertBatchWithIDs adds multiple rows with specific IDs to the DataFrame, inserted in the order of their IDs.
 func (d *DataFrame) InsertBatchWithIDs(entries map[uuid.UUID]map[KeyName]interface{}) error {
 	if len(entries) == 0 {
 		return fmt.Errorf("cannot insert empty batch")
 	}
 
-	d.Locker.Lock()
-	defer d.Locker.Unlock()
+	return d.insertEntries(batchEntriesOf(entries))
+}
+
+// batchEntry is a row about to be inserted with its ID. Batches are slices of entries so rows are
+// inserted in the order they were given.
+type batchEntry struct {
+	id   uuid.UUID
+	data map[KeyName]interface{}
+}
+
+// batchEntriesOf returns the entries of the map sorted by ID.
+func batchEntriesOf(entries map[uuid.UUID]map[KeyName]interface{}) []batchEntry {
+	ids := make([]uuid.UUID, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	batch := make([]batchEntry, len(ids))
+	for i, id := range ids {
+		batch[i] = batchEntry{id: id, data: entries[id]}
+	}
+	return batch
+}
 
-	for id, data := range entries {
-		if data == nil || len(data) == 0 {
+// insertEntries runs the insert hooks for every entry and indexes the accepted ones in order under a single lock.
+// Nil or empty entries are skipped, and entries rejected by a hook are logged and skipped.
+// An entry with the ID of an existing row replaces it. If an accepted entry breaks the schema,
+// none are inserted and a *SchemaError is returned. In strict mode, empty entries, entries rejected by a hook
+// and values that cannot be indexed fail the whole batch instead.
+func (d *DataFrame) insertEntries(entries []batchEntry) error {
+	_, err := d.insertEntriesWithOptions(entries, batchOptions{})
+	return err
+}
+
+// batchOptions adjusts how insertEntriesWithOptions handles the entries.
+type batchOptions struct {
+	// skipExisting keeps existing rows instead of replacing them with entries having the same ID.
+	skipExisting bool
+	// expireAt holds the expiration of the entries that should not expire after the TTL.
+	expireAt map[uuid.UUID]time.Time
+}
+
+// insertEntriesWithOptions works like insertEntries, applying options. Returns the number of inserted rows.
+func (d *DataFrame) insertEntriesWithOptions(entries []batchEntry, options batchOptions) (int, error) {
+	d.Locker.RLock()
+	strict := d.strict
+	d.Locker.RUnlock()
+
+	accepted := make([]batchEntry, 0, len(entries))
+	checked := make(map[uuid.UUID]map[KeyName]interface{}, len(entries))
+	var rejected []error
+	for _, entry := range entries {
+		if len(entry.data) == 0 {
+			if strict {
+				rejected = append(rejected, &IndexError{ID: entry.id, Reason: "row is empty"})
+			}
 			continue
 		}
 
-		var row = make(Row)
-		d.index(data, "", id, &row)
-		d.Data[id] = row
-		d.ExpireAt[id] = time.Now().UTC().Add(d.TTL)
+		data, err := d.runBeforeInsert(entry.data)
+		if err != nil {
+			if strict {
+				rejected = append(rejected, fmt.Errorf("row %s: %w", entry.id, err))
+			} else {
+				log.Printf("error inserting row: %s", err.Error())
+			}
+			continue
+		}
+
+		accepted = append(accepted, batchEntry{id: entry.id, data: data})
+		checked[entry.id] = data
 	}
 
-	return nil
+	if len(rejected) > 0 {
+		return 0, errors.Join(rejected...)
+	}
+
+	d.Locker.Lock()
+	if err := d.validateUnlocked(checked); err != nil {
+		d.Locker.Unlock()
+		return 0, err
+	}
+
+	var inserted map[uuid.UUID]Row
+	if d.hooks.hasAfterInsert() {
+		inserted = make(map[uuid.UUID]Row, len(accepted))
+	}
+	count := 0
+	for _, entry := range accepted {
+		id, data := entry.id, entry.data
+		if _, exists := d.Data[id]; exists {
+			if options.skipExisting {
+				continue
+			}
+			d.removeElementUnlocked(id)
+		}
+
+		d.insertWithIDUnlocked(id, data)
+		if expireAt, ok := options.expireAt[id]; ok {
+			d.setExpireAtUnlocked(id, expireAt)
+		}
+		count++
+
+		if inserted != nil {
+			inserted[id] = copyRow(d.Data[id])
+		}
+	}
+	d.unlockAndNotify()
+
+	for id, row := range inserted {
+		d.runAfterInsert(id, row)
+	}
+
+	return count, nil
 }
 
 // addMapping maps a keyName to a specified keyType in the DataFrame.
 // Returns an error if the keyName already has a different keyType.
 func (d *DataFrame) addMapping(keyName KeyName, keyType KeyType) error {
-	if key, ok := d.Keys[keyName]; ok && key != keyType {
-		return fmt.Errorf("cannot map key '%s' as '%v' because it is already mapped as type '%v'", keyName, keyType, d.Keys[keyName])
+	if key, ok := d.keyTypeUnlocked(keyName); ok && key != keyType {
+		return fmt.Errorf("cannot map key '%s' as '%v' because it is already mapped as type '%v'", keyName, keyType, key)
 	}
 
 	d.Keys[keyName] = keyType
 
 	return nil
 }
+
+// normalizeValue converts a value to the representation used in rows and indexes:
+// integer and float32 numbers become float64 and UUIDs become strings.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case uuid.UUID:
+		return v.String()
+	default:
+		return value
+	}
+}
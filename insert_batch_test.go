@@ -109,6 +109,47 @@ func TestInsertBatchWithIDs(t *testing.T) {
 	}
 }
 
+func TestInsertBatchKeepsOrder(t *testing.T) {
+	var df mframe.DataFrame
+	df.Init(5 * time.Minute)
+	df.SetIDGenerator(mframe.TimeOrderedIDs)
+	df.SetMaxRows(5, mframe.EvictLRU)
+
+	batch := make([]map[mframe.KeyName]interface{}, 20)
+	for i := range batch {
+		batch[i] = map[mframe.KeyName]interface{}{"n": float64(i)}
+	}
+	if err := df.InsertBatch(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := df.FilterIDs(mframe.GreaterOrEqual, "n", 0.0, nil)
+	if len(ids) != 5 {
+		t.Fatalf("expected 5 rows, but got %d", len(ids))
+	}
+
+	type arrival struct {
+		id uuid.UUID
+		n  float64
+	}
+	var kept []arrival
+	for id := range ids {
+		row, _ := df.Get(id)
+		if n := row["n"].(float64); n < 15 {
+			t.Errorf("expected the least recently inserted rows to be evicted, but row %v was kept", n)
+		} else {
+			kept = append(kept, arrival{id, n})
+		}
+	}
+	for _, a := range kept {
+		for _, b := range kept {
+			if a.n < b.n && a.id.String() >= b.id.String() {
+				t.Errorf("expected the ID of row %v to sort before the ID of row %v", a.n, b.n)
+			}
+		}
+	}
+}
+
 func TestInsertWithError(t *testing.T) {
 	df := &mframe.DataFrame{}
 	df.Init(5 * time.Minute)
@@ -139,6 +180,26 @@ func TestInsertWithError(t *testing.T) {
 	}
 }
 
+func TestInsertReturningID(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"name": "John"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row, ok := cache.Get(id); !ok || row["name"] != "John" {
+		t.Errorf("expected the returned ID to reference the row, but got %v", row)
+	}
+
+	if _, err := cache.InsertReturningID(nil); err == nil {
+		t.Error("expected error for nil data")
+	}
+	if _, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{}); err == nil {
+		t.Error("expected error for empty data")
+	}
+}
+
 func TestInitWithOptions(t *testing.T) {
 	df := &mframe.DataFrame{}
 	df.InitWithOptions(5*time.Minute, 500)
@@ -0,0 +1,31 @@
+package mframe
+
+import (
+	"iter"
+)
+
+// Iter returns an iterator over copies of the rows of the DataFrame, with their defaults, for pipelines that only
+// need one pass. The IDs are collected when iteration starts and each row is read under a short read lock, so
+// the loop body may modify the DataFrame; rows removed meanwhile are skipped and rows inserted meanwhile are not
+// visited. Breaking out of the loop stops the iteration.
+func (d *DataFrame) Iter() iter.Seq[Row] {
+	return d.FilterSpecIter(FilterSpec{})
+}
+
+// FilterIter works like Filter but returns an iterator over copies of the matching rows instead of building a
+// new DataFrame, see Iter. Query hooks and statistics, the query memory limit and the scan guard do not apply.
+func (d *DataFrame) FilterIter(operator Operator, key KeyName, value any, options map[FilterOption]bool) iter.Seq[Row] {
+	return d.FilterSpecIter(FilterSpec{Conditions: []Condition{{Operator: operator, Key: key, Value: value, Options: options}}})
+}
+
+// FilterSpecIter returns an iterator over copies of the rows matching every condition of spec, in ID order,
+// see Iter. It is a shorthand for ranging over NewCursor(spec).All().
+func (d *DataFrame) FilterSpecIter(spec FilterSpec) iter.Seq[Row] {
+	return func(yield func(Row) bool) {
+		for _, row := range d.NewCursor(spec).All() {
+			if !yield(row) {
+				return
+			}
+		}
+	}
+}
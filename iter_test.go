@@ -0,0 +1,52 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestIter(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"seq": i, "even": i%2 == 0})
+	}
+
+	total := 0
+	for row := range cache.Iter() {
+		total += int(row["seq"].(float64))
+		row["seq"] = -1.0
+	}
+	if total != 45 {
+		t.Errorf("expected the sum of every seq to be 45, but got %d", total)
+	}
+	if cache.CountWhere(mframe.Equals, "seq", -1.0, nil) != 0 {
+		t.Error("expected the iterator to yield copies")
+	}
+
+	visited := 0
+	for row := range cache.FilterIter(mframe.Equals, "even", true, nil) {
+		if row["even"] != true {
+			t.Errorf("expected only even rows, but got %v", row)
+		}
+		// Removing rows while iterating must not deadlock, and removed rows are skipped.
+		cache.DeleteWhere(mframe.Equals, "even", true, nil)
+		visited++
+	}
+	if visited != 1 {
+		t.Errorf("expected rows removed during the iteration to be skipped, but visited %d", visited)
+	}
+
+	seen := 0
+	for range cache.Iter() {
+		seen++
+		if seen == 2 {
+			break
+		}
+	}
+	if seen != 2 {
+		t.Errorf("expected the iteration to stop at 2, but got %d", seen)
+	}
+}
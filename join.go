@@ -0,0 +1,226 @@
+package mframe
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JoinType selects how rows without a match are handled by Join.
+type JoinType int
+
+const (
+	// JoinInner keeps only the rows with a match in both frames.
+	JoinInner JoinType = 1
+	// JoinLeft keeps every row of the DataFrame, merged with its matches when there are any.
+	JoinLeft JoinType = 2
+	// JoinAnti keeps only the rows of the DataFrame without a match, unmodified.
+	JoinAnti JoinType = 3
+)
+
+// ConflictPolicy selects which value is kept when both joined rows hold the same key.
+type ConflictPolicy int
+
+const (
+	// ConflictKeepLeft keeps the value of the DataFrame. It is the default policy.
+	ConflictKeepLeft ConflictPolicy = iota
+	// ConflictKeepRight keeps the value of the other frame.
+	ConflictKeepRight
+	// ConflictPrefixRight keeps both values, storing the value of the other frame under the key
+	// prefixed with JoinOptions.Prefix.
+	ConflictPrefixRight
+)
+
+// DefaultJoinPrefix is the prefix used by ConflictPrefixRight when JoinOptions.Prefix is empty.
+const DefaultJoinPrefix = "right_"
+
+// JoinOptions adjusts how JoinOn merges matched rows.
+type JoinOptions struct {
+	Conflict ConflictPolicy
+	Prefix   string
+}
+
+// Join combines the rows of the DataFrame with the rows of other having the same value for onKey,
+// using the indexes of other as a hash table. Merged rows hold every key of both rows; when both
+// rows have a key, the value of the DataFrame is kept. Unless provenance is disabled, merged rows also
+// record the name of other, the join key and the ID of the matched row of other.
+// Rows without onKey never match, so they are only kept by JoinLeft and JoinAnti.
+// Both frames are read-locked through LockFrames and the result is built in one batch.
+func (d *DataFrame) Join(other *DataFrame, onKey KeyName, joinType JoinType) *DataFrame {
+	return d.JoinOn(other, map[KeyName]KeyName{onKey: onKey}, joinType, JoinOptions{})
+}
+
+// JoinOn works like Join but matches rows on several keys at once. Each entry of on maps a key
+// of the DataFrame to the key of other holding the same value, and a row matches only when every
+// pair matches. Keys present in both rows are resolved according to options.Conflict.
+func (d *DataFrame) JoinOn(other *DataFrame, on map[KeyName]KeyName, joinType JoinType, options JoinOptions) *DataFrame {
+	if options.Prefix == "" {
+		options.Prefix = DefaultJoinPrefix
+	}
+	joinedOn := joinedOnLabel(on)
+
+	unlock := LockFrames(FrameLock{Frame: d}, FrameLock{Frame: other})
+	newID := d.idGeneratorUnlocked()
+
+	var results = new(DataFrame)
+	results.Init(d.TTL)
+
+	var entries []batchEntry
+
+	for _, leftID := range d.arrivalOrderUnlocked() {
+		left := d.Data[leftID]
+		matches := other.matchRowUnlocked(left, on)
+
+		if len(matches) == 0 {
+			if joinType == JoinLeft || joinType == JoinAnti {
+				entries = append(entries, batchEntry{id: newID(), data: copyRow(left)})
+			}
+			continue
+		}
+
+		if joinType == JoinAnti {
+			continue
+		}
+
+		for rightID := range matches {
+			entries = append(entries, batchEntry{id: newID(), data: d.joinRows(left, other.Data[rightID], other.name, joinedOn, rightID, options)})
+		}
+	}
+	unlock()
+
+	results.insertEntries(entries)
+
+	return results
+}
+
+// matchRowUnlocked returns the IDs of the rows matching every key pair of on for the given row,
+// intersecting the index lookups of each pair. Returns nil if the row lacks any of the keys.
+func (d *DataFrame) matchRowUnlocked(row Row, on map[KeyName]KeyName) map[uuid.UUID]bool {
+	var matches map[uuid.UUID]bool
+	for leftKey, rightKey := range on {
+		value, ok := row[leftKey]
+		if !ok {
+			return nil
+		}
+
+		ids := d.idsForValueUnlocked(rightKey, value)
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if matches == nil {
+			matches = ids
+			continue
+		}
+
+		intersection := make(map[uuid.UUID]bool)
+		for id := range matches {
+			if ids[id] {
+				intersection[id] = true
+			}
+		}
+		if len(intersection) == 0 {
+			return nil
+		}
+		matches = intersection
+	}
+
+	return matches
+}
+
+// joinedOnLabel describes the key pairs of a join for provenance, as a sorted comma separated list.
+// Pairs using the same key on both sides are written as the key alone.
+func joinedOnLabel(on map[KeyName]KeyName) string {
+	pairs := make([]string, 0, len(on))
+	for leftKey, rightKey := range on {
+		if leftKey == rightKey {
+			pairs = append(pairs, string(leftKey))
+		} else {
+			pairs = append(pairs, string(leftKey)+"="+string(rightKey))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// joinRows merges a row of the DataFrame with a matched row of another frame, resolving conflicts
+// according to options and adding provenance keys unless provenance is disabled.
+func (d *DataFrame) joinRows(left, right Row, rightName string, joinedOn string, rightID uuid.UUID, options JoinOptions) Row {
+	row := copyRow(left)
+	for k, v := range right {
+		if _, exists := left[k]; !exists {
+			row[k] = v
+			continue
+		}
+
+		switch options.Conflict {
+		case ConflictKeepRight:
+			row[k] = v
+		case ConflictPrefixRight:
+			row[KeyName(options.Prefix)+k] = v
+		}
+	}
+
+	if !d.noProvenance {
+		if rightName != "" {
+			row[ProvenanceSourceFrame] = rightName
+		}
+		row[ProvenanceJoinedOn] = joinedOn
+		row[ProvenanceMatchedID] = rightID.String()
+	}
+
+	return row
+}
+
+// idsForValueUnlocked returns the IDs of the rows holding the value for the key, looked up
+// in the index matching the type of the value, without acquiring locks. Keys that are not indexed
+// are matched by scanning the rows.
+func (d *DataFrame) idsForValueUnlocked(key KeyName, value interface{}) map[uuid.UUID]bool {
+	if d.notIndexedUnlocked(key) {
+		return d.scanValueUnlocked(key, value)
+	}
+
+	switch v := normalizeValue(value).(type) {
+	case string:
+		return d.Strings[key][v]
+	case float64:
+		return d.Numerics[key][v]
+	case bool:
+		return d.Booleans[key][v]
+	case time.Time:
+		return d.Times[key][v]
+	default:
+		return nil
+	}
+}
+
+// scanValueUnlocked returns the IDs of the rows holding the value for the key by reading every row,
+// for keys left out of the indexes, without acquiring locks.
+func (d *DataFrame) scanValueUnlocked(key KeyName, value interface{}) map[uuid.UUID]bool {
+	value = normalizeValue(value)
+	if _, ok := keyTypeOf(value); !ok {
+		return nil
+	}
+
+	var ids map[uuid.UUID]bool
+	for id, row := range d.Data {
+		stored, ok := row[key]
+		if !ok {
+			continue
+		}
+		if t, isTime := value.(time.Time); isTime {
+			if other, ok := stored.(time.Time); !ok || !other.Equal(t) {
+				continue
+			}
+		} else if stored != value {
+			continue
+		}
+		if ids == nil {
+			ids = make(map[uuid.UUID]bool)
+		}
+		ids[id] = true
+	}
+	return ids
+}
@@ -0,0 +1,172 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func joinFrames() (*mframe.DataFrame, *mframe.DataFrame) {
+	events := new(mframe.DataFrame)
+	events.Init(24 * time.Hour)
+	events.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "action": "login"},
+		{"ip": "10.0.0.1", "action": "logout"},
+		{"ip": "10.0.0.2", "action": "login"},
+		{"ip": "10.0.0.3", "action": "scan"},
+		{"action": "unknown"},
+	})
+
+	intel := new(mframe.DataFrame)
+	intel.Init(24 * time.Hour)
+	intel.SetName("intel")
+	intel.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "reputation": "bad", "action": "block"},
+		{"ip": "10.0.0.3", "reputation": "suspicious"},
+		{"ip": "10.0.0.9", "reputation": "bad"},
+	})
+
+	return events, intel
+}
+
+func TestJoinInner(t *testing.T) {
+	events, intel := joinFrames()
+
+	joined := events.Join(intel, "ip", mframe.JoinInner)
+	if joined.Count() != 3 {
+		t.Fatalf("expected 3 joined rows, but got %d", joined.Count())
+	}
+
+	if joined.CountWhere(mframe.Equals, "reputation", "bad", nil) != 2 {
+		t.Error("expected 2 rows joined with a bad reputation")
+	}
+	if joined.CountWhere(mframe.Equals, "action", "block", nil) != 0 {
+		t.Error("expected conflicting keys to keep the value of the left frame")
+	}
+
+	for _, row := range joined.ToSlice() {
+		if row[mframe.ProvenanceSourceFrame] != "intel" || row[mframe.ProvenanceJoinedOn] != "ip" {
+			t.Errorf("expected provenance keys, but got %v", row)
+		}
+		matchedID, _ := uuid.Parse(row[mframe.ProvenanceMatchedID].(string))
+		if intel.Data[matchedID]["ip"] != row["ip"] {
+			t.Errorf("expected matched id to reference the joined intel row, but got %v", row)
+		}
+	}
+}
+
+func TestJoinNumericKey(t *testing.T) {
+	var ports mframe.DataFrame
+	ports.Init(24 * time.Hour)
+	ports.Insert(map[mframe.KeyName]interface{}{"port": 22, "service": "ssh"})
+
+	var flows mframe.DataFrame
+	flows.Init(24 * time.Hour)
+	flows.SetProvenance(false)
+	flows.Insert(map[mframe.KeyName]interface{}{"port": 22, "bytes": 100})
+	flows.Insert(map[mframe.KeyName]interface{}{"port": 80, "bytes": 200})
+
+	joined := flows.Join(&ports, "port", mframe.JoinInner)
+	rows := joined.ToSlice()
+	if len(rows) != 1 || rows[0]["service"] != "ssh" {
+		t.Fatalf("expected 1 row joined with ssh, but got %v", rows)
+	}
+	if _, ok := rows[0][mframe.ProvenanceMatchedID]; ok {
+		t.Error("expected no provenance keys when provenance is disabled")
+	}
+
+	if self := flows.Join(&flows, "port", mframe.JoinInner); self.Count() != 2 {
+		t.Errorf("expected 2 rows when joining a frame with itself, but got %d", self.Count())
+	}
+}
+
+func TestJoinLeft(t *testing.T) {
+	events, intel := joinFrames()
+
+	joined := events.Join(intel, "ip", mframe.JoinLeft)
+	if joined.Count() != 5 {
+		t.Fatalf("expected 5 rows, but got %d", joined.Count())
+	}
+	if joined.CountWhere(mframe.Equals, mframe.ProvenanceJoinedOn, "ip", nil) != 3 {
+		t.Error("expected 3 merged rows")
+	}
+	if joined.CountWhere(mframe.Equals, "ip", "10.0.0.2", nil) != 1 {
+		t.Error("expected the unmatched row to be kept")
+	}
+}
+
+func TestJoinAnti(t *testing.T) {
+	events, intel := joinFrames()
+
+	joined := events.Join(intel, "ip", mframe.JoinAnti)
+	if joined.Count() != 2 {
+		t.Fatalf("expected 2 rows, but got %d", joined.Count())
+	}
+	if joined.CountWhere(mframe.Equals, "ip", "10.0.0.2", nil) != 1 {
+		t.Error("expected the unmatched row to be kept")
+	}
+	if joined.CountWhere(mframe.Equals, "action", "unknown", nil) != 1 {
+		t.Error("expected the row without the join key to be kept")
+	}
+	if _, ok := joined.Keys[mframe.ProvenanceJoinedOn]; ok {
+		t.Error("expected anti-joined rows to be unmodified")
+	}
+}
+
+func TestJoinOnMultipleKeys(t *testing.T) {
+	var flows mframe.DataFrame
+	flows.Init(24 * time.Hour)
+	flows.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"src_ip": "10.0.0.1", "port": 22, "bytes": 100},
+		{"src_ip": "10.0.0.1", "port": 443, "bytes": 200},
+		{"src_ip": "10.0.0.2", "port": 22, "bytes": 300},
+	})
+
+	var services mframe.DataFrame
+	services.Init(24 * time.Hour)
+	services.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "dst_port": 22, "service": "ssh", "bytes": 1},
+		{"ip": "10.0.0.2", "dst_port": 443, "service": "https", "bytes": 2},
+	})
+
+	on := map[mframe.KeyName]mframe.KeyName{"src_ip": "ip", "port": "dst_port"}
+
+	tests := []struct {
+		name     string
+		options  mframe.JoinOptions
+		key      mframe.KeyName
+		expected interface{}
+	}{
+		{"keep left", mframe.JoinOptions{}, "bytes", 100.0},
+		{"keep right", mframe.JoinOptions{Conflict: mframe.ConflictKeepRight}, "bytes", 1.0},
+		{"default prefix", mframe.JoinOptions{Conflict: mframe.ConflictPrefixRight}, "right_bytes", 1.0},
+		{"custom prefix", mframe.JoinOptions{Conflict: mframe.ConflictPrefixRight, Prefix: "svc."}, "svc.bytes", 1.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			joined := flows.JoinOn(&services, on, mframe.JoinInner, tt.options)
+			rows := joined.ToSlice()
+			if len(rows) != 1 {
+				t.Fatalf("expected 1 joined row, but got %d", len(rows))
+			}
+			if rows[0]["service"] != "ssh" {
+				t.Errorf("expected service ssh, but got %v", rows[0]["service"])
+			}
+			if rows[0][tt.key] != tt.expected {
+				t.Errorf("expected %s to be %v, but got %v", tt.key, tt.expected, rows[0][tt.key])
+			}
+			if rows[0][mframe.ProvenanceJoinedOn] != "port=dst_port,src_ip=ip" {
+				t.Errorf("expected joined on label, but got %v", rows[0][mframe.ProvenanceJoinedOn])
+			}
+		})
+	}
+
+	anti := flows.JoinOn(&services, on, mframe.JoinAnti, mframe.JoinOptions{})
+	if anti.Count() != 2 {
+		t.Errorf("expected 2 unmatched rows, but got %d", anti.Count())
+	}
+}
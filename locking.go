@@ -0,0 +1,66 @@
+package mframe
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// frameSequence numbers the frames in the order they are first initialized, to order lock acquisition.
+var frameSequence atomic.Uint64
+
+// FrameLock requests a lock on a DataFrame for LockFrames, a write lock when Write is set
+// and a read lock otherwise.
+type FrameLock struct {
+	Frame *DataFrame
+	Write bool
+}
+
+// LockFrames acquires the locks of several frames at once and returns a function releasing them.
+// Locks are always taken in the same order, by the sequence number of the frames, so operations spanning several frames
+// cannot deadlock however they are combined concurrently. A frame requested more than once is locked
+// once, with a write lock if any of the requests asks for one. Nil frames are ignored.
+func LockFrames(locks ...FrameLock) func() {
+	write := make(map[*DataFrame]bool, len(locks))
+	for _, l := range locks {
+		if l.Frame == nil {
+			continue
+		}
+		write[l.Frame] = write[l.Frame] || l.Write
+	}
+
+	frames := make([]*DataFrame, 0, len(write))
+	for frame := range write {
+		frames = append(frames, frame)
+	}
+	sort.Slice(frames, func(i, j int) bool {
+		return frameOrder(frames[i]) < frameOrder(frames[j])
+	})
+
+	for _, frame := range frames {
+		if write[frame] {
+			frame.Locker.Lock()
+		} else {
+			frame.Locker.RLock()
+		}
+	}
+
+	return func() {
+		for i := len(frames) - 1; i >= 0; i-- {
+			if write[frames[i]] {
+				frames[i].Locker.Unlock()
+			} else {
+				frames[i].Locker.RUnlock()
+			}
+		}
+	}
+}
+
+// frameOrder returns the sequence number used to order lock acquisition. It is set by Init, or on first
+// use for frames that were never initialized, and never changes afterwards.
+func frameOrder(d *DataFrame) uint64 {
+	if seq := d.seq.Load(); seq != 0 {
+		return seq
+	}
+	d.seq.CompareAndSwap(0, frameSequence.Add(1))
+	return d.seq.Load()
+}
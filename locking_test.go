@@ -0,0 +1,64 @@
+package mframe_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestLockFrames(t *testing.T) {
+	var a, b, c mframe.DataFrame
+	a.Init(24 * time.Hour)
+	b.Init(24 * time.Hour)
+	c.Init(24 * time.Hour)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			unlock := mframe.LockFrames(mframe.FrameLock{Frame: &a, Write: true}, mframe.FrameLock{Frame: &b})
+			unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			unlock := mframe.LockFrames(mframe.FrameLock{Frame: &b, Write: true}, mframe.FrameLock{Frame: &c}, mframe.FrameLock{Frame: &a})
+			unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			unlock := mframe.LockFrames(mframe.FrameLock{Frame: &c, Write: true}, mframe.FrameLock{Frame: &a, Write: true})
+			unlock()
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("concurrent LockFrames calls did not finish")
+	}
+}
+
+func TestLockFramesSameFrame(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	unlock := mframe.LockFrames(mframe.FrameLock{Frame: &cache}, mframe.FrameLock{Frame: &cache, Write: true}, mframe.FrameLock{})
+	if cache.Locker.TryRLock() {
+		t.Error("expected the frame to be write-locked")
+	}
+	unlock()
+
+	if !cache.Locker.TryLock() {
+		t.Fatal("expected the frame to be unlocked")
+	}
+	cache.Locker.Unlock()
+}
@@ -1,9 +1,45 @@
 package mframe
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/montanaflynn/stats"
 )
 
+// ValueCounts holds the number of rows for each distinct value of a key.
+// Only the map matching Type is populated.
+type ValueCounts struct {
+	Type     KeyType
+	Strings  map[string]int
+	Numerics map[float64]int
+	Booleans map[bool]int
+	Times    map[time.Time]int
+}
+
+// CountOptions adjusts how CountUniqueWithOptions counts and returns the values of a field.
+type CountOptions struct {
+	// CaseInsensitive counts string values regardless of case, reporting them in lower case.
+	CaseInsensitive bool
+	// SortByCount orders the result by descending weight instead of by value.
+	SortByCount bool
+	// Limit keeps only the first Limit values of the result. Zero means no limit.
+	Limit int
+	// WeightKey, when set, adds the numeric value of this key for each row instead of 1.
+	// Rows without a numeric weight are ignored.
+	WeightKey KeyName
+}
+
+// ValueCount holds the number of rows and the total weight of a distinct value.
+type ValueCount struct {
+	Value  interface{}
+	Count  int
+	Weight float64
+}
+
 // Count returns the number of elements in the DataFrame.
 func (d *DataFrame) Count() int {
 	d.Locker.RLock()
@@ -17,16 +53,116 @@ func (d *DataFrame) CountUnique(field KeyName) map[interface{}]int {
 	defer d.Locker.RUnlock()
 	var count = make(map[interface{}]int)
 	for _, v := range d.Data {
-		if _, ok := count[v[field]]; !ok {
-			count[v[field]] = 0
-		}
-		count[v[field]] += 1
+		value, _ := d.fieldUnlocked(v, field)
+		count[value] += 1
 
 	}
 
 	return count
 }
 
+// CountUniqueWithOptions counts the occurrences of unique values in the specified field according to options
+// and returns them as an ordered slice. Rows without the field are ignored.
+func (d *DataFrame) CountUniqueWithOptions(field KeyName, options CountOptions) []ValueCount {
+	d.Locker.RLock()
+	counts := make(map[interface{}]*ValueCount)
+	for _, row := range d.Data {
+		value, ok := d.fieldUnlocked(row, field)
+		if !ok {
+			continue
+		}
+
+		weight := 1.0
+		if options.WeightKey != "" {
+			raw, _ := d.fieldUnlocked(row, options.WeightKey)
+			w, ok := raw.(float64)
+			if !ok {
+				continue
+			}
+			weight = w
+		}
+
+		if s, ok := value.(string); ok && options.CaseInsensitive {
+			value = strings.ToLower(s)
+		}
+
+		count, ok := counts[value]
+		if !ok {
+			count = &ValueCount{Value: value}
+			counts[value] = count
+		}
+		count.Count++
+		count.Weight += weight
+	}
+	d.Locker.RUnlock()
+
+	result := make([]ValueCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if options.SortByCount && result[i].Weight != result[j].Weight {
+			return result[i].Weight > result[j].Weight
+		}
+		return valueLess(result[i].Value, result[j].Value)
+	})
+
+	if options.Limit > 0 && len(result) > options.Limit {
+		result = result[:options.Limit]
+	}
+
+	return result
+}
+
+// CountWhere returns the number of rows matching the filter, using the same arguments as Filter,
+// without building a result DataFrame.
+func (d *DataFrame) CountWhere(operator Operator, key KeyName, value any, options map[FilterOption]bool) int {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return len(d.idsUnlocked(Condition{Operator: operator, Key: key, Value: value, Options: options}))
+}
+
+// FilterCount returns the number of rows matching the filter like CountWhere. It is the counting
+// counterpart of FilterIDs.
+func (d *DataFrame) FilterCount(operator Operator, key KeyName, value any, options map[FilterOption]bool) int {
+	return d.CountWhere(operator, key, value, options)
+}
+
+// CountBy counts the rows for each distinct value of the specified key, reading the counts
+// directly from the key's index. It is a typed replacement for CountUnique.
+func (d *DataFrame) CountBy(key KeyName) ValueCounts {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	result := ValueCounts{Type: d.Keys[key]}
+
+	switch result.Type {
+	case String:
+		result.Strings = make(map[string]int, len(d.Strings[key]))
+		for value, ids := range d.Strings[key] {
+			result.Strings[value] = len(ids)
+		}
+	case Numeric:
+		result.Numerics = make(map[float64]int, len(d.Numerics[key]))
+		for value, ids := range d.Numerics[key] {
+			result.Numerics[value] = len(ids)
+		}
+	case Boolean:
+		result.Booleans = make(map[bool]int, len(d.Booleans[key]))
+		for value, ids := range d.Booleans[key] {
+			result.Booleans[value] = len(ids)
+		}
+	case Time:
+		result.Times = make(map[time.Time]int, len(d.Times[key]))
+		for value, ids := range d.Times[key] {
+			result.Times[value] = len(ids)
+		}
+	}
+
+	return result
+}
+
 // Sum calculates the sum of all float64 values in the specified field of the DataFrame and returns the result.
 func (d *DataFrame) Sum(field KeyName) (float64, error) {
 	d.Locker.RLock()
@@ -129,3 +265,191 @@ func (d *DataFrame) HarmonicMean(field KeyName) (float64, error) {
 	defer d.Locker.RUnlock()
 	return stats.HarmonicMean(d.sliceOfFloat64Unlocked(field))
 }
+
+// Correlation calculates the Pearson correlation between two fields, pairing their values per row.
+// Rows missing either field are ignored.
+func (d *DataFrame) Correlation(fieldA, fieldB KeyName) (float64, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return stats.Correlation(d.pairsOfFloat64Unlocked(fieldA, fieldB))
+}
+
+// Covariance calculates the sample covariance between two fields, pairing their values per row.
+// Rows missing either field are ignored.
+func (d *DataFrame) Covariance(fieldA, fieldB KeyName) (float64, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return stats.Covariance(d.pairsOfFloat64Unlocked(fieldA, fieldB))
+}
+
+// WeightedAverage calculates the average of valueField weighted by weightField, pairing both per row.
+// Rows missing either field or with a negative weight are ignored.
+func (d *DataFrame) WeightedAverage(valueField, weightField KeyName) (float64, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	values, weights := d.pairsOfFloat64Unlocked(valueField, weightField)
+
+	var sum, totalWeight float64
+	for i, v := range values {
+		if weights[i] < 0 {
+			continue
+		}
+		sum += v * weights[i]
+		totalWeight += weights[i]
+	}
+
+	if totalWeight == 0 {
+		return 0, stats.EmptyInputErr
+	}
+
+	return sum / totalWeight, nil
+}
+
+// WeightedPercentile calculates the percentile (0-100] of valueField where each row counts
+// as many times as its weightField, pairing both per row.
+// Rows missing either field or with a negative weight are ignored.
+func (d *DataFrame) WeightedPercentile(valueField, weightField KeyName, percent float64) (float64, error) {
+	if percent <= 0 || percent > 100 {
+		return 0, stats.BoundsErr
+	}
+
+	d.Locker.RLock()
+	values, weights := d.pairsOfFloat64Unlocked(valueField, weightField)
+	d.Locker.RUnlock()
+
+	type pair struct{ value, weight float64 }
+	pairs := make([]pair, 0, len(values))
+	var totalWeight float64
+	for i, v := range values {
+		if weights[i] <= 0 {
+			continue
+		}
+		pairs = append(pairs, pair{v, weights[i]})
+		totalWeight += weights[i]
+	}
+
+	if totalWeight == 0 {
+		return 0, stats.EmptyInputErr
+	}
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].value < pairs[j].value })
+
+	target := totalWeight * percent / 100
+	var cumulative float64
+	for _, p := range pairs {
+		cumulative += p.weight
+		if cumulative >= target {
+			return p.value, nil
+		}
+	}
+
+	return pairs[len(pairs)-1].value, nil
+}
+
+// Skewness calculates the population skewness of the values in the specified field.
+// Positive values indicate a longer tail towards high values.
+func (d *DataFrame) Skewness(field KeyName) (float64, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	data := d.sliceOfFloat64Unlocked(field)
+	if len(data) == 0 {
+		return 0, stats.EmptyInputErr
+	}
+	return computeMoments(data).skewness(), nil
+}
+
+// Kurtosis calculates the population excess kurtosis of the values in the specified field.
+// A normal distribution has an excess kurtosis of 0.
+func (d *DataFrame) Kurtosis(field KeyName) (float64, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	data := d.sliceOfFloat64Unlocked(field)
+	if len(data) == 0 {
+		return 0, stats.EmptyInputErr
+	}
+	return computeMoments(data).kurtosis(), nil
+}
+
+// Coverage reports how many rows took part in an aggregation and why the others were skipped.
+type Coverage struct {
+	Used       int // Rows with a numeric value for the field
+	Missing    int // Rows without the field
+	NonNumeric int // Rows where the field holds a non-numeric value, or held one dropped for not fitting the type of the key
+}
+
+// Skipped returns the number of rows that did not take part in the aggregation.
+func (c Coverage) Skipped() int {
+	return c.Missing + c.NonNumeric
+}
+
+// markMismatchUnlocked records that the value of the key was dropped from the row because it did not fit
+// the type of the key, so Coverage counts the row as non-numeric rather than missing, without acquiring locks.
+func (d *DataFrame) markMismatchUnlocked(key KeyName, id uuid.UUID) {
+	if d.mismatched == nil {
+		d.mismatched = make(map[KeyName]map[uuid.UUID]bool)
+	}
+	if d.mismatched[key] == nil {
+		d.mismatched[key] = make(map[uuid.UUID]bool)
+	}
+	d.mismatched[key][id] = true
+}
+
+// forgetMismatchUnlocked forgets the dropped value of the key of a row, without acquiring locks.
+func (d *DataFrame) forgetMismatchUnlocked(key KeyName, id uuid.UUID) {
+	delete(d.mismatched[key], id)
+	if len(d.mismatched[key]) == 0 {
+		delete(d.mismatched, key)
+	}
+}
+
+// forgetMismatchesUnlocked forgets the dropped values of every key of a row, without acquiring locks.
+func (d *DataFrame) forgetMismatchesUnlocked(id uuid.UUID) {
+	for key := range d.mismatched {
+		d.forgetMismatchUnlocked(key, id)
+	}
+}
+
+// Coverage reports how many rows hold a numeric value for the specified field and how many would
+// be skipped by the numeric aggregations.
+func (d *DataFrame) Coverage(field KeyName) Coverage {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	_, coverage := d.sliceOfFloat64WithCoverageUnlocked(field)
+	return coverage
+}
+
+// SumWithCoverage calculates the sum of the specified field like Sum, also reporting the rows skipped.
+func (d *DataFrame) SumWithCoverage(field KeyName) (float64, Coverage, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	data, coverage := d.sliceOfFloat64WithCoverageUnlocked(field)
+	sum, err := stats.Sum(data)
+	return sum, coverage, err
+}
+
+// AverageWithCoverage calculates the mean of the specified field like Average, also reporting the rows skipped.
+func (d *DataFrame) AverageWithCoverage(field KeyName) (float64, Coverage, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	data, coverage := d.sliceOfFloat64WithCoverageUnlocked(field)
+	mean, err := stats.Mean(data)
+	return mean, coverage, err
+}
+
+// AggregateFieldWithCoverage applies a registered aggregation like AggregateField, also reporting
+// how many rows hold a numeric value for the field and how many do not.
+func (d *DataFrame) AggregateFieldWithCoverage(name string, field KeyName) (float64, Coverage, error) {
+	fn, ok := LookupAggregate(name)
+	if !ok {
+		return 0, Coverage{}, fmt.Errorf("unknown aggregate '%s'", name)
+	}
+
+	d.Locker.RLock()
+	values := d.sliceOfUnlocked(field)
+	_, coverage := d.sliceOfFloat64WithCoverageUnlocked(field)
+	d.Locker.RUnlock()
+
+	result, err := fn(values)
+	return result, coverage, err
+}
@@ -1,6 +1,7 @@
 package mframe_test
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -72,6 +73,98 @@ func TestCountUnique(t *testing.T) {
 	}
 }
 
+func TestCountUniqueWithOptions(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"user": "Alice", "bytes": 10.0},
+		{"user": "alice", "bytes": 20.0},
+		{"user": "bob", "bytes": 500.0},
+		{"user": "carol", "bytes": 5.0},
+		{"user": "ALICE"},
+		{"bytes": 1.0},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	tests := []struct {
+		name     string
+		options  mframe.CountOptions
+		expected []mframe.ValueCount
+	}{
+		{
+			name:    "by value",
+			options: mframe.CountOptions{},
+			expected: []mframe.ValueCount{
+				{Value: "ALICE", Count: 1, Weight: 1},
+				{Value: "Alice", Count: 1, Weight: 1},
+				{Value: "alice", Count: 1, Weight: 1},
+				{Value: "bob", Count: 1, Weight: 1},
+				{Value: "carol", Count: 1, Weight: 1},
+			},
+		},
+		{
+			name:    "case insensitive sorted and limited",
+			options: mframe.CountOptions{CaseInsensitive: true, SortByCount: true, Limit: 2},
+			expected: []mframe.ValueCount{
+				{Value: "alice", Count: 3, Weight: 3},
+				{Value: "bob", Count: 1, Weight: 1},
+			},
+		},
+		{
+			name:    "weighted",
+			options: mframe.CountOptions{CaseInsensitive: true, SortByCount: true, WeightKey: "bytes"},
+			expected: []mframe.ValueCount{
+				{Value: "bob", Count: 1, Weight: 500},
+				{Value: "alice", Count: 2, Weight: 30},
+				{Value: "carol", Count: 1, Weight: 5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := cache.CountUniqueWithOptions("user", tt.options)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %v, but got %v", tt.expected, result)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("expected %v at %d, but got %v", tt.expected[i], i, result[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCountUniqueWithOptionsOrdersTypedValues(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, v := range []float64{10, 9, 100} {
+		cache.Insert(map[mframe.KeyName]interface{}{"port": v})
+	}
+	// The later time, in another zone, formats before the earlier one as a string.
+	later := time.Date(2024, 1, 1, 8, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	for _, at := range []time.Time{base.Add(10 * time.Hour), later} {
+		cache.Insert(map[mframe.KeyName]interface{}{"seen": at})
+	}
+
+	ports := cache.CountUniqueWithOptions("port", mframe.CountOptions{SortByCount: true})
+	if len(ports) != 3 || ports[0].Value != 9.0 || ports[1].Value != 10.0 || ports[2].Value != 100.0 {
+		t.Errorf("expected ports in numeric order, but got %v", ports)
+	}
+
+	seen := cache.CountUniqueWithOptions("seen", mframe.CountOptions{})
+	if len(seen) != 2 || !seen[0].Value.(time.Time).Before(seen[1].Value.(time.Time)) {
+		t.Errorf("expected times in chronological order, but got %v", seen)
+	}
+}
+
 func TestSum(t *testing.T) {
 	var cache mframe.DataFrame
 	cache.Init(24 * time.Hour)
@@ -396,3 +489,256 @@ func TestHarmonicMean(t *testing.T) {
 		t.Errorf("Expected harmonic mean ~%v, but got %v", expected, result)
 	}
 }
+
+func TestCountWhere(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"id": 1, "name": "John", "value": 1.0},
+		{"id": 2, "name": "Jane", "value": 2.0},
+		{"id": 3, "name": "john", "value": 3.0},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	if result := cache.CountWhere(mframe.Greater, "value", 1.0, nil); result != 2 {
+		t.Errorf("expected 2, but got %d", result)
+	}
+	if result := cache.CountWhere(mframe.Equals, "name", "JOHN", map[mframe.FilterOption]bool{mframe.CaseSensitive: false}); result != 2 {
+		t.Errorf("expected 2, but got %d", result)
+	}
+	if result := cache.CountWhere(mframe.Equals, "missing", "x", nil); result != 0 {
+		t.Errorf("expected 0, but got %d", result)
+	}
+	if cache.Count() != 3 {
+		t.Errorf("CountWhere should not modify the DataFrame")
+	}
+}
+
+func TestCountBy(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"name": "John", "value": 1.0, "active": true},
+		{"name": "Jane", "value": 1.0, "active": false},
+		{"name": "John", "value": 2.0, "active": true},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	names := cache.CountBy("name")
+	if names.Type != mframe.String || names.Strings["John"] != 2 || names.Strings["Jane"] != 1 {
+		t.Errorf("unexpected string counts: %+v", names)
+	}
+
+	values := cache.CountBy("value")
+	if values.Type != mframe.Numeric || values.Numerics[1.0] != 2 || values.Numerics[2.0] != 1 {
+		t.Errorf("unexpected numeric counts: %+v", values)
+	}
+
+	active := cache.CountBy("active")
+	if active.Type != mframe.Boolean || active.Booleans[true] != 2 || active.Booleans[false] != 1 {
+		t.Errorf("unexpected boolean counts: %+v", active)
+	}
+
+	if missing := cache.CountBy("missing"); missing.Strings != nil || missing.Numerics != nil {
+		t.Errorf("expected no counts for a missing key, but got %+v", missing)
+	}
+}
+
+func TestCorrelationAndCovariance(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"requests": 1.0, "errors": 2.0, "latency": 10.0},
+		{"requests": 2.0, "errors": 4.0, "latency": 8.0},
+		{"requests": 3.0, "errors": 6.0, "latency": 6.0},
+		{"requests": 4.0, "errors": 8.0, "latency": 4.0},
+		{"requests": 5.0},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	correlation, err := cache.Correlation("requests", "errors")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(correlation-1) > 1e-9 {
+		t.Errorf("expected correlation 1, but got %v", correlation)
+	}
+
+	correlation, err = cache.Correlation("requests", "latency")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(correlation+1) > 1e-9 {
+		t.Errorf("expected correlation -1, but got %v", correlation)
+	}
+
+	covariance, err := cache.Covariance("requests", "errors")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(covariance-10.0/3.0) > 1e-9 {
+		t.Errorf("expected covariance %v, but got %v", 10.0/3.0, covariance)
+	}
+
+	if _, err := cache.Correlation("requests", "missing"); err == nil {
+		t.Error("expected error for a missing field")
+	}
+}
+
+func TestWeightedAggregations(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"latency": 10.0, "requests": 1.0},
+		{"latency": 20.0, "requests": 1.0},
+		{"latency": 100.0, "requests": 8.0},
+		{"latency": 1000.0},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	average, err := cache.WeightedAverage("latency", "requests")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(average-83) > 1e-9 {
+		t.Errorf("expected weighted average 83, but got %v", average)
+	}
+
+	tests := []struct {
+		percent  float64
+		expected float64
+	}{
+		{10, 10},
+		{20, 20},
+		{50, 100},
+		{100, 100},
+	}
+
+	for _, tt := range tests {
+		result, err := cache.WeightedPercentile("latency", "requests", tt.percent)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("expected weighted percentile %v to be %v, but got %v", tt.percent, tt.expected, result)
+		}
+	}
+
+	if _, err := cache.WeightedPercentile("latency", "requests", 0); err == nil {
+		t.Error("expected error for an out of range percent")
+	}
+	if _, err := cache.WeightedAverage("latency", "missing"); err == nil {
+		t.Error("expected error for a missing weight field")
+	}
+}
+
+func TestAggregationsWithCoverage(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"value": 1.0},
+		{"value": 3.0},
+		{"value": "n/a", "name": "a"},
+		{"other": 1.0, "name": "b"},
+		{"other": 2.0},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	// The string value conflicts with the numeric mapping of the key, so it is not stored, but the row is
+	// still counted as non-numeric.
+	expected := mframe.Coverage{Used: 2, Missing: 2, NonNumeric: 1}
+
+	sum, coverage, err := cache.SumWithCoverage("value")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum != 4 {
+		t.Errorf("expected sum 4, but got %v", sum)
+	}
+	if coverage != expected {
+		t.Errorf("expected coverage %+v, but got %+v", expected, coverage)
+	}
+	if coverage.Skipped() != 3 {
+		t.Errorf("expected 3 skipped rows, but got %d", coverage.Skipped())
+	}
+
+	average, coverage, err := cache.AverageWithCoverage("value")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if average != 2 || coverage != expected {
+		t.Errorf("expected average 2 with coverage %+v, but got %v with %+v", expected, average, coverage)
+	}
+
+	maxValue, coverage, err := cache.AggregateFieldWithCoverage("max", "value")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxValue != 3 || coverage != expected {
+		t.Errorf("expected max 3 with coverage %+v, but got %v with %+v", expected, maxValue, coverage)
+	}
+
+	if _, _, err := cache.AggregateFieldWithCoverage("nonexistent", "value"); err == nil {
+		t.Error("expected error for an unknown aggregate")
+	}
+
+	if coverage := cache.Coverage("missing"); coverage.Missing != 5 || coverage.Used != 0 {
+		t.Errorf("expected every row to miss the field, but got %+v", coverage)
+	}
+
+	if coverage := cache.Coverage("name"); coverage.NonNumeric != 2 || coverage.Missing != 3 {
+		t.Errorf("expected 2 non-numeric and 3 missing rows, but got %+v", coverage)
+	}
+}
+
+func TestCoverageWithMixedTypes(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"bytes": 10})
+	text, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"bytes": "n/a"})
+	flag, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"bytes": true})
+	cache.Insert(map[mframe.KeyName]interface{}{"bytes": time.Now()})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+
+	expected := mframe.Coverage{Used: 1, Missing: 1, NonNumeric: 3}
+	if coverage := cache.Coverage("bytes"); coverage != expected {
+		t.Errorf("expected coverage %+v, but got %+v", expected, coverage)
+	}
+
+	// Fixing or removing the rows forgets their dropped values.
+	cache.RemoveElement(flag)
+	if _, err := cache.UpdateWhere(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "bytes", Value: 10.0},
+	}}, map[mframe.KeyName]interface{}{"bytes": 20}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cache.UpdateByID(text, map[mframe.KeyName]interface{}{"bytes": 30}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = mframe.Coverage{Used: 2, Missing: 1, NonNumeric: 1}
+	if coverage := cache.Coverage("bytes"); coverage != expected {
+		t.Errorf("expected coverage %+v after the fixes, but got %+v", expected, coverage)
+	}
+}
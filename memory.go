@@ -0,0 +1,77 @@
+package mframe
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ErrMemoryLimit is returned when an insert would exceed the memory limit of a DataFrame using MemoryReject.
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// MemoryPolicy selects what happens when an insert exceeds the memory limit.
+type MemoryPolicy int
+
+const (
+	// MemoryEvict inserts the rows and then removes the rows expiring first until the DataFrame fits the limit.
+	MemoryEvict MemoryPolicy = 1
+	// MemoryReject refuses the rows with ErrMemoryLimit, leaving the DataFrame unchanged.
+	MemoryReject MemoryPolicy = 2
+)
+
+// SetMemoryLimit limits the memory used by the rows of the DataFrame, in bytes, estimated like the
+// MemoryBytes of StatsSnapshot: the number of rows times the average size of a sample of them. Inserts
+// beyond the limit evict rows or fail according to policy, so a misbehaving feed fills the budget instead
+// of the process memory. Evicted rows are reported to the evict hooks with EvictCapacity. Insert cannot
+// return errors and logs them, so callers of MemoryReject should use InsertWithError or InsertBatch.
+// Zero disables the limit. Rows above a new limit are only evicted by the next insert.
+func (d *DataFrame) SetMemoryLimit(bytes int, policy MemoryPolicy) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.memoryLimit = max(bytes, 0)
+	d.memoryPolicy = policy
+}
+
+// checkMemoryUnlocked returns an error wrapping ErrMemoryLimit if inserting the entries would exceed the
+// memory limit of a DataFrame using MemoryReject, without acquiring locks.
+func (d *DataFrame) checkMemoryUnlocked(entries map[uuid.UUID]map[KeyName]interface{}) error {
+	if d.memoryLimit == 0 || d.memoryPolicy != MemoryReject {
+		return nil
+	}
+
+	needed := d.averageRowSizeUnlocked() * len(d.Data)
+	for _, data := range entries {
+		values := make(map[KeyName]interface{}, len(data))
+		flattenValues(data, "", values)
+		needed += estimateRowSize(Row(values))
+	}
+
+	if needed > d.memoryLimit {
+		return fmt.Errorf("%w: inserting %d rows needs about %d bytes of %d", ErrMemoryLimit, len(entries), needed, d.memoryLimit)
+	}
+
+	return nil
+}
+
+// evictMemoryUnlocked removes the rows expiring first, never keep, until the estimated memory of the
+// DataFrame fits the limit of a DataFrame using MemoryEvict, without acquiring locks.
+func (d *DataFrame) evictMemoryUnlocked(keep uuid.UUID) {
+	if d.memoryLimit == 0 || d.memoryPolicy != MemoryEvict {
+		return
+	}
+
+	size := d.averageRowSizeUnlocked()
+	if size == 0 {
+		return
+	}
+
+	for size*len(d.Data) > d.memoryLimit {
+		victim, ok := d.firstExpiringUnlocked(keep)
+		if !ok {
+			return
+		}
+		d.evictElementUnlocked(victim, EvictCapacity)
+	}
+}
@@ -0,0 +1,70 @@
+package mframe_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSetMemoryLimitReject(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetMemoryLimit(1024, mframe.MemoryReject)
+
+	var err error
+	inserted := 0
+	for i := 0; i < 100 && err == nil; i++ {
+		if err = cache.InsertWithError(map[mframe.KeyName]interface{}{"host": fmt.Sprintf("web-%d", i)}); err == nil {
+			inserted++
+		}
+	}
+
+	if !errors.Is(err, mframe.ErrMemoryLimit) {
+		t.Fatalf("expected ErrMemoryLimit, but got %v", err)
+	}
+	if inserted == 0 || cache.Count() != inserted {
+		t.Errorf("expected the %d rows inserted before the limit, but got %d", inserted, cache.Count())
+	}
+	if stats := cache.StatsSnapshot(); stats.MemoryBytes > 1024 {
+		t.Errorf("expected at most 1024 bytes, but got %d", stats.MemoryBytes)
+	}
+
+	if err := cache.InsertBatch([]map[mframe.KeyName]interface{}{{"host": "a"}, {"host": "b"}}); !errors.Is(err, mframe.ErrMemoryLimit) {
+		t.Errorf("expected ErrMemoryLimit for a batch, but got %v", err)
+	}
+}
+
+func TestSetMemoryLimitEvict(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetMemoryLimit(1024, mframe.MemoryEvict)
+
+	var evicted []mframe.EvictReason
+	cache.OnEvict(func(_ uuid.UUID, _ mframe.Row, reason mframe.EvictReason) {
+		evicted = append(evicted, reason)
+	})
+
+	for i := 0; i < 100; i++ {
+		if err := cache.InsertWithError(map[mframe.KeyName]interface{}{"seq": i}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if stats := cache.StatsSnapshot(); stats.MemoryBytes > 1024 {
+		t.Errorf("expected at most 1024 bytes, but got %d", stats.MemoryBytes)
+	}
+	if len(evicted) != 100-cache.Count() || evicted[0] != mframe.EvictCapacity {
+		t.Errorf("expected %d capacity evictions, but got %v", 100-cache.Count(), evicted)
+	}
+	if cache.Filter(mframe.Equals, "seq", 99.0, nil).Count() != 1 {
+		t.Errorf("expected the newest row to be kept")
+	}
+	if cache.Filter(mframe.Equals, "seq", 0.0, nil).Count() != 0 {
+		t.Errorf("expected the oldest row to be evicted")
+	}
+}
@@ -0,0 +1,121 @@
+package mframe
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Merge upserts the rows of other into the DataFrame using onKey as the identity of a row.
+// Rows of the DataFrame with the same value for onKey as a row of other are updated in place with the keys
+// of that row, keeping their IDs, expiration and insertion order; rows of other without a match are inserted
+// as new rows, in the insertion order of other. Unmatched rows of other sharing a value for onKey are inserted
+// as a single row, the keys of later rows overwriting those of earlier ones, as they do for matched rows.
+// Rows of other without onKey are ignored. Returns the number of updated and inserted rows.
+// Unless provenance is disabled with SetProvenance, updated rows record the name of other and the ID of the
+// matched row under ProvenanceSourceFrame and ProvenanceMatchedID, and inserted rows record them under
+// ProvenanceSourceFrame and ProvenanceSourceID, as copies made by AppendWithOptions do.
+// The merge runs under the write lock of the DataFrame and the read lock of other, so concurrent changes are
+// never lost. Updated rows are published as updates; before-insert hooks, which may call back into the
+// DataFrame, are not run, and after-insert hooks run for the inserted rows once the locks are released.
+// If a row breaks the schema, nothing is merged.
+func (d *DataFrame) Merge(other *DataFrame, onKey KeyName) (updated int, inserted int) {
+	LockFrames(FrameLock{Frame: d, Write: true}, FrameLock{Frame: other})
+	unlock := func() {
+		if other != d {
+			other.Locker.RUnlock()
+		}
+		d.unlockAndNotify()
+	}
+
+	newID := d.idGeneratorUnlocked()
+	updates := make(map[uuid.UUID]Row)
+	var entries []batchEntry
+	pending := make(map[interface{}]int)
+	var order []uuid.UUID
+	for _, sourceID := range other.arrivalOrderUnlocked() {
+		source := other.Data[sourceID]
+		value, ok := source[onKey]
+		if !ok {
+			continue
+		}
+
+		matches := d.idsForValueUnlocked(onKey, value)
+		if len(matches) == 0 {
+			row := copyRow(source)
+			if !d.noProvenance {
+				if other.name != "" {
+					row[ProvenanceSourceFrame] = other.name
+				}
+				row[ProvenanceSourceID] = sourceID.String()
+			}
+			if i, ok := pending[value]; ok {
+				for k, v := range row {
+					entries[i].data[k] = v
+				}
+				continue
+			}
+			pending[value] = len(entries)
+			entries = append(entries, batchEntry{id: newID(), data: row})
+			continue
+		}
+
+		for id := range matches {
+			fields, exists := updates[id]
+			if !exists {
+				fields = make(Row, len(source))
+				order = append(order, id)
+			}
+			for k, v := range source {
+				fields[k] = v
+			}
+			if !d.noProvenance {
+				if other.name != "" {
+					fields[ProvenanceSourceFrame] = other.name
+				}
+				fields[ProvenanceMatchedID] = sourceID.String()
+			}
+			updates[id] = fields
+		}
+	}
+
+	checked := make(map[uuid.UUID]map[KeyName]interface{}, len(entries)+len(updates))
+	for _, entry := range entries {
+		checked[entry.id] = entry.data
+	}
+	for id, fields := range updates {
+		row := copyRow(d.Data[id])
+		for k, v := range fields {
+			row[k] = v
+		}
+		checked[id] = row
+	}
+	if err := d.validateUnlocked(checked); err != nil {
+		unlock()
+		log.Printf("error merging rows: %s", err.Error())
+		return 0, 0
+	}
+
+	sortIDs(order)
+	for _, id := range order {
+		d.updateFieldsUnlocked(id, updates[id], nil)
+	}
+
+	var rows map[uuid.UUID]Row
+	if d.hooks.hasAfterInsert() {
+		rows = make(map[uuid.UUID]Row, len(entries))
+	}
+	for _, entry := range entries {
+		d.insertWithIDUnlocked(entry.id, entry.data)
+		if rows != nil {
+			rows[entry.id] = copyRow(d.Data[entry.id])
+		}
+	}
+	unlock()
+
+	for id, row := range rows {
+		d.runAfterInsert(id, row)
+	}
+
+	return len(updates), len(entries)
+}
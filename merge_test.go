@@ -0,0 +1,189 @@
+package mframe_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestMerge(t *testing.T) {
+	var assets mframe.DataFrame
+	assets.Init(time.Hour)
+	assets.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "owner": "alice", "criticality": "low"},
+		{"ip": "10.0.0.2", "owner": "bob", "criticality": "low"},
+	})
+
+	expireAt := make(map[string]time.Time)
+	for id, row := range assets.Data {
+		expireAt[row["ip"].(string)] = assets.ExpireAt[id]
+	}
+
+	var updates mframe.DataFrame
+	updates.Init(24 * time.Hour)
+	updates.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "criticality": "high"},
+		{"ip": "10.0.0.3", "owner": "carol", "criticality": "medium"},
+		{"owner": "nobody"},
+	})
+
+	updated, inserted := assets.Merge(&updates, "ip")
+	if updated != 1 || inserted != 1 {
+		t.Fatalf("expected 1 updated and 1 inserted row, but got %d and %d", updated, inserted)
+	}
+	if assets.Count() != 3 {
+		t.Fatalf("expected 3 rows, but got %d", assets.Count())
+	}
+
+	rows := assets.Filter(mframe.Equals, "ip", "10.0.0.1", nil).ToSlice()
+	if len(rows) != 1 || rows[0]["criticality"] != "high" || rows[0]["owner"] != "alice" {
+		t.Errorf("expected the matching row to be updated, but got %v", rows)
+	}
+	if assets.CountWhere(mframe.Equals, "criticality", "low", nil) != 1 {
+		t.Error("expected the old value to be removed from the index")
+	}
+
+	for id, row := range assets.Data {
+		ip := row["ip"].(string)
+		if previous, ok := expireAt[ip]; ok && !assets.ExpireAt[id].Equal(previous) {
+			t.Errorf("expected %s to keep its expiration %v, but got %v", ip, previous, assets.ExpireAt[id])
+		}
+	}
+
+	if assets.CountWhere(mframe.Equals, "owner", "nobody", nil) != 0 {
+		t.Error("expected rows without the merge key to be ignored")
+	}
+}
+
+func TestMergeUpdatesInPlace(t *testing.T) {
+	var assets mframe.DataFrame
+	assets.Init(time.Hour)
+	assets.SetInsertionOrder(true)
+	first, _ := assets.InsertReturningID(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "owner": "alice"})
+	assets.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.2", "owner": "bob"})
+
+	inserts := 0
+	assets.OnAfterInsert(func(id uuid.UUID, row mframe.Row) {
+		inserts++
+	})
+	events := assets.Subscribe(mframe.FilterSpec{})
+	defer assets.Unsubscribe(events)
+
+	var updates mframe.DataFrame
+	updates.Init(time.Hour)
+	updates.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "owner": "carol"})
+
+	if updated, inserted := assets.Merge(&updates, "ip"); updated != 1 || inserted != 0 {
+		t.Fatalf("expected 1 updated and 0 inserted rows, but got %d and %d", updated, inserted)
+	}
+
+	event := receive(t, events)
+	if event.Type != mframe.ChangeUpdate || event.ID != first || event.Row["owner"] != "carol" {
+		t.Errorf("expected an update event of the merged row, but got %+v", event)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected a single event, but got %d more", len(events))
+	}
+	if inserts != 0 {
+		t.Errorf("expected no after-insert hook for updated rows, but got %d calls", inserts)
+	}
+
+	oldest := assets.Oldest(1)
+	if len(oldest) != 1 || oldest[0]["owner"] != "carol" {
+		t.Errorf("expected the merged row to keep its insertion order, but got %v", oldest)
+	}
+}
+
+func TestMergeDeduplicatesInserts(t *testing.T) {
+	var assets mframe.DataFrame
+	assets.Init(time.Hour)
+	assets.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "owner": "alice"})
+
+	var updates mframe.DataFrame
+	updates.Init(time.Hour)
+	updates.SetInsertionOrder(true)
+	updates.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.2", "owner": "bob", "site": "hq"})
+	updates.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.3", "owner": "dave"})
+	updates.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.2", "owner": "carol"})
+
+	updated, inserted := assets.Merge(&updates, "ip")
+	if updated != 0 || inserted != 2 {
+		t.Fatalf("expected 0 updated and 2 inserted rows, but got %d and %d", updated, inserted)
+	}
+
+	rows := assets.Filter(mframe.Equals, "ip", "10.0.0.2", nil).ToSlice()
+	if len(rows) != 1 {
+		t.Fatalf("expected a single row for the duplicated key, but got %v", rows)
+	}
+	if rows[0]["owner"] != "carol" || rows[0]["site"] != "hq" {
+		t.Errorf("expected the last row to win over the earlier keys, but got %v", rows[0])
+	}
+}
+
+func TestMergeProvenance(t *testing.T) {
+	var assets mframe.DataFrame
+	assets.Init(time.Hour)
+	assets.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "owner": "alice"})
+
+	var updates mframe.DataFrame
+	updates.Init(time.Hour)
+	updates.SetName("cmdb")
+	matched, _ := updates.InsertReturningID(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "owner": "bob"})
+	copied, _ := updates.InsertReturningID(map[mframe.KeyName]interface{}{"ip": "10.0.0.2", "owner": "carol"})
+
+	assets.Merge(&updates, "ip")
+
+	rows := assets.Filter(mframe.Equals, "ip", "10.0.0.1", nil).ToSlice()
+	if len(rows) != 1 || rows[0][mframe.ProvenanceSourceFrame] != "cmdb" ||
+		rows[0][mframe.ProvenanceMatchedID] != matched.String() {
+		t.Errorf("expected the updated row to record its source, but got %v", rows)
+	}
+	rows = assets.Filter(mframe.Equals, "ip", "10.0.0.2", nil).ToSlice()
+	if len(rows) != 1 || rows[0][mframe.ProvenanceSourceFrame] != "cmdb" ||
+		rows[0][mframe.ProvenanceSourceID] != copied.String() {
+		t.Errorf("expected the inserted row to record its source, but got %v", rows)
+	}
+
+	var plain mframe.DataFrame
+	plain.Init(time.Hour)
+	plain.SetProvenance(false)
+	plain.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "owner": "alice"})
+	plain.Merge(&updates, "ip")
+	if plain.CountWhere(mframe.Equals, mframe.ProvenanceSourceFrame, "cmdb", nil) != 0 {
+		t.Error("expected no provenance when disabled")
+	}
+}
+
+func TestMergeConcurrent(t *testing.T) {
+	var counters mframe.DataFrame
+	counters.Init(time.Hour)
+	counters.Insert(map[mframe.KeyName]interface{}{"name": "a", "hits": 0.0})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var update mframe.DataFrame
+			update.Init(time.Hour)
+			update.Insert(map[mframe.KeyName]interface{}{"name": "a", mframe.KeyName(fmt.Sprintf("worker-%d", i)): true})
+			counters.Merge(&update, "name")
+		}(i)
+	}
+	wg.Wait()
+
+	rows := counters.Rows()
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, but got %d", len(rows))
+	}
+	for i := 0; i < 20; i++ {
+		if rows[0][mframe.KeyName(fmt.Sprintf("worker-%d", i))] != true {
+			t.Errorf("expected the update of worker %d to be kept", i)
+		}
+	}
+}
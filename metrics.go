@@ -0,0 +1,289 @@
+package mframe
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the buckets of the filter latency histogram.
+var LatencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// insertRateWindow is the number of seconds the insert rate of Metrics is averaged over.
+const insertRateWindow = 60
+
+// LatencyHistogram is a histogram of durations in seconds.
+type LatencyHistogram struct {
+	// Buckets holds the upper bounds of the buckets and Counts the number of observations lower than or equal
+	// to each bound, so the counts are cumulative as in Prometheus histograms.
+	Buckets []float64
+	Counts  []uint64
+	Count   uint64
+	Sum     float64
+}
+
+// Metrics is a structured report of the state and activity of a DataFrame, returned by Metrics.
+type Metrics struct {
+	Rows int
+	// Cardinality holds the number of distinct values of every key.
+	Cardinality map[KeyName]int
+	// Inserted, Expired and Removed count the rows inserted, expired and otherwise removed since the
+	// DataFrame was created.
+	Inserted int
+	Expired  int
+	Removed  int
+	// InsertRate is the number of rows inserted per second over the last minute.
+	InsertRate float64
+	// FilterLatency is the histogram of the latencies of Filter and FilterContext.
+	FilterLatency LatencyHistogram
+	// CleanerPurged is the number of rows removed by the background cleaner.
+	CleanerPurged int
+	// RegexCacheHits and RegexCacheMisses count the lookups of compiled regular expressions,
+	// and RegexCacheHitRate is the share of hits, zero before the first lookup.
+	RegexCacheHits    uint64
+	RegexCacheMisses  uint64
+	RegexCacheHitRate float64
+	// PlanCacheHits and PlanCacheMisses count the lookups of FilterSpec plans, and PlanCacheHitRate is
+	// the share of hits, zero before the first lookup.
+	PlanCacheHits    uint64
+	PlanCacheMisses  uint64
+	PlanCacheHitRate float64
+}
+
+// metrics holds the counters of a DataFrame not guarded by its lock.
+type metrics struct {
+	mutex       sync.Mutex
+	latency     []uint64
+	count       uint64
+	sum         float64
+	regexHits   atomic.Uint64
+	regexMisses atomic.Uint64
+}
+
+// observeFilter adds a filter latency to the histogram.
+func (m *metrics) observeFilter(latency time.Duration) {
+	seconds := latency.Seconds()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.latency == nil {
+		m.latency = make([]uint64, len(LatencyBuckets))
+	}
+	for i, bound := range LatencyBuckets {
+		if seconds <= bound {
+			m.latency[i]++
+			break
+		}
+	}
+	m.count++
+	m.sum += seconds
+}
+
+// histogram returns the filter latency histogram with cumulative counts.
+func (m *metrics) histogram() LatencyHistogram {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	h := LatencyHistogram{
+		Buckets: append([]float64(nil), LatencyBuckets...),
+		Counts:  make([]uint64, len(LatencyBuckets)),
+		Count:   m.count,
+		Sum:     m.sum,
+	}
+
+	var cumulative uint64
+	for i := range h.Counts {
+		if i < len(m.latency) {
+			cumulative += m.latency[i]
+		}
+		h.Counts[i] = cumulative
+	}
+
+	return h
+}
+
+// rateWindow counts events per second over the last insertRateWindow seconds.
+type rateWindow struct {
+	seconds [insertRateWindow]int64
+	counts  [insertRateWindow]int
+}
+
+// add counts an event at now.
+func (w *rateWindow) add(now time.Time) {
+	second := now.Unix()
+	i := second % insertRateWindow
+	if w.seconds[i] != second {
+		w.seconds[i] = second
+		w.counts[i] = 0
+	}
+	w.counts[i]++
+}
+
+// rate returns the average number of events per second over the window ending at now.
+func (w *rateWindow) rate(now time.Time) float64 {
+	second := now.Unix()
+
+	total := 0
+	for i, at := range w.seconds {
+		if at <= second && second-at < insertRateWindow {
+			total += w.counts[i]
+		}
+	}
+
+	return float64(total) / insertRateWindow
+}
+
+// Metrics returns the number of rows, the cardinality of every key, the insert, expire and remove counts,
+// the insert rate, the filter latency histogram, the rows purged by the cleaner and the regex and plan cache hit rates.
+func (d *DataFrame) Metrics() Metrics {
+	d.Locker.RLock()
+	m := Metrics{
+		Rows:        len(d.Data),
+		Cardinality: make(map[KeyName]int, len(d.Keys)),
+		Inserted:    d.activity.inserted,
+		Expired:     d.activity.expired,
+		Removed:     d.activity.removed,
+		InsertRate:  d.activity.inserts.rate(time.Now()),
+	}
+	for key := range d.Keys {
+		m.Cardinality[key] = d.uniqueValuesUnlocked(key)
+	}
+	d.Locker.RUnlock()
+
+	m.FilterLatency = d.metrics.histogram()
+	m.CleanerPurged = d.CleanerStatus().Purged
+
+	m.RegexCacheHits = d.metrics.regexHits.Load()
+	m.RegexCacheMisses = d.metrics.regexMisses.Load()
+	if lookups := m.RegexCacheHits + m.RegexCacheMisses; lookups > 0 {
+		m.RegexCacheHitRate = float64(m.RegexCacheHits) / float64(lookups)
+	}
+
+	m.PlanCacheHits = d.plans.hits.Load()
+	m.PlanCacheMisses = d.plans.misses.Load()
+	if lookups := m.PlanCacheHits + m.PlanCacheMisses; lookups > 0 {
+		m.PlanCacheHitRate = float64(m.PlanCacheHits) / float64(lookups)
+	}
+
+	return m
+}
+
+// PublishExpvar publishes the Metrics of the DataFrame as the expvar variable name, served as JSON by the
+// /debug/vars handler of the expvar package. Like expvar.Publish, it panics if the name is already in use.
+func (d *DataFrame) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return d.Metrics()
+	}))
+}
+
+// MetricsHandler returns an HTTP handler serving the Metrics of the DataFrame in the Prometheus text
+// exposition format, for scraping by Prometheus-compatible collectors.
+func (d *DataFrame) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = d.WritePrometheus(w)
+	})
+}
+
+// WritePrometheus writes the Metrics of the DataFrame in the Prometheus text exposition format. Metric
+// names start with mframe_ and every sample is labeled with the name of the DataFrame, when it has one.
+func (d *DataFrame) WritePrometheus(w io.Writer) error {
+	m := d.Metrics()
+
+	labels := map[string]string{}
+	if name := d.Name(); name != "" {
+		labels["frame"] = name
+	}
+
+	out := bufio.NewWriter(w)
+	family := func(name, kind, help string) {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	sample := func(name string, extra map[string]string, value float64) {
+		fmt.Fprintf(out, "%s%s %s\n", name, promLabels(labels, extra), formatMetricValue(value))
+	}
+
+	family("mframe_rows", "gauge", "Number of rows.")
+	sample("mframe_rows", nil, float64(m.Rows))
+
+	family("mframe_key_cardinality", "gauge", "Number of distinct values of a key.")
+	keys := make([]KeyName, 0, len(m.Cardinality))
+	for key := range m.Cardinality {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for _, key := range keys {
+		sample("mframe_key_cardinality", map[string]string{"key": string(key)}, float64(m.Cardinality[key]))
+	}
+
+	family("mframe_rows_inserted_total", "counter", "Rows inserted.")
+	sample("mframe_rows_inserted_total", nil, float64(m.Inserted))
+	family("mframe_rows_expired_total", "counter", "Rows expired.")
+	sample("mframe_rows_expired_total", nil, float64(m.Expired))
+	family("mframe_rows_removed_total", "counter", "Rows removed before expiring.")
+	sample("mframe_rows_removed_total", nil, float64(m.Removed))
+	family("mframe_insert_rate", "gauge", "Rows inserted per second over the last minute.")
+	sample("mframe_insert_rate", nil, m.InsertRate)
+
+	family("mframe_filter_duration_seconds", "histogram", "Latency of filters.")
+	for i, bound := range m.FilterLatency.Buckets {
+		sample("mframe_filter_duration_seconds_bucket", map[string]string{"le": formatMetricValue(bound)}, float64(m.FilterLatency.Counts[i]))
+	}
+	sample("mframe_filter_duration_seconds_bucket", map[string]string{"le": "+Inf"}, float64(m.FilterLatency.Count))
+	sample("mframe_filter_duration_seconds_sum", nil, m.FilterLatency.Sum)
+	sample("mframe_filter_duration_seconds_count", nil, float64(m.FilterLatency.Count))
+
+	family("mframe_cleaner_purged_total", "counter", "Rows removed by the cleaner.")
+	sample("mframe_cleaner_purged_total", nil, float64(m.CleanerPurged))
+	family("mframe_regex_cache_hits_total", "counter", "Regular expressions found in the cache.")
+	sample("mframe_regex_cache_hits_total", nil, float64(m.RegexCacheHits))
+	family("mframe_regex_cache_misses_total", "counter", "Regular expressions compiled on a cache miss.")
+	sample("mframe_regex_cache_misses_total", nil, float64(m.RegexCacheMisses))
+	family("mframe_plan_cache_hits_total", "counter", "FilterSpec plans found in the cache.")
+	sample("mframe_plan_cache_hits_total", nil, float64(m.PlanCacheHits))
+	family("mframe_plan_cache_misses_total", "counter", "FilterSpec plans made on a cache miss.")
+	sample("mframe_plan_cache_misses_total", nil, float64(m.PlanCacheMisses))
+
+	return out.Flush()
+}
+
+// promLabels formats the union of two label sets, sorted by name, or an empty string when both are empty.
+func promLabels(base, extra map[string]string) string {
+	merged := make(map[string]string, len(base)+len(extra))
+	for name, value := range base {
+		merged[name] = value
+	}
+	for name, value := range extra {
+		merged[name] = value
+	}
+
+	if len(merged) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(merged))
+	for name := range merged {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", name, metricTextEscaper.Replace(merged[name]))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
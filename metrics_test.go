@@ -0,0 +1,70 @@
+package mframe_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestMetrics(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "bytes": 100},
+		{"host": "web-2", "bytes": 100},
+	})
+
+	cache.Filter(mframe.RegExp, "host", "^web-", nil)
+	cache.Filter(mframe.RegExp, "host", "^web-", nil)
+
+	m := cache.Metrics()
+	if m.Rows != 2 || m.Inserted != 2 || m.Cardinality["host"] != 2 || m.Cardinality["bytes"] != 1 {
+		t.Errorf("expected 2 rows with 2 hosts and 1 byte count, but got %+v", m)
+	}
+	if m.InsertRate <= 0 {
+		t.Errorf("expected a positive insert rate, but got %f", m.InsertRate)
+	}
+	if m.FilterLatency.Count != 2 {
+		t.Errorf("expected 2 filter latencies, but got %d", m.FilterLatency.Count)
+	}
+	if last := m.FilterLatency.Counts[len(m.FilterLatency.Counts)-1]; last > m.FilterLatency.Count {
+		t.Errorf("expected cumulative bucket counts up to %d, but got %d", m.FilterLatency.Count, last)
+	}
+	if m.RegexCacheHits != 1 || m.RegexCacheMisses != 1 || m.RegexCacheHitRate != 0.5 {
+		t.Errorf("expected 1 hit and 1 miss, but got %d hits and %d misses", m.RegexCacheHits, m.RegexCacheMisses)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetName("agents")
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+	cache.Filter(mframe.Equals, "host", "web-1", nil)
+
+	rec := httptest.NewRecorder()
+	cache.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("expected the Prometheus text format, but got '%s'", ct)
+	}
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE mframe_rows gauge",
+		`mframe_rows{frame="agents"} 1`,
+		`mframe_key_cardinality{frame="agents",key="host"} 1`,
+		`mframe_rows_inserted_total{frame="agents"} 1`,
+		"# TYPE mframe_filter_duration_seconds histogram",
+		`mframe_filter_duration_seconds_bucket{frame="agents",le="+Inf"} 1`,
+		`mframe_filter_duration_seconds_count{frame="agents"} 1`,
+		`mframe_cleaner_purged_total{frame="agents"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected line '%s', but got:\n%s", line, body)
+		}
+	}
+}
@@ -134,6 +134,28 @@ func TestToSlice(t *testing.T) {
 	}
 }
 
+func TestRows(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"name": "John"})
+
+	rows := cache.Rows()
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 row, but got %d", len(rows))
+	}
+	rows[0]["name"] = "Mallory"
+
+	if count := cache.CountWhere(mframe.Equals, "name", "John", nil); count != 1 {
+		t.Errorf("expected the indexed data to be unchanged, but got %d rows", count)
+	}
+	for _, row := range cache.RowsRef() {
+		if row["name"] != "John" {
+			t.Errorf("expected modifying a copy not to affect the row, but got %v", row["name"])
+		}
+	}
+}
+
 func TestSliceOf(t *testing.T) {
 	var cache mframe.DataFrame
 	cache.Init(24 * time.Hour)
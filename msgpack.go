@@ -0,0 +1,273 @@
+package mframe
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// msgpackTimestamp is the extension type of MessagePack timestamps, -1 as a byte.
+const msgpackTimestamp byte = 0xff
+
+// msgpackEncoder writes MessagePack values.
+type msgpackEncoder struct {
+	w *bufio.Writer
+}
+
+func (e msgpackEncoder) writeNil() {
+	_ = e.w.WriteByte(0xc0)
+}
+
+func (e msgpackEncoder) writeBool(v bool) {
+	if v {
+		_ = e.w.WriteByte(0xc3)
+	} else {
+		_ = e.w.WriteByte(0xc2)
+	}
+}
+
+func (e msgpackEncoder) writeInt(v int64) {
+	switch {
+	case v >= 0 && v <= 0x7f:
+		_ = e.w.WriteByte(byte(v))
+	case v < 0 && v >= -32:
+		_ = e.w.WriteByte(byte(v))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		_ = e.w.WriteByte(0xd2)
+		e.uint32(uint32(v))
+	default:
+		_ = e.w.WriteByte(0xd3)
+		e.uint64(uint64(v))
+	}
+}
+
+func (e msgpackEncoder) writeFloat(v float64) {
+	_ = e.w.WriteByte(0xcb)
+	e.uint64(math.Float64bits(v))
+}
+
+func (e msgpackEncoder) writeString(v string) {
+	switch n := len(v); {
+	case n < 32:
+		_ = e.w.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		_ = e.w.WriteByte(0xd9)
+		_ = e.w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		_ = e.w.WriteByte(0xda)
+		e.uint16(uint16(n))
+	default:
+		_ = e.w.WriteByte(0xdb)
+		e.uint32(uint32(n))
+	}
+	_, _ = e.w.WriteString(v)
+}
+
+func (e msgpackEncoder) writeBytes(v []byte) {
+	switch n := len(v); {
+	case n <= math.MaxUint8:
+		_ = e.w.WriteByte(0xc4)
+		_ = e.w.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		_ = e.w.WriteByte(0xc5)
+		e.uint16(uint16(n))
+	default:
+		_ = e.w.WriteByte(0xc6)
+		e.uint32(uint32(n))
+	}
+	_, _ = e.w.Write(v)
+}
+
+// writeTime writes a timestamp 96: an ext 8 of 12 bytes holding the nanoseconds and the seconds.
+func (e msgpackEncoder) writeTime(v time.Time) {
+	_ = e.w.WriteByte(0xc7)
+	_ = e.w.WriteByte(12)
+	_ = e.w.WriteByte(msgpackTimestamp)
+	e.uint32(uint32(v.Nanosecond()))
+	e.uint64(uint64(v.Unix()))
+}
+
+func (e msgpackEncoder) writeArrayHeader(n int) {
+	switch {
+	case n < 16:
+		_ = e.w.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		_ = e.w.WriteByte(0xdc)
+		e.uint16(uint16(n))
+	default:
+		_ = e.w.WriteByte(0xdd)
+		e.uint32(uint32(n))
+	}
+}
+
+func (e msgpackEncoder) writeMapHeader(n int) {
+	switch {
+	case n < 16:
+		_ = e.w.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		_ = e.w.WriteByte(0xde)
+		e.uint16(uint16(n))
+	default:
+		_ = e.w.WriteByte(0xdf)
+		e.uint32(uint32(n))
+	}
+}
+
+func (e msgpackEncoder) uint16(v uint16) {
+	_, _ = e.w.Write(binary.BigEndian.AppendUint16(nil, v))
+}
+
+func (e msgpackEncoder) uint32(v uint32) {
+	_, _ = e.w.Write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func (e msgpackEncoder) uint64(v uint64) {
+	_, _ = e.w.Write(binary.BigEndian.AppendUint64(nil, v))
+}
+
+// msgpackDecoder reads MessagePack values.
+type msgpackDecoder struct {
+	r *bufio.Reader
+}
+
+func (d msgpackDecoder) token() (codecToken, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return codecToken{}, unexpectedEOF(err)
+	}
+
+	switch {
+	case b <= 0x7f:
+		return codecToken{value: int64(b)}, nil
+	case b >= 0xe0:
+		return codecToken{value: int64(int8(b))}, nil
+	case b&0xf0 == 0x80:
+		return codecToken{kind: codecMap, n: int(b & 0x0f)}, nil
+	case b&0xf0 == 0x90:
+		return codecToken{kind: codecArray, n: int(b & 0x0f)}, nil
+	case b&0xe0 == 0xa0:
+		return d.str(uint64(b & 0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return codecToken{}, nil
+	case 0xc2:
+		return codecToken{value: false}, nil
+	case 0xc3:
+		return codecToken{value: true}, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (b - 0xc4))
+		if err != nil {
+			return codecToken{}, err
+		}
+		data, err := readCodecBytes(d.r, n)
+		return codecToken{value: data}, err
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (b - 0xc7))
+		if err != nil {
+			return codecToken{}, err
+		}
+		return d.ext(n)
+	case 0xca:
+		bits, err := d.uint(4)
+		return codecToken{value: float64(math.Float32frombits(uint32(bits)))}, err
+	case 0xcb:
+		bits, err := d.uint(8)
+		return codecToken{value: math.Float64frombits(bits)}, err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(1 << (b - 0xcc))
+		if v > math.MaxInt64 {
+			return codecToken{value: float64(v)}, err
+		}
+		return codecToken{value: int64(v)}, err
+	case 0xd0:
+		v, err := d.uint(1)
+		return codecToken{value: int64(int8(v))}, err
+	case 0xd1:
+		v, err := d.uint(2)
+		return codecToken{value: int64(int16(v))}, err
+	case 0xd2:
+		v, err := d.uint(4)
+		return codecToken{value: int64(int32(v))}, err
+	case 0xd3:
+		v, err := d.uint(8)
+		return codecToken{value: int64(v)}, err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (b - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (b - 0xd9))
+		if err != nil {
+			return codecToken{}, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (b - 0xdc))
+		return codecToken{kind: codecArray, n: n}, err
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (b - 0xde))
+		return codecToken{kind: codecMap, n: n}, err
+	default:
+		return codecToken{}, fmt.Errorf("unsupported MessagePack type 0x%02x", b)
+	}
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (d msgpackDecoder) uint(size int) (uint64, error) {
+	var v uint64
+	for i := 0; i < size; i++ {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		v = v<<8 | uint64(b)
+	}
+	return v, nil
+}
+
+// length reads the number of elements of an array or map.
+func (d msgpackDecoder) length(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	return codecLength(n)
+}
+
+func (d msgpackDecoder) str(n uint64) (codecToken, error) {
+	data, err := readCodecBytes(d.r, n)
+	return codecToken{value: string(data)}, err
+}
+
+// ext reads an extension of n bytes, converting timestamps to time.Time and keeping other extensions as
+// their raw bytes.
+func (d msgpackDecoder) ext(n uint64) (codecToken, error) {
+	kind, err := d.r.ReadByte()
+	if err != nil {
+		return codecToken{}, unexpectedEOF(err)
+	}
+	data, err := readCodecBytes(d.r, n)
+	if err != nil {
+		return codecToken{}, err
+	}
+
+	if kind != msgpackTimestamp {
+		return codecToken{value: data}, nil
+	}
+
+	switch len(data) {
+	case 4:
+		return codecToken{value: time.Unix(int64(binary.BigEndian.Uint32(data)), 0).UTC()}, nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return codecToken{value: time.Unix(int64(v&(1<<34-1)), int64(v>>34)).UTC()}, nil
+	case 12:
+		nanos := binary.BigEndian.Uint32(data)
+		seconds := int64(binary.BigEndian.Uint64(data[4:]))
+		return codecToken{value: time.Unix(seconds, int64(nanos)).UTC()}, nil
+	default:
+		return codecToken{}, fmt.Errorf("invalid MessagePack timestamp of %d bytes", len(data))
+	}
+}
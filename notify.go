@@ -0,0 +1,242 @@
+package mframe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultNotificationBody is the body template used by a Notifier without one.
+const DefaultNotificationBody = "{{len .Delta.Added}} new matches, {{len .Delta.Removed}} gone, {{.Delta.Total}} total"
+
+// Notification is the data passed to the templates of a Notifier: the delta of a standing query, the rows
+// added by it and the number of notifications suppressed by rate limiting since the previous one sent.
+type Notification struct {
+	Delta QueryDelta
+	// Rows holds the added rows in the order of Delta.Added, as maps so templates can read their keys,
+	// e.g. {{range .Rows}}{{.ip}}{{end}}. Rows removed before the notification was built are skipped.
+	Rows       []map[string]interface{}
+	Suppressed int
+	At         time.Time
+}
+
+// Message is a rendered notification, as delivered to a Sink.
+type Message struct {
+	Subject      string
+	Body         string
+	Notification Notification
+}
+
+// Sink delivers messages to an external system.
+type Sink interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// WebhookSink posts messages as JSON to URL, with the subject, body, added and removed IDs, total and time.
+type WebhookSink struct {
+	URL     string
+	Headers map[string]string
+	// Client is the HTTP client used, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// Send posts the message, returning an error if the request fails or the response status is not 2xx.
+func (s WebhookSink) Send(ctx context.Context, message Message) error {
+	payload := map[string]interface{}{
+		"subject": message.Subject,
+		"body":    message.Body,
+		"added":   message.Notification.Delta.Added,
+		"removed": message.Notification.Delta.Removed,
+		"total":   message.Notification.Delta.Total,
+		"at":      message.Notification.At,
+	}
+	return postJSON(ctx, s.Client, s.URL, s.Headers, payload)
+}
+
+// SlackSink posts messages to a Slack incoming webhook, as the subject followed by the body.
+type SlackSink struct {
+	WebhookURL string
+	// Client is the HTTP client used, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// Send posts the message, returning an error if the request fails or the response status is not 2xx.
+func (s SlackSink) Send(ctx context.Context, message Message) error {
+	text := message.Body
+	if message.Subject != "" {
+		text = message.Subject + "\n" + message.Body
+	}
+	return postJSON(ctx, s.Client, s.WebhookURL, nil, map[string]string{"text": text})
+}
+
+// EmailSink sends messages as plain text emails through the SMTP server at Addr, in host:port form.
+type EmailSink struct {
+	Addr string
+	From string
+	To   []string
+	// Auth authenticates with the server, no authentication when nil.
+	Auth smtp.Auth
+}
+
+// headerBreaks replaces the line breaks that would let a value inject headers into an email.
+var headerBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// Send sends the message, with the subject on a single line and encoded per RFC 2047 when not ASCII.
+// Returns an error if there are no recipients or an address holds a line break. The context is not used,
+// as net/smtp does not support cancellation.
+func (s EmailSink) Send(_ context.Context, message Message) error {
+	if len(s.To) == 0 {
+		return fmt.Errorf("email sink has no recipients")
+	}
+	for _, address := range append([]string{s.From}, s.To...) {
+		if strings.ContainsAny(address, "\r\n") {
+			return fmt.Errorf("email address %q contains a line break", address)
+		}
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", s.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerBreaks.Replace(message.Subject)))
+	body.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	body.WriteString(message.Body)
+
+	if err := smtp.SendMail(s.Addr, s.Auth, s.From, s.To, []byte(body.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// NotifierOptions configures the templates and the rate limit of a Notifier.
+type NotifierOptions struct {
+	// Subject and Body are text/template templates executed with a Notification.
+	// Body defaults to DefaultNotificationBody.
+	Subject string
+	Body    string
+	// MinInterval is the minimum time between two messages. Notifications arriving sooner are suppressed
+	// and counted in the next message. Zero disables rate limiting.
+	MinInterval time.Duration
+}
+
+// Notifier renders notifications with templates and delivers them to a Sink, with rate limiting.
+type Notifier struct {
+	sink       Sink
+	subject    *template.Template
+	body       *template.Template
+	interval   time.Duration
+	mutex      sync.Mutex
+	last       time.Time
+	suppressed int
+}
+
+// NewNotifier returns a Notifier delivering to sink. Returns an error if sink is nil or a template is invalid.
+func NewNotifier(sink Sink, options NotifierOptions) (*Notifier, error) {
+	if sink == nil {
+		return nil, fmt.Errorf("notification sink cannot be nil")
+	}
+	if options.Body == "" {
+		options.Body = DefaultNotificationBody
+	}
+
+	subject, err := template.New("subject").Parse(options.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subject template: %w", err)
+	}
+	body, err := template.New("body").Parse(options.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse body template: %w", err)
+	}
+
+	return &Notifier{sink: sink, subject: subject, body: body, interval: options.MinInterval}, nil
+}
+
+// Notify renders the notification and sends it, unless it is suppressed by the rate limit, in which case
+// it returns nil without sending. Returns an error if a template fails or the sink fails.
+func (n *Notifier) Notify(ctx context.Context, notification Notification) error {
+	n.mutex.Lock()
+	if n.interval > 0 && !n.last.IsZero() && notification.At.Sub(n.last) < n.interval {
+		n.suppressed++
+		n.mutex.Unlock()
+		return nil
+	}
+	notification.Suppressed = n.suppressed
+	n.suppressed = 0
+	n.last = notification.At
+	n.mutex.Unlock()
+
+	var subject, body strings.Builder
+	if err := n.subject.Execute(&subject, notification); err != nil {
+		return fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := n.body.Execute(&body, notification); err != nil {
+		return fmt.Errorf("failed to render body: %w", err)
+	}
+
+	return n.sink.Send(ctx, Message{Subject: subject.String(), Body: body.String(), Notification: notification})
+}
+
+// Notify evaluates the query every interval until ctx is done, sending each non-empty delta with its added rows
+// through notifier. Delivery errors are logged and do not stop the query.
+func (q *StandingQuery) Notify(ctx context.Context, interval time.Duration, notifier *Notifier) {
+	q.Run(ctx, interval, func(delta QueryDelta) {
+		if err := notifier.Notify(ctx, q.notification(delta)); err != nil {
+			log.Printf("error sending notification: %s", err.Error())
+		}
+	})
+}
+
+// notification builds the notification of a delta with copies of the added rows.
+func (q *StandingQuery) notification(delta QueryDelta) Notification {
+	notification := Notification{Delta: delta, Rows: make([]map[string]interface{}, 0, len(delta.Added)), At: time.Now().UTC()}
+
+	q.frame.Locker.RLock()
+	for _, id := range delta.Added {
+		if row, ok := q.frame.Data[id]; ok {
+			notification.Rows = append(notification.Rows, templateRow(q.frame.copyRowUnlocked(row)))
+		}
+	}
+	q.frame.Locker.RUnlock()
+
+	return notification
+}
+
+// postJSON posts payload as JSON to url with the given headers.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
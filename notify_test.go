@@ -0,0 +1,198 @@
+package mframe_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+type recordingSink struct {
+	messages []mframe.Message
+}
+
+func (s *recordingSink) Send(_ context.Context, message mframe.Message) error {
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	sink := &recordingSink{}
+	notifier, err := mframe.NewNotifier(sink, mframe.NotifierOptions{
+		Subject:     "{{len .Delta.Added}} hits",
+		Body:        "{{range .Rows}}{{.ip}} {{end}}(+{{.Suppressed}} suppressed)",
+		MinInterval: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now().UTC()
+	rows := []map[string]interface{}{{"ip": "10.0.0.1"}}
+	for i, at := range []time.Duration{0, time.Second, 2 * time.Second, time.Minute} {
+		notification := mframe.Notification{Rows: rows, At: start.Add(at)}
+		if err := notifier.Notify(context.Background(), notification); err != nil {
+			t.Fatalf("unexpected error on notification %d: %v", i, err)
+		}
+	}
+
+	if len(sink.messages) != 2 {
+		t.Fatalf("expected 2 messages, but got %d", len(sink.messages))
+	}
+	if sink.messages[0].Body != "10.0.0.1 (+0 suppressed)" {
+		t.Errorf("unexpected body %q", sink.messages[0].Body)
+	}
+	if sink.messages[1].Body != "10.0.0.1 (+2 suppressed)" {
+		t.Errorf("unexpected body %q", sink.messages[1].Body)
+	}
+
+	if _, err := mframe.NewNotifier(sink, mframe.NotifierOptions{Body: "{{"}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+	if _, err := mframe.NewNotifier(nil, mframe.NotifierOptions{}); err == nil {
+		t.Error("expected an error for a nil sink")
+	}
+}
+
+func TestNotifySinks(t *testing.T) {
+	payloads := make(chan map[string]interface{}, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		payloads <- payload
+		if r.Header.Get("X-Token") == "reject" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "verdict": "block"})
+
+	spec := mframe.FilterSpec{Conditions: []mframe.Condition{{Operator: mframe.Equals, Key: "verdict", Value: "block"}}}
+
+	tests := []struct {
+		name     string
+		sink     mframe.Sink
+		key      string
+		expected string
+	}{
+		{"webhook", mframe.WebhookSink{URL: server.URL}, "body", "blocked 10.0.0.1"},
+		{"slack", mframe.SlackSink{WebhookURL: server.URL}, "text", "alert\nblocked 10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier, err := mframe.NewNotifier(tt.sink, mframe.NotifierOptions{
+				Subject: "alert",
+				Body:    "blocked {{range .Rows}}{{.ip}}{{end}}",
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go cache.NewStandingQuery(spec).Notify(ctx, time.Hour, notifier)
+
+			select {
+			case payload := <-payloads:
+				if payload[tt.key] != tt.expected {
+					t.Errorf("expected %s to be %q, but got %v", tt.key, tt.expected, payload)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected a notification")
+			}
+		})
+	}
+
+	sink := mframe.WebhookSink{URL: server.URL, Headers: map[string]string{"X-Token": "reject"}}
+	err := sink.Send(context.Background(), mframe.Message{})
+	<-payloads
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a status error, but got %v", err)
+	}
+}
+
+// serveSMTP accepts a single SMTP session on listener and sends the data of the message to messages.
+func serveSMTP(listener net.Listener, messages chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(command, "DATA"):
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil || line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			messages <- data.String()
+			reply("250 ok")
+		case strings.HasPrefix(command, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func TestEmailSinkHeaders(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+
+	messages := make(chan string, 1)
+	go serveSMTP(listener, messages)
+
+	sink := mframe.EmailSink{Addr: listener.Addr().String(), From: "alerts@example.com", To: []string{"soc@example.com"}}
+	err = sink.Send(context.Background(), mframe.Message{
+		Subject: "alerta crítica\r\nBcc: attacker@example.com",
+		Body:    "blocked",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := <-messages
+	if strings.Contains(data, "\r\nBcc:") {
+		t.Errorf("expected the subject not to inject headers, but got %q", data)
+	}
+	if !strings.Contains(data, "Subject: =?utf-8?q?") {
+		t.Errorf("expected an encoded subject, but got %q", data)
+	}
+
+	for _, sink := range []mframe.EmailSink{
+		{Addr: listener.Addr().String(), From: "alerts@example.com\r\nBcc: attacker@example.com", To: []string{"soc@example.com"}},
+		{Addr: listener.Addr().String(), From: "alerts@example.com", To: []string{"soc@example.com\nBcc: attacker@example.com"}},
+	} {
+		if err := sink.Send(context.Background(), mframe.Message{Subject: "alert"}); err == nil {
+			t.Errorf("expected an error for the addresses %q %v", sink.From, sink.To)
+		}
+	}
+}
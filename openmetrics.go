@@ -0,0 +1,164 @@
+package mframe
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MetricFamily names the aggregated results exported by WriteOpenMetrics. Each aggregation of the groups
+// is exported as a gauge named after the family and the aggregation, such as events_count, with one sample
+// per group labeled with the values of its group keys. Results of Aggregate can be exported as a single
+// group without labels.
+type MetricFamily struct {
+	Name   string
+	Help   string
+	Groups []GroupResult
+}
+
+// WriteOpenMetrics renders the families in the OpenMetrics text exposition format, so scrape-based monitoring
+// systems can collect metrics derived from frames. Metric and label names are sanitized, replacing invalid
+// characters with underscores, and the output ends with the mandatory EOF marker.
+func WriteOpenMetrics(w io.Writer, families ...MetricFamily) error {
+	out := bufio.NewWriter(w)
+
+	seen := make(map[string]bool)
+	for _, family := range families {
+		functions := make(map[string]bool)
+		for _, group := range family.Groups {
+			for function := range group.Values {
+				functions[function] = true
+			}
+		}
+
+		names := make([]string, 0, len(functions))
+		for function := range functions {
+			names = append(names, function)
+		}
+		sort.Strings(names)
+
+		for _, function := range names {
+			name := metricName(family.Name + "_" + function)
+			if seen[name] {
+				return fmt.Errorf("duplicated metric '%s'", name)
+			}
+			seen[name] = true
+
+			fmt.Fprintf(out, "# TYPE %s gauge\n", name)
+			if family.Help != "" {
+				fmt.Fprintf(out, "# HELP %s %s\n", name, metricTextEscaper.Replace(family.Help))
+			}
+
+			for _, group := range family.Groups {
+				value, ok := group.Values[function]
+				if !ok {
+					continue
+				}
+				fmt.Fprintf(out, "%s%s %s\n", name, metricLabels(group.Group), formatMetricValue(value))
+			}
+		}
+	}
+
+	fmt.Fprint(out, "# EOF\n")
+
+	return out.Flush()
+}
+
+// metricLabels renders the group values as a label set, skipping nil values.
+func metricLabels(group map[KeyName]interface{}) string {
+	keys := make([]string, 0, len(group))
+	values := make(map[string]string, len(group))
+	for key, value := range group {
+		if value == nil {
+			continue
+		}
+		name := labelName(string(key))
+		keys = append(keys, name)
+		values[name] = labelValue(value)
+	}
+
+	if len(keys) == 0 {
+		return ""
+	}
+
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", key, metricTextEscaper.Replace(values[key]))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// labelValue formats a group value as a label value.
+func labelValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatMetricValue formats a sample value, spelling out the special float values.
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// metricName replaces the characters not allowed in metric names with underscores.
+func metricName(name string) string {
+	return sanitizeMetricName(name, true)
+}
+
+// labelName replaces the characters not allowed in label names with underscores.
+func labelName(name string) string {
+	return sanitizeMetricName(name, false)
+}
+
+// sanitizeMetricName replaces the characters not allowed in metric or label names with underscores,
+// prefixing names starting with a digit. Colons are only allowed in metric names.
+func sanitizeMetricName(name string, colons bool) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':' && colons:
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// metricTextEscaper escapes backslashes, double quotes and line feeds in help texts and label values.
+var metricTextEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
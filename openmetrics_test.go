@@ -0,0 +1,95 @@
+package mframe_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestGroupBy(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "severity": "high", "bytes": 100},
+		{"host": "web-1", "severity": "high", "bytes": 300},
+		{"host": "web-1", "severity": "low", "bytes": 50},
+		{"host": "web-2", "severity": "high"},
+	})
+
+	groups, err := cache.GroupBy(mframe.FilterSpec{}, []mframe.KeyName{"host", "severity"},
+		mframe.AggSpec{Field: "bytes", Functions: []string{"count", "sum"}})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, but got %d", len(groups))
+	}
+
+	first := groups[0]
+	if first.Group["host"] != "web-1" || first.Group["severity"] != "high" || first.Rows != 2 || first.Values["sum"] != 400 {
+		t.Errorf("expected web-1/high with 2 rows summing 400, but got %+v", first)
+	}
+	if last := groups[2]; last.Rows != 1 || last.Values["count"] != 0 {
+		t.Errorf("expected web-2 with 1 row and no bytes, but got %+v", last)
+	}
+	if _, ok := groups[2].Values["sum"]; ok {
+		t.Error("expected the failing sum to be left out")
+	}
+
+	if _, err := cache.GroupBy(mframe.FilterSpec{}, []mframe.KeyName{"host"}, mframe.AggSpec{Functions: []string{"nope"}}); err == nil {
+		t.Error("expected an error for an unknown aggregate")
+	}
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "user.name": `a"b`, "bytes": 100},
+		{"host": "web-1", "user.name": `a"b`, "bytes": 300},
+		{"host": "web-2", "bytes": 50},
+	})
+
+	groups, err := cache.GroupBy(mframe.FilterSpec{}, []mframe.KeyName{"host", "user.name"},
+		mframe.AggSpec{Field: "bytes", Functions: []string{"sum", "count"}})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	total, _ := cache.Aggregate(mframe.FilterSpec{}, mframe.AggSpec{Field: "bytes", Functions: []string{"max"}})
+
+	var out bytes.Buffer
+	err = mframe.WriteOpenMetrics(&out,
+		mframe.MetricFamily{Name: "flow-bytes", Help: "Bytes per host", Groups: groups},
+		mframe.MetricFamily{Name: "flow_bytes_total", Groups: []mframe.GroupResult{{Values: total}}},
+	)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	expected := `# TYPE flow_bytes_count gauge
+# HELP flow_bytes_count Bytes per host
+flow_bytes_count{host="web-1",user_name="a\"b"} 2
+flow_bytes_count{host="web-2"} 1
+# TYPE flow_bytes_sum gauge
+# HELP flow_bytes_sum Bytes per host
+flow_bytes_sum{host="web-1",user_name="a\"b"} 400
+flow_bytes_sum{host="web-2"} 50
+# TYPE flow_bytes_total_max gauge
+flow_bytes_total_max 300
+# EOF
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, out.String())
+	}
+
+	err = mframe.WriteOpenMetrics(&out,
+		mframe.MetricFamily{Name: "a", Groups: groups},
+		mframe.MetricFamily{Name: "a", Groups: groups},
+	)
+	if err == nil || !strings.Contains(err.Error(), "duplicated") {
+		t.Errorf("expected a duplicated metric error, but got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package mframe
+
+import (
+	"container/list"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// insertionOrder is the optional list of row IDs in arrival order, with the position of each row
+// so removals do not scan the list.
+type insertionOrder struct {
+	enabled  bool
+	rows     *list.List
+	elements map[uuid.UUID]*list.Element
+}
+
+// SetInsertionOrder enables or disables tracking the arrival order of rows, used by Latest and Oldest.
+// Rows already in the DataFrame when it is enabled, or loaded from a file, are ordered by expiration time, then by ID.
+// Replacing a row with the same ID counts as a new arrival, while updating it keeps its position.
+func (d *DataFrame) SetInsertionOrder(enabled bool) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.order = insertionOrder{enabled: enabled}
+	d.seedOrderUnlocked()
+}
+
+// seedOrderUnlocked adds the rows of the DataFrame to an empty insertion order, ordered by expiration
+// time and then by ID, without acquiring locks. It does nothing unless insertion order is enabled.
+func (d *DataFrame) seedOrderUnlocked() {
+	if !d.order.enabled {
+		return
+	}
+
+	for _, id := range d.expiryOrderUnlocked() {
+		d.order.push(id)
+	}
+}
+
+// arrivalOrderUnlocked returns the IDs of the rows in insertion order when it is tracked, or ordered by
+// expiration time and then by ID otherwise, without acquiring locks. Operations copying rows into another
+// DataFrame use it so the copies arrive in a stable order.
+func (d *DataFrame) arrivalOrderUnlocked() []uuid.UUID {
+	if !d.order.enabled || len(d.order.elements) != len(d.Data) {
+		return d.expiryOrderUnlocked()
+	}
+
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for e := d.order.rows.Front(); e != nil; e = e.Next() {
+		ids = append(ids, e.Value.(uuid.UUID))
+	}
+	return ids
+}
+
+// expiryOrderUnlocked returns the IDs of the rows ordered by expiration time and then by ID, without acquiring locks.
+func (d *DataFrame) expiryOrderUnlocked() []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for id := range d.Data {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := d.ExpireAt[ids[i]], d.ExpireAt[ids[j]]
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return ids[i].String() < ids[j].String()
+	})
+	return ids
+}
+
+// Latest returns copies of the n most recently inserted rows, newest first, in O(n).
+// Returns nil unless insertion order is enabled with SetInsertionOrder.
+func (d *DataFrame) Latest(n int) []Row {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	if !d.order.enabled {
+		return nil
+	}
+
+	result := make([]Row, 0, min(max(n, 0), len(d.order.elements)))
+	for e := d.order.rows.Back(); e != nil && len(result) < n; e = e.Prev() {
+		result = append(result, d.copyRowUnlocked(d.Data[e.Value.(uuid.UUID)]))
+	}
+
+	return result
+}
+
+// Oldest returns copies of the n earliest inserted rows still in the DataFrame, oldest first, in O(n).
+// Returns nil unless insertion order is enabled with SetInsertionOrder.
+func (d *DataFrame) Oldest(n int) []Row {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	if !d.order.enabled {
+		return nil
+	}
+
+	result := make([]Row, 0, min(max(n, 0), len(d.order.elements)))
+	for e := d.order.rows.Front(); e != nil && len(result) < n; e = e.Next() {
+		result = append(result, d.copyRowUnlocked(d.Data[e.Value.(uuid.UUID)]))
+	}
+
+	return result
+}
+
+// push appends the ID to the end of the order, if tracking is enabled and the ID is not tracked yet.
+func (o *insertionOrder) push(id uuid.UUID) {
+	if !o.enabled {
+		return
+	}
+	if o.rows == nil {
+		o.rows = list.New()
+		o.elements = make(map[uuid.UUID]*list.Element)
+	}
+	if _, ok := o.elements[id]; ok {
+		return
+	}
+	o.elements[id] = o.rows.PushBack(id)
+}
+
+// remove drops the ID from the order.
+func (o *insertionOrder) remove(id uuid.UUID) {
+	if e, ok := o.elements[id]; ok {
+		o.rows.Remove(e)
+		delete(o.elements, id)
+	}
+}
@@ -0,0 +1,143 @@
+package mframe_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestLatestOldest(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	if rows := cache.Latest(10); rows != nil {
+		t.Errorf("expected nil while insertion order is disabled, but got %v", rows)
+	}
+
+	cache.SetInsertionOrder(true)
+
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"seq": i})
+	}
+
+	cache.DeleteWhere(mframe.Equals, "seq", 9.0, nil)
+	cache.DeleteWhere(mframe.Equals, "seq", 0.0, nil)
+
+	tests := []struct {
+		name     string
+		rows     []mframe.Row
+		expected []float64
+	}{
+		{"latest", cache.Latest(3), []float64{8, 7, 6}},
+		{"oldest", cache.Oldest(3), []float64{1, 2, 3}},
+		{"more than available", cache.Latest(100), []float64{8, 7, 6, 5, 4, 3, 2, 1}},
+		{"none", cache.Oldest(0), []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.rows) != len(tt.expected) {
+				t.Fatalf("expected %d rows, but got %d", len(tt.expected), len(tt.rows))
+			}
+			for i, row := range tt.rows {
+				if row["seq"] != tt.expected[i] {
+					t.Errorf("expected seq %v at %d, but got %v", tt.expected[i], i, row["seq"])
+				}
+			}
+		})
+	}
+}
+
+func TestInsertionOrderAfterLoad(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"seq": 1})
+
+	filename := filepath.Join(t.TempDir(), "frame.gob")
+	if err := cache.SaveToFile(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var loaded mframe.DataFrame
+	loaded.Init(24 * time.Hour)
+	loaded.SetInsertionOrder(true)
+	loaded.Insert(map[mframe.KeyName]interface{}{"seq": 2})
+
+	if err := loaded.LoadFromFile(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := loaded.Latest(10)
+	if len(rows) != 1 || rows[0]["seq"] != 1.0 {
+		t.Errorf("expected the loaded row only, but got %v", rows)
+	}
+}
+
+func TestLatestOldestAfterBulkInserts(t *testing.T) {
+	expectOrder := func(t *testing.T, cache *mframe.DataFrame, key mframe.KeyName, n int) {
+		t.Helper()
+		oldest, latest := cache.Oldest(n), cache.Latest(n)
+		if len(oldest) != n || len(latest) != n {
+			t.Fatalf("expected %d rows, but got %d oldest and %d latest", n, len(oldest), len(latest))
+		}
+		for i := 0; i < n; i++ {
+			if got := oldest[i][key]; got != fmt.Sprintf("row-%d", i) {
+				t.Errorf("expected oldest row %d to be row-%d, but got %v", i, i, got)
+			}
+			if got := latest[i][key]; got != fmt.Sprintf("row-%d", n-1-i) {
+				t.Errorf("expected latest row %d to be row-%d, but got %v", i, n-1-i, got)
+			}
+		}
+	}
+
+	t.Run("InsertBatch", func(t *testing.T) {
+		var cache mframe.DataFrame
+		cache.Init(24 * time.Hour)
+		cache.SetInsertionOrder(true)
+
+		rows := make([]map[mframe.KeyName]interface{}, 20)
+		for i := range rows {
+			rows[i] = map[mframe.KeyName]interface{}{"name": fmt.Sprintf("row-%d", i)}
+		}
+		if err := cache.InsertBatch(rows); err != nil {
+			t.Fatal(err)
+		}
+		expectOrder(t, &cache, "name", 20)
+	})
+
+	t.Run("ImportFromCSV", func(t *testing.T) {
+		var cache mframe.DataFrame
+		cache.Init(24 * time.Hour)
+		cache.SetInsertionOrder(true)
+
+		var csv strings.Builder
+		csv.WriteString("name\n")
+		for i := 0; i < 20; i++ {
+			fmt.Fprintf(&csv, "row-%d\n", i)
+		}
+		if _, err := cache.ImportFromCSV(strings.NewReader(csv.String()), mframe.CSVSchema{}); err != nil {
+			t.Fatal(err)
+		}
+		expectOrder(t, &cache, "name", 20)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		var source, cache mframe.DataFrame
+		source.Init(24 * time.Hour)
+		source.SetInsertionOrder(true)
+		cache.Init(24 * time.Hour)
+		cache.SetInsertionOrder(true)
+
+		for i := 0; i < 20; i++ {
+			source.Insert(map[mframe.KeyName]interface{}{"name": fmt.Sprintf("row-%d", i)})
+		}
+		if _, inserted := cache.Merge(&source, "name"); inserted != 20 {
+			t.Fatalf("expected 20 inserted rows, but got %d", inserted)
+		}
+		expectOrder(t, &cache, "name", 20)
+	})
+}
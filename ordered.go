@@ -0,0 +1,150 @@
+package mframe
+
+// orderedMaxLevel is the number of levels of the skip list of an orderedSet, enough for billions of values.
+const orderedMaxLevel = 24
+
+// orderedSet keeps unique values sorted in a skip list, so values are added and removed in logarithmic time
+// and ranges are walked in order from a seek instead of shifting a sorted slice on every change.
+// Values comparing as equal but not identical, such as times of the same instant in locations sharing a name,
+// share a node.
+type orderedSet[T comparable] struct {
+	compare func(a, b T) int
+	head    orderedNode[T]
+	level   int
+	length  int
+	links   int
+	seed    uint64
+}
+
+// orderedNode holds the values of an orderedSet comparing as equal and the following nodes at each level.
+type orderedNode[T comparable] struct {
+	values []T
+	next   []*orderedNode[T]
+}
+
+// newOrderedSet returns an empty set ordered by compare.
+func newOrderedSet[T comparable](compare func(a, b T) int) *orderedSet[T] {
+	s := &orderedSet[T]{compare: compare, level: 1, seed: 0x9e3779b97f4a7c15}
+	s.head.next = make([]*orderedNode[T], orderedMaxLevel)
+	return s
+}
+
+// randomLevel returns the level of a new node, each level being four times less likely than the previous one.
+func (s *orderedSet[T]) randomLevel() int {
+	s.seed ^= s.seed << 13
+	s.seed ^= s.seed >> 7
+	s.seed ^= s.seed << 17
+
+	level := 1
+	for bits := s.seed; level < orderedMaxLevel && bits&3 == 0; bits >>= 2 {
+		level++
+	}
+	return level
+}
+
+// path returns the last node before v at each level.
+func (s *orderedSet[T]) path(v T) [orderedMaxLevel]*orderedNode[T] {
+	var update [orderedMaxLevel]*orderedNode[T]
+	node := &s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && s.compare(node.next[i].values[0], v) < 0 {
+			node = node.next[i]
+		}
+		update[i] = node
+	}
+	return update
+}
+
+// add inserts v, doing nothing if it is already in the set.
+func (s *orderedSet[T]) add(v T) {
+	update := s.path(v)
+	if next := update[0].next[0]; next != nil && s.compare(next.values[0], v) == 0 {
+		for _, existing := range next.values {
+			if existing == v {
+				return
+			}
+		}
+		next.values = append(next.values, v)
+		s.length++
+		return
+	}
+
+	level := s.randomLevel()
+	for i := s.level; i < level; i++ {
+		update[i] = &s.head
+	}
+	if level > s.level {
+		s.level = level
+	}
+
+	node := &orderedNode[T]{values: []T{v}, next: make([]*orderedNode[T], level)}
+	for i := 0; i < level; i++ {
+		node.next[i] = update[i].next[i]
+		update[i].next[i] = node
+	}
+	s.length++
+	s.links += level
+}
+
+// remove deletes v, doing nothing if it is not in the set.
+func (s *orderedSet[T]) remove(v T) {
+	update := s.path(v)
+	node := update[0].next[0]
+	if node == nil || s.compare(node.values[0], v) != 0 {
+		return
+	}
+
+	for i, existing := range node.values {
+		if existing == v {
+			node.values = append(node.values[:i], node.values[i+1:]...)
+			s.length--
+			break
+		}
+	}
+	if len(node.values) > 0 {
+		return
+	}
+
+	for i := 0; i < len(node.next); i++ {
+		update[i].next[i] = node.next[i]
+	}
+	for s.level > 1 && s.head.next[s.level-1] == nil {
+		s.level--
+	}
+	s.links -= len(node.next)
+}
+
+// first returns the node of the smallest values, or nil when the set is empty.
+func (s *orderedSet[T]) first() *orderedNode[T] {
+	return s.head.next[0]
+}
+
+// search returns the first node whose values satisfy ok, or nil if there is none. Like sort.Search, ok must
+// be false for the values before some node and true from that node on.
+func (s *orderedSet[T]) search(ok func(v T) bool) *orderedNode[T] {
+	node := &s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for node.next[i] != nil && !ok(node.next[i].values[0]) {
+			node = node.next[i]
+		}
+	}
+	return node.next[0]
+}
+
+// walk calls visit with every value of the nodes from from up to, but excluding, to. A nil to walks to the
+// end of the set. Returns false when visit stopped the walk.
+func (s *orderedSet[T]) walk(from, to *orderedNode[T], visit func(v T) bool) bool {
+	for node := from; node != nil && node != to; node = node.next[0] {
+		for _, v := range node.values {
+			if !visit(v) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// size estimates the memory used by the set in bytes, given the size of a value.
+func (s *orderedSet[T]) size(valueSize int) int {
+	return 64 + 8*orderedMaxLevel + s.length*(48+valueSize) + 8*s.links
+}
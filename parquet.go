@@ -0,0 +1,1114 @@
+package mframe
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ParquetIDColumn is the name of the column holding the ID of the rows in Parquet files.
+const ParquetIDColumn = "_id"
+
+// parquetMagic starts and ends every Parquet file.
+const parquetMagic = "PAR1"
+
+// Physical types of Parquet columns.
+const (
+	parquetBoolean   int64 = 0
+	parquetInt32     int64 = 1
+	parquetInt64     int64 = 2
+	parquetInt96     int64 = 3
+	parquetFloat     int64 = 4
+	parquetDouble    int64 = 5
+	parquetByteArray int64 = 6
+)
+
+// Encodings, page types, compression codecs, repetitions and converted types of Parquet files.
+const (
+	parquetPlain                int64 = 0
+	parquetPlainDictionary      int64 = 2
+	parquetRLE                  int64 = 3
+	parquetBitPacked            int64 = 4
+	parquetDeltaBinaryPacked    int64 = 5
+	parquetDeltaLengthByteArray int64 = 6
+	parquetDeltaByteArray       int64 = 7
+	parquetRLEDictionary        int64 = 8
+	parquetByteStreamSplit      int64 = 9
+
+	parquetDataPage       int64 = 0
+	parquetIndexPage      int64 = 1
+	parquetDictionaryPage int64 = 2
+	parquetDataPageV2     int64 = 3
+
+	parquetUncompressed int64 = 0
+	parquetSnappy       int64 = 1
+	parquetGzip         int64 = 2
+
+	parquetRequired int64 = 0
+	parquetOptional int64 = 1
+
+	parquetUTF8            int64 = 0
+	parquetDate            int64 = 6
+	parquetTimestampMillis int64 = 9
+	parquetTimestampMicros int64 = 10
+)
+
+// parquetColumn is a column of an exported Parquet file.
+type parquetColumn struct {
+	name     string
+	keyType  KeyType
+	physical int64
+}
+
+// ExportToParquet exports the rows of the DataFrame to an uncompressed Parquet file, so expired data can be
+// queried with tools such as DuckDB or Spark. Every key becomes an optional column named after its dotted
+// path, so nested keys are flattened, and the ID of each row is kept in ParquetIDColumn. Strings are stored
+// as UTF-8 byte arrays, numbers as doubles, booleans as booleans and times as UTC timestamps with
+// microsecond precision.
+func (d *DataFrame) ExportToParquet(filename string) error {
+	return d.exportToParquet(filename, parquetUncompressed)
+}
+
+// ExportToParquetCompressed works like ExportToParquet, compressing the pages with gzip.
+func (d *DataFrame) ExportToParquetCompressed(filename string) error {
+	return d.exportToParquet(filename, parquetGzip)
+}
+
+// exportToParquet writes the rows to a Parquet file with a single row group, compressing the pages with codec.
+func (d *DataFrame) exportToParquet(filename string, codec int64) error {
+	d.Locker.RLock()
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for id := range d.Data {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	columns := []parquetColumn{{name: ParquetIDColumn, keyType: String, physical: parquetByteArray}}
+	stored := d.storedKeysUnlocked(ids)
+	keys := make([]KeyName, 0, len(stored))
+	for key := range stored {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for _, key := range keys {
+		if key == ParquetIDColumn {
+			continue
+		}
+		columns = append(columns, parquetColumn{name: string(key), keyType: stored[key], physical: parquetPhysicalType(stored[key])})
+	}
+
+	file := bytes.NewBufferString(parquetMagic)
+	chunks := make([]thriftWriter, 0, len(columns))
+	for _, column := range columns {
+		values := make([]interface{}, len(ids))
+		for i, id := range ids {
+			if column.name == ParquetIDColumn {
+				values[i] = id.String()
+				continue
+			}
+			values[i] = d.Data[id][KeyName(column.name)]
+		}
+
+		chunk, err := writeParquetChunk(file, column, values, codec)
+		if err != nil {
+			d.Locker.RUnlock()
+			return err
+		}
+		chunks = append(chunks, chunk)
+	}
+	d.Locker.RUnlock()
+
+	var footer thriftWriter
+	footer.begin()
+	footer.i32Field(1, 1)
+	footer.listField(2, thriftStruct, len(columns)+1)
+	footer.begin()
+	footer.binaryField(4, "schema")
+	footer.i32Field(5, int32(len(columns)))
+	footer.end()
+	for _, column := range columns {
+		writeParquetSchemaElement(&footer, column)
+	}
+	footer.i64Field(3, int64(len(ids)))
+	footer.listField(4, thriftStruct, 1)
+	footer.begin()
+	footer.listField(1, thriftStruct, len(chunks))
+	for _, chunk := range chunks {
+		footer.buf = append(footer.buf, chunk.buf...)
+	}
+	footer.i64Field(2, int64(file.Len()-len(parquetMagic)))
+	footer.i64Field(3, int64(len(ids)))
+	footer.end()
+	footer.binaryField(6, "mframe")
+	footer.end()
+
+	file.Write(footer.buf)
+	file.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(footer.buf))))
+	file.WriteString(parquetMagic)
+
+	return writeFileAtomically(filename, ".tmp-mframe-*.parquet", file.Bytes())
+}
+
+// writeParquetChunk appends a column chunk holding the values as a single data page to file and returns the
+// encoded ColumnChunk metadata. Nil values and values not matching the type of the column are stored as nulls.
+func writeParquetChunk(file *bytes.Buffer, column parquetColumn, values []interface{}, codec int64) (thriftWriter, error) {
+	present := make([]bool, len(values))
+	var plain []byte
+	var bits []bool
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			if column.keyType == String {
+				plain = binary.LittleEndian.AppendUint32(plain, uint32(len(v)))
+				plain = append(plain, v...)
+				present[i] = true
+			}
+		case float64:
+			if column.keyType == Numeric {
+				plain = binary.LittleEndian.AppendUint64(plain, math.Float64bits(v))
+				present[i] = true
+			}
+		case bool:
+			if column.keyType == Boolean {
+				bits = append(bits, v)
+				present[i] = true
+			}
+		case time.Time:
+			if column.keyType == Time {
+				plain = binary.LittleEndian.AppendUint64(plain, uint64(v.UnixMicro()))
+				present[i] = true
+			}
+		}
+	}
+	if column.keyType == Boolean {
+		plain = packBits(bits)
+	}
+
+	levels := encodeDefinitionLevels(present)
+	page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+	page = append(page, levels...)
+	page = append(page, plain...)
+
+	compressed := page
+	if codec == parquetGzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(page); err != nil {
+			return thriftWriter{}, fmt.Errorf("failed to compress column '%s': %w", column.name, err)
+		}
+		if err := gz.Close(); err != nil {
+			return thriftWriter{}, fmt.Errorf("failed to compress column '%s': %w", column.name, err)
+		}
+		compressed = buf.Bytes()
+	}
+
+	var header thriftWriter
+	header.begin()
+	header.i32Field(1, int32(parquetDataPage))
+	header.i32Field(2, int32(len(page)))
+	header.i32Field(3, int32(len(compressed)))
+	header.structField(5)
+	header.i32Field(1, int32(len(values)))
+	header.i32Field(2, int32(parquetPlain))
+	header.i32Field(3, int32(parquetRLE))
+	header.i32Field(4, int32(parquetRLE))
+	header.end()
+	header.end()
+
+	offset := int64(file.Len())
+	file.Write(header.buf)
+	file.Write(compressed)
+
+	var chunk thriftWriter
+	chunk.begin()
+	chunk.i64Field(2, offset)
+	chunk.structField(3)
+	chunk.i32Field(1, int32(column.physical))
+	chunk.listField(2, thriftI32, 2)
+	chunk.varint(parquetPlain)
+	chunk.varint(parquetRLE)
+	chunk.listField(3, thriftBinary, 1)
+	chunk.binary(column.name)
+	chunk.i32Field(4, int32(codec))
+	chunk.i64Field(5, int64(len(values)))
+	chunk.i64Field(6, int64(len(header.buf)+len(page)))
+	chunk.i64Field(7, int64(len(header.buf)+len(compressed)))
+	chunk.i64Field(9, offset)
+	chunk.end()
+	chunk.end()
+
+	return chunk, nil
+}
+
+// writeParquetSchemaElement writes the SchemaElement of a column.
+func writeParquetSchemaElement(w *thriftWriter, column parquetColumn) {
+	w.begin()
+	w.i32Field(1, int32(column.physical))
+	w.i32Field(3, int32(parquetOptional))
+	w.binaryField(4, column.name)
+	switch column.keyType {
+	case String:
+		w.i32Field(6, int32(parquetUTF8))
+		w.structField(10)
+		w.structField(1)
+		w.end()
+		w.end()
+	case Time:
+		w.i32Field(6, int32(parquetTimestampMicros))
+		w.structField(10)
+		w.structField(8)
+		w.boolField(1, true)
+		w.structField(2)
+		w.structField(2)
+		w.end()
+		w.end()
+		w.end()
+		w.end()
+	}
+	w.end()
+}
+
+// parquetPhysicalType returns the physical type storing the values of a key type.
+func parquetPhysicalType(keyType KeyType) int64 {
+	switch keyType {
+	case Numeric:
+		return parquetDouble
+	case Boolean:
+		return parquetBoolean
+	case Time:
+		return parquetInt64
+	default:
+		return parquetByteArray
+	}
+}
+
+// encodeDefinitionLevels encodes whether each value is present as runs of the RLE/bit-packing hybrid
+// encoding with a bit width of 1.
+func encodeDefinitionLevels(present []bool) []byte {
+	var out []byte
+	for i := 0; i < len(present); {
+		j := i
+		for j < len(present) && present[j] == present[i] {
+			j++
+		}
+		out = binary.AppendUvarint(out, uint64(j-i)<<1)
+		if present[i] {
+			out = append(out, 1)
+		} else {
+			out = append(out, 0)
+		}
+		i = j
+	}
+	return out
+}
+
+// packBits packs booleans into bytes, least significant bit first.
+func packBits(bits []bool) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
+// writeFileAtomically writes data to a temporary file in the directory of filename and renames it.
+func writeFileAtomically(filename, pattern string, data []byte) error {
+	dir := filepath.Dir(filename)
+	tmpFile, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write to temporary file: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// parquetSchemaColumn is a column read from the schema of a Parquet file.
+type parquetSchemaColumn struct {
+	name     string
+	physical int64
+	optional bool
+	convert  func(interface{}) interface{}
+}
+
+// ImportFromParquet inserts the rows of a Parquet file as new rows and returns the number of inserted rows.
+// Only flat schemas are supported, with data pages of version 1 or 2, uncompressed or compressed with Snappy
+// or gzip, using the plain, dictionary, RLE, delta or byte stream split encodings. Byte arrays are read as strings, integers and floating point numbers as numbers,
+// timestamps and dates as times, and null values are left out of the rows. A ParquetIDColumn holding UUIDs
+// sets the ID of the rows, replacing existing rows with the same ID.
+func (d *DataFrame) ImportFromParquet(filename string) (int, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	footerStart, err := parquetFooterStart(data)
+	if err != nil {
+		return 0, err
+	}
+
+	reader := thriftReader{data: data[:len(data)-8], pos: footerStart}
+	meta, err := reader.readStruct(0)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode Parquet metadata: %w", err)
+	}
+
+	columns, err := parquetSchemaColumns(meta.list(2))
+	if err != nil {
+		return 0, err
+	}
+
+	d.Locker.RLock()
+	newID := d.idGeneratorUnlocked()
+	d.Locker.RUnlock()
+
+	var entries []batchEntry
+	for _, element := range meta.list(4) {
+		group, _ := element.(thriftFields)
+		rows, _ := group.i64(3)
+		chunks := group.list(1)
+		if len(chunks) != len(columns) {
+			return 0, fmt.Errorf("row group has %d columns for %d in the schema", len(chunks), len(columns))
+		}
+
+		values := make([][]interface{}, len(columns))
+		for i, column := range columns {
+			chunk, _ := chunks[i].(thriftFields)
+			values[i], err = readParquetChunk(data[:footerStart], column, chunk.fields(3), int(rows))
+			if err != nil {
+				return 0, fmt.Errorf("failed to read column '%s': %w", column.name, err)
+			}
+		}
+
+		for r := 0; r < int(rows); r++ {
+			id := uuid.Nil
+			row := make(map[KeyName]interface{}, len(columns))
+			for i, column := range columns {
+				value := values[i][r]
+				if value == nil {
+					continue
+				}
+				if column.name == ParquetIDColumn {
+					if s, ok := value.(string); ok {
+						if parsed, err := uuid.Parse(s); err == nil {
+							id = parsed
+							continue
+						}
+					}
+				}
+				row[KeyName(column.name)] = value
+			}
+
+			if id == uuid.Nil {
+				id = newID()
+			}
+			entries = append(entries, batchEntry{id: id, data: row})
+		}
+	}
+
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	return d.insertEntriesWithOptions(entries, batchOptions{})
+}
+
+// parquetFooterStart checks the magic numbers of a Parquet file and returns the offset of its metadata.
+func parquetFooterStart(data []byte) (int, error) {
+	if len(data) < 2*len(parquetMagic)+4 ||
+		string(data[:len(parquetMagic)]) != parquetMagic ||
+		string(data[len(data)-len(parquetMagic):]) != parquetMagic {
+		return 0, fmt.Errorf("not a Parquet file")
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	start := len(data) - 8 - size
+	if size <= 0 || start < len(parquetMagic) {
+		return 0, fmt.Errorf("invalid Parquet metadata size %d", size)
+	}
+
+	return start, nil
+}
+
+// parquetSchemaColumns returns the columns of a flat schema.
+func parquetSchemaColumns(schema []interface{}) ([]parquetSchemaColumn, error) {
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("missing Parquet schema")
+	}
+
+	columns := make([]parquetSchemaColumn, 0, len(schema)-1)
+	for _, element := range schema[1:] {
+		fields, _ := element.(thriftFields)
+		name := fields.str(4)
+
+		if children, _ := fields.i64(5); children > 0 {
+			return nil, fmt.Errorf("nested Parquet column '%s' is not supported", name)
+		}
+
+		repetition, _ := fields.i64(3)
+		if repetition != parquetRequired && repetition != parquetOptional {
+			return nil, fmt.Errorf("repeated Parquet column '%s' is not supported", name)
+		}
+
+		physical, _ := fields.i64(1)
+		convert, err := parquetConverter(physical, fields)
+		if err != nil {
+			return nil, fmt.Errorf("column '%s': %w", name, err)
+		}
+
+		columns = append(columns, parquetSchemaColumn{
+			name:     name,
+			physical: physical,
+			optional: repetition == parquetOptional,
+			convert:  convert,
+		})
+	}
+
+	return columns, nil
+}
+
+// parquetConverter returns the function converting the plain values of a column to values of the DataFrame,
+// following its converted and logical types.
+func parquetConverter(physical int64, element thriftFields) (func(interface{}) interface{}, error) {
+	converted, hasConverted := element.i64(6)
+	logical := element.fields(10)
+
+	switch physical {
+	case parquetBoolean, parquetByteArray, parquetFloat, parquetDouble:
+		return func(v interface{}) interface{} { return v }, nil
+	case parquetInt32:
+		if (hasConverted && converted == parquetDate) || logical.fields(6) != nil {
+			return func(v interface{}) interface{} {
+				return time.Unix(int64(v.(float64))*86400, 0).UTC()
+			}, nil
+		}
+		return func(v interface{}) interface{} { return v }, nil
+	case parquetInt64:
+		var toTime func(int64) time.Time
+		switch {
+		case logical.fields(8) != nil:
+			switch units := logical.fields(8).fields(2); {
+			case units.fields(1) != nil:
+				toTime = time.UnixMilli
+			case units.fields(2) != nil:
+				toTime = time.UnixMicro
+			case units.fields(3) != nil:
+				toTime = func(v int64) time.Time { return time.Unix(0, v) }
+			}
+		case hasConverted && converted == parquetTimestampMillis:
+			toTime = time.UnixMilli
+		case hasConverted && converted == parquetTimestampMicros:
+			toTime = time.UnixMicro
+		}
+		if toTime == nil {
+			return func(v interface{}) interface{} { return float64(v.(int64)) }, nil
+		}
+		return func(v interface{}) interface{} { return toTime(v.(int64)).UTC() }, nil
+	default:
+		return nil, fmt.Errorf("unsupported Parquet type %d", physical)
+	}
+}
+
+// readParquetChunk reads the values of a column chunk, nil for nulls.
+func readParquetChunk(data []byte, column parquetSchemaColumn, meta thriftFields, rows int) ([]interface{}, error) {
+	if meta == nil {
+		return nil, fmt.Errorf("missing column metadata")
+	}
+
+	codec, _ := meta.i64(4)
+	if codec != parquetUncompressed && codec != parquetSnappy && codec != parquetGzip {
+		return nil, fmt.Errorf("unsupported compression codec %d", codec)
+	}
+
+	pos, _ := meta.i64(9)
+	if dictionary, ok := meta.i64(11); ok && dictionary > 0 && dictionary < pos {
+		pos = dictionary
+	}
+
+	var dictionary []interface{}
+	values := make([]interface{}, 0, rows)
+	for len(values) < rows {
+		if pos < 0 || pos >= int64(len(data)) {
+			return nil, fmt.Errorf("page offset %d out of bounds", pos)
+		}
+
+		reader := thriftReader{data: data, pos: int(pos)}
+		header, err := reader.readStruct(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode page header: %w", err)
+		}
+
+		size, _ := header.i64(3)
+		if size < 0 || int64(reader.pos)+size > int64(len(data)) {
+			return nil, fmt.Errorf("page size %d out of bounds", size)
+		}
+		page := data[reader.pos : int64(reader.pos)+size]
+		pos = int64(reader.pos) + size
+
+		switch kind, _ := header.i64(1); kind {
+		case parquetDictionaryPage:
+			if page, err = decompressParquetPage(page, codec); err != nil {
+				return nil, err
+			}
+			count, _ := header.fields(7).i64(1)
+			dictionary, _, err = decodeParquetPlain(page, column.physical, int(count))
+			if err != nil {
+				return nil, err
+			}
+		case parquetDataPage:
+			if page, err = decompressParquetPage(page, codec); err != nil {
+				return nil, err
+			}
+			values, err = readParquetDataPage(values, page, column, header.fields(5), dictionary, rows)
+			if err != nil {
+				return nil, err
+			}
+		case parquetDataPageV2:
+			values, err = readParquetDataPageV2(values, page, codec, column, header.fields(8), dictionary, rows)
+			if err != nil {
+				return nil, err
+			}
+		case parquetIndexPage:
+		default:
+			return nil, fmt.Errorf("unsupported page type %d", kind)
+		}
+	}
+
+	if len(values) != rows {
+		return nil, fmt.Errorf("%d values for %d rows", len(values), rows)
+	}
+
+	for i, value := range values {
+		if value != nil {
+			values[i] = column.convert(value)
+		}
+	}
+
+	return values, nil
+}
+
+// decompressParquetPage decompresses a page, or the values of a version 2 data page, with the codec of its column.
+func decompressParquetPage(page []byte, codec int64) ([]byte, error) {
+	switch codec {
+	case parquetSnappy:
+		decoded, err := decodeSnappy(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress page: %w", err)
+		}
+		return decoded, nil
+	case parquetGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(page))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress page: %w", err)
+		}
+		decoded, err := io.ReadAll(gz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress page: %w", err)
+		}
+		return decoded, nil
+	default:
+		return page, nil
+	}
+}
+
+// readParquetDataPage decodes a data page (version 1) and appends its values to values, nil for nulls, up to
+// rows values. The definition levels of optional columns precede the values, prefixed by their length when
+// encoded with the RLE/bit-packing hybrid encoding.
+func readParquetDataPage(values []interface{}, page []byte, column parquetSchemaColumn, header thriftFields, dictionary []interface{}, rows int) ([]interface{}, error) {
+	if header == nil {
+		return nil, fmt.Errorf("missing data page header")
+	}
+
+	count, _ := header.i64(1)
+	if count <= 0 || count > int64(rows-len(values)) {
+		return nil, fmt.Errorf("invalid number of values %d", count)
+	}
+
+	var present []bool
+	if column.optional {
+		var levels []int
+		switch encoding, _ := header.i64(3); encoding {
+		case parquetRLE:
+			if len(page) < 4 {
+				return nil, fmt.Errorf("truncated definition levels")
+			}
+			size := int(binary.LittleEndian.Uint32(page))
+			if size == 0 && len(page) >= 8 {
+				// Some writers, such as parquet-go, prefix the definition levels of flat columns with an empty
+				// section of repetition levels. Definition levels of a page holding values are never empty.
+				page = page[4:]
+				size = int(binary.LittleEndian.Uint32(page))
+			}
+			if size < 0 || size > len(page)-4 {
+				return nil, fmt.Errorf("truncated definition levels")
+			}
+			var err error
+			if levels, err = decodeRLEHybrid(page[4:4+size], 1, int(count)); err != nil {
+				return nil, fmt.Errorf("invalid definition levels: %w", err)
+			}
+			page = page[4+size:]
+		case parquetBitPacked:
+			size := (int(count) + 7) / 8
+			if size > len(page) {
+				return nil, fmt.Errorf("truncated definition levels")
+			}
+			levels = make([]int, count)
+			for i := range levels {
+				levels[i] = int(page[i/8]>>(7-i%8)) & 1
+			}
+			page = page[size:]
+		default:
+			return nil, fmt.Errorf("unsupported definition level encoding %d", encoding)
+		}
+		present = definedValues(levels)
+	}
+
+	encoding, _ := header.i64(2)
+	return appendParquetValues(values, page, column, encoding, present, int(count), dictionary)
+}
+
+// readParquetDataPageV2 decodes a data page (version 2) and appends its values to values, nil for nulls, up to
+// rows values. Its definition levels are never compressed nor prefixed by their length, and only the values
+// that follow them are compressed, unless the page says otherwise.
+func readParquetDataPageV2(values []interface{}, page []byte, codec int64, column parquetSchemaColumn, header thriftFields, dictionary []interface{}, rows int) ([]interface{}, error) {
+	if header == nil {
+		return nil, fmt.Errorf("missing data page header")
+	}
+
+	count, _ := header.i64(1)
+	if count <= 0 || count > int64(rows-len(values)) {
+		return nil, fmt.Errorf("invalid number of values %d", count)
+	}
+
+	definitions, _ := header.i64(5)
+	repetitions, _ := header.i64(6)
+	if definitions < 0 || repetitions < 0 || definitions+repetitions > int64(len(page)) {
+		return nil, fmt.Errorf("truncated levels")
+	}
+	if repetitions > 0 {
+		return nil, fmt.Errorf("repeated values are not supported")
+	}
+
+	var present []bool
+	if column.optional {
+		levels, err := decodeRLEHybrid(page[:definitions], 1, int(count))
+		if err != nil {
+			return nil, fmt.Errorf("invalid definition levels: %w", err)
+		}
+		present = definedValues(levels)
+	}
+
+	page = page[definitions:]
+	if compressed, ok := header[7].(bool); !ok || compressed {
+		var err error
+		if page, err = decompressParquetPage(page, codec); err != nil {
+			return nil, err
+		}
+	}
+
+	encoding, _ := header.i64(4)
+	return appendParquetValues(values, page, column, encoding, present, int(count), dictionary)
+}
+
+// definedValues returns whether each value of a flat column is defined, from its definition levels.
+func definedValues(levels []int) []bool {
+	present := make([]bool, len(levels))
+	for i, level := range levels {
+		present[i] = level == 1
+	}
+	return present
+}
+
+// appendParquetValues decodes the values of a data page and appends them to values, with nil for the count
+// values not present. A nil present means every value is.
+func appendParquetValues(values []interface{}, page []byte, column parquetSchemaColumn, encoding int64, present []bool, count int, dictionary []interface{}) ([]interface{}, error) {
+	nonNull := count
+	if present != nil {
+		nonNull = 0
+		for _, ok := range present {
+			if ok {
+				nonNull++
+			}
+		}
+	}
+
+	decoded, err := decodeParquetValues(page, column.physical, encoding, nonNull, dictionary)
+	if err != nil {
+		return nil, err
+	}
+
+	if present == nil {
+		return append(values, decoded...), nil
+	}
+
+	next := 0
+	for _, ok := range present {
+		if !ok {
+			values = append(values, nil)
+			continue
+		}
+		values = append(values, decoded[next])
+		next++
+	}
+
+	return values, nil
+}
+
+// decodeParquetValues decodes count values of a physical type with the given encoding.
+func decodeParquetValues(page []byte, physical, encoding int64, count int, dictionary []interface{}) ([]interface{}, error) {
+	switch encoding {
+	case parquetPlain:
+		decoded, _, err := decodeParquetPlain(page, physical, count)
+		return decoded, err
+	case parquetPlainDictionary, parquetRLEDictionary:
+		if len(page) == 0 {
+			if count == 0 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("missing dictionary bit width")
+		}
+		indexes, err := decodeRLEHybrid(page[1:], int(page[0]), count)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dictionary indexes: %w", err)
+		}
+		decoded := make([]interface{}, count)
+		for i, index := range indexes {
+			if index >= len(dictionary) {
+				return nil, fmt.Errorf("dictionary index %d out of bounds", index)
+			}
+			decoded[i] = dictionary[index]
+		}
+		return decoded, nil
+	case parquetRLE:
+		if physical != parquetBoolean {
+			return nil, fmt.Errorf("RLE encoding of Parquet type %d is not supported", physical)
+		}
+		if len(page) < 4 {
+			return nil, fmt.Errorf("truncated boolean values")
+		}
+		size := int(binary.LittleEndian.Uint32(page))
+		if size < 0 || size > len(page)-4 {
+			return nil, fmt.Errorf("truncated boolean values")
+		}
+		bits, err := decodeRLEHybrid(page[4:4+size], 1, count)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean values: %w", err)
+		}
+		decoded := make([]interface{}, count)
+		for i, bit := range bits {
+			decoded[i] = bit == 1
+		}
+		return decoded, nil
+	case parquetDeltaBinaryPacked:
+		deltas, _, err := decodeDeltaBinaryPacked(page, count)
+		if err != nil {
+			return nil, err
+		}
+		decoded := make([]interface{}, count)
+		for i, v := range deltas {
+			switch physical {
+			case parquetInt32:
+				decoded[i] = float64(int32(v))
+			case parquetInt64:
+				decoded[i] = v
+			default:
+				return nil, fmt.Errorf("delta encoding of Parquet type %d is not supported", physical)
+			}
+		}
+		return decoded, nil
+	case parquetDeltaLengthByteArray, parquetDeltaByteArray:
+		if physical != parquetByteArray {
+			return nil, fmt.Errorf("delta encoding of Parquet type %d is not supported", physical)
+		}
+		var prefixes []int64
+		if encoding == parquetDeltaByteArray {
+			var n int
+			var err error
+			if prefixes, n, err = decodeDeltaBinaryPacked(page, count); err != nil {
+				return nil, err
+			}
+			page = page[n:]
+		}
+		lengths, n, err := decodeDeltaBinaryPacked(page, count)
+		if err != nil {
+			return nil, err
+		}
+		page = page[n:]
+
+		decoded := make([]interface{}, count)
+		previous := ""
+		for i, length := range lengths {
+			if length < 0 || length > int64(len(page)) {
+				return nil, fmt.Errorf("truncated byte array values")
+			}
+			value := string(page[:length])
+			page = page[length:]
+			if prefixes != nil {
+				if prefixes[i] < 0 || prefixes[i] > int64(len(previous)) {
+					return nil, fmt.Errorf("invalid byte array prefix length %d", prefixes[i])
+				}
+				value = previous[:prefixes[i]] + value
+			}
+			decoded[i] = value
+			previous = value
+		}
+		return decoded, nil
+	case parquetByteStreamSplit:
+		var width int
+		switch physical {
+		case parquetInt32, parquetFloat:
+			width = 4
+		case parquetInt64, parquetDouble:
+			width = 8
+		default:
+			return nil, fmt.Errorf("byte stream split encoding of Parquet type %d is not supported", physical)
+		}
+		if len(page) < count*width {
+			return nil, fmt.Errorf("truncated byte stream split values")
+		}
+		plain := make([]byte, count*width)
+		for i := 0; i < count; i++ {
+			for b := 0; b < width; b++ {
+				plain[i*width+b] = page[b*count+i]
+			}
+		}
+		decoded, _, err := decodeParquetPlain(plain, physical, count)
+		return decoded, err
+	default:
+		return nil, fmt.Errorf("unsupported encoding %d", encoding)
+	}
+}
+
+// decodeParquetPlain decodes count plain-encoded values of a physical type and returns the bytes consumed.
+// Integers and floating point numbers are returned as float64, except 64-bit integers which are returned
+// as int64 to be converted by the column.
+func decodeParquetPlain(data []byte, physical int64, count int) ([]interface{}, int, error) {
+	values := make([]interface{}, 0, count)
+	pos := 0
+	fixed := func(size int) ([]byte, error) {
+		if pos+size > len(data) {
+			return nil, fmt.Errorf("truncated plain values")
+		}
+		b := data[pos : pos+size]
+		pos += size
+		return b, nil
+	}
+
+	for i := 0; i < count; i++ {
+		switch physical {
+		case parquetBoolean:
+			if i/8 >= len(data) {
+				return nil, 0, fmt.Errorf("truncated plain values")
+			}
+			values = append(values, data[i/8]&(1<<(i%8)) != 0)
+			pos = i/8 + 1
+		case parquetInt32:
+			b, err := fixed(4)
+			if err != nil {
+				return nil, 0, err
+			}
+			values = append(values, float64(int32(binary.LittleEndian.Uint32(b))))
+		case parquetInt64:
+			b, err := fixed(8)
+			if err != nil {
+				return nil, 0, err
+			}
+			values = append(values, int64(binary.LittleEndian.Uint64(b)))
+		case parquetFloat:
+			b, err := fixed(4)
+			if err != nil {
+				return nil, 0, err
+			}
+			values = append(values, float64(math.Float32frombits(binary.LittleEndian.Uint32(b))))
+		case parquetDouble:
+			b, err := fixed(8)
+			if err != nil {
+				return nil, 0, err
+			}
+			values = append(values, math.Float64frombits(binary.LittleEndian.Uint64(b)))
+		case parquetByteArray:
+			b, err := fixed(4)
+			if err != nil {
+				return nil, 0, err
+			}
+			if b, err = fixed(int(binary.LittleEndian.Uint32(b))); err != nil {
+				return nil, 0, err
+			}
+			values = append(values, string(b))
+		default:
+			return nil, 0, fmt.Errorf("unsupported Parquet type %d", physical)
+		}
+	}
+
+	return values, pos, nil
+}
+
+// decodeRLEHybrid decodes count values of the RLE/bit-packing hybrid encoding.
+func decodeRLEHybrid(data []byte, bitWidth, count int) ([]int, error) {
+	if bitWidth < 0 || bitWidth > 32 {
+		return nil, fmt.Errorf("invalid bit width %d", bitWidth)
+	}
+
+	values := make([]int, 0, count)
+	pos := 0
+	for len(values) < count {
+		header, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return nil, fmt.Errorf("truncated run header")
+		}
+		pos += n
+
+		if header&1 == 0 {
+			run := int(header >> 1)
+			width := (bitWidth + 7) / 8
+			if pos+width > len(data) || run > count-len(values) {
+				return nil, fmt.Errorf("invalid run")
+			}
+			value := 0
+			for i := 0; i < width; i++ {
+				value |= int(data[pos+i]) << (8 * i)
+			}
+			pos += width
+			for i := 0; i < run; i++ {
+				values = append(values, value)
+			}
+			continue
+		}
+
+		groups := int(header >> 1)
+		size := groups * bitWidth
+		if size > len(data)-pos {
+			return nil, fmt.Errorf("truncated bit-packed run")
+		}
+		unpacked := unpackBits(data[pos:pos+size], bitWidth, min(groups*8, count-len(values)))
+		pos += size
+		for _, value := range unpacked {
+			values = append(values, int(value))
+		}
+	}
+
+	return values, nil
+}
+
+// unpackBits reads count values of bitWidth bits packed least significant bit first.
+func unpackBits(packed []byte, bitWidth, count int) []uint64 {
+	values := make([]uint64, count)
+	for i := range values {
+		var value uint64
+		for b := 0; b < bitWidth; b++ {
+			bit := i*bitWidth + b
+			if packed[bit/8]&(1<<(bit%8)) != 0 {
+				value |= 1 << b
+			}
+		}
+		values[i] = value
+	}
+	return values
+}
+
+// decodeDeltaBinaryPacked decodes count integers of the DELTA_BINARY_PACKED encoding and returns the bytes
+// consumed, which are followed by the byte arrays of the delta byte array encodings.
+func decodeDeltaBinaryPacked(data []byte, count int) ([]int64, int, error) {
+	pos := 0
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return 0, fmt.Errorf("truncated delta header")
+		}
+		pos += n
+		return v, nil
+	}
+	varint := func() (int64, error) {
+		v, n := binary.Varint(data[pos:])
+		if n <= 0 {
+			return 0, fmt.Errorf("truncated delta header")
+		}
+		pos += n
+		return v, nil
+	}
+
+	blockSize, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	miniblocks, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := uvarint()
+	if err != nil {
+		return nil, 0, err
+	}
+	value, err := varint()
+	if err != nil {
+		return nil, 0, err
+	}
+	if miniblocks == 0 || blockSize == 0 || blockSize%miniblocks != 0 || blockSize/miniblocks%8 != 0 || total < uint64(count) {
+		return nil, 0, fmt.Errorf("invalid delta header")
+	}
+	perMiniblock := int(blockSize / miniblocks)
+
+	values := make([]int64, 0, count)
+	if count > 0 {
+		values = append(values, value)
+	}
+	for len(values) < int(total) {
+		minDelta, err := varint()
+		if err != nil {
+			return nil, 0, err
+		}
+		if pos+int(miniblocks) > len(data) {
+			return nil, 0, fmt.Errorf("truncated delta block")
+		}
+		widths := data[pos : pos+int(miniblocks)]
+		pos += int(miniblocks)
+
+		for _, width := range widths {
+			if len(values) >= int(total) {
+				break
+			}
+			if width > 64 {
+				return nil, 0, fmt.Errorf("invalid delta bit width %d", width)
+			}
+			size := perMiniblock * int(width) / 8
+			if size > len(data)-pos {
+				return nil, 0, fmt.Errorf("truncated delta miniblock")
+			}
+			for _, delta := range unpackBits(data[pos:pos+size], int(width), perMiniblock) {
+				if len(values) >= int(total) {
+					break
+				}
+				value += minDelta + int64(delta)
+				values = append(values, value)
+			}
+			pos += size
+		}
+	}
+
+	return values[:count], pos, nil
+}
@@ -0,0 +1,180 @@
+package mframe_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestExportImportParquet(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "bytes": 1.5, "blocked": true, "seen": seen, "geo": map[string]interface{}{"city": "Lisbon"}},
+		{"host": "web-2", "bytes": 100, "blocked": false},
+		{"user": "alice"},
+	})
+
+	exports := map[string]func(string) error{
+		"frame.parquet":    cache.ExportToParquet,
+		"frame.gz.parquet": cache.ExportToParquetCompressed,
+	}
+
+	dir := t.TempDir()
+	for name, export := range exports {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(dir, name)
+			if err := export(filename); err != nil {
+				t.Fatalf("expected no error, but got %v", err)
+			}
+
+			content, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(content, []byte("PAR1")) || !bytes.HasSuffix(content, []byte("PAR1")) {
+				t.Error("expected the Parquet magic numbers")
+			}
+
+			var imported mframe.DataFrame
+			imported.Init(24 * time.Hour)
+			n, err := imported.ImportFromParquet(filename)
+			if err != nil {
+				t.Fatalf("expected no error, but got %v", err)
+			}
+			if n != 3 {
+				t.Fatalf("expected 3 imported rows, but got %d", n)
+			}
+
+			for id, row := range cache.Data {
+				copied, ok := imported.Data[id]
+				if !ok {
+					t.Fatalf("expected row %s to keep its ID", id)
+				}
+				if len(copied) != len(row) {
+					t.Errorf("expected %v, but got %v", row, copied)
+				}
+				for key, value := range row {
+					if copied[key] != value {
+						t.Errorf("expected %s to be %v, but got %v", key, value, copied[key])
+					}
+				}
+			}
+
+			if imported.Keys["geo.city"] != mframe.String || imported.Keys["seen"] != mframe.Time {
+				t.Errorf("expected nested keys to be flattened and times to be typed, but got %v", imported.Keys)
+			}
+		})
+	}
+}
+
+// TestImportParquetFromOtherWriters imports files written by parquet-go, with version 1 pages and optional
+// columns, and with version 2 pages compressed with Snappy.
+func TestImportParquetFromOtherWriters(t *testing.T) {
+	seen := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	expected := make(map[float64]map[mframe.KeyName]interface{})
+	for i := 0; i < 10; i++ {
+		row := map[mframe.KeyName]interface{}{
+			"host":   fmt.Sprintf("web-%d", i%3),
+			"bytes":  float64(100 * i),
+			"port":   float64(80 + i%2),
+			"seen":   seen.Add(time.Duration(i) * time.Minute),
+			"action": []string{"login", "logout"}[i%2],
+		}
+		switch i % 3 {
+		case 0:
+			row["user"], row["score"], row["blocked"] = "alice", 9.5, true
+		case 1:
+			row["user"], row["blocked"] = "bob", false
+		case 2:
+			row["score"] = 0.25
+		}
+		expected[float64(100*i)] = row
+	}
+
+	for _, name := range []string{"parquet-go-v1.parquet", "parquet-go-v2-snappy.parquet"} {
+		t.Run(name, func(t *testing.T) {
+			var imported mframe.DataFrame
+			imported.Init(24 * time.Hour)
+			n, err := imported.ImportFromParquet(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatalf("expected no error, but got %v", err)
+			}
+			if n != len(expected) {
+				t.Fatalf("expected %d imported rows, but got %d", len(expected), n)
+			}
+
+			for _, row := range imported.Data {
+				want, ok := expected[row["bytes"].(float64)]
+				if !ok {
+					t.Fatalf("unexpected row %v", row)
+				}
+				if len(row) != len(want) {
+					t.Errorf("expected %v, but got %v", want, row)
+				}
+				for key, value := range want {
+					if got := row[key]; got != value {
+						if when, ok := got.(time.Time); !ok || !when.Equal(value.(time.Time)) {
+							t.Errorf("expected %s to be %v, but got %v", key, value, got)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestImportParquetErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+
+	valid := filepath.Join(dir, "valid.parquet")
+	if err := cache.ExportToParquet(valid); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(valid)
+
+	truncated := append([]byte{}, content[:len(content)-12]...)
+	truncated = append(truncated, content[len(content)-8:]...)
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"empty", nil},
+		{"not parquet", []byte("host,bytes\nweb-1,100\n")},
+		{"truncated footer", truncated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, "invalid.parquet")
+			if err := os.WriteFile(filename, tt.content, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var imported mframe.DataFrame
+			imported.Init(24 * time.Hour)
+			if _, err := imported.ImportFromParquet(filename); err == nil {
+				t.Error("expected an error")
+			}
+			if imported.Count() != 0 {
+				t.Errorf("expected no rows, but got %d", imported.Count())
+			}
+		})
+	}
+
+	if _, err := cache.ImportFromParquet(filepath.Join(dir, "missing.parquet")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -34,26 +34,37 @@ type persistentDataFrame struct {
 	TTL           time.Duration
 	MaxRegexCache int
 	RegexPatterns []string // Store patterns to recompile after load
+	Compact       bool     // Indexes were left out and are rebuilt from Data on load
 }
 
-// SaveToFile saves the DataFrame to a file using gob encoding.
-// It performs an atomic write by first writing to a temporary file and then renaming it.
-func (d *DataFrame) SaveToFile(filename string) error {
-	d.Locker.RLock()
-	defer d.Locker.RUnlock()
+// SetCompactPersistence enables or disables leaving the Strings, Numerics, Booleans and Times indexes out
+// of the files written by SaveToFile, SaveToFileCompressed and SaveToWriter. Compact files are much smaller
+// and faster to save, and their indexes are rebuilt from the rows on load. Files of both kinds can be loaded
+// whatever the setting.
+func (d *DataFrame) SetCompactPersistence(enabled bool) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
 
-	// Create a persistable version of the DataFrame
+	d.compactPersistence = enabled
+}
+
+// persistentUnlocked returns the persistable version of the DataFrame, without acquiring the DataFrame lock.
+func (d *DataFrame) persistentUnlocked() *persistentDataFrame {
 	pdf := &persistentDataFrame{
 		Version:       d.Version,
 		Data:          d.Data,
 		Keys:          d.Keys,
-		Strings:       d.Strings,
-		Numerics:      d.Numerics,
-		Booleans:      d.Booleans,
-		Times:         d.Times,
 		ExpireAt:      d.ExpireAt,
 		TTL:           d.TTL,
 		MaxRegexCache: d.maxRegexCache,
+		Compact:       d.compactPersistence,
+	}
+
+	if !pdf.Compact {
+		pdf.Strings = d.Strings
+		pdf.Numerics = d.Numerics
+		pdf.Booleans = d.Booleans
+		pdf.Times = d.Times
 	}
 
 	// Extract regex patterns
@@ -64,6 +75,129 @@ func (d *DataFrame) SaveToFile(filename string) error {
 	}
 	d.regexMutex.RUnlock()
 
+	return pdf
+}
+
+// restorePersistentUnlocked replaces the contents of the DataFrame with a decoded persistable version,
+// rebuilding the indexes of compact files, without acquiring the DataFrame lock.
+func (d *DataFrame) restorePersistentUnlocked(pdf *persistentDataFrame) error {
+	// Validate version
+	if pdf.Version > d.Version {
+		return fmt.Errorf("unsupported file version %d (current version is %d)", pdf.Version, d.Version)
+	}
+
+	// Clear existing data
+	d.Data = pdf.Data
+	d.Keys = pdf.Keys
+	d.Strings = pdf.Strings
+	d.Numerics = pdf.Numerics
+	d.Booleans = pdf.Booleans
+	d.Times = pdf.Times
+	d.ExpireAt = pdf.ExpireAt
+	d.TTL = pdf.TTL
+	d.maxRegexCache = pdf.MaxRegexCache
+
+	// Gob leaves empty maps out, so every map may be nil
+	if d.Data == nil {
+		d.Data = make(map[uuid.UUID]Row)
+	}
+	if d.Keys == nil {
+		d.Keys = make(KeysIndex)
+	}
+	if d.ExpireAt == nil {
+		d.ExpireAt = make(ExpireAtIndex)
+	}
+	if pdf.Compact {
+		d.rebuildIndexesUnlocked()
+	}
+	if d.Strings == nil {
+		d.Strings = make(StringsIndex)
+	}
+	if d.Numerics == nil {
+		d.Numerics = make(NumericsIndex)
+	}
+	if d.Booleans == nil {
+		d.Booleans = make(BooleansIndex)
+	}
+	if d.Times == nil {
+		d.Times = make(TimesIndex)
+	}
+
+	// Re-initialize non-serializable fields
+	d.regexCache = make(map[string]*regexp.Regexp)
+	d.regexCacheSize = 0
+	d.regexMutex = sync.RWMutex{}
+
+	// Recompile regex patterns
+	for _, pattern := range pdf.RegexPatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			d.regexCache[pattern] = re
+			d.regexCacheSize++
+			if d.regexCacheSize >= d.maxRegexCache {
+				break
+			}
+		}
+	}
+
+	// Rebuild the structures derived from the loaded rows
+	d.resetDerivedUnlocked()
+
+	return nil
+}
+
+// rebuildIndexesUnlocked rebuilds the Strings, Numerics, Booleans and Times indexes from the rows,
+// without acquiring locks. Rows are stored already flattened and typed, so the values are indexed as they are.
+func (d *DataFrame) rebuildIndexesUnlocked() {
+	d.Strings = make(StringsIndex)
+	d.Numerics = make(NumericsIndex)
+	d.Booleans = make(BooleansIndex)
+	d.Times = make(TimesIndex)
+
+	for id, row := range d.Data {
+		for key, value := range row {
+			if d.notIndexedUnlocked(key) {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				indexValue(d.Strings, key, v, id)
+			case float64:
+				indexValue(d.Numerics, key, v, id)
+			case bool:
+				indexValue(d.Booleans, key, v, id)
+			case time.Time:
+				indexValue(d.Times, key, v, id)
+			}
+		}
+	}
+}
+
+// indexValue adds the ID to the entry of the value in a typed index.
+func indexValue[T comparable](index map[KeyName]map[T]map[uuid.UUID]bool, key KeyName, value T, id uuid.UUID) {
+	values, ok := index[key]
+	if !ok {
+		values = make(map[T]map[uuid.UUID]bool)
+		index[key] = values
+	}
+
+	ids, ok := values[value]
+	if !ok {
+		ids = make(map[uuid.UUID]bool)
+		values[value] = ids
+	}
+
+	ids[id] = true
+}
+
+// SaveToFile saves the DataFrame to a file using gob encoding.
+// It performs an atomic write by first writing to a temporary file and then renaming it.
+func (d *DataFrame) SaveToFile(filename string) error {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	// Create a persistable version of the DataFrame
+	pdf := d.persistentUnlocked()
+
 	// Create temporary file in the same directory for atomic write
 	dir := filepath.Dir(filename)
 	tmpFile, err := os.CreateTemp(dir, ".tmp-mframe-*.gob")
@@ -101,12 +235,9 @@ func (d *DataFrame) LoadFromFile(filename string) error {
 	}
 	defer func() { _ = file.Close() }()
 
-	// Stop the cleaner if it's running
-	wasCleanerRunning := false
-	select {
-	case d.stopCleaner <- true:
-		wasCleanerRunning = true
-	default:
+	// Stop the cleaner while loading, restarting it once the lock is released
+	if d.cleaner.stop() {
+		defer d.StartCleaner()
 	}
 
 	d.Locker.Lock()
@@ -119,45 +250,7 @@ func (d *DataFrame) LoadFromFile(filename string) error {
 		return fmt.Errorf("failed to decode dataframe: %w", err)
 	}
 
-	// Validate version
-	if pdf.Version > d.Version {
-		return fmt.Errorf("unsupported file version %d (current version is %d)", pdf.Version, d.Version)
-	}
-
-	// Clear existing data
-	d.Data = pdf.Data
-	d.Keys = pdf.Keys
-	d.Strings = pdf.Strings
-	d.Numerics = pdf.Numerics
-	d.Booleans = pdf.Booleans
-	d.Times = pdf.Times
-	d.ExpireAt = pdf.ExpireAt
-	d.TTL = pdf.TTL
-	d.maxRegexCache = pdf.MaxRegexCache
-
-	// Re-initialize non-serializable fields
-	d.regexCache = make(map[string]*regexp.Regexp)
-	d.regexCacheSize = 0
-	d.regexMutex = sync.RWMutex{}
-	d.stopCleaner = make(chan bool)
-
-	// Recompile regex patterns
-	for _, pattern := range pdf.RegexPatterns {
-		if re, err := regexp.Compile(pattern); err == nil {
-			d.regexCache[pattern] = re
-			d.regexCacheSize++
-			if d.regexCacheSize >= d.maxRegexCache {
-				break
-			}
-		}
-	}
-
-	// Restart cleaner if it was running
-	if wasCleanerRunning {
-		go d.CleanExpired()
-	}
-
-	return nil
+	return d.restorePersistentUnlocked(&pdf)
 }
 
 // SaveToFileCompressed saves the DataFrame to a gzip-compressed file.
@@ -166,26 +259,7 @@ func (d *DataFrame) SaveToFileCompressed(filename string) error {
 	defer d.Locker.RUnlock()
 
 	// Create a persistable version of the DataFrame
-	pdf := &persistentDataFrame{
-		Version:       d.Version,
-		Data:          d.Data,
-		Keys:          d.Keys,
-		Strings:       d.Strings,
-		Numerics:      d.Numerics,
-		Booleans:      d.Booleans,
-		Times:         d.Times,
-		ExpireAt:      d.ExpireAt,
-		TTL:           d.TTL,
-		MaxRegexCache: d.maxRegexCache,
-	}
-
-	// Extract regex patterns
-	d.regexMutex.RLock()
-	pdf.RegexPatterns = make([]string, 0, len(d.regexCache))
-	for pattern := range d.regexCache {
-		pdf.RegexPatterns = append(pdf.RegexPatterns, pattern)
-	}
-	d.regexMutex.RUnlock()
+	pdf := d.persistentUnlocked()
 
 	// Create temporary file in the same directory for atomic write
 	dir := filepath.Dir(filename)
@@ -241,12 +315,9 @@ func (d *DataFrame) LoadFromFileCompressed(filename string) error {
 	}
 	defer func() { _ = gzReader.Close() }()
 
-	// Stop the cleaner if it's running
-	wasCleanerRunning := false
-	select {
-	case d.stopCleaner <- true:
-		wasCleanerRunning = true
-	default:
+	// Stop the cleaner while loading, restarting it once the lock is released
+	if d.cleaner.stop() {
+		defer d.StartCleaner()
 	}
 
 	d.Locker.Lock()
@@ -259,45 +330,7 @@ func (d *DataFrame) LoadFromFileCompressed(filename string) error {
 		return fmt.Errorf("failed to decode dataframe: %w", err)
 	}
 
-	// Validate version
-	if pdf.Version > d.Version {
-		return fmt.Errorf("unsupported file version %d (current version is %d)", pdf.Version, d.Version)
-	}
-
-	// Clear existing data
-	d.Data = pdf.Data
-	d.Keys = pdf.Keys
-	d.Strings = pdf.Strings
-	d.Numerics = pdf.Numerics
-	d.Booleans = pdf.Booleans
-	d.Times = pdf.Times
-	d.ExpireAt = pdf.ExpireAt
-	d.TTL = pdf.TTL
-	d.maxRegexCache = pdf.MaxRegexCache
-
-	// Re-initialize non-serializable fields
-	d.regexCache = make(map[string]*regexp.Regexp)
-	d.regexCacheSize = 0
-	d.regexMutex = sync.RWMutex{}
-	d.stopCleaner = make(chan bool)
-
-	// Recompile regex patterns
-	for _, pattern := range pdf.RegexPatterns {
-		if re, err := regexp.Compile(pattern); err == nil {
-			d.regexCache[pattern] = re
-			d.regexCacheSize++
-			if d.regexCacheSize >= d.maxRegexCache {
-				break
-			}
-		}
-	}
-
-	// Restart cleaner if it was running
-	if wasCleanerRunning {
-		go d.CleanExpired()
-	}
-
-	return nil
+	return d.restorePersistentUnlocked(&pdf)
 }
 
 // SaveToWriter saves the DataFrame to an io.Writer using gob encoding.
@@ -306,26 +339,7 @@ func (d *DataFrame) SaveToWriter(w io.Writer) error {
 	defer d.Locker.RUnlock()
 
 	// Create a persistable version of the DataFrame
-	pdf := &persistentDataFrame{
-		Version:       d.Version,
-		Data:          d.Data,
-		Keys:          d.Keys,
-		Strings:       d.Strings,
-		Numerics:      d.Numerics,
-		Booleans:      d.Booleans,
-		Times:         d.Times,
-		ExpireAt:      d.ExpireAt,
-		TTL:           d.TTL,
-		MaxRegexCache: d.maxRegexCache,
-	}
-
-	// Extract regex patterns
-	d.regexMutex.RLock()
-	pdf.RegexPatterns = make([]string, 0, len(d.regexCache))
-	for pattern := range d.regexCache {
-		pdf.RegexPatterns = append(pdf.RegexPatterns, pattern)
-	}
-	d.regexMutex.RUnlock()
+	pdf := d.persistentUnlocked()
 
 	// Encode
 	encoder := gob.NewEncoder(w)
@@ -334,12 +348,9 @@ func (d *DataFrame) SaveToWriter(w io.Writer) error {
 
 // LoadFromReader loads a DataFrame from an io.Reader using gob decoding.
 func (d *DataFrame) LoadFromReader(r io.Reader) error {
-	// Stop the cleaner if it's running
-	wasCleanerRunning := false
-	select {
-	case d.stopCleaner <- true:
-		wasCleanerRunning = true
-	default:
+	// Stop the cleaner while loading, restarting it once the lock is released
+	if d.cleaner.stop() {
+		defer d.StartCleaner()
 	}
 
 	d.Locker.Lock()
@@ -352,43 +363,5 @@ func (d *DataFrame) LoadFromReader(r io.Reader) error {
 		return fmt.Errorf("failed to decode dataframe: %w", err)
 	}
 
-	// Validate version
-	if pdf.Version > d.Version {
-		return fmt.Errorf("unsupported file version %d (current version is %d)", pdf.Version, d.Version)
-	}
-
-	// Clear existing data
-	d.Data = pdf.Data
-	d.Keys = pdf.Keys
-	d.Strings = pdf.Strings
-	d.Numerics = pdf.Numerics
-	d.Booleans = pdf.Booleans
-	d.Times = pdf.Times
-	d.ExpireAt = pdf.ExpireAt
-	d.TTL = pdf.TTL
-	d.maxRegexCache = pdf.MaxRegexCache
-
-	// Re-initialize non-serializable fields
-	d.regexCache = make(map[string]*regexp.Regexp)
-	d.regexCacheSize = 0
-	d.regexMutex = sync.RWMutex{}
-	d.stopCleaner = make(chan bool)
-
-	// Recompile regex patterns
-	for _, pattern := range pdf.RegexPatterns {
-		if re, err := regexp.Compile(pattern); err == nil {
-			d.regexCache[pattern] = re
-			d.regexCacheSize++
-			if d.regexCacheSize >= d.maxRegexCache {
-				break
-			}
-		}
-	}
-
-	// Restart cleaner if it was running
-	if wasCleanerRunning {
-		go d.CleanExpired()
-	}
-
-	return nil
+	return d.restorePersistentUnlocked(&pdf)
 }
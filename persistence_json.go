@@ -1,11 +1,14 @@
 package mframe
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,50 +23,21 @@ type jsonDataFrame struct {
 	TTL      string                            `json:"ttl"`
 }
 
+// JSONExportOptions configures ExportToJSONWithOptions and WriteJSON.
+type JSONExportOptions struct {
+	// SkipExpired leaves out the rows whose expiration time has passed but the cleaner has not removed yet.
+	SkipExpired bool
+	// Compact writes the document without indentation.
+	Compact bool
+}
+
 // ExportToJSON exports the DataFrame to a JSON file for human-readable inspection
 func (d *DataFrame) ExportToJSON(filename string) error {
-	d.Locker.RLock()
-	defer d.Locker.RUnlock()
-
-	// Create JSON structure
-	jdf := jsonDataFrame{
-		Version:  d.Version,
-		Data:     make(map[string]map[string]interface{}),
-		Keys:     make(map[string]int),
-		ExpireAt: make(map[string]string),
-		TTL:      d.TTL.String(),
-	}
-
-	// Convert UUIDs to strings for JSON
-	for id, row := range d.Data {
-		rowData := make(map[string]interface{})
-		for key, value := range row {
-			// Convert KeyName to string
-			keyStr := string(key)
-
-			// Handle special types that need conversion
-			switch v := value.(type) {
-			case time.Time:
-				rowData[keyStr] = v.Format(time.RFC3339Nano)
-			case uuid.UUID:
-				rowData[keyStr] = v.String()
-			default:
-				rowData[keyStr] = v
-			}
-		}
-		jdf.Data[id.String()] = rowData
-	}
-
-	// Convert Keys
-	for key, keyType := range d.Keys {
-		jdf.Keys[string(key)] = int(keyType)
-	}
-
-	// Convert ExpireAt
-	for id, expireTime := range d.ExpireAt {
-		jdf.ExpireAt[id.String()] = expireTime.Format(time.RFC3339Nano)
-	}
+	return d.ExportToJSONWithOptions(filename, JSONExportOptions{})
+}
 
+// ExportToJSONWithOptions exports the DataFrame to a JSON file with the given options, performing an atomic write.
+func (d *DataFrame) ExportToJSONWithOptions(filename string, opts JSONExportOptions) error {
 	// Create a temporary file in the same directory for atomic writing
 	dir := filepath.Dir(filename)
 	tmpFile, err := os.CreateTemp(dir, ".tmp-mframe-*.json")
@@ -73,12 +47,15 @@ func (d *DataFrame) ExportToJSON(filename string) error {
 	tmpName := tmpFile.Name()
 	defer func() { _ = os.Remove(tmpName) }()
 
-	// Encode with indentation for readability
-	encoder := json.NewEncoder(tmpFile)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(jdf); err != nil {
+	writer := bufio.NewWriter(tmpFile)
+	if err := d.WriteJSON(writer, opts); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
 		_ = tmpFile.Close()
-		return fmt.Errorf("failed to encode to JSON: %w", err)
+		return fmt.Errorf("failed to write to temporary file: %w", err)
 	}
 
 	// Close the temporary file
@@ -94,7 +71,122 @@ func (d *DataFrame) ExportToJSON(filename string) error {
 	return nil
 }
 
-// ImportFromJSON imports a DataFrame from a JSON file
+// WriteJSON writes the DataFrame to w in the format read by ImportFromJSON, encoding one row at a time
+// instead of building the whole document in memory. Rows are written ordered by ID.
+func (d *DataFrame) WriteJSON(w io.Writer, opts JSONExportOptions) error {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	now := time.Now()
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for id := range d.Data {
+		if expireAt, ok := d.ExpireAt[id]; ok && opts.SkipExpired && expireAt.Before(now) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	keys := make(map[string]int, len(d.Keys))
+	for key, keyType := range d.Keys {
+		keys[string(key)] = int(keyType)
+	}
+
+	jw := jsonWriter{w: w, compact: opts.Compact}
+	jw.raw("{")
+	jw.field(1, "version", d.Version, false)
+	jw.field(1, "ttl", d.TTL.String(), true)
+	jw.field(1, "keys", keys, true)
+
+	// Convert UUIDs to strings for JSON
+	jw.separator(1, true)
+	jw.raw(`"data": {`)
+	for i, id := range ids {
+		jw.field(2, id.String(), jsonRow(d.Data[id]), i > 0)
+	}
+	jw.close(1, len(ids) > 0)
+
+	// Convert ExpireAt
+	jw.separator(1, true)
+	jw.raw(`"expire_at": {`)
+	written := 0
+	for _, id := range ids {
+		if expireTime, ok := d.ExpireAt[id]; ok {
+			jw.field(2, id.String(), expireTime.Format(time.RFC3339Nano), written > 0)
+			written++
+		}
+	}
+	jw.close(1, written > 0)
+	jw.close(0, true)
+	jw.raw("\n")
+
+	if jw.err != nil {
+		return fmt.Errorf("failed to encode to JSON: %w", jw.err)
+	}
+
+	return nil
+}
+
+// jsonWriter writes a JSON document piece by piece, indenting it with two spaces per level unless compact,
+// and keeps the first error.
+type jsonWriter struct {
+	w       io.Writer
+	compact bool
+	err     error
+}
+
+// raw writes s as is.
+func (j *jsonWriter) raw(s string) {
+	if j.err == nil {
+		_, j.err = io.WriteString(j.w, s)
+	}
+}
+
+// separator starts a member at the given depth, after a comma if it is not the first one.
+func (j *jsonWriter) separator(depth int, comma bool) {
+	if comma {
+		j.raw(",")
+	}
+	if !j.compact {
+		j.raw("\n" + strings.Repeat("  ", depth))
+	}
+}
+
+// field writes a member of an object at the given depth.
+func (j *jsonWriter) field(depth int, name string, value interface{}, comma bool) {
+	if j.err != nil {
+		return
+	}
+
+	var encoded []byte
+	if j.compact {
+		encoded, j.err = json.Marshal(value)
+	} else {
+		encoded, j.err = json.MarshalIndent(value, strings.Repeat("  ", depth), "  ")
+	}
+	if j.err != nil {
+		return
+	}
+
+	quoted, _ := json.Marshal(name)
+	j.separator(depth, comma)
+	j.raw(string(quoted) + ":")
+	if !j.compact {
+		j.raw(" ")
+	}
+	j.raw(string(encoded))
+}
+
+// close ends an object at the given depth, on a new line if it has members.
+func (j *jsonWriter) close(depth int, members bool) {
+	if members && !j.compact {
+		j.raw("\n" + strings.Repeat("  ", depth))
+	}
+	j.raw("}")
+}
+
+// ImportFromJSON imports a DataFrame from a JSON file, replacing its rows.
+// A file that cannot be read leaves the DataFrame unchanged.
 func (d *DataFrame) ImportFromJSON(filename string) error {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -102,12 +194,9 @@ func (d *DataFrame) ImportFromJSON(filename string) error {
 	}
 	defer func() { _ = file.Close() }()
 
-	// Stop the cleaner if it's running
-	wasCleanerRunning := false
-	select {
-	case d.stopCleaner <- true:
-		wasCleanerRunning = true
-	default:
+	// Stop the cleaner while importing, restarting it once the lock is released
+	if d.cleaner.stop() {
+		defer d.StartCleaner()
 	}
 
 	d.Locker.Lock()
@@ -131,25 +220,7 @@ func (d *DataFrame) ImportFromJSON(filename string) error {
 		return fmt.Errorf("failed to parse TTL: %w", err)
 	}
 
-	// Clear and reinitialize
-	d.Data = make(map[uuid.UUID]Row)
-	d.Keys = make(KeysIndex)
-	d.Strings = make(StringsIndex)
-	d.Numerics = make(NumericsIndex)
-	d.Booleans = make(BooleansIndex)
-	d.Times = make(TimesIndex)
-	d.ExpireAt = make(ExpireAtIndex)
-	d.TTL = ttl
-
-	// Re-initialize non-serializable fields
-	d.regexCache = make(map[string]*regexp.Regexp)
-	d.regexCacheSize = 0
-	d.stopCleaner = make(chan bool)
-
-	// Convert Keys back
-	for keyStr, keyType := range jdf.Keys {
-		d.Keys[KeyName(keyStr)] = KeyType(keyType)
-	}
+	stage := d.importStageUnlocked(ttl, jdf.Keys)
 
 	// Convert Data and rebuild indexes
 	for idStr, rowData := range jdf.Data {
@@ -158,40 +229,7 @@ func (d *DataFrame) ImportFromJSON(filename string) error {
 			return fmt.Errorf("failed to parse UUID %s: %w", idStr, err)
 		}
 
-		// Convert row data
-		row := make(Row)
-		convertedData := make(map[KeyName]interface{})
-
-		for keyStr, value := range rowData {
-			key := KeyName(keyStr)
-
-			// Check if this is a known key and handle type conversion
-			if keyType, exists := d.Keys[key]; exists {
-				switch keyType {
-				case Time:
-					// Try to parse as time
-					if strVal, ok := value.(string); ok {
-						if t, err := time.Parse(time.RFC3339Nano, strVal); err == nil {
-							convertedData[key] = t
-							continue
-						}
-					}
-				case String:
-					// For UUID fields stored as strings, keep them as strings
-					if strVal, ok := value.(string); ok {
-						convertedData[key] = strVal
-						continue
-					}
-				}
-			}
-
-			// Default: use value as-is
-			convertedData[key] = value
-		}
-
-		// Index the data
-		d.index(convertedData, "", id, &row)
-		d.Data[id] = row
+		stage.Data[id] = stage.restoreRowUnlocked(id, rowData)
 	}
 
 	// Convert ExpireAt
@@ -206,13 +244,152 @@ func (d *DataFrame) ImportFromJSON(filename string) error {
 			continue // Skip invalid times
 		}
 
-		d.ExpireAt[id] = expireTime
+		stage.ExpireAt[id] = expireTime
 	}
 
-	// Restart cleaner if it was running
-	if wasCleanerRunning {
-		go d.CleanExpired()
-	}
+	d.commitImportUnlocked(stage)
 
 	return nil
 }
+
+// jsonRow converts a row to its JSON representation, formatting times and UUIDs as strings.
+func jsonRow(row Row) map[string]interface{} {
+	rowData := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		// Handle special types that need conversion
+		switch v := value.(type) {
+		case time.Time:
+			rowData[string(key)] = v.Format(time.RFC3339Nano)
+		case uuid.UUID:
+			rowData[string(key)] = v.String()
+		default:
+			rowData[string(key)] = v
+		}
+	}
+	return rowData
+}
+
+// importStageUnlocked returns an empty DataFrame with the TTL, the indexing configuration of the DataFrame and
+// the types of keys, except the keys stored but not indexed, without acquiring locks. Imports restore the rows into
+// it and only replace the rows of the DataFrame with commitImportUnlocked once the whole file was read, so a file
+// failing half way leaves the DataFrame as it was.
+func (d *DataFrame) importStageUnlocked(ttl time.Duration, keys map[string]int) *DataFrame {
+	stage := &DataFrame{
+		Data:        make(map[uuid.UUID]Row),
+		Keys:        make(KeysIndex),
+		Strings:     make(StringsIndex),
+		Numerics:    make(NumericsIndex),
+		Booleans:    make(BooleansIndex),
+		Times:       make(TimesIndex),
+		ExpireAt:    make(ExpireAtIndex),
+		TTL:         ttl,
+		Version:     d.Version,
+		notIndexed:  d.notIndexed,
+		entropyKeys: d.entropyKeys,
+		coercion:    d.coercion,
+		declared:    d.declared,
+	}
+
+	for keyStr, keyType := range keys {
+		if !d.notIndexedUnlocked(KeyName(keyStr)) {
+			stage.Keys[KeyName(keyStr)] = KeyType(keyType)
+		}
+	}
+
+	return stage
+}
+
+// commitImportUnlocked replaces the rows, keys, indexes, expirations and TTL of the DataFrame with those restored
+// into stage and rebuilds the structures derived from them, without acquiring locks.
+func (d *DataFrame) commitImportUnlocked(stage *DataFrame) {
+	d.Data = stage.Data
+	d.Keys = stage.Keys
+	d.Strings = stage.Strings
+	d.Numerics = stage.Numerics
+	d.Booleans = stage.Booleans
+	d.Times = stage.Times
+	d.ExpireAt = stage.ExpireAt
+	d.TTL = stage.TTL
+
+	// Re-initialize non-serializable fields
+	d.regexCache = make(map[string]*regexp.Regexp)
+	d.regexCacheSize = 0
+
+	// Rebuild the structures derived from the loaded rows
+	d.resetDerivedUnlocked()
+}
+
+// restoreRowUnlocked restores a row read from a file and indexes its values, without acquiring locks.
+// Values matching the type recorded for their key, or of a JSON type when the key has none, are restored
+// exactly as they were exported, without coercion. Time keys also accept their values formatted as strings.
+// Other values, such as nested objects in hand-written files, are indexed like inserted data. Values of keys
+// set as not indexed with SetIndexPolicy are stored without being indexed.
+func (d *DataFrame) restoreRowUnlocked(id uuid.UUID, rowData map[string]interface{}) Row {
+	row := make(Row, len(rowData))
+	var rest map[KeyName]interface{}
+
+	for keyStr, value := range rowData {
+		key := KeyName(keyStr)
+
+		if d.notIndexedUnlocked(key) {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}, nil:
+			default:
+				row[key] = normalizeValue(value)
+				continue
+			}
+		}
+
+		keyType, known := d.Keys[key]
+		typed, ok := restoredValue(keyType, known, value)
+		if !ok {
+			if rest == nil {
+				rest = make(map[KeyName]interface{})
+			}
+			rest[key] = value
+			continue
+		}
+
+		row[key] = typed
+		switch v := typed.(type) {
+		case string:
+			d.Keys[key] = String
+			indexValue(d.Strings, key, v, id)
+		case float64:
+			d.Keys[key] = Numeric
+			indexValue(d.Numerics, key, v, id)
+		case bool:
+			d.Keys[key] = Boolean
+			indexValue(d.Booleans, key, v, id)
+		case time.Time:
+			d.Keys[key] = Time
+			indexValue(d.Times, key, v, id)
+		}
+	}
+
+	if rest != nil {
+		d.index(rest, "", id, &row)
+	}
+
+	return row
+}
+
+// restoredValue returns the value to restore for a key of the given type, or false if it does not match.
+func restoredValue(keyType KeyType, known bool, value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		if !known || keyType == String {
+			return v, true
+		}
+		if keyType == Time {
+			return parseTime(v, nil, false)
+		}
+	case float64:
+		return v, !known || keyType == Numeric
+	case bool:
+		return v, !known || keyType == Boolean
+	case time.Time:
+		return v, !known || keyType == Time
+	}
+	return nil, false
+}
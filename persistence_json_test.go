@@ -1,8 +1,10 @@
 package mframe_test
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -170,6 +172,31 @@ func TestDataFrame_ImportInvalidJSON(t *testing.T) {
 	}
 }
 
+func TestDataFrame_ImportInvalidUUIDJSON(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "frame.json")
+	content := `{"version":1,"ttl":"2h0m0s","keys":{"host":0},"data":{` +
+		`"6ba7b810-9dad-11d1-80b4-00c04fd430c8":{"host":"web-2"},"nope":{"host":"web-3"}}}`
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+	df.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+	df.StartCleaner()
+	defer df.StopCleaner()
+
+	if err := df.ImportFromJSON(filename); err == nil {
+		t.Error("expected an error for an invalid UUID")
+	}
+	if df.Count() != 1 || df.Filter(mframe.Equals, "host", "web-1", nil).Count() != 1 || df.TTL != time.Hour {
+		t.Errorf("expected the DataFrame to be left unchanged, but got %d rows and a TTL of %v", df.Count(), df.TTL)
+	}
+	if !df.CleanerStatus().Running {
+		t.Error("expected the cleaner to be restarted")
+	}
+}
+
 func TestDataFrame_ImportVersionMismatchJSON(t *testing.T) {
 	// Create a temporary file with higher version
 	tempFile, err := os.CreateTemp("", "mframe-version-*.json")
@@ -386,3 +413,111 @@ func BenchmarkDataFrame_ImportFromJSON(b *testing.B) {
 		}
 	}
 }
+
+func TestDataFrame_ExportToJSONWithOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+
+	created := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	df.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"name": "Alice", "zip": "02139", "age": float64(30), "active": true, "created": created},
+		{"name": "Bob", "zip": "10001", "age": float64(25), "active": false},
+	})
+	expired, err := df.InsertReturningID(map[mframe.KeyName]interface{}{"name": "Carol"})
+	if err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+	df.ExpireAt[expired] = time.Now().Add(-time.Minute)
+
+	var buf bytes.Buffer
+	if err := df.WriteJSON(&buf, mframe.JSONExportOptions{SkipExpired: true, Compact: true}); err != nil {
+		t.Fatalf("Failed to write JSON: %v", err)
+	}
+	if strings.Contains(buf.String(), "\n  ") || strings.Contains(buf.String(), expired.String()) {
+		t.Errorf("expected compact JSON without the expired row, but got %s", buf.String())
+	}
+
+	filename := filepath.Join(dir, "skip.json")
+	if err := os.WriteFile(filename, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	df2 := &mframe.DataFrame{}
+	df2.Init(24 * time.Hour)
+	df2.SetCoercion(mframe.CoercionOptions{Numerics: true, DetectTimes: true})
+	if err := df2.ImportFromJSON(filename); err != nil {
+		t.Fatalf("Failed to import from JSON: %v", err)
+	}
+
+	if len(df2.Data) != 2 {
+		t.Fatalf("expected 2 rows, but got %d", len(df2.Data))
+	}
+	for id, row := range df.Data {
+		if id == expired {
+			continue
+		}
+		for key, value := range row {
+			if df2.Data[id][key] != value {
+				t.Errorf("expected %s to be %v (%T), but got %v (%T)", key, value, value, df2.Data[id][key], df2.Data[id][key])
+			}
+		}
+		if !df2.ExpireAt[id].Equal(df.ExpireAt[id]) {
+			t.Errorf("expected expiration %v, but got %v", df.ExpireAt[id], df2.ExpireAt[id])
+		}
+	}
+
+	if df2.Keys["zip"] != mframe.String || df2.Keys["created"] != mframe.Time {
+		t.Errorf("expected the key types to be kept, but got %v", df2.Keys)
+	}
+	if df2.Filter(mframe.Equals, "active", false, nil).Count() != 1 {
+		t.Error("expected the boolean index to be rebuilt")
+	}
+	if df2.Filter(mframe.Greater, "age", 26.0, nil).Count() != 1 {
+		t.Error("expected the numeric index to be rebuilt")
+	}
+
+	// The pretty-printed export keeps the expired row and its past expiration.
+	pretty := filepath.Join(dir, "pretty.json")
+	if err := df.ExportToJSON(pretty); err != nil {
+		t.Fatalf("Failed to export to JSON: %v", err)
+	}
+
+	df3 := &mframe.DataFrame{}
+	df3.Init(time.Hour)
+	if err := df3.ImportFromJSON(pretty); err != nil {
+		t.Fatalf("Failed to import from JSON: %v", err)
+	}
+	if !df3.ExpireAt[expired].Equal(df.ExpireAt[expired]) {
+		t.Errorf("expected expiration %v, but got %v", df.ExpireAt[expired], df3.ExpireAt[expired])
+	}
+}
+
+func TestDataFrame_ImportHandWrittenJSON(t *testing.T) {
+	id := uuid.New()
+	content := `{"version": 1, "ttl": "1h0m0s", "keys": {}, "expire_at": {},
+		"data": {"` + id.String() + `": {"host": "web-1", "geo": {"city": "Lisbon"}, "ports": [80, 443]}}}`
+
+	filename := filepath.Join(t.TempDir(), "hand.json")
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+	if err := df.ImportFromJSON(filename); err != nil {
+		t.Fatalf("Failed to import from JSON: %v", err)
+	}
+
+	row := df.Data[id]
+	if row["host"] != "web-1" || row["geo.city"] != "Lisbon" || row["ports.1"] != 443.0 {
+		t.Errorf("expected the nested values to be flattened, but got %v", row)
+	}
+	if _, ok := df.ExpireAt[id]; ok {
+		t.Error("expected no expiration")
+	}
+	if df.Filter(mframe.Equals, "geo.city", "Lisbon", nil).Count() != 1 {
+		t.Error("expected the nested values to be indexed")
+	}
+}
@@ -0,0 +1,154 @@
+package mframe
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ndjsonHeader is the first line of an NDJSON file, holding the settings and key types of the DataFrame.
+type ndjsonHeader struct {
+	Version int            `json:"version"`
+	Keys    map[string]int `json:"keys"`
+	TTL     string         `json:"ttl"`
+}
+
+// ndjsonRow is a line of an NDJSON file holding a row.
+type ndjsonRow struct {
+	ID       string                 `json:"id"`
+	ExpireAt string                 `json:"expire_at,omitempty"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// ExportToNDJSON exports the DataFrame to a file of JSON lines: a header line with the settings and key types,
+// followed by one line per row. Rows are encoded one at a time, so frames larger than the available memory
+// headroom can be dumped.
+func (d *DataFrame) ExportToNDJSON(filename string) error {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	// Create a temporary file in the same directory for atomic writing
+	dir := filepath.Dir(filename)
+	tmpFile, err := os.CreateTemp(dir, ".tmp-mframe-*.ndjson")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	writer := bufio.NewWriter(tmpFile)
+	encoder := json.NewEncoder(writer)
+
+	header := ndjsonHeader{Version: d.Version, Keys: make(map[string]int, len(d.Keys)), TTL: d.TTL.String()}
+	for key, keyType := range d.Keys {
+		header.Keys[string(key)] = int(keyType)
+	}
+
+	if err := encoder.Encode(header); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to encode header to JSON: %w", err)
+	}
+
+	for id, row := range d.Data {
+		line := ndjsonRow{ID: id.String(), Data: jsonRow(row)}
+		if expireAt, ok := d.ExpireAt[id]; ok {
+			line.ExpireAt = expireAt.Format(time.RFC3339Nano)
+		}
+
+		if err := encoder.Encode(line); err != nil {
+			_ = tmpFile.Close()
+			return fmt.Errorf("failed to encode row %s to JSON: %w", id, err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write to temporary file: %w", err)
+	}
+
+	// Close the temporary file
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	// Atomic rename
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// ImportFromNDJSON imports a DataFrame from a file written by ExportToNDJSON, replacing its rows.
+// Rows are decoded one at a time, so only the loaded DataFrame needs to fit in memory. They replace the rows
+// of the DataFrame once the whole file is read, so a file that cannot be read leaves the DataFrame unchanged.
+func (d *DataFrame) ImportFromNDJSON(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// Stop the cleaner while importing, restarting it once the lock is released
+	if d.cleaner.stop() {
+		defer d.StartCleaner()
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+
+	var header ndjsonHeader
+	if err := decoder.Decode(&header); err != nil {
+		return fmt.Errorf("failed to decode header from JSON: %w", err)
+	}
+
+	// Validate version
+	if header.Version > d.Version {
+		return fmt.Errorf("unsupported file version %d (current version is %d)", header.Version, d.Version)
+	}
+
+	// Parse TTL
+	ttl, err := time.ParseDuration(header.TTL)
+	if err != nil {
+		return fmt.Errorf("failed to parse TTL: %w", err)
+	}
+
+	stage := d.importStageUnlocked(ttl, header.Keys)
+
+	for line := 2; ; line++ {
+		var entry ndjsonRow
+		err := decoder.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode line %d from JSON: %w", line, err)
+		}
+
+		id, err := uuid.Parse(entry.ID)
+		if err != nil {
+			return fmt.Errorf("failed to parse UUID %s on line %d: %w", entry.ID, line, err)
+		}
+
+		stage.Data[id] = stage.restoreRowUnlocked(id, entry.Data)
+
+		if entry.ExpireAt != "" {
+			if expireTime, err := time.Parse(time.RFC3339Nano, entry.ExpireAt); err == nil {
+				stage.ExpireAt[id] = expireTime
+			}
+		}
+	}
+
+	d.commitImportUnlocked(stage)
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package mframe_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestDataFrame_ExportImportNDJSON(t *testing.T) {
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	df.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"name": "Alice", "age": float64(30), "active": true, "created": now},
+		{"name": "Bob", "age": float64(25), "nested": map[string]interface{}{"city": "New York"}},
+	})
+
+	filename := filepath.Join(t.TempDir(), "frame.ndjson")
+	if err := df.ExportToNDJSON(filename); err != nil {
+		t.Fatalf("Failed to export to NDJSON: %v", err)
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read NDJSON file: %v", err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 3 {
+		t.Errorf("expected a header and 2 row lines, but got %d lines", lines)
+	}
+
+	df2 := &mframe.DataFrame{}
+	df2.Init(24 * time.Hour)
+	if err := df2.ImportFromNDJSON(filename); err != nil {
+		t.Fatalf("Failed to import from NDJSON: %v", err)
+	}
+
+	if df2.TTL != time.Hour {
+		t.Errorf("expected TTL %v, but got %v", time.Hour, df2.TTL)
+	}
+	if len(df2.Data) != len(df.Data) {
+		t.Fatalf("expected %d rows, but got %d", len(df.Data), len(df2.Data))
+	}
+
+	for id, row := range df.Data {
+		for key, value := range row {
+			if df2.Data[id][key] != value {
+				t.Errorf("expected %s to be %v, but got %v", key, value, df2.Data[id][key])
+			}
+		}
+		if !df2.ExpireAt[id].Equal(df.ExpireAt[id]) {
+			t.Errorf("expected expiration %v, but got %v", df.ExpireAt[id], df2.ExpireAt[id])
+		}
+	}
+
+	if df2.Filter(mframe.Between, "created", []time.Time{now, now}, nil).Count() != 1 {
+		t.Error("expected times to be indexed after import")
+	}
+}
+
+func TestDataFrame_ImportNDJSONErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing header", ""},
+		{"bad version", `{"version":99,"keys":{},"ttl":"1h0m0s"}` + "\n"},
+		{"bad ttl", `{"version":1,"keys":{},"ttl":"forever"}` + "\n"},
+		{"bad row", `{"version":1,"keys":{},"ttl":"1h0m0s"}` + "\n{nope\n"},
+		{"bad id", `{"version":1,"keys":{},"ttl":"1h0m0s"}` + "\n" + `{"id":"nope","data":{}}` + "\n"},
+		{"bad row after rows", `{"version":1,"keys":{"host":0},"ttl":"2h0m0s"}` + "\n" +
+			`{"id":"6ba7b810-9dad-11d1-80b4-00c04fd430c8","data":{"host":"web-2"}}` + "\n{nope\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".ndjson")
+			if err := os.WriteFile(filename, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			df := &mframe.DataFrame{}
+			df.Init(time.Hour)
+			df.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+			df.StartCleaner()
+			defer df.StopCleaner()
+
+			if err := df.ImportFromNDJSON(filename); err == nil {
+				t.Error("expected an error")
+			}
+			if df.Count() != 1 || df.Filter(mframe.Equals, "host", "web-1", nil).Count() != 1 || df.TTL != time.Hour {
+				t.Errorf("expected the DataFrame to be left unchanged, but got %d rows and a TTL of %v", df.Count(), df.TTL)
+			}
+			if !df.CleanerStatus().Running {
+				t.Error("expected the cleaner to be restarted")
+			}
+		})
+	}
+
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+	if err := df.ImportFromNDJSON(filepath.Join(dir, "missing.ndjson")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -429,3 +429,61 @@ func BenchmarkDataFrame_LoadFromFile(b *testing.B) {
 		}
 	}
 }
+
+func TestDataFrame_SaveAndLoadCompact(t *testing.T) {
+	dir := t.TempDir()
+
+	df := &mframe.DataFrame{}
+	df.Init(time.Hour)
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 200; i++ {
+		df.Insert(map[mframe.KeyName]interface{}{
+			"host":    uuid.NewString(),
+			"bytes":   float64(i),
+			"blocked": i%2 == 0,
+			"seen":    seen.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	full := filepath.Join(dir, "full.gob")
+	if err := df.SaveToFile(full); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	df.SetCompactPersistence(true)
+	compact := filepath.Join(dir, "compact.gob")
+	if err := df.SaveToFile(compact); err != nil {
+		t.Fatalf("Failed to save compact: %v", err)
+	}
+
+	fullInfo, _ := os.Stat(full)
+	compactInfo, _ := os.Stat(compact)
+	if compactInfo.Size() >= fullInfo.Size() {
+		t.Errorf("expected the compact file to be smaller than %d bytes, but got %d", fullInfo.Size(), compactInfo.Size())
+	}
+
+	for _, filename := range []string{full, compact} {
+		df2 := &mframe.DataFrame{}
+		df2.Init(24 * time.Hour)
+		if err := df2.LoadFromFile(filename); err != nil {
+			t.Fatalf("Failed to load %s: %v", filename, err)
+		}
+
+		if len(df2.Data) != 200 {
+			t.Errorf("expected 200 rows, but got %d", len(df2.Data))
+		}
+		if count := df2.Filter(mframe.Equals, "blocked", true, nil).Count(); count != 100 {
+			t.Errorf("expected 100 blocked rows, but got %d", count)
+		}
+		if count := df2.Filter(mframe.Greater, "bytes", 149.0, nil).Count(); count != 50 {
+			t.Errorf("expected 50 rows above 149 bytes, but got %d", count)
+		}
+		if count := df2.Filter(mframe.Between, "seen", []time.Time{seen, seen}, nil).Count(); count != 1 {
+			t.Errorf("expected 1 row seen at %v, but got %d", seen, count)
+		}
+		if len(df2.Strings["host"]) != 200 {
+			t.Errorf("expected 200 indexed hosts, but got %d", len(df2.Strings["host"]))
+		}
+	}
+}
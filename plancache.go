@@ -0,0 +1,136 @@
+package mframe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultPlanCacheSize is the number of FilterSpec plans a DataFrame keeps.
+const DefaultPlanCacheSize = 1000
+
+// planCache holds the plans of the FilterSpecs evaluated by a DataFrame, keyed by their normalized form.
+// It has its own lock, as plans are made by queries holding the read lock of the DataFrame.
+type planCache struct {
+	mutex  sync.Mutex
+	plans  map[string]*specPlan
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// specPlan is the order in which the conditions of a normalized FilterSpec are evaluated, from the one
+// expected to match the fewest rows, along with the number of rows of the DataFrame it was made for.
+type specPlan struct {
+	order []int
+	rows  int
+}
+
+// stale reports whether the number of rows doubled or halved since the plan was made.
+func (p *specPlan) stale(rows int) bool {
+	return rows > 2*p.rows || 2*rows < p.rows
+}
+
+// clear forgets every plan.
+func (c *planCache) clear() {
+	c.mutex.Lock()
+	c.plans = nil
+	c.mutex.Unlock()
+}
+
+// planUnlocked returns the positions of the conditions of the spec in the order they are evaluated, from
+// the cached plan of the spec or from a new one, without acquiring the lock of the DataFrame. Conditions are
+// ordered by the number of rows they are expected to match, so the intersection shrinks quickly and ends
+// as soon as it is empty. Specs are normalized so the same conditions in any order share a plan.
+func (d *DataFrame) planUnlocked(spec FilterSpec) []int {
+	normalized := make([]string, len(spec.Conditions))
+	for i, c := range spec.Conditions {
+		normalized[i] = normalizeCondition(c)
+	}
+	sorted := make([]int, len(spec.Conditions))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return normalized[sorted[i]] < normalized[sorted[j]] })
+
+	var key strings.Builder
+	for _, i := range sorted {
+		key.WriteString(normalized[i])
+		key.WriteByte('\x1e')
+	}
+
+	rows := len(d.Data)
+
+	d.plans.mutex.Lock()
+	plan, ok := d.plans.plans[key.String()]
+	d.plans.mutex.Unlock()
+
+	if ok && !plan.stale(rows) {
+		d.plans.hits.Add(1)
+	} else {
+		d.plans.misses.Add(1)
+
+		estimates := make([]int, len(sorted))
+		for i, position := range sorted {
+			c := spec.Conditions[position]
+			estimates[i] = d.estimateRowsUnlocked(c.Operator, c.Key, c.Value)
+		}
+		plan = &specPlan{order: make([]int, len(sorted)), rows: rows}
+		for i := range plan.order {
+			plan.order[i] = i
+		}
+		sort.SliceStable(plan.order, func(i, j int) bool { return estimates[plan.order[i]] < estimates[plan.order[j]] })
+
+		d.plans.mutex.Lock()
+		if d.plans.plans == nil {
+			d.plans.plans = make(map[string]*specPlan)
+		}
+		if _, exists := d.plans.plans[key.String()]; !exists && len(d.plans.plans) >= DefaultPlanCacheSize {
+			// Simple eviction: remove one random entry
+			for k := range d.plans.plans {
+				delete(d.plans.plans, k)
+				break
+			}
+		}
+		d.plans.plans[key.String()] = plan
+		d.plans.mutex.Unlock()
+	}
+
+	positions := make([]int, len(plan.order))
+	for i, normalizedIndex := range plan.order {
+		positions[i] = sorted[normalizedIndex]
+	}
+	return positions
+}
+
+// normalizeCondition returns a string identifying the condition, with its options sorted.
+func normalizeCondition(c Condition) string {
+	options := make([]string, 0, len(c.Options))
+	for option, enabled := range c.Options {
+		options = append(options, fmt.Sprintf("%d=%t", option, enabled))
+	}
+	sort.Strings(options)
+
+	return fmt.Sprintf("%d\x1f%s\x1f%#v\x1f%s", c.Operator, c.Key, c.Value, strings.Join(options, ","))
+}
+
+// estimateRowsUnlocked returns the number of rows a condition is expected to match, as estimated by
+// Explain, without acquiring locks.
+func (d *DataFrame) estimateRowsUnlocked(operator Operator, key KeyName, value any) int {
+	switch d.Keys[key] {
+	case Numeric:
+		return estimateNumericRows(operator, value, d.Numerics[key])
+	case String:
+		return estimateStringRows(operator, value, d.Strings[key])
+	case Boolean:
+		return estimateBooleanRows(operator, value, d.Booleans[key])
+	case Time:
+		return estimateTimeRows(operator, value, d.Times[key])
+	default:
+		if isKeyPattern(key) {
+			return len(d.Data)
+		}
+		return 0
+	}
+}
@@ -0,0 +1,70 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestPlanCache(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{
+			"host":  []string{"web-1", "web-2", "web-3", "web-4"}[i%4],
+			"bytes": i,
+		})
+	}
+
+	spec := mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.RegExp, Key: "host", Value: "^web-1$"},
+		{Operator: mframe.Major, Key: "bytes", Value: 89.0},
+	}}
+	reordered := mframe.FilterSpec{Conditions: []mframe.Condition{spec.Conditions[1], spec.Conditions[0]}}
+
+	for _, s := range []mframe.FilterSpec{spec, spec, reordered} {
+		if got := countSpec(&cache, s); got != 2 {
+			t.Fatalf("expected 2 matching rows, but got %d", got)
+		}
+	}
+
+	m := cache.Metrics()
+	if m.PlanCacheMisses != 1 || m.PlanCacheHits != 2 {
+		t.Errorf("expected 1 miss and 2 hits, but got %d misses and %d hits", m.PlanCacheMisses, m.PlanCacheHits)
+	}
+
+	// Once the number of rows doubles, the plan is made again.
+	for i := 0; i < 150; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"host": "db-1", "bytes": 1000 + i})
+	}
+	if got := countSpec(&cache, spec); got != 2 {
+		t.Errorf("expected 2 matching rows, but got %d", got)
+	}
+	if m := cache.Metrics(); m.PlanCacheMisses != 2 {
+		t.Errorf("expected a new plan for the grown frame, but got %d misses", m.PlanCacheMisses)
+	}
+
+	// A condition matching nothing ends the evaluation before the regular expression is evaluated.
+	before := cache.Metrics().RegexCacheHits + cache.Metrics().RegexCacheMisses
+	empty := mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.RegExp, Key: "host", Value: "^web-2$"},
+		{Operator: mframe.Equals, Key: "bytes", Value: -1.0},
+	}}
+	if got := countSpec(&cache, empty); got != 0 {
+		t.Errorf("expected no matching rows, but got %d", got)
+	}
+	if after := cache.Metrics().RegexCacheHits + cache.Metrics().RegexCacheMisses; after != before {
+		t.Errorf("expected the regular expression to be skipped, but got %d lookups", after-before)
+	}
+}
+
+// countSpec returns the number of rows matching spec.
+func countSpec(cache *mframe.DataFrame, spec mframe.FilterSpec) int {
+	count := 0
+	for range cache.FilterSpecIter(spec) {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,165 @@
+package mframe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+)
+
+// ErrTypeMismatch is returned when the value of a condition does not fit its operator or the type of its key.
+var ErrTypeMismatch = errors.New("type mismatch")
+
+// PreparedFilter is a FilterSpec whose conditions were checked once by Prepare, so it can be run repeatedly,
+// on the DataFrame that prepared it or on others, and fail with an error on a mismatch instead of matching
+// no rows.
+type PreparedFilter struct {
+	spec  FilterSpec
+	types []KeyType
+}
+
+// Prepare checks every condition of spec and returns a PreparedFilter running it. A condition is valid when
+// its operator is known, its value has the type the operator requires, such as float64 for Greater, []float64
+// or []time.Time of two elements for Between or a string for Contains, regular expressions and CIDRs parse,
+// and its key, unless it is a key pattern, is either not mapped yet or mapped to the type of the value.
+// Returns the errors of all the invalid conditions joined with errors.Join, wrapping ErrTypeMismatch for
+// mismatches.
+func (d *DataFrame) Prepare(spec FilterSpec) (*PreparedFilter, error) {
+	prepared := &PreparedFilter{
+		spec:  FilterSpec{Conditions: append([]Condition(nil), spec.Conditions...)},
+		types: make([]KeyType, len(spec.Conditions)),
+	}
+
+	var errs []error
+	for i, c := range spec.Conditions {
+		keyType, err := conditionKeyType(c)
+		if err == nil && isKeyPattern(c.Key) {
+			_, err = d.getCompiledRegex(string(c.Key))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("condition %d on key '%s': %w", i, c.Key, err))
+			continue
+		}
+		prepared.types[i] = keyType
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	if err := prepared.checkUnlocked(d); err != nil {
+		return nil, err
+	}
+
+	return prepared, nil
+}
+
+// Spec returns a copy of the FilterSpec of the prepared filter.
+func (p *PreparedFilter) Spec() FilterSpec {
+	return FilterSpec{Conditions: append([]Condition(nil), p.spec.Conditions...)}
+}
+
+// Run returns a new DataFrame with the rows of d matching every condition of the prepared filter, keeping
+// their IDs. Returns an error wrapping ErrTypeMismatch if a key of a condition is mapped to another type in d.
+func (p *PreparedFilter) Run(d *DataFrame) (*DataFrame, error) {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	if err := p.checkUnlocked(d); err != nil {
+		return nil, err
+	}
+
+	var results = new(DataFrame)
+	results.Init(d.TTL)
+	for id := range d.specIDsUnlocked(p.spec) {
+		results.insertWithIDUnlocked(id, d.Data[id])
+	}
+
+	return results, nil
+}
+
+// checkUnlocked checks the keys of the conditions against the key types of d, without acquiring locks.
+func (p *PreparedFilter) checkUnlocked(d *DataFrame) error {
+	var errs []error
+	for i, c := range p.spec.Conditions {
+		if isKeyPattern(c.Key) {
+			continue
+		}
+		if mapped, ok := d.Keys[c.Key]; ok && mapped != p.types[i] {
+			errs = append(errs, fmt.Errorf("condition %d: %s on key '%s' requires a %s key, but it is %s: %w",
+				i, operatorToString(c.Operator), c.Key, keyTypeToString(p.types[i]), keyTypeToString(mapped), ErrTypeMismatch))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isKeyPattern reports whether a key is a regular expression matching several keys, as Filter treats it.
+func isKeyPattern(key KeyName) bool {
+	return ContainsF(string(key), "^") || ContainsF(string(key), "[") || ContainsF(string(key), "(")
+}
+
+// conditionKeyType returns the type of the keys the condition applies to, given its operator and the type
+// of its value. Returns an error if the operator is unknown, it does not accept the value or the value is
+// an invalid regular expression, CIDR or range.
+func conditionKeyType(c Condition) (KeyType, error) {
+	switch c.Operator {
+	case Equals, NotEquals:
+		switch c.Value.(type) {
+		case string:
+			return String, nil
+		case float64:
+			return Numeric, nil
+		case bool:
+			return Boolean, nil
+		}
+	case Major, Minor, MajorEquals, MinorEquals:
+		if _, ok := c.Value.(float64); ok {
+			return Numeric, nil
+		}
+	case InList, NotInList:
+		switch c.Value.(type) {
+		case []string:
+			return String, nil
+		case []float64:
+			return Numeric, nil
+		}
+	case Between, NotBetween:
+		switch v := c.Value.(type) {
+		case []float64:
+			if len(v) != 2 {
+				return 0, fmt.Errorf("%s requires a range of 2 values, but got %d: %w", operatorToString(c.Operator), len(v), ErrTypeMismatch)
+			}
+			return Numeric, nil
+		case []time.Time:
+			if len(v) != 2 {
+				return 0, fmt.Errorf("%s requires a range of 2 values, but got %d: %w", operatorToString(c.Operator), len(v), ErrTypeMismatch)
+			}
+			return Time, nil
+		}
+	case RegExp, NotRegExp:
+		if v, ok := c.Value.(string); ok {
+			if _, err := regexp.Compile(v); err != nil {
+				return 0, fmt.Errorf("invalid regular expression: %w", err)
+			}
+			return String, nil
+		}
+	case InCIDR, NotInCIDR:
+		if v, ok := c.Value.(string); ok {
+			if _, _, err := net.ParseCIDR(v); err != nil {
+				return 0, fmt.Errorf("invalid CIDR: %w", err)
+			}
+			return String, nil
+		}
+	case Contains, NotContains, StartsWith, NotStartsWith, EndsWith, NotEndsWith:
+		if _, ok := c.Value.(string); ok {
+			return String, nil
+		}
+	default:
+		return 0, fmt.Errorf("unknown operator %d", c.Operator)
+	}
+
+	return 0, fmt.Errorf("%s does not accept %T values: %w", operatorToString(c.Operator), c.Value, ErrTypeMismatch)
+}
@@ -0,0 +1,79 @@
+package mframe_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestPrepare(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1", "bytes": i})
+	}
+
+	prepared, err := cache.Prepare(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "host", Value: "web-1"},
+		{Operator: mframe.Between, Key: "bytes", Value: []float64{2, 4}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := prepared.Run(&cache)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results.Count() != 3 {
+		t.Errorf("expected 3 rows, but got %d", results.Count())
+	}
+
+	var other mframe.DataFrame
+	other.Init(24 * time.Hour)
+	other.Insert(map[mframe.KeyName]interface{}{"host": "web-1", "bytes": "3"})
+	if _, err := prepared.Run(&other); !errors.Is(err, mframe.ErrTypeMismatch) {
+		t.Errorf("expected a type mismatch on a frame with string bytes, but got %v", err)
+	}
+}
+
+func TestPrepareMismatches(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1", "bytes": 10})
+
+	tests := []struct {
+		name      string
+		condition mframe.Condition
+		mismatch  bool
+	}{
+		{"integer value", mframe.Condition{Operator: mframe.Greater, Key: "bytes", Value: 5}, true},
+		{"string for numeric key", mframe.Condition{Operator: mframe.Equals, Key: "bytes", Value: "10"}, true},
+		{"contains on numeric key", mframe.Condition{Operator: mframe.Contains, Key: "bytes", Value: "1"}, true},
+		{"short range", mframe.Condition{Operator: mframe.Between, Key: "bytes", Value: []float64{1}}, true},
+		{"invalid regular expression", mframe.Condition{Operator: mframe.RegExp, Key: "host", Value: "("}, false},
+		{"invalid CIDR", mframe.Condition{Operator: mframe.InCIDR, Key: "host", Value: "10.0.0.0"}, false},
+		{"unknown operator", mframe.Condition{Operator: mframe.Operator(99), Key: "host", Value: "x"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cache.Prepare(mframe.FilterSpec{Conditions: []mframe.Condition{tt.condition}})
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if errors.Is(err, mframe.ErrTypeMismatch) != tt.mismatch {
+				t.Errorf("expected mismatch %v, but got %v", tt.mismatch, err)
+			}
+		})
+	}
+
+	if _, err := cache.Prepare(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "missing", Value: true},
+	}}); err != nil {
+		t.Errorf("expected keys not mapped yet to be accepted, but got %v", err)
+	}
+}
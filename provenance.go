@@ -0,0 +1,36 @@
+package mframe
+
+// Provenance keys added to rows produced by combining frames, so consumers can trace
+// where each row came from.
+const (
+	// ProvenanceSourceFrame holds the name of the frame the row, or its joined fields, came from.
+	ProvenanceSourceFrame KeyName = "_source_frame"
+	// ProvenanceSourceID holds the ID of the row in the frame it was copied from.
+	ProvenanceSourceID KeyName = "_source_id"
+	// ProvenanceJoinedOn holds the key, or comma-separated keys, two rows were joined on.
+	ProvenanceJoinedOn KeyName = "_joined_on"
+	// ProvenanceMatchedID holds the ID of the row of the other frame that was matched.
+	ProvenanceMatchedID KeyName = "_matched_id"
+)
+
+// SetName sets the name identifying the DataFrame in provenance keys.
+func (d *DataFrame) SetName(name string) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+	d.name = name
+}
+
+// Name returns the name of the DataFrame.
+func (d *DataFrame) Name() string {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return d.name
+}
+
+// SetProvenance enables or disables adding provenance keys to rows combined from other frames by Join and
+// Merge, and by AppendWithOptions when requested with AppendOptions.Provenance. Provenance is enabled by default.
+func (d *DataFrame) SetProvenance(enabled bool) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+	d.noProvenance = !enabled
+}
@@ -0,0 +1,200 @@
+package mframe
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// QualityRule declares the expectations for the values of a key.
+// Only the constraints that are set are checked.
+type QualityRule struct {
+	Key      KeyName
+	Required bool          // The key must be present in every row
+	Pattern  string        // String values must match this regular expression
+	Min      *float64      // Numeric values must be greater than or equal to Min
+	Max      *float64      // Numeric values must be less than or equal to Max
+	Allowed  []interface{} // Values must be one of these
+}
+
+// Keys of the rows of a violations DataFrame.
+const (
+	ViolationRowID   KeyName = "row_id"
+	ViolationKey     KeyName = "key"
+	ViolationRule    KeyName = "rule"
+	ViolationValue   KeyName = "value"
+	ViolationMessage KeyName = "message"
+)
+
+// compiledQualityRule is a QualityRule ready to be evaluated.
+type compiledQualityRule struct {
+	QualityRule
+	pattern *regexp.Regexp
+	allowed map[interface{}]bool
+}
+
+// quality holds the quality rules of a DataFrame and the violations found on insert.
+type quality struct {
+	mutex          sync.RWMutex
+	rules          []compiledQualityRule
+	onInsert       bool
+	hookRegistered bool
+	violations     *DataFrame
+}
+
+// AddQualityRule adds a rule checked by ValidateAll and, when enabled, on insert.
+// Returns an error if the key is empty or the pattern is not a valid regular expression.
+func (d *DataFrame) AddQualityRule(rule QualityRule) error {
+	if rule.Key == "" {
+		return fmt.Errorf("quality rule key cannot be empty")
+	}
+
+	compiled := compiledQualityRule{QualityRule: rule}
+
+	if rule.Pattern != "" {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern for key '%s': %w", rule.Key, err)
+		}
+		compiled.pattern = re
+	}
+
+	if len(rule.Allowed) > 0 {
+		compiled.allowed = make(map[interface{}]bool, len(rule.Allowed))
+		for _, v := range rule.Allowed {
+			compiled.allowed[normalizeValue(v)] = true
+		}
+	}
+
+	d.quality.mutex.Lock()
+	defer d.quality.mutex.Unlock()
+	d.quality.rules = append(d.quality.rules, compiled)
+
+	return nil
+}
+
+// SetQualityOnInsert enables or disables checking the quality rules on every insert.
+// Violations found on insert are recorded in the DataFrame returned by QualityViolations;
+// the offending rows are still inserted.
+func (d *DataFrame) SetQualityOnInsert(enabled bool) {
+	d.quality.mutex.Lock()
+	defer d.quality.mutex.Unlock()
+
+	d.quality.onInsert = enabled
+	if !enabled || d.quality.hookRegistered {
+		return
+	}
+
+	d.quality.hookRegistered = true
+	d.OnAfterInsert(func(id uuid.UUID, row Row) {
+		d.quality.mutex.Lock()
+		defer d.quality.mutex.Unlock()
+
+		if !d.quality.onInsert {
+			return
+		}
+
+		violations := d.checkQuality(id, row)
+		if len(violations) == 0 {
+			return
+		}
+
+		if d.quality.violations == nil {
+			d.quality.violations = new(DataFrame)
+			d.quality.violations.Init(d.TTL)
+		}
+		for _, v := range violations {
+			d.quality.violations.Insert(v)
+		}
+	})
+}
+
+// QualityViolations returns the violations found on insert since quality checks were enabled.
+// Each row holds the ViolationRowID, ViolationKey, ViolationRule, ViolationValue and ViolationMessage keys.
+func (d *DataFrame) QualityViolations() *DataFrame {
+	d.quality.mutex.RLock()
+	violations := d.quality.violations
+	d.quality.mutex.RUnlock()
+
+	if violations == nil {
+		d.Locker.RLock()
+		defer d.Locker.RUnlock()
+		violations = new(DataFrame)
+		violations.Init(d.TTL)
+	}
+
+	return violations
+}
+
+// ValidateAll checks every row against the quality rules and returns a DataFrame of violations,
+// with the same keys as the one returned by QualityViolations.
+func (d *DataFrame) ValidateAll() *DataFrame {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	d.quality.mutex.RLock()
+	defer d.quality.mutex.RUnlock()
+
+	var results = new(DataFrame)
+	results.Init(d.TTL)
+
+	for id, row := range d.Data {
+		for _, v := range d.checkQuality(id, row) {
+			results.Insert(v)
+		}
+	}
+
+	return results
+}
+
+// checkQuality evaluates the rules against a row and returns one violation row per failed constraint.
+// The caller must hold the quality mutex.
+func (d *DataFrame) checkQuality(id uuid.UUID, row Row) []map[KeyName]interface{} {
+	var violations []map[KeyName]interface{}
+
+	add := func(rule compiledQualityRule, name string, value interface{}, message string) {
+		violation := map[KeyName]interface{}{
+			ViolationRowID:   id.String(),
+			ViolationKey:     string(rule.Key),
+			ViolationRule:    name,
+			ViolationMessage: message,
+		}
+		if value != nil {
+			violation[ViolationValue] = fmt.Sprint(value)
+		}
+		violations = append(violations, violation)
+	}
+
+	for _, rule := range d.quality.rules {
+		value, ok := row[rule.Key]
+		if !ok {
+			if rule.Required {
+				add(rule, "required", nil, fmt.Sprintf("key '%s' is required", rule.Key))
+			}
+			continue
+		}
+
+		if rule.pattern != nil {
+			if s, isString := value.(string); isString && !rule.pattern.MatchString(s) {
+				add(rule, "pattern", value, fmt.Sprintf("value does not match pattern '%s'", rule.Pattern))
+			}
+		}
+
+		if f, isNumber := value.(float64); isNumber {
+			if rule.Min != nil && f < *rule.Min {
+				add(rule, "range", value, fmt.Sprintf("value is lower than %v", *rule.Min))
+			}
+			if rule.Max != nil && f > *rule.Max {
+				add(rule, "range", value, fmt.Sprintf("value is greater than %v", *rule.Max))
+			}
+		}
+
+		if rule.allowed != nil && !rule.allowed[value] {
+			add(rule, "allowed", value, "value is not one of the allowed values")
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,96 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func qualityRules(t *testing.T, df *mframe.DataFrame) {
+	t.Helper()
+
+	minPort, maxPort := 1.0, 65535.0
+	rules := []mframe.QualityRule{
+		{Key: "ip", Required: true, Pattern: `^\d+\.\d+\.\d+\.\d+$`},
+		{Key: "port", Min: &minPort, Max: &maxPort},
+		{Key: "protocol", Allowed: []interface{}{"tcp", "udp"}},
+	}
+
+	for _, rule := range rules {
+		if err := df.AddQualityRule(rule); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	qualityRules(t, &cache)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "port": 22, "protocol": "tcp"},
+		{"ip": "not-an-ip", "port": 22, "protocol": "tcp"},
+		{"port": 70000, "protocol": "icmp"},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	violations := cache.ValidateAll()
+	if violations.Count() != 4 {
+		t.Fatalf("expected 4 violations, but got %d: %v", violations.Count(), violations.ToSlice())
+	}
+
+	for rule, expected := range map[string]int{"required": 1, "pattern": 1, "range": 1, "allowed": 1} {
+		if count := violations.CountWhere(mframe.Equals, mframe.ViolationRule, rule, nil); count != expected {
+			t.Errorf("expected %d '%s' violations, but got %d", expected, rule, count)
+		}
+	}
+
+	patternViolation := violations.Filter(mframe.Equals, mframe.ViolationRule, "pattern", nil).ToSlice()[0]
+	if patternViolation[mframe.ViolationValue] != "not-an-ip" {
+		t.Errorf("expected the offending value to be recorded, but got %v", patternViolation)
+	}
+}
+
+func TestQualityOnInsert(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	qualityRules(t, &cache)
+
+	if cache.QualityViolations().Count() != 0 {
+		t.Error("expected no violations before enabling checks")
+	}
+
+	cache.SetQualityOnInsert(true)
+	cache.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "port": 0})
+	cache.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.2", "port": 80})
+
+	if cache.Count() != 2 {
+		t.Errorf("expected rows to be inserted despite violations, but got %d rows", cache.Count())
+	}
+	if cache.QualityViolations().Count() != 1 {
+		t.Errorf("expected 1 violation, but got %d", cache.QualityViolations().Count())
+	}
+
+	cache.SetQualityOnInsert(false)
+	cache.Insert(map[mframe.KeyName]interface{}{"port": 0})
+	if cache.QualityViolations().Count() != 1 {
+		t.Errorf("expected no new violations once disabled, but got %d", cache.QualityViolations().Count())
+	}
+}
+
+func TestAddQualityRuleErrors(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	if err := cache.AddQualityRule(mframe.QualityRule{}); err == nil {
+		t.Error("expected error for an empty key")
+	}
+	if err := cache.AddQualityRule(mframe.QualityRule{Key: "ip", Pattern: "("}); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+}
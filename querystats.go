@@ -0,0 +1,131 @@
+package mframe
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultQueryHistory is the number of executed queries kept by default for QueryStats.
+const DefaultQueryHistory = 1024
+
+// QueryStat summarizes the recent executions of queries sharing the same shape: the operator, the key
+// and the type of the value, regardless of the value itself.
+type QueryStat struct {
+	Shape      string
+	Operator   Operator
+	Key        KeyName
+	Count      int
+	AvgLatency time.Duration
+	MaxLatency time.Duration
+	AvgRows    float64
+	LastRun    time.Time
+}
+
+// queryRun records a single query execution.
+type queryRun struct {
+	operator Operator
+	key      KeyName
+	shape    string
+	latency  time.Duration
+	rows     int
+	at       time.Time
+}
+
+// queryHistory is a ring buffer of the most recent query executions.
+type queryHistory struct {
+	mutex    sync.Mutex
+	size     int
+	disabled bool
+	runs     []queryRun
+	next     int
+}
+
+// SetQueryHistory sets how many of the most recent query executions are kept for QueryStats.
+// Zero disables the history. The history keeps DefaultQueryHistory executions by default.
+func (d *DataFrame) SetQueryHistory(size int) {
+	h := &d.queryHistory
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.size = size
+	h.disabled = size <= 0
+	h.runs = nil
+	h.next = 0
+}
+
+// QueryStats summarizes the queries kept in the history by shape, ordered from the most to the least executed.
+func (d *DataFrame) QueryStats() []QueryStat {
+	h := &d.queryHistory
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	stats := make(map[string]*QueryStat)
+	totals := make(map[string]time.Duration)
+	rows := make(map[string]int)
+	for _, run := range h.runs {
+		stat, ok := stats[run.shape]
+		if !ok {
+			stat = &QueryStat{Shape: run.shape, Operator: run.operator, Key: run.key}
+			stats[run.shape] = stat
+		}
+		stat.Count++
+		totals[run.shape] += run.latency
+		rows[run.shape] += run.rows
+		stat.MaxLatency = max(stat.MaxLatency, run.latency)
+		if run.at.After(stat.LastRun) {
+			stat.LastRun = run.at
+		}
+	}
+
+	result := make([]QueryStat, 0, len(stats))
+	for shape, stat := range stats {
+		stat.AvgLatency = totals[shape] / time.Duration(stat.Count)
+		stat.AvgRows = float64(rows[shape]) / float64(stat.Count)
+		result = append(result, *stat)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Shape < result[j].Shape
+	})
+
+	return result
+}
+
+// add records a query execution, overwriting the oldest one once the history is full.
+func (h *queryHistory) add(query Condition, latency time.Duration, rows int) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.disabled {
+		return
+	}
+	if h.size == 0 {
+		h.size = DefaultQueryHistory
+	}
+
+	run := queryRun{
+		operator: query.Operator,
+		key:      query.Key,
+		shape:    queryShape(query),
+		latency:  latency,
+		rows:     rows,
+		at:       time.Now().UTC(),
+	}
+
+	if len(h.runs) < h.size {
+		h.runs = append(h.runs, run)
+		return
+	}
+
+	h.runs[h.next] = run
+	h.next = (h.next + 1) % h.size
+}
+
+// queryShape normalizes a query to its operator, key and value type.
+func queryShape(query Condition) string {
+	return fmt.Sprintf("%s(%s, %T)", operatorToString(query.Operator), query.Key, query.Value)
+}
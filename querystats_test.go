@@ -0,0 +1,64 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestQueryStats(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"score": i, "host": "a"})
+	}
+
+	cache.Filter(mframe.Greater, "score", 7.0, nil)
+	cache.Filter(mframe.Greater, "score", 5.0, nil)
+	cache.Filter(mframe.Greater, "score", 3.0, nil)
+	cache.Filter(mframe.Equals, "host", "a", nil)
+
+	stats := cache.QueryStats()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 query shapes, but got %v", stats)
+	}
+
+	greater := stats[0]
+	if greater.Shape != "Greater(score, float64)" || greater.Count != 3 {
+		t.Errorf("expected 3 Greater queries on score first, but got %+v", greater)
+	}
+	if greater.AvgRows != 4 {
+		t.Errorf("expected an average of 4 rows, but got %v", greater.AvgRows)
+	}
+	if greater.MaxLatency < greater.AvgLatency || greater.LastRun.IsZero() {
+		t.Errorf("expected latencies and last run to be set, but got %+v", greater)
+	}
+
+	if stats[1].Key != "host" || stats[1].AvgRows != 10 {
+		t.Errorf("expected the Equals query on host, but got %+v", stats[1])
+	}
+}
+
+func TestQueryHistorySize(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"score": 1})
+
+	cache.SetQueryHistory(2)
+	cache.Filter(mframe.Equals, "score", 1.0, nil)
+	cache.Filter(mframe.Less, "score", 1.0, nil)
+	cache.Filter(mframe.Less, "score", 1.0, nil)
+
+	stats := cache.QueryStats()
+	if len(stats) != 1 || stats[0].Count != 2 {
+		t.Errorf("expected only the 2 most recent queries, but got %v", stats)
+	}
+
+	cache.SetQueryHistory(0)
+	cache.Filter(mframe.Equals, "score", 1.0, nil)
+	if stats := cache.QueryStats(); len(stats) != 0 {
+		t.Errorf("expected no history when disabled, but got %v", stats)
+	}
+}
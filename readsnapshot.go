@@ -0,0 +1,215 @@
+package mframe
+
+import (
+	"maps"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// readSnapshots holds the latest read snapshot of a DataFrame, published atomically for Snapshot.
+type readSnapshots struct {
+	current    atomic.Pointer[readSnapshot]
+	refreshing atomic.Bool
+	interval   atomic.Int64
+}
+
+// readSnapshot is an immutable copy of a DataFrame at a generation.
+type readSnapshot struct {
+	frame      *DataFrame
+	generation uint64
+	at         time.Time
+}
+
+// SetSnapshotInterval sets the minimum time between two refreshes of the snapshot returned by Snapshot.
+// Longer intervals make reads staler and cheaper on frames receiving a steady flow of inserts. Zero, the
+// default, refreshes the snapshot whenever the DataFrame changed.
+func (d *DataFrame) SetSnapshotInterval(interval time.Duration) {
+	d.snapshots.interval.Store(int64(max(interval, 0)))
+}
+
+// Snapshot returns a read-only copy of the DataFrame, so Filter, the math functions and any other read
+// run on it never wait for inserts or the cleaner. The copy is refreshed by the reads themselves, at most
+// once per snapshot interval: a read finding the DataFrame locked by a writer returns the previous copy
+// instead of waiting, so results may lag behind the latest writes. Only the first call waits for the lock.
+//
+// Refreshing copies the rows changed since the previous snapshot and the index entries of their keys, and
+// shares the rest with the previous snapshot, so the returned DataFrame and the rows it holds must never be
+// modified. Snapshots do not carry the options of the DataFrame, such as hooks, accelerators or tags.
+func (d *DataFrame) Snapshot() *DataFrame {
+	current := d.snapshots.current.Load()
+	if current == nil {
+		d.Locker.RLock()
+		next := d.nextSnapshotUnlocked(nil)
+		d.Locker.RUnlock()
+
+		d.snapshots.current.Store(next)
+		return next.frame
+	}
+
+	if time.Since(current.at) < time.Duration(d.snapshots.interval.Load()) {
+		return current.frame
+	}
+
+	if !d.snapshots.refreshing.CompareAndSwap(false, true) {
+		return current.frame
+	}
+	defer d.snapshots.refreshing.Store(false)
+
+	if !d.Locker.TryRLock() {
+		return current.frame
+	}
+	next := d.nextSnapshotUnlocked(current)
+	d.Locker.RUnlock()
+
+	d.snapshots.current.Store(next)
+	return next.frame
+}
+
+// nextSnapshotUnlocked returns the snapshot following previous, copying the rows changed since it, or a
+// complete copy when there is no previous snapshot or its changes are no longer remembered, without
+// acquiring locks.
+func (d *DataFrame) nextSnapshotUnlocked(previous *readSnapshot) *readSnapshot {
+	next := &readSnapshot{generation: d.changes.generation, at: time.Now()}
+
+	if previous != nil && previous.generation == next.generation {
+		next.frame = previous.frame
+		return next
+	}
+
+	var changed []uuid.UUID
+	var err error
+	if previous != nil {
+		changed, err = d.changedSinceUnlocked(previous.generation)
+	}
+
+	frame := new(DataFrame)
+	frame.Init(d.TTL)
+	frame.name = d.name
+	frame.coercion = d.coercion
+	frame.Keys = maps.Clone(d.Keys)
+	frame.ExpireAt = maps.Clone(d.ExpireAt)
+	frame.changes.generation = next.generation
+
+	if previous == nil || err != nil {
+		for id, row := range d.Data {
+			frame.Data[id] = copyRow(row)
+		}
+		frame.Strings = cloneIndex(d.Strings)
+		frame.Numerics = cloneIndex(d.Numerics)
+		frame.Booleans = cloneIndex(d.Booleans)
+		frame.Times = cloneIndex(d.Times)
+
+		next.frame = frame
+		return next
+	}
+
+	base := previous.frame
+	frame.Data = maps.Clone(base.Data)
+	strs := newCowIndex(base.Strings)
+	nums := newCowIndex(base.Numerics)
+	bools := newCowIndex(base.Booleans)
+	times := newCowIndex(base.Times)
+
+	update := func(id uuid.UUID, row Row, add bool) {
+		for key, value := range row {
+			if d.notIndexedUnlocked(key) {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				strs.update(key, v, id, add)
+			case float64:
+				nums.update(key, v, id, add)
+			case bool:
+				bools.update(key, v, id, add)
+			case time.Time:
+				times.update(key, v, id, add)
+			}
+		}
+	}
+
+	for _, id := range changed {
+		if old, ok := base.Data[id]; ok {
+			update(id, old, false)
+			delete(frame.Data, id)
+		}
+		if row, ok := d.Data[id]; ok {
+			row = copyRow(row)
+			update(id, row, true)
+			frame.Data[id] = row
+		}
+	}
+
+	frame.Strings = strs.index
+	frame.Numerics = nums.index
+	frame.Booleans = bools.index
+	frame.Times = times.index
+
+	next.frame = frame
+	return next
+}
+
+// cloneIndex returns a deep copy of an index.
+func cloneIndex[V comparable](index map[KeyName]map[V]map[uuid.UUID]bool) map[KeyName]map[V]map[uuid.UUID]bool {
+	clone := make(map[KeyName]map[V]map[uuid.UUID]bool, len(index))
+	for key, values := range index {
+		clone[key] = make(map[V]map[uuid.UUID]bool, len(values))
+		for value, ids := range values {
+			clone[key][value] = maps.Clone(ids)
+		}
+	}
+	return clone
+}
+
+// cowIndex is an index sharing its maps with the index of a previous snapshot until they are modified,
+// when they are copied.
+type cowIndex[V comparable] struct {
+	index  map[KeyName]map[V]map[uuid.UUID]bool
+	keys   map[KeyName]bool
+	values map[KeyName]map[V]bool
+}
+
+// newCowIndex returns a copy-on-write index over base, which is never modified.
+func newCowIndex[V comparable](base map[KeyName]map[V]map[uuid.UUID]bool) *cowIndex[V] {
+	return &cowIndex[V]{
+		index:  maps.Clone(base),
+		keys:   make(map[KeyName]bool),
+		values: make(map[KeyName]map[V]bool),
+	}
+}
+
+// update adds the ID to the IDs of the value, or removes it when add is false, copying the shared maps
+// it modifies first. Values and keys left without IDs are removed.
+func (c *cowIndex[V]) update(key KeyName, value V, id uuid.UUID, add bool) {
+	if !c.keys[key] {
+		c.index[key] = maps.Clone(c.index[key])
+		c.keys[key] = true
+		c.values[key] = make(map[V]bool)
+	}
+	if c.index[key] == nil {
+		c.index[key] = make(map[V]map[uuid.UUID]bool)
+	}
+
+	if !c.values[key][value] {
+		c.index[key][value] = maps.Clone(c.index[key][value])
+		c.values[key][value] = true
+	}
+	if c.index[key][value] == nil {
+		c.index[key][value] = make(map[uuid.UUID]bool)
+	}
+
+	if add {
+		c.index[key][value][id] = true
+		return
+	}
+
+	delete(c.index[key][value], id)
+	if len(c.index[key][value]) == 0 {
+		delete(c.index[key], value)
+	}
+	if len(c.index[key]) == 0 {
+		delete(c.index, key)
+	}
+}
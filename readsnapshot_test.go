@@ -0,0 +1,123 @@
+package mframe_test
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSnapshot(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "bytes": 100},
+		{"host": "web-2", "bytes": 200},
+	})
+
+	first := cache.Snapshot()
+	if first.Count() != 2 {
+		t.Fatalf("expected 2 rows, but got %d", first.Count())
+	}
+
+	id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"host": "web-3", "bytes": 300})
+	if _, err := cache.UpdateWhere(mframe.FilterSpec{Conditions: []mframe.Condition{{Operator: mframe.Equals, Key: "host", Value: "web-1"}}}, map[mframe.KeyName]interface{}{"bytes": 150}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache.DeleteWhere(mframe.Equals, "host", "web-2", nil)
+
+	second := cache.Snapshot()
+	if second.Count() != 2 {
+		t.Errorf("expected 2 rows, but got %d", second.Count())
+	}
+	if sum, _ := second.Sum("bytes"); sum != 450 {
+		t.Errorf("expected a sum of 450, but got %f", sum)
+	}
+	if n := second.Filter(mframe.Equals, "host", "web-2", nil).Count(); n != 0 {
+		t.Errorf("expected the removed row to be gone, but got %d rows", n)
+	}
+	if _, ok := second.Get(id); !ok {
+		t.Errorf("expected the inserted row")
+	}
+
+	if sum, _ := first.Sum("bytes"); sum != 300 {
+		t.Errorf("expected the first snapshot to be unchanged with a sum of 300, but got %f", sum)
+	}
+	if n := first.Filter(mframe.Equals, "host", "web-2", nil).Count(); n != 1 {
+		t.Errorf("expected the first snapshot to keep the removed row, but got %d rows", n)
+	}
+
+	if cache.Snapshot() != second {
+		t.Errorf("expected the same snapshot when nothing changed")
+	}
+}
+
+func TestSnapshotInterval(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetSnapshotInterval(time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+
+	snapshot := cache.Snapshot()
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-2"})
+
+	if cache.Snapshot() != snapshot || snapshot.Count() != 1 {
+		t.Errorf("expected the stale snapshot within the interval")
+	}
+}
+
+func TestSnapshotAfterLoad(t *testing.T) {
+	var source mframe.DataFrame
+	source.Init(24 * time.Hour)
+	source.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+	source.Insert(map[mframe.KeyName]interface{}{"host": "web-2"})
+
+	filename := filepath.Join(t.TempDir(), "frame.gob")
+	if err := source.SaveToFile(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "old"})
+	_ = cache.Snapshot()
+
+	if err := cache.LoadFromFile(filename); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot := cache.Snapshot()
+	if snapshot.Count() != 2 || snapshot.Filter(mframe.Equals, "host", "old", nil).Count() != 0 {
+		t.Errorf("expected the loaded rows, but got %d rows", snapshot.Count())
+	}
+}
+
+func TestSnapshotConcurrent(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			cache.Insert(map[mframe.KeyName]interface{}{"seq": i})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 500; i++ {
+			snapshot := cache.Snapshot()
+			if n := snapshot.Filter(mframe.GreaterOrEqual, "seq", 0.0, nil).Count(); n != snapshot.Count() {
+				t.Errorf("expected an index consistent with %d rows, but got %d", snapshot.Count(), n)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if n := cache.Snapshot().Count(); n != 500 {
+		t.Errorf("expected 500 rows, but got %d", n)
+	}
+}
@@ -0,0 +1,116 @@
+package mframe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReindexKey converts the values of key in every row to keyType and rebuilds the index of the key, for keys
+// whose type was decided by a first value of the wrong type, such as a number sent as a string. Later values
+// of the other type were dropped with a mapping error; once reindexed, they are accepted, and coerced when
+// enabled with SetCoercion.
+//
+// Strings are converted like with coercion, whatever the options set with SetCoercion: numbers are parsed
+// as decimals, booleans as "true"/"false", "t"/"f" and "1"/"0" and times as RFC 3339, the registered layouts,
+// the layouts of the DataFrame or epoch seconds or milliseconds. Numbers, booleans and times are converted to
+// strings in their canonical form, and numbers to times as epoch seconds or milliseconds. Acceleration
+// structures of another type are dropped. Rows keep their IDs and expiration.
+//
+// Returns the number of modified rows. No row is modified if a value cannot be converted.
+func (d *DataFrame) ReindexKey(key KeyName, keyType KeyType) (int, error) {
+	if keyType < String || keyType > Time {
+		return 0, fmt.Errorf("invalid type %d for key '%s'", keyType, key)
+	}
+
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	if d.notIndexedUnlocked(key) {
+		return 0, fmt.Errorf("key '%s' is not indexed", key)
+	}
+
+	current, mapped := d.Keys[key]
+	if mapped && current == keyType {
+		return 0, nil
+	}
+
+	converted := make(map[uuid.UUID]interface{})
+	for id, row := range d.Data {
+		value, ok := row[key]
+		if !ok {
+			continue
+		}
+		v, ok := d.convertUnlocked(value, keyType)
+		if !ok {
+			return 0, fmt.Errorf("cannot convert value '%v' of key '%s' to %s", value, key, keyTypeToString(keyType))
+		}
+		converted[id] = v
+	}
+
+	for kind := SortedNumericIndex; kind <= TrigramIndex; kind++ {
+		if kindType, _ := kind.keyType(); kindType != keyType {
+			d.dropAcceleratorUnlocked(key, kind)
+		}
+	}
+
+	// The key is removed from every row first, so it is free to be mapped to the new type.
+	if _, declared := d.declared[key]; declared {
+		d.declared[key] = keyType
+	}
+	for id := range converted {
+		d.updateFieldsUnlocked(id, nil, []KeyName{key})
+	}
+	d.Keys[key] = keyType
+	for id, value := range converted {
+		d.updateFieldsUnlocked(id, Row{key: value}, nil)
+	}
+
+	return len(converted), nil
+}
+
+// convertUnlocked converts a stored value to keyType for ReindexKey, without acquiring locks.
+// Returns false if the value cannot be converted.
+func (d *DataFrame) convertUnlocked(value interface{}, keyType KeyType) (interface{}, bool) {
+	switch keyType {
+	case String:
+		switch v := value.(type) {
+		case string:
+			return v, true
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(v), true
+		case time.Time:
+			return v.Format(time.RFC3339Nano), true
+		}
+	case Numeric:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case string:
+			return parseFinite(v)
+		}
+	case Boolean:
+		switch v := value.(type) {
+		case bool:
+			return v, true
+		case string:
+			b, err := strconv.ParseBool(strings.ToLower(strings.TrimSpace(v)))
+			return b, err == nil
+		}
+	case Time:
+		switch v := value.(type) {
+		case time.Time:
+			return v, true
+		case string:
+			return parseTime(v, d.coercion.TimeLayouts, true)
+		case float64:
+			return parseTime(strconv.FormatFloat(v, 'f', -1, 64), nil, true)
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,65 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestReindexKey(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"bytes": "42", "host": "a"})
+	cache.Insert(map[mframe.KeyName]interface{}{"bytes": " 8 ", "host": "b"})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "c"})
+	if err := cache.InsertWithError(map[mframe.KeyName]interface{}{"bytes": 100}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cache.Filter(mframe.Equals, "bytes", 100.0, nil).Count(); got != 0 {
+		t.Fatalf("expected the number to be dropped before reindexing, but got %d rows", got)
+	}
+
+	modified, err := cache.ReindexKey("bytes", mframe.Numeric)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if modified != 2 {
+		t.Errorf("expected 2 modified rows, but got %d", modified)
+	}
+	if got := cache.Filter(mframe.Equals, "bytes", 42.0, nil).Count(); got != 1 {
+		t.Errorf("expected 1 row with 42, but got %d", got)
+	}
+	if got := cache.Filter(mframe.Equals, "bytes", "42", nil).Count(); got != 0 {
+		t.Errorf("expected no string values left, but got %d", got)
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"bytes": 100})
+	if sum, _ := cache.Sum("bytes"); sum != 150 {
+		t.Errorf("expected sum 150, but got %v", sum)
+	}
+
+	if modified, err := cache.ReindexKey("bytes", mframe.Numeric); err != nil || modified != 0 {
+		t.Errorf("expected no change for the same type, but got %d, %v", modified, err)
+	}
+}
+
+func TestReindexKeyRejectsInvalidValues(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"port": "80"})
+	cache.Insert(map[mframe.KeyName]interface{}{"port": "http"})
+
+	if _, err := cache.ReindexKey("port", mframe.Numeric); err == nil {
+		t.Fatalf("expected an error for a value that is not a number")
+	}
+	if got := cache.Filter(mframe.Equals, "port", "80", nil).Count(); got != 1 {
+		t.Errorf("expected the rows to be left unchanged, but got %d", got)
+	}
+
+	if _, err := cache.ReindexKey("port", mframe.KeyType(9)); err == nil {
+		t.Errorf("expected an error for an invalid type")
+	}
+}
@@ -0,0 +1,52 @@
+package mframe
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/google/uuid"
+)
+
+// RenderRows executes the text/template tmpl once for each row matching every condition, writing the output
+// to w, e.g. "{{.ip}}\n" to produce a block-list with one IP per line. Without conditions every row is rendered.
+// Each row is passed to the template as a map[string]interface{} including the defaults declared with SetDefault;
+// keys that are not valid identifiers, such as flattened keys, are read with index, e.g. {{index . "src.ip"}}.
+// Rows are rendered in ID order, after the lock is released, so a slow writer does not block the DataFrame.
+// Returns an error if the template cannot be parsed or executed, or the writer fails.
+func (d *DataFrame) RenderRows(tmpl string, w io.Writer, conditions ...Condition) error {
+	t, err := template.New("rows").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	d.Locker.RLock()
+	ids := make([]uuid.UUID, 0)
+	for id := range d.specIDsUnlocked(FilterSpec{Conditions: conditions}) {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	rows := make([]map[string]interface{}, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, templateRow(d.copyRowUnlocked(d.Data[id])))
+	}
+	d.Locker.RUnlock()
+
+	for _, row := range rows {
+		if err := t.Execute(w, row); err != nil {
+			return fmt.Errorf("failed to render row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// templateRow converts a row to a map keyed by strings, so templates can read its keys as fields.
+func templateRow(row Row) map[string]interface{} {
+	data := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		data[string(k)] = v
+	}
+	return data
+}
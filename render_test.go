@@ -0,0 +1,59 @@
+package mframe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestRenderRows(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "verdict": "block", "geo": map[string]interface{}{"country": "NL"}},
+		{"ip": "10.0.0.2", "verdict": "allow", "geo": map[string]interface{}{"country": "US"}},
+		{"ip": "10.0.0.3", "verdict": "block", "geo": map[string]interface{}{"country": "DE"}},
+	})
+
+	tests := []struct {
+		name       string
+		tmpl       string
+		conditions []mframe.Condition
+		expected   []string
+	}{
+		{"block-list", "{{.ip}}\n", []mframe.Condition{{Operator: mframe.Equals, Key: "verdict", Value: "block"}}, []string{"10.0.0.1", "10.0.0.3"}},
+		{"flattened key", `{{.ip}} {{index . "geo.country"}}` + "\n", []mframe.Condition{{Operator: mframe.Equals, Key: "ip", Value: "10.0.0.2"}}, []string{"10.0.0.2 US"}},
+		{"every row", "{{.verdict}}\n", nil, []string{"allow", "block", "block"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			if err := cache.RenderRows(tt.tmpl, &out, tt.conditions...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != len(tt.expected) {
+				t.Fatalf("expected %d lines, but got %q", len(tt.expected), out.String())
+			}
+
+			count := make(map[string]int)
+			for _, line := range lines {
+				count[line]++
+			}
+			for _, line := range tt.expected {
+				if count[line] == 0 {
+					t.Errorf("expected line %q, but got %q", line, out.String())
+				}
+				count[line]--
+			}
+		})
+	}
+
+	if err := cache.RenderRows("{{.ip", &strings.Builder{}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,106 @@
+package mframe
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RowFormat selects the encoding used by ExportRow.
+type RowFormat int
+
+const (
+	// RowFormatJSON encodes the row as a JSON object with sorted keys.
+	RowFormatJSON RowFormat = 1
+	// RowFormatBinary encodes the row in a compact binary form with sorted keys. Each key is written as its
+	// length as a uvarint followed by its bytes, then a type byte ('s' string, 'n' numeric, 'b' boolean,
+	// 't' time) and the value: strings as their length as a uvarint and bytes, numbers as the big endian
+	// IEEE 754 bits, booleans as one byte and times as big endian Unix nanoseconds.
+	RowFormatBinary RowFormat = 2
+)
+
+// RowHash returns a stable content hash of the row with the specified ID: the hex encoded SHA-256 of
+// its RowFormatBinary encoding. Rows with the same keys and values have the same hash regardless of
+// their ID, the frame holding them or the order they were inserted in.
+// Returns an error if the row does not exist.
+func (d *DataFrame) RowHash(id uuid.UUID) (string, error) {
+	data, err := d.ExportRow(id, RowFormatBinary)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ExportRow encodes the row with the specified ID in the given format.
+// Returns an error if the row does not exist or the format is unknown.
+func (d *DataFrame) ExportRow(id uuid.UUID, format RowFormat) ([]byte, error) {
+	d.Locker.RLock()
+	row, ok := d.Data[id]
+	if ok {
+		row = copyRow(row)
+	}
+	d.Locker.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("row '%s' not found", id)
+	}
+
+	switch format {
+	case RowFormatJSON:
+		return json.Marshal(row)
+	case RowFormatBinary:
+		return appendRowBinary(nil, row), nil
+	default:
+		return nil, fmt.Errorf("unknown row format '%v'", format)
+	}
+}
+
+// appendRowBinary appends the RowFormatBinary encoding of the row to buf.
+func appendRowBinary(buf []byte, row Row) []byte {
+	keys := make([]string, 0, len(row))
+	for k := range row {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		buf = binary.AppendUvarint(buf, uint64(len(k)))
+		buf = append(buf, k...)
+
+		switch v := row[KeyName(k)].(type) {
+		case string:
+			buf = append(buf, 's')
+			buf = binary.AppendUvarint(buf, uint64(len(v)))
+			buf = append(buf, v...)
+		case float64:
+			buf = append(buf, 'n')
+			buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(v))
+		case bool:
+			buf = append(buf, 'b')
+			if v {
+				buf = append(buf, 1)
+			} else {
+				buf = append(buf, 0)
+			}
+		case time.Time:
+			buf = append(buf, 't')
+			buf = binary.BigEndian.AppendUint64(buf, uint64(v.UnixNano()))
+		default:
+			buf = append(buf, '?')
+			s := fmt.Sprint(v)
+			buf = binary.AppendUvarint(buf, uint64(len(s)))
+			buf = append(buf, s...)
+		}
+	}
+
+	return buf
+}
@@ -0,0 +1,84 @@
+package mframe_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestRowHash(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var a, b mframe.DataFrame
+	a.Init(24 * time.Hour)
+	b.Init(time.Hour)
+
+	a.Insert(map[mframe.KeyName]interface{}{"host": "web", "port": 443, "tls": true, "seen": at})
+	a.Insert(map[mframe.KeyName]interface{}{"host": "web", "port": 80, "tls": false, "seen": at})
+	b.Insert(map[mframe.KeyName]interface{}{"seen": at, "tls": true, "port": 443.0, "host": "web"})
+
+	hashes := make(map[float64]string)
+	for id, row := range a.Data {
+		hash, err := a.RowHash(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		hashes[row["port"].(float64)] = hash
+	}
+
+	if hashes[443] == hashes[80] {
+		t.Error("expected different rows to have different hashes")
+	}
+
+	for id := range b.Data {
+		hash, err := b.RowHash(id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hash != hashes[443] {
+			t.Errorf("expected equal rows in different frames to have the same hash, but got %s and %s", hash, hashes[443])
+		}
+	}
+
+	if _, err := a.RowHash(uuid.New()); err == nil {
+		t.Error("expected error for a missing row")
+	}
+}
+
+func TestExportRow(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web", "port": 443})
+
+	for id := range cache.Data {
+		data, err := cache.ExportRow(id, mframe.RowFormatJSON)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != `{"host":"web","port":443}` {
+			t.Errorf("expected JSON with sorted keys, but got %s", data)
+		}
+
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Errorf("expected valid JSON, but got %v", err)
+		}
+
+		binary, err := cache.ExportRow(id, mframe.RowFormatBinary)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		again, _ := cache.ExportRow(id, mframe.RowFormatBinary)
+		if len(binary) == 0 || string(binary) != string(again) {
+			t.Error("expected a stable binary encoding")
+		}
+
+		if _, err := cache.ExportRow(id, mframe.RowFormat(99)); err == nil {
+			t.Error("expected error for an unknown format")
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package mframe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3UnsignedPayload is the payload hash of requests whose body is not signed.
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Store is a SnapshotStore keeping snapshots as objects of an S3-compatible bucket, such as AWS S3, MinIO
+// or Ceph, signing requests with AWS Signature Version 4. Objects are addressed in path style, as
+// Endpoint/Bucket/Prefix+name.
+type S3Store struct {
+	// Endpoint is the base URL of the service, e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000.
+	Endpoint string
+	Bucket   string
+	// Prefix is prepended to the names of the snapshots, e.g. "frames/".
+	Prefix string
+	// Region is the region used to sign requests, us-east-1 when empty.
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// Client is the HTTP client used, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// Put uploads the snapshot with a single PUT request.
+func (s S3Store) Put(ctx context.Context, name string, body io.Reader, size int64) error {
+	req, err := s.request(ctx, http.MethodPut, name, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	return nil
+}
+
+// Get downloads the snapshot. Missing objects return an error wrapping os.ErrNotExist.
+func (s S3Store) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// request builds an unsigned request for the object of a snapshot.
+func (s S3Store) request(ctx context.Context, method, name string, body io.Reader) (*http.Request, error) {
+	if s.Bucket == "" {
+		return nil, fmt.Errorf("S3 store has no bucket")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("invalid snapshot name '%s'", name)
+	}
+
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint '%s'", s.Endpoint)
+	}
+
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + s.Bucket + "/" + s.Prefix + name
+	endpoint.RawPath = s3EncodePath(endpoint.Path)
+
+	return http.NewRequestWithContext(ctx, method, endpoint.String(), body)
+}
+
+// do signs and sends a request, turning non-2xx responses into errors.
+func (s S3Store) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	err = fmt.Errorf("S3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", os.ErrNotExist, err)
+	}
+
+	return nil, err
+}
+
+// sign adds the AWS Signature Version 4 headers to a request, signing the host, the x-amz-* headers and any
+// other header already set, with an unsigned payload.
+func (s S3Store) sign(req *http.Request, now time.Time) {
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	scope := day + "/" + region + "/s3/aws4_request"
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	}
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3CanonicalQuery returns the query parameters sorted and encoded as AWS Signature Version 4 expects.
+func s3CanonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, s3Encode(name, true)+"="+s3Encode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// s3EncodePath encodes a path as AWS Signature Version 4 expects, keeping the slashes.
+func s3EncodePath(path string) string {
+	return s3Encode(path, false)
+}
+
+// s3Encode percent-encodes every byte but the unreserved characters, and the slashes unless encodeSlash is set.
+func s3Encode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package mframe_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+// fakeS3 keeps the objects uploaded to it in memory, rejecting unsigned requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Security-Token") != "token" {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	}
+}
+
+func TestS3Store(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ctx := context.Background()
+	store := mframe.S3Store{
+		Endpoint:     server.URL,
+		Bucket:       "snapshots",
+		Prefix:       "frames/",
+		Region:       "eu-west-1",
+		AccessKey:    "AKID",
+		SecretKey:    "secret",
+		SessionToken: "token",
+		Client:       server.Client(),
+	}
+
+	var cache mframe.DataFrame
+	cache.Init(time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+
+	if err := cache.SaveToStore(ctx, store, "alerts 2024.snapshot"); err != nil {
+		t.Fatalf("Failed to save to S3: %v", err)
+	}
+	if _, ok := fake.objects["/snapshots/frames/alerts 2024.snapshot"]; !ok {
+		t.Fatalf("expected the object to be stored under the bucket and prefix, but got %v", fake.objects)
+	}
+
+	var restored mframe.DataFrame
+	restored.Init(time.Hour)
+	if err := restored.LoadFromStore(ctx, store, "alerts 2024.snapshot"); err != nil {
+		t.Fatalf("Failed to load from S3: %v", err)
+	}
+	if restored.Count() != 1 {
+		t.Errorf("expected 1 row, but got %d", restored.Count())
+	}
+
+	if err := restored.LoadFromStore(ctx, store, "missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, but got %v", err)
+	}
+
+	store.SessionToken = ""
+	if err := cache.SaveToStore(ctx, store, "denied"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected a 403 error, but got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+package mframe
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldSchema declares the type and constraints of a key in a Schema.
+type FieldSchema struct {
+	Type     KeyType
+	Required bool          // The key must be present in every row
+	Allowed  []interface{} // Values must be one of these
+	Pattern  string        // String values must match this regular expression
+}
+
+// Schema maps keys, by their dotted name for nested values, to the declaration of their values.
+// Keys not in the schema are accepted with any type.
+type Schema map[KeyName]FieldSchema
+
+// SchemaViolation is a value of a row breaking a FieldSchema.
+type SchemaViolation struct {
+	// ID is the ID the row would have had, uuid.Nil for rows inserted one at a time.
+	ID  uuid.UUID
+	Key KeyName
+	// Rule is the broken constraint: "required", "type", "allowed" or "pattern".
+	Rule    string
+	Value   interface{}
+	Message string
+}
+
+// SchemaError is returned when inserted rows break the schema of the DataFrame. None of the rows are inserted.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// Error describes the first violations.
+func (e *SchemaError) Error() string {
+	const shown = 3
+
+	messages := make([]string, 0, shown)
+	for i, v := range e.Violations {
+		if i == shown {
+			messages = append(messages, fmt.Sprintf("and %d more", len(e.Violations)-shown))
+			break
+		}
+		messages = append(messages, v.Message)
+	}
+
+	return "schema violation: " + strings.Join(messages, "; ")
+}
+
+// compiledFieldSchema is a FieldSchema ready to be evaluated.
+type compiledFieldSchema struct {
+	FieldSchema
+	pattern *regexp.Regexp
+	allowed map[interface{}]bool
+}
+
+// SetSchema sets the schema checked by Insert, InsertBatch and the other insert methods, which reject rows
+// breaking it with a *SchemaError. A nil schema disables validation. Rows already in the DataFrame are not checked.
+// Returns an error if a type is invalid or a pattern is not a valid regular expression for a String key.
+func (d *DataFrame) SetSchema(schema Schema) error {
+	compiled := make(map[KeyName]compiledFieldSchema, len(schema))
+	for key, field := range schema {
+		if field.Type < String || field.Type > Time {
+			return fmt.Errorf("invalid type %d for key '%s'", field.Type, key)
+		}
+
+		c := compiledFieldSchema{FieldSchema: field}
+
+		if field.Pattern != "" {
+			if field.Type != String {
+				return fmt.Errorf("pattern for key '%s' requires the String type", key)
+			}
+			re, err := regexp.Compile(field.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid pattern for key '%s': %w", key, err)
+			}
+			c.pattern = re
+		}
+
+		if len(field.Allowed) > 0 {
+			c.allowed = make(map[interface{}]bool, len(field.Allowed))
+			for _, v := range field.Allowed {
+				c.allowed[schemaValue(normalizeValue(v))] = true
+			}
+		}
+
+		compiled[key] = c
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	if schema == nil {
+		compiled = nil
+	}
+	d.schema = compiled
+
+	return nil
+}
+
+// Schema returns the schema set with SetSchema, or nil if there is none.
+func (d *DataFrame) Schema() Schema {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	if d.schema == nil {
+		return nil
+	}
+
+	schema := make(Schema, len(d.schema))
+	for key, field := range d.schema {
+		schema[key] = field.FieldSchema
+	}
+	return schema
+}
+
+// validateUnlocked checks the data of rows about to be inserted, in strict mode that every value can be indexed,
+// then against the schema and then against the memory limit, without acquiring locks. Returns the errors of
+// strict mode, a *SchemaError with the violations of every row, sorted by ID and key, an error wrapping
+// ErrMemoryLimit, or nil.
+func (d *DataFrame) validateUnlocked(entries map[uuid.UUID]map[KeyName]interface{}) error {
+	if d.strict {
+		if err := d.checkIndexableUnlocked(entries); err != nil {
+			return err
+		}
+	}
+
+	if d.schema == nil {
+		return d.checkMemoryUnlocked(entries)
+	}
+
+	var violations []SchemaViolation
+	for id, data := range entries {
+		values := make(map[KeyName]interface{}, len(data))
+		flattenValues(data, "", values)
+		violations = append(violations, d.checkSchemaUnlocked(id, values)...)
+	}
+
+	if len(violations) == 0 {
+		return d.checkMemoryUnlocked(entries)
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].ID != violations[j].ID {
+			return violations[i].ID.String() < violations[j].ID.String()
+		}
+		return violations[i].Key < violations[j].Key
+	})
+
+	return &SchemaError{Violations: violations}
+}
+
+// checkSchemaUnlocked returns the violations of the flattened values of a row, without acquiring locks.
+// Strings are checked after the coercion they would go through when indexed.
+func (d *DataFrame) checkSchemaUnlocked(id uuid.UUID, values map[KeyName]interface{}) []SchemaViolation {
+	var violations []SchemaViolation
+
+	add := func(key KeyName, rule string, value interface{}, message string) {
+		violations = append(violations, SchemaViolation{ID: id, Key: key, Rule: rule, Value: value, Message: message})
+	}
+
+	for key, field := range d.schema {
+		value, ok := values[key]
+		if !ok || value == nil {
+			if field.Required {
+				add(key, "required", nil, fmt.Sprintf("key '%s' is required", key))
+			}
+			continue
+		}
+
+		if s, isString := value.(string); isString && field.Type != String {
+			value = d.coerceUnlocked(key, s)
+		}
+
+		if keyType, typed := keyTypeOf(value); !typed || keyType != field.Type {
+			add(key, "type", value, fmt.Sprintf("key '%s' expects %s, but got %T", key, keyTypeToString(field.Type), value))
+			continue
+		}
+
+		if field.pattern != nil && !field.pattern.MatchString(value.(string)) {
+			add(key, "pattern", value, fmt.Sprintf("value of key '%s' does not match pattern '%s'", key, field.Pattern))
+		}
+
+		if field.allowed != nil && !field.allowed[schemaValue(value)] {
+			add(key, "allowed", value, fmt.Sprintf("value of key '%s' is not one of the allowed values", key))
+		}
+	}
+
+	return violations
+}
+
+// flattenValues adds the values of data to values under the dotted names they get when indexed,
+// normalizing numbers and UUIDs.
+func flattenValues(data map[KeyName]interface{}, prefix KeyName, values map[KeyName]interface{}) {
+	for key, value := range data {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			nested := make(map[KeyName]interface{}, len(v))
+			for k, item := range v {
+				nested[KeyName(k)] = item
+			}
+			flattenValues(nested, key, values)
+		case []interface{}:
+			for i, item := range v {
+				flattenValues(map[KeyName]interface{}{KeyName(fmt.Sprint(i)): item}, key, values)
+			}
+		default:
+			values[key] = normalizeValue(value)
+		}
+	}
+}
+
+// keyTypeOf returns the key type of a normalized value, or false if it cannot be indexed.
+func keyTypeOf(value interface{}) (KeyType, bool) {
+	switch value.(type) {
+	case string:
+		return String, true
+	case float64:
+		return Numeric, true
+	case bool:
+		return Boolean, true
+	case time.Time:
+		return Time, true
+	default:
+		return 0, false
+	}
+}
+
+// schemaValue returns the comparable form of a value for the allowed values of a schema:
+// times are compared as instants in UTC.
+func schemaValue(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC()
+	}
+	return value
+}
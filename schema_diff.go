@@ -0,0 +1,119 @@
+package mframe
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// TypeChange describes a key whose type differs between two schemas.
+type TypeChange struct {
+	Key  KeyName
+	From KeyType
+	To   KeyType
+}
+
+// SchemaDiff reports the differences between two schemas.
+type SchemaDiff struct {
+	Added   []KeyName
+	Removed []KeyName
+	Changed []TypeChange
+}
+
+// HasChanges reports whether the schemas differ.
+func (s SchemaDiff) HasChanges() bool {
+	return len(s.Added) > 0 || len(s.Removed) > 0 || len(s.Changed) > 0
+}
+
+// KeyTypes returns a copy of the keys currently known to the DataFrame and their types.
+func (d *DataFrame) KeyTypes() KeysIndex {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	result := make(KeysIndex, len(d.Keys))
+	for k, v := range d.Keys {
+		result[k] = v
+	}
+	return result
+}
+
+// CompareSchemas reports the keys added, removed or retyped in b relative to a.
+func CompareSchemas(a, b *DataFrame) SchemaDiff {
+	return CompareKeys(a.KeyTypes(), b.KeyTypes())
+}
+
+// CompareKeys reports the keys added, removed or retyped in after relative to before.
+// Results are sorted by key name.
+func CompareKeys(before, after KeysIndex) SchemaDiff {
+	var diff SchemaDiff
+
+	for key, from := range before {
+		to, ok := after[key]
+		if !ok {
+			diff.Removed = append(diff.Removed, key)
+			continue
+		}
+		if from != to {
+			diff.Changed = append(diff.Changed, TypeChange{Key: key, From: from, To: to})
+		}
+	}
+
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			diff.Added = append(diff.Added, key)
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i] < diff.Added[j] })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i] < diff.Removed[j] })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Key < diff.Changed[j].Key })
+
+	return diff
+}
+
+// CompareSchemaWithFile reports the keys added, removed or retyped in the DataFrame relative to
+// the snapshot saved in filename by SaveToFile or SaveToFileCompressed.
+func (d *DataFrame) CompareSchemaWithFile(filename string) (SchemaDiff, error) {
+	before, err := LoadSchemaFromFile(filename)
+	if err != nil {
+		return SchemaDiff{}, err
+	}
+	return CompareKeys(before, d.KeyTypes()), nil
+}
+
+// LoadSchemaFromFile reads the keys and their types from a snapshot saved by SaveToFile
+// or SaveToFileCompressed.
+func LoadSchemaFromFile(filename string) (KeysIndex, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+
+	var source io.Reader = reader
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer func() { _ = gzReader.Close() }()
+		source = gzReader
+	}
+
+	var pdf persistentDataFrame
+	if err := gob.NewDecoder(source).Decode(&pdf); err != nil {
+		return nil, fmt.Errorf("failed to decode dataframe: %w", err)
+	}
+
+	if pdf.Keys == nil {
+		pdf.Keys = make(KeysIndex)
+	}
+
+	return pdf.Keys, nil
+}
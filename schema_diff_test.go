@@ -0,0 +1,71 @@
+package mframe_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	var before mframe.DataFrame
+	before.Init(24 * time.Hour)
+	before.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "port": 22, "legacy": true})
+
+	var after mframe.DataFrame
+	after.Init(24 * time.Hour)
+	after.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1", "port": "22", "user": "root"})
+
+	diff := mframe.CompareSchemas(&before, &after)
+	if !diff.HasChanges() {
+		t.Fatal("expected changes")
+	}
+	if len(diff.Added) != 1 || diff.Added[0] != "user" {
+		t.Errorf("expected 'user' to be added, but got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "legacy" {
+		t.Errorf("expected 'legacy' to be removed, but got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Key != "port" ||
+		diff.Changed[0].From != mframe.Numeric || diff.Changed[0].To != mframe.String {
+		t.Errorf("expected 'port' to change from numeric to string, but got %v", diff.Changed)
+	}
+
+	if mframe.CompareSchemas(&before, &before).HasChanges() {
+		t.Error("expected no changes when comparing a schema with itself")
+	}
+}
+
+func TestCompareSchemaWithFile(t *testing.T) {
+	dir := t.TempDir()
+
+	var df mframe.DataFrame
+	df.Init(24 * time.Hour)
+	df.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.1"})
+
+	plain := filepath.Join(dir, "snapshot.gob")
+	if err := df.SaveToFile(plain); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	compressed := filepath.Join(dir, "snapshot.gob.gz")
+	if err := df.SaveToFileCompressed(compressed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	df.Insert(map[mframe.KeyName]interface{}{"ip": "10.0.0.2", "port": 443})
+
+	for _, filename := range []string{plain, compressed} {
+		diff, err := df.CompareSchemaWithFile(filename)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(diff.Added) != 1 || diff.Added[0] != "port" {
+			t.Errorf("expected 'port' to be added relative to %s, but got %v", filename, diff.Added)
+		}
+	}
+
+	if _, err := mframe.LoadSchemaFromFile(filepath.Join(dir, "missing.gob")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
@@ -0,0 +1,163 @@
+package mframe_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func newSchemaFrame(t *testing.T) *mframe.DataFrame {
+	t.Helper()
+
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	err := cache.SetSchema(mframe.Schema{
+		"host":       {Type: mframe.String, Required: true, Pattern: `^[a-z]+-\d+$`},
+		"severity":   {Type: mframe.String, Allowed: []interface{}{"low", "high"}},
+		"bytes":      {Type: mframe.Numeric},
+		"geo.region": {Type: mframe.String, Allowed: []interface{}{"eu", "us"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	return &cache
+}
+
+func TestSchemaInsert(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  map[mframe.KeyName]interface{}
+		rules []string
+	}{
+		{"valid", map[mframe.KeyName]interface{}{"host": "web-1", "bytes": 10, "other": true}, nil},
+		{"nested", map[mframe.KeyName]interface{}{"host": "web-1", "geo": map[string]interface{}{"region": "eu"}}, nil},
+		{"missing", map[mframe.KeyName]interface{}{"bytes": 10.0}, []string{"required"}},
+		{"type", map[mframe.KeyName]interface{}{"host": "web-1", "bytes": "10"}, []string{"type"}},
+		{"pattern", map[mframe.KeyName]interface{}{"host": "Web 1"}, []string{"pattern"}},
+		{"allowed", map[mframe.KeyName]interface{}{"host": "web-1", "severity": "medium"}, []string{"allowed"}},
+		{"nested allowed", map[mframe.KeyName]interface{}{"host": "web-1", "geo": map[string]interface{}{"region": "ap"}}, []string{"allowed"}},
+		{"several", map[mframe.KeyName]interface{}{"bytes": true, "severity": "medium"}, []string{"type", "required", "allowed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newSchemaFrame(t)
+
+			_, err := cache.InsertReturningID(tt.data)
+			if tt.rules == nil {
+				if err != nil {
+					t.Fatalf("expected no error, but got %v", err)
+				}
+				if cache.Count() != 1 {
+					t.Errorf("expected 1 row, but got %d", cache.Count())
+				}
+				return
+			}
+
+			var schemaErr *mframe.SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("expected a *SchemaError, but got %v", err)
+			}
+			if len(schemaErr.Violations) != len(tt.rules) {
+				t.Fatalf("expected %d violations, but got %v", len(tt.rules), schemaErr.Violations)
+			}
+			for i, v := range schemaErr.Violations {
+				if v.Rule != tt.rules[i] {
+					t.Errorf("expected rule %s, but got %s (%s)", tt.rules[i], v.Rule, v.Message)
+				}
+			}
+			if cache.Count() != 0 {
+				t.Errorf("expected no rows, but got %d", cache.Count())
+			}
+		})
+	}
+}
+
+func TestSchemaInsertBatch(t *testing.T) {
+	cache := newSchemaFrame(t)
+
+	err := cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1"},
+		{"host": "web-2", "severity": "medium"},
+		{"bytes": 1.0},
+	})
+
+	var schemaErr *mframe.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("expected a *SchemaError, but got %v", err)
+	}
+	if len(schemaErr.Violations) != 2 {
+		t.Errorf("expected 2 violations, but got %v", schemaErr.Violations)
+	}
+	if !strings.HasPrefix(err.Error(), "schema violation: ") {
+		t.Errorf("unexpected error message %q", err.Error())
+	}
+	if cache.Count() != 0 {
+		t.Errorf("expected the batch to be rejected, but got %d rows", cache.Count())
+	}
+
+	if err := cache.InsertBatch([]map[mframe.KeyName]interface{}{{"host": "web-1"}, {"host": "web-2"}}); err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 rows, but got %d", cache.Count())
+	}
+}
+
+func TestSchemaCoercionAndUpsert(t *testing.T) {
+	cache := newSchemaFrame(t)
+	cache.SetCoercion(mframe.CoercionOptions{Numerics: true})
+	if err := cache.SetKeyType("bytes", mframe.Numeric); err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	if _, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"host": "web-1", "bytes": "10"}); err != nil {
+		t.Errorf("expected coerced strings to be accepted, but got %v", err)
+	}
+
+	if _, err := cache.Upsert("host", map[mframe.KeyName]interface{}{"host": "web-1", "severity": "medium"}); err == nil {
+		t.Error("expected Upsert to validate the schema")
+	}
+	if _, err := cache.Upsert("host", map[mframe.KeyName]interface{}{"host": "web-1", "severity": "low"}); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+	if cache.Count() != 1 {
+		t.Errorf("expected 1 row, but got %d", cache.Count())
+	}
+
+	if err := cache.SetSchema(nil); err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if cache.Schema() != nil {
+		t.Error("expected no schema")
+	}
+	if _, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"severity": "medium"}); err != nil {
+		t.Errorf("expected no validation without a schema, but got %v", err)
+	}
+}
+
+func TestSetSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema mframe.Schema
+	}{
+		{"invalid type", mframe.Schema{"host": {}}},
+		{"invalid pattern", mframe.Schema{"host": {Type: mframe.String, Pattern: "("}}},
+		{"pattern on number", mframe.Schema{"bytes": {Type: mframe.Numeric, Pattern: "1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache mframe.DataFrame
+			cache.Init(time.Hour)
+			if err := cache.SetSchema(tt.schema); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
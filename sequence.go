@@ -0,0 +1,168 @@
+package mframe
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SequenceStep is one step of an ordered sequence of events.
+type SequenceStep struct {
+	Condition Condition
+	// MaxGap is the maximum time allowed since the previous step. Zero means no limit.
+	MaxGap time.Duration
+}
+
+// SequenceSpec describes an ordered sequence of events that must happen for the same entity,
+// such as a port scan followed by a login followed by an outbound transfer.
+type SequenceSpec struct {
+	// EntityKey identifies the entity the events belong to, e.g. a source IP.
+	EntityKey KeyName
+	// TimeKey holds the time.Time used to order the events.
+	TimeKey KeyName
+	// Steps are the conditions each event of the sequence must match, in order.
+	Steps []SequenceStep
+	// Window is the maximum time between the first and the last step. Zero means no limit.
+	Window time.Duration
+}
+
+// SequenceMatch is an occurrence of a sequence for an entity.
+type SequenceMatch struct {
+	Entity string
+	IDs    []uuid.UUID // Row IDs, one per step
+	Start  time.Time
+	End    time.Time
+}
+
+// sequenceEvent is a row taking part in sequence detection.
+type sequenceEvent struct {
+	id      uuid.UUID
+	at      time.Time
+	matches []bool
+}
+
+// DetectSequences finds, for every entity, the non-overlapping occurrences of the ordered steps
+// in the specification, earliest first. Rows without the entity key or a time.Time in the time key are ignored.
+func (d *DataFrame) DetectSequences(spec SequenceSpec) ([]SequenceMatch, error) {
+	if len(spec.Steps) == 0 {
+		return nil, fmt.Errorf("sequence must have at least one step")
+	}
+	if spec.EntityKey == "" || spec.TimeKey == "" {
+		return nil, fmt.Errorf("sequence entity and time keys cannot be empty")
+	}
+
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	stepIDs := make([]map[uuid.UUID]struct{}, len(spec.Steps))
+	for i, step := range spec.Steps {
+		stepIDs[i] = d.idsUnlocked(step.Condition)
+	}
+
+	entities := make(map[string][]*sequenceEvent)
+	seen := make(map[uuid.UUID]bool)
+	for _, ids := range stepIDs {
+		for id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			row := d.Data[id]
+			entity, ok := row[spec.EntityKey]
+			if !ok {
+				continue
+			}
+			at, ok := row[spec.TimeKey].(time.Time)
+			if !ok {
+				continue
+			}
+
+			event := &sequenceEvent{id: id, at: at, matches: make([]bool, len(spec.Steps))}
+			for i := range spec.Steps {
+				_, event.matches[i] = stepIDs[i][id]
+			}
+
+			name := fmt.Sprint(entity)
+			entities[name] = append(entities[name], event)
+		}
+	}
+
+	result := make([]SequenceMatch, 0)
+	for entity, events := range entities {
+		sort.Slice(events, func(i, j int) bool {
+			if events[i].at.Equal(events[j].at) {
+				return events[i].id.String() < events[j].id.String()
+			}
+			return events[i].at.Before(events[j].at)
+		})
+
+		result = append(result, matchSequences(entity, events, spec)...)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Start.Equal(result[j].Start) {
+			return result[i].Entity < result[j].Entity
+		}
+		return result[i].Start.Before(result[j].Start)
+	})
+
+	return result, nil
+}
+
+// matchSequences greedily finds the non-overlapping sequences in time-ordered events of one entity.
+func matchSequences(entity string, events []*sequenceEvent, spec SequenceSpec) []SequenceMatch {
+	var result []SequenceMatch
+
+	for i := 0; i < len(events); {
+		if !events[i].matches[0] {
+			i++
+			continue
+		}
+
+		chain := []int{i}
+		for step := 1; step < len(spec.Steps); step++ {
+			previous := events[chain[len(chain)-1]]
+			found := -1
+			for j := chain[len(chain)-1] + 1; j < len(events); j++ {
+				event := events[j]
+				if spec.Steps[step].MaxGap > 0 && event.at.Sub(previous.at) > spec.Steps[step].MaxGap {
+					break
+				}
+				if spec.Window > 0 && event.at.Sub(events[i].at) > spec.Window {
+					break
+				}
+				if event.matches[step] {
+					found = j
+					break
+				}
+			}
+			if found < 0 {
+				break
+			}
+			chain = append(chain, found)
+		}
+
+		if len(chain) < len(spec.Steps) {
+			i++
+			continue
+		}
+
+		match := SequenceMatch{
+			Entity: entity,
+			IDs:    make([]uuid.UUID, 0, len(chain)),
+			Start:  events[chain[0]].at,
+			End:    events[chain[len(chain)-1]].at,
+		}
+		for _, index := range chain {
+			match.IDs = append(match.IDs, events[index].id)
+		}
+		result = append(result, match)
+
+		i = chain[len(chain)-1] + 1
+	}
+
+	return result
+}
@@ -0,0 +1,78 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestDetectSequences(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	events := []map[mframe.KeyName]interface{}{
+		{"src": "10.0.0.1", "action": "scan", "at": base},
+		{"src": "10.0.0.1", "action": "login", "at": base.Add(2 * time.Minute)},
+		{"src": "10.0.0.1", "action": "upload", "at": base.Add(4 * time.Minute)},
+		{"src": "10.0.0.2", "action": "login", "at": base},
+		{"src": "10.0.0.2", "action": "scan", "at": base.Add(time.Minute)},
+		{"src": "10.0.0.2", "action": "upload", "at": base.Add(2 * time.Minute)},
+		{"src": "10.0.0.3", "action": "scan", "at": base},
+		{"src": "10.0.0.3", "action": "login", "at": base.Add(time.Hour)},
+		{"src": "10.0.0.3", "action": "upload", "at": base.Add(time.Hour + time.Minute)},
+	}
+
+	for _, v := range events {
+		cache.Insert(v)
+	}
+
+	spec := mframe.SequenceSpec{
+		EntityKey: "src",
+		TimeKey:   "at",
+		Steps: []mframe.SequenceStep{
+			{Condition: mframe.Condition{Operator: mframe.Equals, Key: "action", Value: "scan"}},
+			{Condition: mframe.Condition{Operator: mframe.Equals, Key: "action", Value: "login"}, MaxGap: 10 * time.Minute},
+			{Condition: mframe.Condition{Operator: mframe.Equals, Key: "action", Value: "upload"}, MaxGap: 10 * time.Minute},
+		},
+		Window: 30 * time.Minute,
+	}
+
+	matches, err := cache.DetectSequences(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, but got %d: %+v", len(matches), matches)
+	}
+
+	match := matches[0]
+	if match.Entity != "10.0.0.1" {
+		t.Errorf("expected entity 10.0.0.1, but got %s", match.Entity)
+	}
+	if len(match.IDs) != 3 {
+		t.Errorf("expected 3 ids, but got %d", len(match.IDs))
+	}
+	if !match.Start.Equal(base) || !match.End.Equal(base.Add(4*time.Minute)) {
+		t.Errorf("unexpected match bounds %v - %v", match.Start, match.End)
+	}
+	if cache.Data[match.IDs[1]]["action"] != "login" {
+		t.Errorf("expected second step to be the login, but got %v", cache.Data[match.IDs[1]])
+	}
+
+	spec.Window = 0
+	spec.Steps[1].MaxGap = 0
+	matches, err = cache.DetectSequences(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("expected 2 matches without time constraints, but got %d", len(matches))
+	}
+
+	if _, err := cache.DetectSequences(mframe.SequenceSpec{EntityKey: "src", TimeKey: "at"}); err == nil {
+		t.Error("expected error for a sequence without steps")
+	}
+}
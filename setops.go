@@ -0,0 +1,85 @@
+package mframe
+
+import (
+	"github.com/google/uuid"
+)
+
+// Union returns a new DataFrame with the rows of the DataFrame and the rows of other not already in it.
+// Rows are the same when they have the same ID or, if by is set, the same value for by. When both
+// frames hold the same row the one of the DataFrame is kept. Rows keep their IDs.
+func (d *DataFrame) Union(other *DataFrame, by KeyName) *DataFrame {
+	unlock := LockFrames(FrameLock{Frame: d}, FrameLock{Frame: other})
+
+	entries := make([]batchEntry, 0, len(d.Data)+len(other.Data))
+	for _, id := range d.arrivalOrderUnlocked() {
+		entries = append(entries, batchEntry{id: id, data: copyRow(d.Data[id])})
+	}
+	for _, id := range other.arrivalOrderUnlocked() {
+		row := other.Data[id]
+		if _, exists := d.Data[id]; exists || d.containsUnlocked(id, row, by) {
+			continue
+		}
+		entries = append(entries, batchEntry{id: id, data: copyRow(row)})
+	}
+	unlock()
+
+	return d.setResult(entries)
+}
+
+// Intersect returns a new DataFrame with the rows of the DataFrame that are also in other.
+// Rows are the same when they have the same ID or, if by is set, the same value for by.
+// Rows without by are never in other. Rows keep their IDs.
+func (d *DataFrame) Intersect(other *DataFrame, by KeyName) *DataFrame {
+	unlock := LockFrames(FrameLock{Frame: d}, FrameLock{Frame: other})
+
+	var entries []batchEntry
+	for _, id := range d.arrivalOrderUnlocked() {
+		if row := d.Data[id]; other.containsUnlocked(id, row, by) {
+			entries = append(entries, batchEntry{id: id, data: copyRow(row)})
+		}
+	}
+	unlock()
+
+	return d.setResult(entries)
+}
+
+// Difference returns a new DataFrame with the rows of the DataFrame that are not in other.
+// Rows are the same when they have the same ID or, if by is set, the same value for by.
+// Rows without by are never in other. Rows keep their IDs.
+func (d *DataFrame) Difference(other *DataFrame, by KeyName) *DataFrame {
+	unlock := LockFrames(FrameLock{Frame: d}, FrameLock{Frame: other})
+
+	var entries []batchEntry
+	for _, id := range d.arrivalOrderUnlocked() {
+		if row := d.Data[id]; !other.containsUnlocked(id, row, by) {
+			entries = append(entries, batchEntry{id: id, data: copyRow(row)})
+		}
+	}
+	unlock()
+
+	return d.setResult(entries)
+}
+
+// containsUnlocked reports whether the DataFrame holds the row with the given ID or, if by is set,
+// a row with the same value for by.
+func (d *DataFrame) containsUnlocked(id uuid.UUID, row Row, by KeyName) bool {
+	if by == "" {
+		_, exists := d.Data[id]
+		return exists
+	}
+
+	value, ok := row[by]
+	if !ok {
+		return false
+	}
+
+	return len(d.idsForValueUnlocked(by, value)) > 0
+}
+
+// setResult builds the DataFrame returned by a set operation from the selected rows.
+func (d *DataFrame) setResult(entries []batchEntry) *DataFrame {
+	var results = new(DataFrame)
+	results.Init(d.TTL)
+	results.insertEntries(entries)
+	return results
+}
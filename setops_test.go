@@ -0,0 +1,87 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSetOperationsByID(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"user": "alice", "severity": "high", "country": "US"},
+		{"user": "bob", "severity": "high", "country": "RU"},
+		{"user": "carol", "severity": "low", "country": "RU"},
+		{"user": "dave", "severity": "low", "country": "US"},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	high := cache.Filter(mframe.Equals, "severity", "high", nil)
+	russia := cache.Filter(mframe.Equals, "country", "RU", nil)
+
+	tests := []struct {
+		name     string
+		result   *mframe.DataFrame
+		expected []string
+	}{
+		{"union", high.Union(russia, ""), []string{"alice", "bob", "carol"}},
+		{"intersect", high.Intersect(russia, ""), []string{"bob"}},
+		{"difference", high.Difference(russia, ""), []string{"alice"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.result.Count() != len(tt.expected) {
+				t.Fatalf("expected %d rows, but got %d", len(tt.expected), tt.result.Count())
+			}
+			for _, user := range tt.expected {
+				if tt.result.CountWhere(mframe.Equals, "user", user, nil) != 1 {
+					t.Errorf("expected a row for %s", user)
+				}
+			}
+			for id := range tt.result.Data {
+				if _, ok := cache.Data[id]; !ok {
+					t.Errorf("expected rows to keep the ID of the source row, but got %s", id)
+				}
+			}
+		})
+	}
+}
+
+func TestSetOperationsByKey(t *testing.T) {
+	var events mframe.DataFrame
+	events.Init(24 * time.Hour)
+	events.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1", "action": "login"},
+		{"ip": "10.0.0.2", "action": "login"},
+		{"action": "unknown"},
+	})
+
+	var allowlist mframe.DataFrame
+	allowlist.Init(24 * time.Hour)
+	allowlist.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"ip": "10.0.0.1"},
+		{"ip": "10.0.0.9"},
+	})
+
+	if count := events.Union(&allowlist, "ip").Count(); count != 4 {
+		t.Errorf("expected 4 rows in the union, but got %d", count)
+	}
+	if count := events.Intersect(&allowlist, "ip").Count(); count != 1 {
+		t.Errorf("expected 1 row in the intersection, but got %d", count)
+	}
+
+	difference := events.Difference(&allowlist, "ip")
+	if difference.Count() != 2 {
+		t.Errorf("expected 2 rows in the difference, but got %d", difference.Count())
+	}
+	if difference.CountWhere(mframe.Equals, "ip", "10.0.0.2", nil) != 1 {
+		t.Error("expected the row not in the allowlist to be kept")
+	}
+}
@@ -0,0 +1,274 @@
+package mframe
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/montanaflynn/stats"
+)
+
+// ShardedDataFrame partitions rows by ID across several DataFrames, each with its own lock and indexes,
+// so concurrent inserts touching different shards do not serialize on a single lock. Inserts are routed
+// to the shard owning the ID of the row and filters and math functions run on every shard in parallel.
+//
+// Only the operations below are sharded. Options such as hooks, schemas or limits are set on every shard
+// through Shards, and apply to each shard on its own: a limit of 1000 rows on 4 shards holds up to 4000 rows.
+type ShardedDataFrame struct {
+	shards []*DataFrame
+	TTL    time.Duration
+}
+
+// InitSharded initializes the DataFrame with the given number of shards, each one initialized with ttl.
+// A number of shards of 0 or less uses one shard per CPU.
+func (s *ShardedDataFrame) InitSharded(ttl time.Duration, shards int) {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+
+	s.TTL = ttl
+	s.shards = make([]*DataFrame, shards)
+	for i := range s.shards {
+		s.shards[i] = new(DataFrame)
+		s.shards[i].Init(ttl)
+	}
+}
+
+// Shards returns the shards, to configure them or to run the operations that are not sharded on each one.
+func (s *ShardedDataFrame) Shards() []*DataFrame {
+	return s.shards
+}
+
+// SetIDGenerator sets the function generating the IDs of new rows on every shard. Passing nil restores RandomIDs.
+func (s *ShardedDataFrame) SetIDGenerator(generator IDGenerator) {
+	for _, shard := range s.shards {
+		shard.SetIDGenerator(generator)
+	}
+}
+
+// idGenerator returns the ID generator of the first shard, which generates the IDs of new rows before they are
+// routed to the shard owning them.
+func (s *ShardedDataFrame) idGenerator() IDGenerator {
+	first := s.shards[0]
+	first.Locker.RLock()
+	defer first.Locker.RUnlock()
+	return first.idGeneratorUnlocked()
+}
+
+// shardFor returns the shard owning the ID.
+func (s *ShardedDataFrame) shardFor(id uuid.UUID) *DataFrame {
+	return s.shards[s.indexFor(id)]
+}
+
+// indexFor returns the position of the shard owning the ID.
+func (s *ShardedDataFrame) indexFor(id uuid.UUID) int {
+	h := fnv.New32a()
+	h.Write(id[:])
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// Insert adds a new row with a generated ID to the shard owning the ID, logging errors like DataFrame.Insert.
+func (s *ShardedDataFrame) Insert(data map[KeyName]interface{}) {
+	if _, err := s.InsertReturningID(data); err != nil {
+		log.Printf("error inserting row: %s", err.Error())
+	}
+}
+
+// InsertWithError adds a new row like Insert and returns an error if the data is invalid or rejected by the shard.
+func (s *ShardedDataFrame) InsertWithError(data map[KeyName]interface{}) error {
+	_, err := s.InsertReturningID(data)
+	return err
+}
+
+// InsertReturningID adds a new row like InsertWithError and returns its generated ID.
+func (s *ShardedDataFrame) InsertReturningID(data map[KeyName]interface{}) (uuid.UUID, error) {
+	if data == nil {
+		return uuid.Nil, fmt.Errorf("cannot insert nil data")
+	}
+	if len(data) == 0 {
+		return uuid.Nil, fmt.Errorf("cannot insert empty data")
+	}
+
+	id := s.idGenerator()()
+	if err := s.shardFor(id).insertEntries([]batchEntry{{id: id, data: data}}); err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+// InsertBatch adds multiple rows with generated IDs, inserting the rows of every shard as one batch,
+// with the shards written in parallel. Returns the errors of the shards joined with errors.Join; the
+// batches of the other shards are still inserted.
+func (s *ShardedDataFrame) InsertBatch(rows []map[KeyName]interface{}) error {
+	if len(rows) == 0 {
+		return fmt.Errorf("cannot insert empty batch")
+	}
+
+	newID := s.idGenerator()
+	entries := make([]batchEntry, 0, len(rows))
+	for _, data := range rows {
+		entries = append(entries, batchEntry{id: newID(), data: data})
+	}
+
+	return s.insertEntries(entries)
+}
+
+// InsertBatchWithIDs adds multiple rows with specific IDs like InsertBatch, inserted in the order of their IDs.
+func (s *ShardedDataFrame) InsertBatchWithIDs(entries map[uuid.UUID]map[KeyName]interface{}) error {
+	if len(entries) == 0 {
+		return fmt.Errorf("cannot insert empty batch")
+	}
+
+	return s.insertEntries(batchEntriesOf(entries))
+}
+
+// insertEntries splits the entries into one batch per shard, keeping their order, and inserts the batches
+// in parallel.
+func (s *ShardedDataFrame) insertEntries(entries []batchEntry) error {
+	batches := make([][]batchEntry, len(s.shards))
+	for _, entry := range entries {
+		i := s.indexFor(entry.id)
+		batches[i] = append(batches[i], entry)
+	}
+
+	errs := make([]error, len(s.shards))
+	s.each(func(i int, shard *DataFrame) {
+		if batches[i] != nil {
+			errs[i] = shard.insertEntries(batches[i])
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// Get returns a copy of the row with the specified ID and whether it exists.
+func (s *ShardedDataFrame) Get(id uuid.UUID) (Row, bool) {
+	return s.shardFor(id).Get(id)
+}
+
+// RemoveElement removes the row with the specified ID.
+func (s *ShardedDataFrame) RemoveElement(id uuid.UUID) {
+	s.shardFor(id).RemoveElement(id)
+}
+
+// Count returns the number of rows of all the shards.
+func (s *ShardedDataFrame) Count() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Count()
+	}
+	return total
+}
+
+// Filter runs the filter on every shard in parallel and returns the matching rows of all the shards
+// in a new, unsharded DataFrame. Like FilterIDs, each shard runs its before-query hooks and counts the query
+// in its statistics, while after-query hooks are not run. The matching rows are indexed once, in the result.
+func (s *ShardedDataFrame) Filter(operator Operator, key KeyName, value any, options map[FilterOption]bool) *DataFrame {
+	query := Condition{Operator: operator, Key: key, Value: value, Options: options}
+
+	parts := make([]map[uuid.UUID]Row, len(s.shards))
+	s.each(func(i int, shard *DataFrame) {
+		parts[i] = shard.filterRows(query)
+	})
+
+	var results = new(DataFrame)
+	results.Init(s.TTL)
+	for _, part := range parts {
+		for id, row := range part {
+			results.insertWithIDUnlocked(id, row)
+		}
+	}
+
+	return results
+}
+
+// filterRows returns the rows of the shard matching the query, without copying them into a DataFrame.
+// The rows are not copied either, so they must only be read.
+func (d *DataFrame) filterRows(query Condition) map[uuid.UUID]Row {
+	if err := d.runBeforeQuery(query); err != nil {
+		log.Printf("query on key '%s' rejected: %s", query.Key, err.Error())
+		return nil
+	}
+
+	start := time.Now()
+	d.Locker.RLock()
+	ids := d.idsUnlocked(query)
+	rows := make(map[uuid.UUID]Row, len(ids))
+	for id := range ids {
+		rows[id] = d.Data[id]
+	}
+	d.Locker.RUnlock()
+
+	d.recordQuery(query, time.Since(start), len(rows))
+
+	return rows
+}
+
+// Sum calculates the sum of the numeric values of the field across all the shards.
+func (s *ShardedDataFrame) Sum(field KeyName) (float64, error) {
+	return stats.Sum(s.float64s(field))
+}
+
+// Average calculates the mean of the numeric values of the field across all the shards.
+func (s *ShardedDataFrame) Average(field KeyName) (float64, error) {
+	return stats.Mean(s.float64s(field))
+}
+
+// Max returns the maximum numeric value of the field across all the shards.
+func (s *ShardedDataFrame) Max(field KeyName) (float64, error) {
+	return stats.Max(s.float64s(field))
+}
+
+// Min returns the minimum numeric value of the field across all the shards.
+func (s *ShardedDataFrame) Min(field KeyName) (float64, error) {
+	return stats.Min(s.float64s(field))
+}
+
+// float64s collects the numeric values of the field from every shard in parallel.
+func (s *ShardedDataFrame) float64s(field KeyName) []float64 {
+	parts := make([][]float64, len(s.shards))
+	s.each(func(i int, shard *DataFrame) {
+		shard.Locker.RLock()
+		parts[i] = shard.sliceOfFloat64Unlocked(field)
+		shard.Locker.RUnlock()
+	})
+
+	var values []float64
+	for _, part := range parts {
+		values = append(values, part...)
+	}
+	return values
+}
+
+// StartCleaner starts the background cleaner of every shard.
+func (s *ShardedDataFrame) StartCleaner() {
+	for _, shard := range s.shards {
+		shard.StartCleaner()
+	}
+}
+
+// StopCleaner stops the background cleaner of every shard and waits for them to exit.
+func (s *ShardedDataFrame) StopCleaner() {
+	for _, shard := range s.shards {
+		shard.StopCleaner()
+	}
+}
+
+// each runs fn on every shard in parallel and waits for all of them to return.
+func (s *ShardedDataFrame) each(fn func(i int, shard *DataFrame)) {
+	var wg sync.WaitGroup
+	for i, shard := range s.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(i, shard)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,114 @@
+package mframe_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestShardedDataFrame(t *testing.T) {
+	var cache mframe.ShardedDataFrame
+	cache.InitSharded(24*time.Hour, 4)
+
+	if len(cache.Shards()) != 4 {
+		t.Fatalf("expected 4 shards, but got %d", len(cache.Shards()))
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				cache.Insert(map[mframe.KeyName]interface{}{"worker": fmt.Sprint(w), "bytes": 10})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cache.Count() != 400 {
+		t.Errorf("expected 400 rows, but got %d", cache.Count())
+	}
+	for i, shard := range cache.Shards() {
+		if shard.Count() == 0 {
+			t.Errorf("expected rows in shard %d", i)
+		}
+	}
+
+	if n := cache.Filter(mframe.Equals, "worker", "3", nil).Count(); n != 50 {
+		t.Errorf("expected 50 rows for worker 3, but got %d", n)
+	}
+	if sum, err := cache.Sum("bytes"); err != nil || sum != 4000 {
+		t.Errorf("expected a sum of 4000, but got %f (%v)", sum, err)
+	}
+
+	id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"worker": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if row, ok := cache.Get(id); !ok || row["worker"] != "x" {
+		t.Errorf("expected the inserted row, but got %v", row)
+	}
+	cache.RemoveElement(id)
+	if _, ok := cache.Get(id); ok {
+		t.Errorf("expected the row to be removed")
+	}
+}
+
+func TestShardedDataFrameInsertBatch(t *testing.T) {
+	var cache mframe.ShardedDataFrame
+	cache.InitSharded(24*time.Hour, 3)
+
+	rows := make([]map[mframe.KeyName]interface{}, 30)
+	for i := range rows {
+		rows[i] = map[mframe.KeyName]interface{}{"seq": i}
+	}
+	if err := cache.InsertBatch(rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cache.Count() != 30 {
+		t.Errorf("expected 30 rows, but got %d", cache.Count())
+	}
+	if max, err := cache.Max("seq"); err != nil || max != 29 {
+		t.Errorf("expected a max of 29, but got %f (%v)", max, err)
+	}
+}
+
+func TestShardedDataFrameIDGenerator(t *testing.T) {
+	var cache mframe.ShardedDataFrame
+	cache.InitSharded(24*time.Hour, 3)
+	cache.SetIDGenerator(mframe.TimeOrderedIDs)
+
+	id, err := cache.InsertReturningID(map[mframe.KeyName]interface{}{"seq": -1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := mframe.IDTime(id); !ok {
+		t.Errorf("expected a time-ordered ID, but got version %d", id.Version())
+	}
+
+	rows := make([]map[mframe.KeyName]interface{}, 30)
+	for i := range rows {
+		rows[i] = map[mframe.KeyName]interface{}{"seq": i}
+	}
+	if err := cache.InsertBatch(rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results := cache.Filter(mframe.GreaterOrEqual, "seq", 0.0, nil)
+	if results.Count() != 30 {
+		t.Fatalf("expected 30 rows, but got %d", results.Count())
+	}
+	for id := range results.Data {
+		if _, ok := mframe.IDTime(id); !ok {
+			t.Errorf("expected a time-ordered ID, but got version %d", id.Version())
+		}
+	}
+	if n := results.Filter(mframe.Less, "seq", 10.0, nil).Count(); n != 10 {
+		t.Errorf("expected the merged result to be indexed, but got %d rows", n)
+	}
+}
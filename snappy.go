@@ -0,0 +1,82 @@
+package mframe
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// snappyMaxExpansion bounds the ratio between the decoded and encoded sizes of a Snappy block, as a copy
+// of at most 64 bytes takes at least 2 bytes.
+const snappyMaxExpansion = 32
+
+// decodeSnappy decompresses a block of the Snappy format, the raw format without framing used by the
+// pages of Parquet files.
+func decodeSnappy(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 || length > uint64(len(src))*snappyMaxExpansion {
+		return nil, fmt.Errorf("invalid snappy length")
+	}
+
+	dst := make([]byte, 0, length)
+	for pos := n; pos < len(src); {
+		tag := src[pos]
+		var size, offset int
+		switch tag & 3 {
+		case 0:
+			size = int(tag >> 2)
+			pos++
+			if size >= 60 {
+				extra := size - 59
+				if pos+extra > len(src) {
+					return nil, fmt.Errorf("truncated snappy literal")
+				}
+				size = 0
+				for i := 0; i < extra; i++ {
+					size |= int(src[pos+i]) << (8 * i)
+				}
+				pos += extra
+			}
+			size++
+			if size <= 0 || size > len(src)-pos {
+				return nil, fmt.Errorf("truncated snappy literal")
+			}
+			dst = append(dst, src[pos:pos+size]...)
+			pos += size
+			continue
+		case 1:
+			if pos+2 > len(src) {
+				return nil, fmt.Errorf("truncated snappy copy")
+			}
+			size = 4 + int(tag>>2)&7
+			offset = int(tag&0xe0)<<3 | int(src[pos+1])
+			pos += 2
+		case 2:
+			if pos+3 > len(src) {
+				return nil, fmt.Errorf("truncated snappy copy")
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[pos+1:]))
+			pos += 3
+		case 3:
+			if pos+5 > len(src) {
+				return nil, fmt.Errorf("truncated snappy copy")
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[pos+1:]))
+			pos += 5
+		}
+
+		if offset <= 0 || offset > len(dst) || uint64(len(dst)+size) > length {
+			return nil, fmt.Errorf("invalid snappy copy")
+		}
+		for i := 0; i < size; i++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+
+	if uint64(len(dst)) != length {
+		return nil, fmt.Errorf("snappy block decoded to %d bytes instead of %d", len(dst), length)
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,114 @@
+package mframe
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SnapshotStore persists named snapshots of DataFrames, e.g. in a directory or an object store.
+type SnapshotStore interface {
+	// Put stores the size bytes read from body under name, replacing any previous snapshot.
+	Put(ctx context.Context, name string, body io.Reader, size int64) error
+	// Get returns the snapshot stored under name, or an error wrapping os.ErrNotExist if there is none.
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// SaveToStore saves a gzip-compressed snapshot of the DataFrame, in the format of SaveToWriter, to the store
+// under name. The snapshot is encoded in memory while holding the read lock and uploaded after releasing it.
+func (d *DataFrame) SaveToStore(ctx context.Context, store SnapshotStore, name string) error {
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	if err := d.SaveToWriter(gzWriter); err != nil {
+		return fmt.Errorf("failed to encode dataframe: %w", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+
+	if err := store.Put(ctx, name, bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		return fmt.Errorf("failed to store snapshot '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// LoadFromStore loads the DataFrame from a snapshot saved with SaveToStore, replacing its rows.
+func (d *DataFrame) LoadFromStore(ctx context.Context, store SnapshotStore, name string) error {
+	body, err := store.Get(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot '%s': %w", name, err)
+	}
+	defer func() { _ = body.Close() }()
+
+	gzReader, err := gzip.NewReader(body)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer func() { _ = gzReader.Close() }()
+
+	return d.LoadFromReader(gzReader)
+}
+
+// FileStore is a SnapshotStore keeping each snapshot as a file of Dir named after it.
+type FileStore struct {
+	Dir string
+}
+
+// Put writes the snapshot to a temporary file and renames it, so readers never see partial snapshots.
+func (s FileStore) Put(ctx context.Context, name string, body io.Reader, _ int64) error {
+	filename, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(s.Dir, ".tmp-mframe-*.snapshot")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmpFile.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := io.Copy(tmpFile, body); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("failed to write to temporary file: %w", err)
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %w", err)
+	}
+
+	return nil
+}
+
+// Get opens the file of the snapshot.
+func (s FileStore) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	filename, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return os.Open(filename)
+}
+
+// path returns the file of a snapshot, rejecting names that would escape Dir.
+func (s FileStore) path(name string) (string, error) {
+	if !filepath.IsLocal(name) || filepath.Base(name) != name {
+		return "", fmt.Errorf("invalid snapshot name '%s'", name)
+	}
+	return filepath.Join(s.Dir, name), nil
+}
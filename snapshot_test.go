@@ -0,0 +1,68 @@
+package mframe_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestDataFrame_SaveLoadStore(t *testing.T) {
+	ctx := context.Background()
+	store := mframe.FileStore{Dir: t.TempDir()}
+
+	var cache mframe.DataFrame
+	cache.Init(time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "bytes": 10.0},
+		{"host": "web-2", "bytes": 20.0},
+	})
+
+	if err := cache.SaveToStore(ctx, store, "frame.snapshot"); err != nil {
+		t.Fatalf("Failed to save to store: %v", err)
+	}
+
+	var restored mframe.DataFrame
+	restored.Init(24 * time.Hour)
+	if err := restored.LoadFromStore(ctx, store, "frame.snapshot"); err != nil {
+		t.Fatalf("Failed to load from store: %v", err)
+	}
+
+	if restored.Count() != 2 {
+		t.Errorf("expected 2 rows, but got %d", restored.Count())
+	}
+	if restored.TTL != time.Hour {
+		t.Errorf("expected TTL %v, but got %v", time.Hour, restored.TTL)
+	}
+	if restored.Filter(mframe.Equals, "host", "web-2", nil).Count() != 1 {
+		t.Error("expected the indexes to be restored")
+	}
+}
+
+func TestFileStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	store := mframe.FileStore{Dir: t.TempDir()}
+
+	var cache mframe.DataFrame
+	cache.Init(time.Hour)
+
+	if err := cache.LoadFromStore(ctx, store, "missing"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected os.ErrNotExist, but got %v", err)
+	}
+
+	for _, name := range []string{"", "../frame", "dir/frame", "/tmp/frame"} {
+		if err := store.Put(ctx, name, strings.NewReader("data"), 4); err == nil {
+			t.Errorf("expected an error for the name '%s'", name)
+		}
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := cache.SaveToStore(canceled, store, "frame"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, but got %v", err)
+	}
+}
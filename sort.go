@@ -0,0 +1,161 @@
+package mframe
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Direction selects the order of a sort key.
+type Direction int
+
+const (
+	Asc  Direction = 1
+	Desc Direction = 2
+)
+
+// OrderBy is a sort key: the key to compare and the direction to order it in.
+type OrderBy struct {
+	Key       KeyName
+	Direction Direction
+}
+
+// Sort returns the rows of the DataFrame ordered by the given sort keys, comparing by the first key
+// and falling back to the following ones on ties. Rows without a key are placed after the others
+// for that key regardless of the direction.
+// Remaining ties are broken by row ID, so the order is the same on every call.
+func (d *DataFrame) Sort(orders ...OrderBy) []Row {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	ids := d.sortedIDsUnlocked(orders)
+	result := make([]Row, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, d.Data[id])
+	}
+
+	return result
+}
+
+// Limit returns the first n rows of the DataFrame in the order given by the sort keys, see Sort.
+func (d *DataFrame) Limit(n int, orders ...OrderBy) []Row {
+	return d.Page(0, n, orders...)
+}
+
+// Page returns up to limit rows of the DataFrame, skipping the first offset rows in the order given by
+// the sort keys, see Sort. Without sort keys rows are ordered by ID, so consecutive pages never repeat
+// or skip rows while the DataFrame does not change. Returns an empty slice if offset is past the last row.
+func (d *DataFrame) Page(offset, limit int, orders ...OrderBy) []Row {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	ids := d.sortedIDsUnlocked(orders)
+	offset = min(max(offset, 0), len(ids))
+	end := min(offset+max(limit, 0), len(ids))
+
+	result := make([]Row, 0, end-offset)
+	for _, id := range ids[offset:end] {
+		result = append(result, d.Data[id])
+	}
+
+	return result
+}
+
+// sortedIDsUnlocked returns the IDs of every row ordered by the given sort keys, without acquiring locks.
+func (d *DataFrame) sortedIDsUnlocked(orders []OrderBy) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(d.Data))
+	for id := range d.Data {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := d.Data[ids[i]], d.Data[ids[j]]
+		for _, order := range orders {
+			x, _ := d.fieldUnlocked(a, order.Key)
+			y, _ := d.fieldUnlocked(b, order.Key)
+			c := compareValues(x, y)
+			if c == 0 {
+				continue
+			}
+			if c == missingFirst || c == missingLast {
+				return c == missingLast
+			}
+			if order.Direction == Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return ids[i].String() < ids[j].String()
+	})
+
+	return ids
+}
+
+const (
+	// missingFirst is returned by compareValues when only the second value is comparable.
+	missingFirst = 2
+	// missingLast is returned by compareValues when only the first value is comparable.
+	missingLast = -2
+)
+
+// compareValues compares two values of the same type, returning -1, 0 or 1. When only one of them
+// is a comparable value it returns missingLast or missingFirst, so the comparable value sorts first.
+// Values of different comparable types, or of no comparable type, are equal.
+func compareValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return cmp.Compare(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			if x == y {
+				return 0
+			}
+			if !x {
+				return -1
+			}
+			return 1
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+
+	aComparable, bComparable := isComparable(a), isComparable(b)
+	switch {
+	case aComparable && !bComparable:
+		return missingLast
+	case !aComparable && bComparable:
+		return missingFirst
+	default:
+		return 0
+	}
+}
+
+// valueLess orders values of the same type like compareValues, numbers numerically and times
+// chronologically, and values of different types by the name of their type.
+func valueLess(a, b interface{}) bool {
+	if c := compareValues(a, b); c != 0 {
+		return c < 0
+	}
+	return fmt.Sprintf("%T", a) < fmt.Sprintf("%T", b)
+}
+
+// isComparable reports whether the value is of a type compareValues orders.
+func isComparable(v interface{}) bool {
+	switch v.(type) {
+	case float64, string, bool, time.Time:
+		return true
+	default:
+		return false
+	}
+}
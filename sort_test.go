@@ -0,0 +1,107 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSort(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"name": "carol", "score": 5},
+		{"name": "alice", "score": 9},
+		{"name": "bob", "score": 5},
+		{"name": "dave"},
+		{"name": "eve", "score": 7},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	tests := []struct {
+		name     string
+		orders   []mframe.OrderBy
+		expected []string
+	}{
+		{"score desc then name", []mframe.OrderBy{{"score", mframe.Desc}, {"name", mframe.Asc}}, []string{"alice", "eve", "bob", "carol", "dave"}},
+		{"score asc then name desc", []mframe.OrderBy{{"score", mframe.Asc}, {"name", mframe.Desc}}, []string{"carol", "bob", "eve", "alice", "dave"}},
+		{"name", []mframe.OrderBy{{"name", mframe.Asc}}, []string{"alice", "bob", "carol", "dave", "eve"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := cache.Sort(tt.orders...)
+			if len(rows) != len(tt.expected) {
+				t.Fatalf("expected %d rows, but got %d", len(tt.expected), len(rows))
+			}
+			for i, name := range tt.expected {
+				if rows[i]["name"] != name {
+					t.Errorf("expected %s at position %d, but got %v", name, i, rows[i]["name"])
+				}
+			}
+		})
+	}
+
+	first := cache.Sort()
+	second := cache.Sort()
+	for i := range first {
+		if first[i]["name"] != second[i]["name"] {
+			t.Fatal("expected the same order on every call")
+		}
+	}
+}
+
+func TestPage(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"seq": i})
+	}
+
+	tests := []struct {
+		name     string
+		offset   int
+		limit    int
+		expected []float64
+	}{
+		{"first page", 0, 4, []float64{9, 8, 7, 6}},
+		{"second page", 4, 4, []float64{5, 4, 3, 2}},
+		{"last page", 8, 4, []float64{1, 0}},
+		{"past the end", 20, 4, []float64{}},
+		{"negative offset", -1, 2, []float64{9, 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := cache.Page(tt.offset, tt.limit, mframe.OrderBy{Key: "seq", Direction: mframe.Desc})
+			if len(rows) != len(tt.expected) {
+				t.Fatalf("expected %d rows, but got %d", len(tt.expected), len(rows))
+			}
+			for i, seq := range tt.expected {
+				if rows[i]["seq"] != seq {
+					t.Errorf("expected seq %v at position %d, but got %v", seq, i, rows[i]["seq"])
+				}
+			}
+		})
+	}
+
+	if rows := cache.Limit(3, mframe.OrderBy{Key: "seq", Direction: mframe.Asc}); len(rows) != 3 || rows[2]["seq"] != 2.0 {
+		t.Errorf("expected the 3 lowest rows, but got %v", rows)
+	}
+
+	seen := make(map[float64]bool)
+	for offset := 0; offset < 10; offset += 3 {
+		for _, row := range cache.Page(offset, 3) {
+			seen[row["seq"].(float64)] = true
+		}
+	}
+	if len(seen) != 10 {
+		t.Errorf("expected pages without sort keys to cover every row once, but got %d rows", len(seen))
+	}
+}
@@ -0,0 +1,93 @@
+package mframe
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueryDelta is the change in the result of a standing query between two evaluations.
+type QueryDelta struct {
+	// Added holds the IDs of the rows that match now but did not match in the previous evaluation.
+	Added []uuid.UUID
+	// Removed holds the IDs of the rows that matched in the previous evaluation but do not match now,
+	// either because they changed or because they are gone.
+	Removed []uuid.UUID
+	// Total is the number of rows matching now.
+	Total int
+}
+
+// Empty reports whether the result did not change.
+func (q QueryDelta) Empty() bool {
+	return len(q.Added) == 0 && len(q.Removed) == 0
+}
+
+// StandingQuery is a query evaluated repeatedly against a DataFrame that reports only what changed
+// since its previous evaluation, so alerting consumers do not process the same matches every interval.
+type StandingQuery struct {
+	frame    *DataFrame
+	spec     FilterSpec
+	mutex    sync.Mutex
+	previous map[uuid.UUID]struct{}
+}
+
+// NewStandingQuery returns a standing query for the rows matching spec. Its first evaluation reports
+// every matching row as added.
+func (d *DataFrame) NewStandingQuery(spec FilterSpec) *StandingQuery {
+	return &StandingQuery{frame: d, spec: spec, previous: make(map[uuid.UUID]struct{})}
+}
+
+// Evaluate runs the query and returns the delta relative to the previous evaluation.
+// The IDs in the delta are sorted.
+func (q *StandingQuery) Evaluate() QueryDelta {
+	q.frame.Locker.RLock()
+	current := q.frame.specIDsUnlocked(q.spec)
+	q.frame.Locker.RUnlock()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delta := QueryDelta{Added: make([]uuid.UUID, 0), Removed: make([]uuid.UUID, 0), Total: len(current)}
+	for id := range current {
+		if _, ok := q.previous[id]; !ok {
+			delta.Added = append(delta.Added, id)
+		}
+	}
+	for id := range q.previous {
+		if _, ok := current[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+	q.previous = current
+
+	sortIDs(delta.Added)
+	sortIDs(delta.Removed)
+
+	return delta
+}
+
+// Run evaluates the query every interval until ctx is done, calling deliver with each non-empty delta.
+func (q *StandingQuery) Run(ctx context.Context, interval time.Duration, deliver func(QueryDelta)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if delta := q.Evaluate(); !delta.Empty() {
+			deliver(delta)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sortIDs sorts IDs by their string form.
+func sortIDs(ids []uuid.UUID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+}
@@ -0,0 +1,77 @@
+package mframe_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestStandingQuery(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"severity": "high", "host": "a"})
+	cache.Insert(map[mframe.KeyName]interface{}{"severity": "low", "host": "b"})
+
+	query := cache.NewStandingQuery(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "severity", Value: "high"},
+	}})
+
+	first := query.Evaluate()
+	if len(first.Added) != 1 || len(first.Removed) != 0 || first.Total != 1 {
+		t.Fatalf("expected the first evaluation to add the matching row, but got %+v", first)
+	}
+
+	if delta := query.Evaluate(); !delta.Empty() || delta.Total != 1 {
+		t.Errorf("expected no change, but got %+v", delta)
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"severity": "high", "host": "c"})
+	cache.RemoveElement(first.Added[0])
+
+	delta := query.Evaluate()
+	if len(delta.Added) != 1 || len(delta.Removed) != 1 || delta.Total != 1 {
+		t.Fatalf("expected one added and one removed row, but got %+v", delta)
+	}
+	if delta.Removed[0] != first.Added[0] {
+		t.Errorf("expected the removed row to be %s, but got %s", first.Added[0], delta.Removed[0])
+	}
+	if cache.Data[delta.Added[0]]["host"] != "c" {
+		t.Errorf("expected the added row to be host c, but got %v", cache.Data[delta.Added[0]])
+	}
+}
+
+func TestStandingQueryRun(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.Insert(map[mframe.KeyName]interface{}{"severity": "high"})
+
+	query := cache.NewStandingQuery(mframe.FilterSpec{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deltas := make(chan mframe.QueryDelta, 10)
+	done := make(chan struct{})
+	go func() {
+		query.Run(ctx, 10*time.Millisecond, func(delta mframe.QueryDelta) { deltas <- delta })
+		close(done)
+	}()
+
+	if delta := <-deltas; len(delta.Added) != 1 {
+		t.Errorf("expected the initial row to be delivered, but got %+v", delta)
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"severity": "low"})
+	select {
+	case delta := <-deltas:
+		if len(delta.Added) != 1 || delta.Total != 2 {
+			t.Errorf("expected only the new row to be delivered, but got %+v", delta)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected a delta after inserting a row")
+	}
+
+	cancel()
+	<-done
+}
@@ -1,43 +1,22 @@
 package mframe
 
-import (
-	"log"
-	"time"
-)
+import "log"
 
-// Stats log statistical information about string, numeric, and boolean indices in the DataFrame for tracking purposes.
+// Stats logs a summary of the StatsSnapshot of the DataFrame: the number of rows, the entries of every index,
+// the estimated memory and the keys with the most distinct values. It returns once the summary is logged.
+//
+// Deprecated: Use StatsSnapshot to read the statistics, or Metrics, WritePrometheus or PublishExpvar to
+// export them.
 func (d *DataFrame) Stats(name string) {
-	for {
-		d.Locker.RLock()
+	stats := d.StatsSnapshot()
 
-		log.Printf("[%s] data in memory: %d", name, len(d.Data))
-		log.Printf("[%s] string indices: %d", name, len(d.Strings))
-		log.Printf("[%s] numeric indices: %d", name, len(d.Numerics))
-		log.Printf("[%s] boolean indices: %d", name, len(d.Booleans))
+	log.Printf("[%s] data in memory: %d rows, about %d bytes", name, stats.Rows, stats.MemoryBytes)
+	log.Printf("[%s] string index: %d keys, %d values", name, stats.Strings.Keys, stats.Strings.Values)
+	log.Printf("[%s] numeric index: %d keys, %d values", name, stats.Numerics.Keys, stats.Numerics.Values)
+	log.Printf("[%s] boolean index: %d keys, %d values", name, stats.Booleans.Keys, stats.Booleans.Values)
+	log.Printf("[%s] time index: %d keys, %d values", name, stats.Times.Keys, stats.Times.Values)
 
-		for k1, v1 := range d.Strings {
-			log.Printf(`[%s] %d values in '%s' string index`, name, len(v1), k1)
-			for k2, v2 := range v1 {
-				log.Printf(`[%s] %d IDs for value '%s' in '%s' string index`, name, len(v2), k2, k1)
-			}
-		}
-
-		for k1, v1 := range d.Numerics {
-			log.Printf(`[%s] %d values in '%s' numeric index`, name, len(v1), k1)
-			for k2, v2 := range v1 {
-				log.Printf(`[%s] %d IDs for value '%f' in '%s' numeric index`, name, len(v2), k2, k1)
-			}
-		}
-
-		for k1, v1 := range d.Booleans {
-			log.Printf(`[%s] %d values in '%s' boolean index`, name, len(v1), k1)
-			for k2, v2 := range v1 {
-				log.Printf(`[%s] %d IDs for value '%t' in '%s' boolean index`, name, len(v2), k2, k1)
-			}
-		}
-
-		d.Locker.RUnlock()
-
-		time.Sleep(1 * time.Minute)
+	for _, key := range stats.TopCardinality {
+		log.Printf("[%s] %d values in '%s'", name, key.Values, key.Key)
 	}
 }
@@ -0,0 +1,154 @@
+package mframe
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// topCardinalityKeys is the number of keys reported in FrameStats.TopCardinality.
+const topCardinalityKeys = 10
+
+// FrameStats is a snapshot of the size and activity of a DataFrame, taken by StatsSnapshot.
+// Compare two snapshots with DiffStats to trend growth and plan TTL and memory budgets.
+type FrameStats struct {
+	At   time.Time
+	Rows int
+	// Cardinality holds the number of distinct values of every key.
+	Cardinality map[KeyName]int
+	// TopCardinality holds the keys with the most distinct values, in decreasing order.
+	TopCardinality []KeyCardinality
+	// Strings, Numerics, Booleans and Times count the entries of each index.
+	Strings  IndexCounts
+	Numerics IndexCounts
+	Booleans IndexCounts
+	Times    IndexCounts
+	// MemoryBytes estimates the memory used by the rows, from a sample of them.
+	MemoryBytes int
+	// Inserted, Expired and Removed count the rows inserted, expired and otherwise removed since the
+	// DataFrame was created.
+	Inserted int
+	Expired  int
+	Removed  int
+}
+
+// KeyCardinality is the number of distinct values of a key.
+type KeyCardinality struct {
+	Key    KeyName
+	Values int
+}
+
+// IndexCounts counts the entries of an index: its keys, their distinct values and the row IDs
+// referenced by those values.
+type IndexCounts struct {
+	Keys   int
+	Values int
+	IDs    int
+}
+
+// StatsDiff is the change between two snapshots, with the activity expressed as rates per second.
+type StatsDiff struct {
+	Interval    time.Duration
+	Rows        int
+	MemoryBytes int
+	// Cardinality holds the change of the number of distinct values of every key found in either snapshot.
+	Cardinality map[KeyName]int
+	Inserted    int
+	Expired     int
+	Removed     int
+	InsertRate  float64
+	ExpireRate  float64
+	RemoveRate  float64
+}
+
+// activity counts the rows inserted and removed since the DataFrame was created, under its lock.
+type activity struct {
+	inserted int
+	expired  int
+	removed  int
+	inserts  rateWindow
+}
+
+// StatsSnapshot returns the number of rows, the cardinality of every key and the keys with the most
+// distinct values, the entries of every index, the estimated memory of the rows and the number of rows
+// inserted, expired and removed so far.
+func (d *DataFrame) StatsSnapshot() FrameStats {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	stats := FrameStats{
+		At:          time.Now().UTC(),
+		Rows:        len(d.Data),
+		Cardinality: make(map[KeyName]int, len(d.Keys)),
+		MemoryBytes: d.averageRowSizeUnlocked() * len(d.Data),
+		Inserted:    d.activity.inserted,
+		Expired:     d.activity.expired,
+		Removed:     d.activity.removed,
+		Strings:     countIndex(d.Strings),
+		Numerics:    countIndex(d.Numerics),
+		Booleans:    countIndex(d.Booleans),
+		Times:       countIndex(d.Times),
+	}
+
+	for key := range d.Keys {
+		stats.Cardinality[key] = d.uniqueValuesUnlocked(key)
+		stats.TopCardinality = append(stats.TopCardinality, KeyCardinality{Key: key, Values: stats.Cardinality[key]})
+	}
+
+	sort.Slice(stats.TopCardinality, func(i, j int) bool {
+		a, b := stats.TopCardinality[i], stats.TopCardinality[j]
+		if a.Values != b.Values {
+			return a.Values > b.Values
+		}
+		return a.Key < b.Key
+	})
+	if len(stats.TopCardinality) > topCardinalityKeys {
+		stats.TopCardinality = stats.TopCardinality[:topCardinalityKeys]
+	}
+
+	return stats
+}
+
+// countIndex counts the keys, values and IDs of an index.
+func countIndex[V comparable](index map[KeyName]map[V]map[uuid.UUID]bool) IndexCounts {
+	counts := IndexCounts{Keys: len(index)}
+	for _, values := range index {
+		counts.Values += len(values)
+		for _, ids := range values {
+			counts.IDs += len(ids)
+		}
+	}
+	return counts
+}
+
+// DiffStats returns the change from snapshot a to the later snapshot b. Rates are zero when both
+// snapshots were taken at the same time.
+func DiffStats(a, b FrameStats) StatsDiff {
+	diff := StatsDiff{
+		Interval:    b.At.Sub(a.At),
+		Rows:        b.Rows - a.Rows,
+		MemoryBytes: b.MemoryBytes - a.MemoryBytes,
+		Cardinality: make(map[KeyName]int),
+		Inserted:    b.Inserted - a.Inserted,
+		Expired:     b.Expired - a.Expired,
+		Removed:     b.Removed - a.Removed,
+	}
+
+	for key, n := range b.Cardinality {
+		diff.Cardinality[key] = n - a.Cardinality[key]
+	}
+	for key, n := range a.Cardinality {
+		if _, ok := b.Cardinality[key]; !ok {
+			diff.Cardinality[key] = -n
+		}
+	}
+
+	if seconds := diff.Interval.Seconds(); seconds > 0 {
+		diff.InsertRate = float64(diff.Inserted) / seconds
+		diff.ExpireRate = float64(diff.Expired) / seconds
+		diff.RemoveRate = float64(diff.Removed) / seconds
+	}
+
+	return diff
+}
@@ -0,0 +1,95 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestStatsSnapshot(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "bytes": 100},
+		{"host": "web-2", "bytes": 100},
+	})
+
+	before := cache.StatsSnapshot()
+	if before.Rows != 2 || before.Inserted != 2 || before.Cardinality["host"] != 2 || before.Cardinality["bytes"] != 1 {
+		t.Errorf("expected 2 rows with 2 hosts and 1 byte count, but got %+v", before)
+	}
+	if before.MemoryBytes <= 0 {
+		t.Errorf("expected a memory estimate, but got %d", before.MemoryBytes)
+	}
+
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-3", "user": "alice"},
+		{"host": "web-4", "user": "bob"},
+	})
+	cache.DeleteWhere(mframe.Equals, "host", "web-1", nil)
+
+	after := cache.StatsSnapshot()
+	after.At = before.At.Add(2 * time.Second)
+
+	diff := mframe.DiffStats(before, after)
+	if diff.Rows != 1 || diff.Inserted != 2 || diff.Removed != 1 || diff.Expired != 0 {
+		t.Errorf("expected 1 more row after 2 inserts and 1 removal, but got %+v", diff)
+	}
+	if diff.InsertRate != 1 || diff.RemoveRate != 0.5 {
+		t.Errorf("expected rates of 1 insert and 0.5 removals per second, but got %f and %f", diff.InsertRate, diff.RemoveRate)
+	}
+	if diff.Cardinality["host"] != 1 || diff.Cardinality["user"] != 2 {
+		t.Errorf("expected cardinality changes of 1 host and 2 users, but got %v", diff.Cardinality)
+	}
+
+	if reverse := mframe.DiffStats(after, before); reverse.Cardinality["user"] != -2 || reverse.InsertRate != 0 {
+		t.Errorf("expected the users to disappear without rates, but got %+v", reverse)
+	}
+}
+
+func TestStatsSnapshotExpired(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(10 * time.Millisecond)
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "web-1"})
+
+	cache.StartCleaner()
+	time.Sleep(1500 * time.Millisecond)
+	cache.StopCleaner()
+
+	if stats := cache.StatsSnapshot(); stats.Rows != 0 || stats.Expired != 1 {
+		t.Errorf("expected 1 expired row, but got %+v", stats)
+	}
+}
+
+func TestStatsSnapshotIndexes(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "web-1", "port": 80, "up": true},
+		{"host": "web-2", "port": 80, "up": true},
+		{"host": "web-3", "port": 443, "up": false},
+	})
+
+	stats := cache.StatsSnapshot()
+
+	if stats.Strings != (mframe.IndexCounts{Keys: 1, Values: 3, IDs: 3}) {
+		t.Errorf("expected 1 string key with 3 values, but got %+v", stats.Strings)
+	}
+	if stats.Numerics != (mframe.IndexCounts{Keys: 1, Values: 2, IDs: 3}) {
+		t.Errorf("expected 1 numeric key with 2 values, but got %+v", stats.Numerics)
+	}
+	if stats.Booleans != (mframe.IndexCounts{Keys: 1, Values: 2, IDs: 3}) {
+		t.Errorf("expected 1 boolean key with 2 values, but got %+v", stats.Booleans)
+	}
+
+	want := []mframe.KeyCardinality{{Key: "host", Values: 3}, {Key: "port", Values: 2}, {Key: "up", Values: 2}}
+	if len(stats.TopCardinality) != len(want) {
+		t.Fatalf("expected %d top keys, but got %+v", len(want), stats.TopCardinality)
+	}
+	for i := range want {
+		if stats.TopCardinality[i] != want[i] {
+			t.Errorf("expected top key %d to be %+v, but got %+v", i, want[i], stats.TopCardinality[i])
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package mframe
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// IndexError is returned in strict mode for a value that cannot be indexed.
+type IndexError struct {
+	// ID is the ID the row would have had, uuid.Nil for rows inserted one at a time.
+	ID     uuid.UUID
+	Key    KeyName
+	Value  interface{}
+	Reason string
+}
+
+// Error describes the value and why it cannot be indexed.
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("cannot index key '%s': %s", e.Key, e.Reason)
+}
+
+// SetStrict enables or disables strict mode. By default, values that cannot be indexed, such as values of
+// unsupported types, values conflicting with the type of their key and nil values or maps, are logged and
+// left out of the row, and empty rows are skipped. In strict mode, the insert methods returning errors reject
+// the rows holding them instead, returning an *IndexError per value, joined with errors.Join.
+// Insert cannot return errors and logs them, so strict callers should use InsertWithError.
+func (d *DataFrame) SetStrict(enabled bool) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.strict = enabled
+}
+
+// checkIndexableUnlocked returns the errors of the values of the entries that cannot be indexed, without
+// acquiring locks. Keys mapped by an entry are taken into account for the following ones, in ID order.
+func (d *DataFrame) checkIndexableUnlocked(entries map[uuid.UUID]map[KeyName]interface{}) error {
+	ids := make([]uuid.UUID, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+
+	var errs []error
+	pending := make(map[KeyName]KeyType)
+	for _, id := range ids {
+		if len(entries[id]) == 0 {
+			errs = append(errs, &IndexError{ID: id, Reason: "row is empty"})
+			continue
+		}
+		errs = d.checkValuesUnlocked(id, entries[id], "", pending, errs)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkValuesUnlocked appends to errs the errors of the values of data under prefix, in key order,
+// recording the types of new keys in pending, without acquiring locks.
+func (d *DataFrame) checkValuesUnlocked(id uuid.UUID, data map[KeyName]interface{}, prefix KeyName, pending map[KeyName]KeyType, errs []error) []error {
+	keys := make([]KeyName, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, key := range keys {
+		value := data[key]
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if d.notIndexedUnlocked(key) {
+			continue
+		}
+
+		fail := func(reason string) {
+			errs = append(errs, &IndexError{ID: id, Key: key, Value: value, Reason: reason})
+		}
+
+		switch v := value.(type) {
+		case nil:
+			fail("value is nil")
+			continue
+		case map[string]interface{}:
+			if v == nil {
+				fail("map is nil")
+				continue
+			}
+			nested := make(map[KeyName]interface{}, len(v))
+			for k, item := range v {
+				nested[KeyName(k)] = item
+			}
+			errs = d.checkValuesUnlocked(id, nested, key, pending, errs)
+			continue
+		case []interface{}:
+			for i, item := range v {
+				errs = d.checkValuesUnlocked(id, map[KeyName]interface{}{KeyName(fmt.Sprint(i)): item}, key, pending, errs)
+			}
+			continue
+		case string:
+			value = d.coerceUnlocked(key, v)
+		default:
+			value = normalizeValue(value)
+		}
+
+		keyType, ok := keyTypeOf(value)
+		if !ok {
+			fail(fmt.Sprintf("unsupported type %T", value))
+			continue
+		}
+
+		mapped, exists := d.Keys[key]
+		if !exists {
+			mapped, exists = pending[key]
+		}
+		if exists && mapped != keyType {
+			fail(fmt.Sprintf("cannot map as %s because it is already mapped as %s", keyTypeToString(keyType), keyTypeToString(mapped)))
+			continue
+		}
+		pending[key] = keyType
+	}
+
+	return errs
+}
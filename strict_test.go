@@ -0,0 +1,89 @@
+package mframe_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+type unsupported struct{}
+
+func TestStrictInsert(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[mframe.KeyName]interface{}
+		key  mframe.KeyName
+	}{
+		{"unsupported type", map[mframe.KeyName]interface{}{"host": "web-1", "other": unsupported{}}, "other"},
+		{"type conflict", map[mframe.KeyName]interface{}{"bytes": "many"}, "bytes"},
+		{"nested conflict", map[mframe.KeyName]interface{}{"geo": map[string]interface{}{"city": 1}}, "geo.city"},
+		{"nil map", map[mframe.KeyName]interface{}{"geo": map[string]interface{}(nil)}, "geo"},
+		{"nil value", map[mframe.KeyName]interface{}{"host": nil}, "host"},
+		{"empty row", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cache mframe.DataFrame
+			cache.Init(time.Hour)
+			cache.Insert(map[mframe.KeyName]interface{}{"bytes": 1.0, "geo": map[string]interface{}{"city": "Lisbon"}})
+			cache.SetStrict(true)
+
+			_, err := cache.InsertReturningID(tt.data)
+			if tt.data == nil {
+				err = cache.InsertBatchWithIDs(map[uuid.UUID]map[mframe.KeyName]interface{}{uuid.New(): {}})
+			}
+
+			var indexErr *mframe.IndexError
+			if !errors.As(err, &indexErr) {
+				t.Fatalf("expected an *IndexError, but got %v", err)
+			}
+			if indexErr.Key != tt.key {
+				t.Errorf("expected key '%s', but got '%s'", tt.key, indexErr.Key)
+			}
+			if cache.Count() != 1 {
+				t.Errorf("expected the row to be rejected, but got %d rows", cache.Count())
+			}
+		})
+	}
+}
+
+func TestStrictInsertBatch(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(time.Hour)
+	cache.SetStrict(true)
+
+	err := cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"port": 80.0},
+		{"port": "http"},
+	})
+	var indexErr *mframe.IndexError
+	if !errors.As(err, &indexErr) || indexErr.Key != "port" {
+		t.Fatalf("expected a conflict on 'port' within the batch, but got %v", err)
+	}
+	if cache.Count() != 0 {
+		t.Errorf("expected the batch to be rejected, but got %d rows", cache.Count())
+	}
+
+	cache.OnBeforeInsert(func(data map[mframe.KeyName]interface{}) (map[mframe.KeyName]interface{}, error) {
+		if _, ok := data["drop"]; ok {
+			return nil, errors.New("dropped by hook")
+		}
+		return data, nil
+	})
+	if err := cache.InsertBatch([]map[mframe.KeyName]interface{}{{"port": 80.0}, {"drop": true}}); err == nil {
+		t.Error("expected the hook error to be returned")
+	}
+
+	cache.SetStrict(false)
+	if err := cache.InsertBatch([]map[mframe.KeyName]interface{}{{"port": 80.0}, {"port": "http"}, {"drop": true}}); err != nil {
+		t.Fatalf("expected no error outside strict mode, but got %v", err)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 rows, but got %d", cache.Count())
+	}
+}
@@ -0,0 +1,157 @@
+package mframe
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSubscriptionBuffer is the capacity of the channels returned by Subscribe.
+const DefaultSubscriptionBuffer = 1024
+
+// ChangeType is the kind of change reported by a ChangeEvent.
+type ChangeType int
+
+const (
+	ChangeInsert ChangeType = 1
+	ChangeUpdate ChangeType = 2
+	ChangeDelete ChangeType = 3
+	ChangeExpire ChangeType = 4
+)
+
+// String returns the name of the change type.
+func (c ChangeType) String() string {
+	switch c {
+	case ChangeInsert:
+		return "insert"
+	case ChangeUpdate:
+		return "update"
+	case ChangeDelete:
+		return "delete"
+	case ChangeExpire:
+		return "expire"
+	default:
+		return "unknown"
+	}
+}
+
+// ChangeEvent describes a change of a row matching a subscription.
+type ChangeEvent struct {
+	Type ChangeType
+	ID   uuid.UUID
+	// Row is a copy of the row after the change, or of its last content for deletions and expirations.
+	Row Row
+	// Matches reports whether the row matches the filter after the change. Updates are reported when the
+	// row matches before or after them, so an update with Matches false means the row left the filter.
+	Matches bool
+	At      time.Time
+}
+
+// subscription is a channel receiving the changes of the rows matching a filter.
+type subscription struct {
+	events  chan ChangeEvent
+	matcher *rowMatcher
+	dropped int
+}
+
+// rowMatcher evaluates a filter against single rows by indexing each row alone in a scratch DataFrame,
+// so rows are matched with exactly the semantics of Filter.
+type rowMatcher struct {
+	spec    FilterSpec
+	scratch *DataFrame
+}
+
+// Subscribe returns a channel receiving an event for each insert, update, deletion and expiration of a row
+// matching every condition of spec, so detection logic can react to new data without polling. Rows replaced by
+// a batch insert with the same ID are reported as inserted. Events are sent without blocking: when the channel
+// is full they are dropped and counted, see DroppedEvents. The channel is closed by Unsubscribe.
+func (d *DataFrame) Subscribe(spec FilterSpec) <-chan ChangeEvent {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	s := &subscription{events: make(chan ChangeEvent, DefaultSubscriptionBuffer), matcher: newRowMatcher(spec)}
+	d.subscriptions = append(d.subscriptions, s)
+
+	return s.events
+}
+
+// Unsubscribe stops the subscription of the channel and closes it. It does nothing for unknown channels.
+func (d *DataFrame) Unsubscribe(events <-chan ChangeEvent) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	for i, s := range d.subscriptions {
+		if s.events == events {
+			close(s.events)
+			d.subscriptions = append(d.subscriptions[:i], d.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// DroppedEvents returns the number of events dropped because the channel of the subscription was full.
+func (d *DataFrame) DroppedEvents(events <-chan ChangeEvent) int {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	for _, s := range d.subscriptions {
+		if s.events == events {
+			return s.dropped
+		}
+	}
+	return 0
+}
+
+// publishUnlocked sends the change of a row to the matching subscriptions, without acquiring locks.
+// The DataFrame must be write-locked. before and after are the contents of the row around the change,
+// nil when it did not exist.
+func (d *DataFrame) publishUnlocked(change ChangeType, id uuid.UUID, before, after Row) {
+	if len(d.subscriptions) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, s := range d.subscriptions {
+		matchesBefore := before != nil && s.matcher.matches(id, before)
+		matchesAfter := after != nil && s.matcher.matches(id, after)
+		if !matchesBefore && !matchesAfter {
+			continue
+		}
+
+		row := after
+		if row == nil {
+			row = before
+		}
+
+		select {
+		case s.events <- ChangeEvent{Type: change, ID: id, Row: copyRow(row), Matches: matchesAfter, At: now}:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// newRowMatcher returns a matcher for spec.
+func newRowMatcher(spec FilterSpec) *rowMatcher {
+	scratch := new(DataFrame)
+	scratch.Init(0)
+	return &rowMatcher{spec: spec, scratch: scratch}
+}
+
+// matches reports whether the row matches every condition of the spec. It is not safe for concurrent use.
+func (m *rowMatcher) matches(id uuid.UUID, row Row) bool {
+	if len(m.spec.Conditions) == 0 {
+		return true
+	}
+
+	var indexed = make(Row)
+	m.scratch.index(row, "", id, &indexed)
+	m.scratch.Data[id] = indexed
+
+	_, ok := m.scratch.specIDsUnlocked(m.spec)[id]
+
+	m.scratch.unindexRowUnlocked(id)
+	delete(m.scratch.Data, id)
+
+	return ok
+}
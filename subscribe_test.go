@@ -0,0 +1,103 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func receive(t *testing.T, events <-chan mframe.ChangeEvent) mframe.ChangeEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	default:
+		t.Fatal("expected an event, but got none")
+		return mframe.ChangeEvent{}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	events := cache.Subscribe(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "action", Value: "login"},
+	}})
+
+	id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"action": "login", "user": "alice"})
+	cache.Insert(map[mframe.KeyName]interface{}{"action": "logout", "user": "bob"})
+
+	event := receive(t, events)
+	if event.Type != mframe.ChangeInsert || event.ID != id || event.Row["user"] != "alice" || !event.Matches {
+		t.Errorf("expected an insert event of alice, but got %+v", event)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected the non-matching insert to be filtered out, but got %d events", len(events))
+	}
+
+	cache.UpdateBatch(map[uuid.UUID]mframe.Row{id: {"action": "logout", "user": "alice"}})
+	event = receive(t, events)
+	if event.Type != mframe.ChangeUpdate || event.Matches {
+		t.Errorf("expected an update leaving the filter, but got %+v", event)
+	}
+
+	cache.UpdateBatch(map[uuid.UUID]mframe.Row{id: {"action": "login", "user": "alice"}})
+	if event = receive(t, events); event.Type != mframe.ChangeUpdate || !event.Matches {
+		t.Errorf("expected an update entering the filter, but got %+v", event)
+	}
+
+	cache.RemoveElement(id)
+	event = receive(t, events)
+	if event.Type != mframe.ChangeDelete || event.Row["user"] != "alice" {
+		t.Errorf("expected a delete event with the last content of the row, but got %+v", event)
+	}
+
+	cache.Unsubscribe(events)
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed")
+	}
+	cache.Insert(map[mframe.KeyName]interface{}{"action": "login"})
+}
+
+func TestSubscribeExpire(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(10 * time.Millisecond)
+
+	events := cache.Subscribe(mframe.FilterSpec{})
+	defer cache.Unsubscribe(events)
+
+	id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"action": "login"})
+	receive(t, events)
+
+	cache.StartCleaner()
+	defer cache.StopCleaner()
+
+	select {
+	case event := <-events:
+		if event.Type != mframe.ChangeExpire || event.ID != id {
+			t.Errorf("expected an expire event, but got %+v", event)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("expected an expire event, but got none")
+	}
+}
+
+func TestSubscribeDropped(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	events := cache.Subscribe(mframe.FilterSpec{})
+	defer cache.Unsubscribe(events)
+
+	for i := 0; i < mframe.DefaultSubscriptionBuffer+5; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"n": i})
+	}
+
+	if dropped := cache.DroppedEvents(events); dropped != 5 {
+		t.Errorf("expected 5 dropped events, but got %d", dropped)
+	}
+}
@@ -0,0 +1,122 @@
+package mframe
+
+import (
+	"math"
+	"sort"
+
+	"github.com/montanaflynn/stats"
+)
+
+// SummaryPercentiles are the percentiles reported by Summary.
+var SummaryPercentiles = []float64{25, 50, 75, 90, 95, 99}
+
+// Summary describes the distribution of a numeric field.
+type Summary struct {
+	Count       int
+	Min         float64
+	Max         float64
+	Mean        float64
+	Median      float64
+	StdDev      float64
+	Skewness    float64
+	Kurtosis    float64
+	Percentiles map[float64]float64 // Keyed by the values in SummaryPercentiles
+}
+
+// Summary computes the main statistics of the specified field under a single lock acquisition,
+// reading the values once from the numeric index.
+func (d *DataFrame) Summary(field KeyName) (Summary, error) {
+	d.Locker.RLock()
+	data := make([]float64, 0)
+	for value, ids := range d.Numerics[field] {
+		for range ids {
+			data = append(data, value)
+		}
+	}
+	d.Locker.RUnlock()
+
+	if len(data) == 0 {
+		return Summary{}, stats.EmptyInputErr
+	}
+
+	sort.Float64s(data)
+
+	m := computeMoments(data)
+	result := Summary{
+		Count:       len(data),
+		Min:         data[0],
+		Max:         data[len(data)-1],
+		Mean:        m.mean,
+		StdDev:      math.Sqrt(m.m2),
+		Skewness:    m.skewness(),
+		Kurtosis:    m.kurtosis(),
+		Percentiles: make(map[float64]float64, len(SummaryPercentiles)),
+	}
+
+	var err error
+	result.Median, err = stats.Median(data)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	for _, p := range SummaryPercentiles {
+		result.Percentiles[p], err = stats.Percentile(data, p)
+		if err != nil {
+			return Summary{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// moments holds the mean and the central moments of a sample.
+type moments struct {
+	mean float64
+	m2   float64
+	m3   float64
+	m4   float64
+}
+
+// computeMoments returns the mean and the second, third and fourth population central moments.
+func computeMoments(data []float64) moments {
+	var m moments
+	if len(data) == 0 {
+		return m
+	}
+
+	for _, v := range data {
+		m.mean += v
+	}
+	m.mean /= float64(len(data))
+
+	for _, v := range data {
+		diff := v - m.mean
+		sq := diff * diff
+		m.m2 += sq
+		m.m3 += sq * diff
+		m.m4 += sq * sq
+	}
+
+	n := float64(len(data))
+	m.m2 /= n
+	m.m3 /= n
+	m.m4 /= n
+
+	return m
+}
+
+// skewness returns the population skewness, or 0 when there is no variation.
+func (m moments) skewness() float64 {
+	if m.m2 == 0 {
+		return 0
+	}
+	return m.m3 / math.Pow(m.m2, 1.5)
+}
+
+// kurtosis returns the population excess kurtosis, or 0 when there is no variation.
+func (m moments) kurtosis() float64 {
+	if m.m2 == 0 {
+		return 0
+	}
+	return m.m4/(m.m2*m.m2) - 3
+}
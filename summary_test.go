@@ -0,0 +1,85 @@
+package mframe_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestSummary(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 1; i <= 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"value": float64(i)})
+	}
+
+	summary, err := cache.Summary("value")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if summary.Count != 100 || summary.Min != 1 || summary.Max != 100 {
+		t.Errorf("unexpected count/min/max: %+v", summary)
+	}
+
+	for name, check := range map[string][2]float64{
+		"mean":     {summary.Mean, 50.5},
+		"median":   {summary.Median, 50.5},
+		"skewness": {summary.Skewness, 0},
+	} {
+		if math.Abs(check[0]-check[1]) > 1e-9 {
+			t.Errorf("expected %s %v, but got %v", name, check[1], check[0])
+		}
+	}
+
+	stdDev, _ := cache.StandardDeviation("value")
+	if math.Abs(summary.StdDev-stdDev) > 1e-9 {
+		t.Errorf("expected standard deviation %v, but got %v", stdDev, summary.StdDev)
+	}
+
+	for _, p := range mframe.SummaryPercentiles {
+		expected, _ := cache.Percentile("value", p)
+		if summary.Percentiles[p] != expected {
+			t.Errorf("expected percentile %v to be %v, but got %v", p, expected, summary.Percentiles[p])
+		}
+	}
+
+	if _, err := cache.Summary("missing"); err == nil {
+		t.Error("expected error for a missing field")
+	}
+}
+
+func TestSkewnessAndKurtosis(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for _, v := range []float64{1, 1, 1, 1, 1, 1, 1, 1, 1, 10} {
+		cache.Insert(map[mframe.KeyName]interface{}{"value": v})
+	}
+
+	skewness, err := cache.Skewness("value")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(skewness-8.0/3.0) > 1e-9 {
+		t.Errorf("expected skewness %v, but got %v", 8.0/3.0, skewness)
+	}
+
+	kurtosis, err := cache.Kurtosis("value")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(kurtosis-(73.0/9.0-3)) > 1e-9 {
+		t.Errorf("expected kurtosis %v, but got %v", 73.0/9.0-3, kurtosis)
+	}
+
+	if _, err := cache.Skewness("missing"); err == nil {
+		t.Error("expected error for a missing field")
+	}
+	if _, err := cache.Kurtosis("missing"); err == nil {
+		t.Error("expected error for a missing field")
+	}
+}
@@ -0,0 +1,112 @@
+package mframe
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// tagIndex is the multi-value index of the tags of the rows, kept apart from the row fields.
+type tagIndex struct {
+	rows map[string]map[uuid.UUID]bool
+	tags map[uuid.UUID]map[string]bool
+}
+
+// AddTag labels the row with the specified ID with tag, e.g. triaged or false-positive, without modifying
+// the fields of the row. Adding a tag the row already has does nothing.
+// Returns an error if the tag is empty or the row does not exist.
+func (d *DataFrame) AddTag(id uuid.UUID, tag string) error {
+	if tag == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	if _, ok := d.Data[id]; !ok {
+		return fmt.Errorf("row '%s' not found", id)
+	}
+
+	d.addTagUnlocked(id, tag)
+
+	return nil
+}
+
+// RemoveTag removes tag from the row with the specified ID. Removing a tag the row does not have does nothing.
+func (d *DataFrame) RemoveTag(id uuid.UUID, tag string) {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	delete(d.tags.tags[id], tag)
+	if len(d.tags.tags[id]) == 0 {
+		delete(d.tags.tags, id)
+	}
+
+	delete(d.tags.rows[tag], id)
+	if len(d.tags.rows[tag]) == 0 {
+		delete(d.tags.rows, tag)
+	}
+}
+
+// Tags returns the sorted tags of the row with the specified ID.
+func (d *DataFrame) Tags(id uuid.UUID) []string {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	result := make([]string, 0, len(d.tags.tags[id]))
+	for tag := range d.tags.tags[id] {
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// FilterByTag returns a new DataFrame with the rows labeled with tag, read from the tag index.
+// The rows keep their IDs and tags.
+func (d *DataFrame) FilterByTag(tag string) *DataFrame {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	var results = new(DataFrame)
+	results.Init(d.TTL)
+
+	for id := range d.tags.rows[tag] {
+		results.insertWithIDUnlocked(id, d.Data[id])
+		for t := range d.tags.tags[id] {
+			results.addTagUnlocked(id, t)
+		}
+	}
+
+	return results
+}
+
+// addTagUnlocked adds the tag to the tag index without acquiring locks.
+func (d *DataFrame) addTagUnlocked(id uuid.UUID, tag string) {
+	if d.tags.rows == nil {
+		d.tags.rows = make(map[string]map[uuid.UUID]bool)
+		d.tags.tags = make(map[uuid.UUID]map[string]bool)
+	}
+
+	if d.tags.rows[tag] == nil {
+		d.tags.rows[tag] = make(map[uuid.UUID]bool)
+	}
+	d.tags.rows[tag][id] = true
+
+	if d.tags.tags[id] == nil {
+		d.tags.tags[id] = make(map[string]bool)
+	}
+	d.tags.tags[id][tag] = true
+}
+
+// untagRowUnlocked removes every tag of a row from the tag index without acquiring locks.
+func (d *DataFrame) untagRowUnlocked(id uuid.UUID) {
+	for tag := range d.tags.tags[id] {
+		delete(d.tags.rows[tag], id)
+		if len(d.tags.rows[tag]) == 0 {
+			delete(d.tags.rows, tag)
+		}
+	}
+	delete(d.tags.tags, id)
+}
@@ -0,0 +1,65 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestTags(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"alert": "a"})
+	cache.Insert(map[mframe.KeyName]interface{}{"alert": "b"})
+	cache.Insert(map[mframe.KeyName]interface{}{"alert": "c"})
+
+	ids := make(map[string]uuid.UUID)
+	for id, row := range cache.Data {
+		ids[row["alert"].(string)] = id
+	}
+
+	for _, tag := range []struct {
+		alert string
+		tag   string
+	}{{"a", "triaged"}, {"a", "escalate"}, {"b", "triaged"}, {"b", "triaged"}, {"c", "false-positive"}} {
+		if err := cache.AddTag(ids[tag.alert], tag.tag); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := cache.AddTag(uuid.New(), "triaged"); err == nil {
+		t.Error("expected error when tagging a missing row")
+	}
+	if err := cache.AddTag(ids["a"], ""); err == nil {
+		t.Error("expected error when adding an empty tag")
+	}
+
+	if tags := cache.Tags(ids["a"]); len(tags) != 2 || tags[0] != "escalate" || tags[1] != "triaged" {
+		t.Errorf("expected tags [escalate triaged], but got %v", tags)
+	}
+
+	triaged := cache.FilterByTag("triaged")
+	if triaged.Count() != 2 {
+		t.Errorf("expected 2 triaged rows, but got %d", triaged.Count())
+	}
+	if tags := triaged.Tags(ids["a"]); len(tags) != 2 {
+		t.Errorf("expected filtered rows to keep their tags, but got %v", tags)
+	}
+	if _, ok := cache.Data[ids["a"]]["triaged"]; ok {
+		t.Error("expected tags not to modify the row fields")
+	}
+
+	cache.RemoveTag(ids["b"], "triaged")
+	if count := cache.FilterByTag("triaged").Count(); count != 1 {
+		t.Errorf("expected 1 triaged row after removing a tag, but got %d", count)
+	}
+
+	cache.RemoveElement(ids["c"])
+	if count := cache.FilterByTag("false-positive").Count(); count != 0 {
+		t.Errorf("expected removed rows to leave the tag index, but got %d", count)
+	}
+}
@@ -0,0 +1,176 @@
+package mframe
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/montanaflynn/stats"
+)
+
+// DefaultCompression is the t-digest compression used by ApproxPercentile.
+const DefaultCompression = 100
+
+// centroid is a cluster of values summarized by their mean and total weight.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest is a merging t-digest, a compact sketch answering approximate quantile queries
+// with higher accuracy near the tails. Higher compression gives more accuracy and uses more memory.
+// A TDigest is not safe for concurrent use.
+type TDigest struct {
+	compression float64
+	centroids   []centroid
+	buffer      []centroid
+	count       float64
+	min         float64
+	max         float64
+}
+
+// NewTDigest creates an empty TDigest with the given compression. Values lower than 1 use DefaultCompression.
+func NewTDigest(compression float64) *TDigest {
+	if compression < 1 {
+		compression = DefaultCompression
+	}
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Add records a value with the given weight. Non-positive weights are ignored.
+func (t *TDigest) Add(value, weight float64) {
+	if weight <= 0 || math.IsNaN(value) {
+		return
+	}
+
+	t.buffer = append(t.buffer, centroid{mean: value, weight: weight})
+	t.count += weight
+	t.min = math.Min(t.min, value)
+	t.max = math.Max(t.max, value)
+
+	if len(t.buffer) >= int(t.compression)*5 {
+		t.compress()
+	}
+}
+
+// Count returns the total weight recorded in the digest.
+func (t *TDigest) Count() float64 {
+	return t.count
+}
+
+// Quantile returns the approximate value at quantile q, with q between 0 and 1.
+func (t *TDigest) Quantile(q float64) (float64, error) {
+	if q < 0 || q > 1 {
+		return 0, fmt.Errorf("quantile %v out of range [0, 1]", q)
+	}
+	if t.count == 0 {
+		return 0, stats.EmptyInputErr
+	}
+
+	t.compress()
+
+	if q == 0 {
+		return t.min, nil
+	}
+	if q == 1 {
+		return t.max, nil
+	}
+
+	target := q * t.count
+
+	first := t.centroids[0]
+	if target < first.weight/2 {
+		return interpolate(t.min, first.mean, target/(first.weight/2)), nil
+	}
+
+	cumulative := 0.0
+	for i := 0; i < len(t.centroids)-1; i++ {
+		left, right := t.centroids[i], t.centroids[i+1]
+		leftCenter := cumulative + left.weight/2
+		rightCenter := cumulative + left.weight + right.weight/2
+		if target <= rightCenter {
+			return interpolate(left.mean, right.mean, (target-leftCenter)/(rightCenter-leftCenter)), nil
+		}
+		cumulative += left.weight
+	}
+
+	last := t.centroids[len(t.centroids)-1]
+	lastCenter := t.count - last.weight/2
+	return interpolate(last.mean, t.max, (target-lastCenter)/(t.count-lastCenter)), nil
+}
+
+// compress merges the buffered values into the centroids, keeping each centroid
+// under the size allowed by the k1 scale function at its position.
+func (t *TDigest) compress() {
+	if len(t.buffer) == 0 {
+		return
+	}
+
+	all := append(t.centroids, t.buffer...)
+	t.buffer = t.buffer[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, len(all))
+	current := all[0]
+	weightSoFar := 0.0
+	limit := t.count * t.quantileLimit(0)
+
+	for _, next := range all[1:] {
+		if weightSoFar+current.weight+next.weight <= limit {
+			total := current.weight + next.weight
+			current.mean += (next.mean - current.mean) * next.weight / total
+			current.weight = total
+			continue
+		}
+
+		weightSoFar += current.weight
+		merged = append(merged, current)
+		current = next
+		limit = t.count * t.quantileLimit(weightSoFar/t.count)
+	}
+
+	t.centroids = append(merged, current)
+}
+
+// quantileLimit returns the highest quantile a centroid starting at q may reach,
+// one unit further along the k1 scale function.
+func (t *TDigest) quantileLimit(q float64) float64 {
+	k := t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+	k++
+	if k >= t.compression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/t.compression) + 1) / 2
+}
+
+// interpolate returns the value at fraction f between a and b.
+func interpolate(a, b, f float64) float64 {
+	return a + (b-a)*math.Max(0, math.Min(1, f))
+}
+
+// Digest builds a TDigest over the numeric values of the specified field, feeding it from
+// the numeric index so repeated values are added once with their row count as weight.
+func (d *DataFrame) Digest(field KeyName, compression float64) *TDigest {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+
+	digest := NewTDigest(compression)
+	for value, ids := range d.Numerics[field] {
+		digest.Add(value, float64(len(ids)))
+	}
+
+	return digest
+}
+
+// ApproxPercentile estimates the percentile value (0-100) of the specified field using a t-digest,
+// which is much faster than Percentile on large frames.
+func (d *DataFrame) ApproxPercentile(field KeyName, percent float64) (float64, error) {
+	if percent < 0 || percent > 100 {
+		return 0, stats.BoundsErr
+	}
+	return d.Digest(field, DefaultCompression).Quantile(percent / 100)
+}
@@ -0,0 +1,71 @@
+package mframe_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestTDigestQuantiles(t *testing.T) {
+	digest := mframe.NewTDigest(100)
+	random := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 100000; i++ {
+		digest.Add(random.Float64()*1000, 1)
+	}
+
+	if digest.Count() != 100000 {
+		t.Errorf("expected count 100000, but got %v", digest.Count())
+	}
+
+	for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99} {
+		result, err := digest.Quantile(q)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if math.Abs(result-q*1000) > 10 {
+			t.Errorf("expected quantile %v to be close to %v, but got %v", q, q*1000, result)
+		}
+	}
+
+	if _, err := digest.Quantile(1.5); err == nil {
+		t.Error("expected error for an out of range quantile")
+	}
+	if _, err := mframe.NewTDigest(100).Quantile(0.5); err == nil {
+		t.Error("expected error for an empty digest")
+	}
+}
+
+func TestApproxPercentile(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 1; i <= 1000; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"value": float64(i)})
+	}
+
+	exact, err := cache.Percentile("value", 90)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	approx, err := cache.ApproxPercentile("value", 90)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if math.Abs(exact-approx) > 10 {
+		t.Errorf("expected approximate percentile close to %v, but got %v", exact, approx)
+	}
+
+	if result, _ := cache.ApproxPercentile("value", 100); result != 1000 {
+		t.Errorf("expected the 100th percentile to be the maximum, but got %v", result)
+	}
+
+	if _, err := cache.ApproxPercentile("value", 101); err == nil {
+		t.Error("expected error for an out of range percent")
+	}
+}
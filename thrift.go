@@ -0,0 +1,294 @@
+package mframe
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Types of the Thrift compact protocol, used to encode the metadata of Parquet files.
+const (
+	thriftTrue   byte = 1
+	thriftFalse  byte = 2
+	thriftByte   byte = 3
+	thriftI16    byte = 4
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftDouble byte = 7
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftSet    byte = 10
+	thriftMap    byte = 11
+	thriftStruct byte = 12
+)
+
+// thriftMaxDepth bounds the nesting of decoded structures.
+const thriftMaxDepth = 32
+
+// thriftWriter encodes structures with the Thrift compact protocol.
+type thriftWriter struct {
+	buf  []byte
+	last []int16
+}
+
+// begin starts a structure, either the top-level one or an element of a list of structures.
+func (w *thriftWriter) begin() {
+	w.last = append(w.last, 0)
+}
+
+// end finishes the current structure.
+func (w *thriftWriter) end() {
+	w.buf = append(w.buf, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+// field writes the header of a field of the current structure.
+func (w *thriftWriter) field(id int16, kind byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|kind)
+	} else {
+		w.buf = append(w.buf, kind)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+// structField starts a structure held by a field, finished with end.
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.begin()
+}
+
+// boolField writes a boolean field.
+func (w *thriftWriter) boolField(id int16, value bool) {
+	if value {
+		w.field(id, thriftTrue)
+	} else {
+		w.field(id, thriftFalse)
+	}
+}
+
+// i32Field writes a 32-bit integer field.
+func (w *thriftWriter) i32Field(id int16, value int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(value))
+}
+
+// i64Field writes a 64-bit integer field.
+func (w *thriftWriter) i64Field(id int16, value int64) {
+	w.field(id, thriftI64)
+	w.varint(value)
+}
+
+// binaryField writes a string field.
+func (w *thriftWriter) binaryField(id int16, value string) {
+	w.field(id, thriftBinary)
+	w.binary(value)
+}
+
+// listField writes the header of a list field of n elements of the given type. The elements follow.
+func (w *thriftWriter) listField(id int16, kind byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|kind)
+	} else {
+		w.buf = append(w.buf, 0xf0|kind)
+		w.buf = binary.AppendUvarint(w.buf, uint64(n))
+	}
+}
+
+// varint writes a zigzag-encoded integer, the encoding of every integer type.
+func (w *thriftWriter) varint(value int64) {
+	w.buf = binary.AppendVarint(w.buf, value)
+}
+
+// binary writes a length-prefixed string.
+func (w *thriftWriter) binary(value string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(value)))
+	w.buf = append(w.buf, value...)
+}
+
+// thriftFields holds the fields of a decoded structure by ID. Values are bool, int64, float64,
+// []byte, []interface{} or thriftFields.
+type thriftFields map[int16]interface{}
+
+// i64 returns the value of an integer field.
+func (f thriftFields) i64(id int16) (int64, bool) {
+	v, ok := f[id].(int64)
+	return v, ok
+}
+
+// str returns the value of a string field.
+func (f thriftFields) str(id int16) string {
+	v, _ := f[id].([]byte)
+	return string(v)
+}
+
+// boolean returns the value of a boolean field.
+func (f thriftFields) boolean(id int16) bool {
+	v, _ := f[id].(bool)
+	return v
+}
+
+// list returns the elements of a list field.
+func (f thriftFields) list(id int16) []interface{} {
+	v, _ := f[id].([]interface{})
+	return v
+}
+
+// fields returns the value of a structure field, nil if missing.
+func (f thriftFields) fields(id int16) thriftFields {
+	v, _ := f[id].(thriftFields)
+	return v
+}
+
+// thriftReader decodes structures encoded with the Thrift compact protocol.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+// readStruct decodes a structure.
+func (r *thriftReader) readStruct(depth int) (thriftFields, error) {
+	if depth > thriftMaxDepth {
+		return nil, fmt.Errorf("thrift structure nested too deeply")
+	}
+
+	fields := make(thriftFields)
+	var last int16
+	for {
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if header == 0 {
+			return fields, nil
+		}
+
+		kind := header & 0x0f
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			v, err := r.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		last = id
+
+		switch kind {
+		case thriftTrue:
+			fields[id] = true
+		case thriftFalse:
+			fields[id] = false
+		default:
+			value, err := r.readValue(kind, depth)
+			if err != nil {
+				return nil, err
+			}
+			fields[id] = value
+		}
+	}
+}
+
+// readValue decodes a value of the given type.
+func (r *thriftReader) readValue(kind byte, depth int) (interface{}, error) {
+	switch kind {
+	case thriftTrue, thriftFalse:
+		b, err := r.readByte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := r.readByte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return r.readVarint()
+	case thriftDouble:
+		if r.pos+8 > len(r.data) {
+			return nil, fmt.Errorf("truncated thrift double")
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.data[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case thriftBinary:
+		n, err := r.readLength()
+		if err != nil {
+			return nil, err
+		}
+		v := r.data[r.pos : r.pos+n]
+		r.pos += n
+		return v, nil
+	case thriftList, thriftSet:
+		header, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		n := int(header >> 4)
+		if n == 15 {
+			if n, err = r.readLength(); err != nil {
+				return nil, err
+			}
+		}
+		elements := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := r.readValue(header&0x0f, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, v)
+		}
+		return elements, nil
+	case thriftMap:
+		n, err := r.readLength()
+		if err != nil || n == 0 {
+			return nil, err
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			if _, err := r.readValue(types>>4, depth+1); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(types&0x0f, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStruct:
+		return r.readStruct(depth + 1)
+	default:
+		return nil, fmt.Errorf("unknown thrift type %d", kind)
+	}
+}
+
+// readByte reads a single byte.
+func (r *thriftReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, fmt.Errorf("truncated thrift data")
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+// readVarint reads a zigzag-encoded integer.
+func (r *thriftReader) readVarint() (int64, error) {
+	v, n := binary.Varint(r.data[r.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid thrift varint")
+	}
+	r.pos += n
+	return v, nil
+}
+
+// readLength reads the length of a string or a collection, checking it against the remaining data.
+func (r *thriftReader) readLength() (int, error) {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 || v > uint64(len(r.data)-r.pos-n) {
+		return 0, fmt.Errorf("invalid thrift length")
+	}
+	r.pos += n
+	return int(v), nil
+}
@@ -0,0 +1,34 @@
+package mframe
+
+import (
+	"slices"
+	"sync"
+)
+
+// timeLayouts is the registry of the layouts tried when parsing timestamp strings, after RFC 3339.
+var timeLayouts struct {
+	mutex   sync.RWMutex
+	layouts []string
+}
+
+// RegisterTimeLayout adds layouts, in the format of time.Parse, to the layouts tried in registration
+// order after RFC 3339 whenever a timestamp string is parsed: by time coercion and when loading rows from
+// JSON. Empty and already registered layouts are ignored. It is safe for concurrent use.
+func RegisterTimeLayout(layouts ...string) {
+	timeLayouts.mutex.Lock()
+	defer timeLayouts.mutex.Unlock()
+
+	for _, layout := range layouts {
+		if layout == "" || slices.Contains(timeLayouts.layouts, layout) {
+			continue
+		}
+		timeLayouts.layouts = append(timeLayouts.layouts, layout)
+	}
+}
+
+// TimeLayouts returns the registered time layouts in registration order.
+func TimeLayouts() []string {
+	timeLayouts.mutex.RLock()
+	defer timeLayouts.mutex.RUnlock()
+	return slices.Clone(timeLayouts.layouts)
+}
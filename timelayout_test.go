@@ -0,0 +1,35 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestRegisterTimeLayout(t *testing.T) {
+	const layout = "02/Jan/2006:15:04:05 -0700"
+	mframe.RegisterTimeLayout(layout, "", layout)
+
+	count := 0
+	for _, registered := range mframe.TimeLayouts() {
+		if registered == layout {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected the layout to be registered once, but got %d", count)
+	}
+
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetCoercion(mframe.CoercionOptions{DetectTimes: true})
+
+	id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"timestamp": "10/Oct/2024:13:55:36 +0200"})
+	row, _ := cache.Get(id)
+
+	expected := time.Date(2024, 10, 10, 11, 55, 36, 0, time.UTC)
+	if value, ok := row["timestamp"].(time.Time); !ok || !value.Equal(expected) {
+		t.Errorf("expected %v, but got %v", expected, row["timestamp"])
+	}
+}
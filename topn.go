@@ -0,0 +1,99 @@
+package mframe
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// rankedID pairs a row ID with the numeric value used to rank it.
+type rankedID struct {
+	id    uuid.UUID
+	value float64
+}
+
+// rankHeap is a bounded heap of rankedID. When highest is true the heap keeps
+// the N highest values (its root is the smallest retained value), otherwise
+// it keeps the N lowest values (its root is the largest retained value).
+type rankHeap struct {
+	items   []rankedID
+	highest bool
+}
+
+func (h *rankHeap) Len() int { return len(h.items) }
+
+func (h *rankHeap) Less(i, j int) bool {
+	if h.highest {
+		return h.items[i].value < h.items[j].value
+	}
+	return h.items[i].value > h.items[j].value
+}
+
+func (h *rankHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *rankHeap) Push(x any) { h.items = append(h.items, x.(rankedID)) }
+
+func (h *rankHeap) Pop() any {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	h.items = old[:n-1]
+	return item
+}
+
+// TopN returns copies of the n rows with the highest numeric values in the specified field,
+// ordered from highest to lowest.
+func (d *DataFrame) TopN(field KeyName, n int) []Row {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return d.rankUnlocked(field, n, true)
+}
+
+// BottomN returns copies of the n rows with the lowest numeric values in the specified field,
+// ordered from lowest to highest.
+func (d *DataFrame) BottomN(field KeyName, n int) []Row {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	return d.rankUnlocked(field, n, false)
+}
+
+// rankUnlocked selects the n best ranked rows for the field using a bounded heap
+// over the numeric index, so only O(total * log n) work is done instead of a full sort.
+func (d *DataFrame) rankUnlocked(field KeyName, n int, highest bool) []Row {
+	if n <= 0 {
+		return []Row{}
+	}
+
+	h := &rankHeap{items: make([]rankedID, 0, min(n, len(d.Data))), highest: highest}
+
+	for value, ids := range d.Numerics[field] {
+		for id := range ids {
+			if h.Len() < n {
+				heap.Push(h, rankedID{id: id, value: value})
+				continue
+			}
+
+			root := h.items[0].value
+			if (highest && value > root) || (!highest && value < root) {
+				h.items[0] = rankedID{id: id, value: value}
+				heap.Fix(h, 0)
+			}
+		}
+	}
+
+	ranked := h.items
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if highest {
+			return ranked[i].value > ranked[j].value
+		}
+		return ranked[i].value < ranked[j].value
+	})
+
+	result := make([]Row, 0, len(ranked))
+	for _, r := range ranked {
+		result = append(result, d.copyRowUnlocked(d.Data[r.id]))
+	}
+
+	return result
+}
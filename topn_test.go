@@ -0,0 +1,73 @@
+package mframe_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestTopNAndBottomN(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	for i := 1; i <= 20; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"id": i, "score": float64(i * 10)})
+	}
+	cache.Insert(map[mframe.KeyName]interface{}{"id": 21, "name": "no score"})
+
+	top := cache.TopN("score", 3)
+	if len(top) != 3 {
+		t.Fatalf("expected 3 rows, but got %d", len(top))
+	}
+	for i, expected := range []float64{200, 190, 180} {
+		if top[i]["score"] != expected {
+			t.Errorf("expected top[%d] score %v, but got %v", i, expected, top[i]["score"])
+		}
+	}
+
+	bottom := cache.BottomN("score", 2)
+	if len(bottom) != 2 {
+		t.Fatalf("expected 2 rows, but got %d", len(bottom))
+	}
+	for i, expected := range []float64{10, 20} {
+		if bottom[i]["score"] != expected {
+			t.Errorf("expected bottom[%d] score %v, but got %v", i, expected, bottom[i]["score"])
+		}
+	}
+
+	if all := cache.TopN("score", 100); len(all) != 20 {
+		t.Errorf("expected 20 rows when n exceeds the row count, but got %d", len(all))
+	}
+
+	if none := cache.TopN("missing", 5); len(none) != 0 {
+		t.Errorf("expected no rows for a missing field, but got %d", len(none))
+	}
+
+	if none := cache.BottomN("score", 0); len(none) != 0 {
+		t.Errorf("expected no rows for n=0, but got %d", len(none))
+	}
+}
+
+func TestTopNReturnsCopies(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"score": 10, "user": "alice"})
+	cache.Insert(map[mframe.KeyName]interface{}{"score": 20, "user": "bob"})
+
+	// A huge n must not be used to size the result.
+	top := cache.TopN("score", math.MaxInt)
+	if len(top) != 2 {
+		t.Fatalf("expected 2 rows, but got %d", len(top))
+	}
+
+	top[0]["user"] = "mallory"
+	if rows := cache.Filter(mframe.Equals, "score", 20.0, nil).ToSlice(); len(rows) != 1 || rows[0]["user"] != "bob" {
+		t.Errorf("expected the frame to be unchanged, but got %v", rows)
+	}
+	if rows := cache.BottomN("score", 1); len(rows) != 1 || rows[0]["user"] != "alice" {
+		t.Errorf("expected alice at the bottom, but got %v", rows)
+	}
+}
@@ -1,6 +1,7 @@
 package mframe
 
 // ToSlice converts the DataFrame into a slice of Row, preserving the order of rows in the DataFrame.
+// The rows are references to the internal rows, like RowsRef; use Rows to get copies.
 func (d *DataFrame) ToSlice() []Row {
 	d.Locker.RLock()
 	defer d.Locker.RUnlock()
@@ -13,6 +14,27 @@ func (d *DataFrame) ToSlice() []Row {
 	return result
 }
 
+// Rows returns copies of the rows of the DataFrame, with the defaults declared with SetDefault.
+// Callers may freely modify them without affecting the indexed data.
+func (d *DataFrame) Rows() []Row {
+	d.Locker.RLock()
+	defer d.Locker.RUnlock()
+	var result = make([]Row, 0, len(d.Data))
+
+	for _, row := range d.Data {
+		result = append(result, d.copyRowUnlocked(row))
+	}
+
+	return result
+}
+
+// RowsRef returns the rows of the DataFrame without copying them. The rows are references to the
+// internal rows and must be treated as read-only: modifying them corrupts the indexes, and reading
+// them while the DataFrame is being updated is a data race. Use Rows when in doubt.
+func (d *DataFrame) RowsRef() []Row {
+	return d.ToSlice()
+}
+
 // SliceOf returns a slice of interface values corresponding to the specified field
 // (KeyName) from the DataFrame's data rows.
 func (d *DataFrame) SliceOf(field KeyName) []interface{} {
@@ -25,7 +47,7 @@ func (d *DataFrame) SliceOf(field KeyName) []interface{} {
 func (d *DataFrame) sliceOfUnlocked(field KeyName) []interface{} {
 	var list []interface{}
 	for _, v := range d.Data {
-		value, ok := v[field]
+		value, ok := d.fieldUnlocked(v, field)
 		if !ok {
 			continue
 		}
@@ -59,3 +81,65 @@ func (d *DataFrame) sliceOfFloat64Unlocked(field KeyName) []float64 {
 
 	return fList
 }
+
+// copyRow returns a shallow copy of the row. Rows hold flattened scalar values,
+// so a shallow copy is independent of the original.
+func copyRow(row Row) Row {
+	result := make(Row, len(row))
+	for k, v := range row {
+		result[k] = v
+	}
+	return result
+}
+
+// pairsOfFloat64Unlocked returns aligned slices with the float64 values of two fields,
+// taking only the rows where both fields hold a float64, without acquiring locks.
+func (d *DataFrame) pairsOfFloat64Unlocked(fieldA, fieldB KeyName) ([]float64, []float64) {
+	var listA, listB []float64
+	for _, row := range d.Data {
+		value, _ := d.fieldUnlocked(row, fieldA)
+		a, ok := value.(float64)
+		if !ok {
+			continue
+		}
+		value, _ = d.fieldUnlocked(row, fieldB)
+		b, ok := value.(float64)
+		if !ok {
+			continue
+		}
+		listA = append(listA, a)
+		listB = append(listB, b)
+	}
+
+	return listA, listB
+}
+
+// sliceOfFloat64WithCoverageUnlocked extracts float64 values like sliceOfFloat64Unlocked,
+// also reporting how many rows were skipped, without acquiring locks.
+func (d *DataFrame) sliceOfFloat64WithCoverageUnlocked(field KeyName) ([]float64, Coverage) {
+	var coverage Coverage
+	list := make([]float64, 0)
+
+	for id, row := range d.Data {
+		value, ok := d.fieldUnlocked(row, field)
+		if !ok {
+			if d.mismatched[field][id] {
+				coverage.NonNumeric++
+			} else {
+				coverage.Missing++
+			}
+			continue
+		}
+
+		v, ok := value.(float64)
+		if !ok {
+			coverage.NonNumeric++
+			continue
+		}
+
+		coverage.Used++
+		list = append(list, v)
+	}
+
+	return list, coverage
+}
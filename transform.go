@@ -0,0 +1,286 @@
+package mframe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Apply replaces every value of the specified field with the value returned by fn, keeping the indexes
+// consistent, e.g. to lowercase or convert units in place. Returning nil removes the field from the row.
+// Rows without the field are not passed to fn. Returns the number of modified rows.
+func (d *DataFrame) Apply(field KeyName, fn func(v interface{}) interface{}) int {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	modified := 0
+	for id, row := range d.Data {
+		value, ok := row[field]
+		if !ok {
+			continue
+		}
+
+		newValue := fn(value)
+		if reflect.DeepEqual(newValue, value) {
+			continue
+		}
+
+		data := copyRow(row)
+		if newValue == nil {
+			delete(data, field)
+		} else {
+			data[field] = newValue
+		}
+
+		d.reindexRowUnlocked(id, data)
+		modified++
+	}
+
+	return modified
+}
+
+// MapRows replaces every row with the row returned by fn, keeping the indexes consistent. fn receives
+// a copy of the row, so it may modify and return it. Returning nil leaves the row unchanged.
+// Rows keep their IDs and expiration. Returns the number of modified rows.
+func (d *DataFrame) MapRows(fn func(Row) Row) int {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	modified := 0
+	for id, row := range d.Data {
+		newRow := fn(copyRow(row))
+		if newRow == nil || reflect.DeepEqual(newRow, row) {
+			continue
+		}
+
+		d.reindexRowUnlocked(id, newRow)
+		modified++
+	}
+
+	return modified
+}
+
+// UpdateWhere sets the keys in changes on every row matching spec atomically under the write lock,
+// reindexing only the changed keys. A nil value removes the key from the rows, and nested values are
+// flattened like on insert. Rows keep their IDs and expiration. Returns the number of modified rows.
+// No row is modified if changes is empty or would change the type of a key still held by rows
+// that do not match spec.
+func (d *DataFrame) UpdateWhere(spec FilterSpec, changes map[KeyName]interface{}) (int, error) {
+	if len(changes) == 0 {
+		return 0, fmt.Errorf("no changes to apply")
+	}
+
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	return d.updateIDsUnlocked(d.specIDsUnlocked(spec), changes)
+}
+
+// updateIDsUnlocked applies changes to the rows with the given IDs like UpdateWhere, without acquiring locks.
+func (d *DataFrame) updateIDsUnlocked(ids map[uuid.UUID]struct{}, changes map[KeyName]interface{}) (int, error) {
+	var scratch DataFrame
+	scratch.Init(d.TTL)
+	scratch.entropyKeys = d.entropyKeys
+	flat := make(Row)
+	scratch.index(changes, "", uuid.Nil, &flat)
+
+	removals := make([]KeyName, 0)
+	for k, v := range changes {
+		if v == nil {
+			removals = append(removals, k)
+		}
+	}
+
+	retyped := make([]KeyName, 0)
+	for key, keyType := range scratch.Keys {
+		current, ok := d.Keys[key]
+		if !ok || current == keyType {
+			continue
+		}
+		if d.holdersUnlocked(key) > d.matchedHoldersUnlocked(key, ids) {
+			return 0, fmt.Errorf("cannot set key '%s' as '%v' because other rows hold it as '%v'", key, keyType, current)
+		}
+		retyped = append(retyped, key)
+	}
+
+	modified := make(map[uuid.UUID]bool)
+	if len(retyped) > 0 {
+		// Keys changing type are removed from every row first, so the key is free to be mapped to the new type.
+		for id := range ids {
+			if d.updateFieldsUnlocked(id, nil, retyped) {
+				modified[id] = true
+			}
+		}
+	}
+	for id := range ids {
+		if d.updateFieldsUnlocked(id, flat, removals) {
+			modified[id] = true
+		}
+	}
+
+	return len(modified), nil
+}
+
+// TransformWhere replaces every row matching spec with the row returned by fn under a single lock,
+// keeping the indexes consistent, e.g. to fix a mis-parsed field across many rows. fn receives a copy
+// of the row, so it may modify and return it. Returning nil leaves the row unchanged.
+// Rows keep their IDs and expiration. Returns the number of modified rows.
+func (d *DataFrame) TransformWhere(spec FilterSpec, fn func(Row) Row) (int, error) {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	modified := 0
+	for id := range d.specIDsUnlocked(spec) {
+		row := d.Data[id]
+		newRow := fn(copyRow(row))
+		if newRow == nil || reflect.DeepEqual(newRow, row) {
+			continue
+		}
+
+		d.reindexRowUnlocked(id, newRow)
+		modified++
+	}
+
+	return modified, nil
+}
+
+// updateFieldsUnlocked sets the flattened values and removes the keys of a row, reindexing only the
+// affected keys, without acquiring locks. Returns whether the row changed.
+func (d *DataFrame) updateFieldsUnlocked(id uuid.UUID, flat Row, removals []KeyName) bool {
+	before := d.Data[id]
+	row := copyRow(before)
+	changed := false
+
+	for _, key := range removals {
+		if old, ok := row[key]; ok {
+			d.unindexFieldUnlocked(id, key, old)
+			delete(row, key)
+			changed = true
+		}
+	}
+
+	for _, key := range removals {
+		d.forgetMismatchUnlocked(key, id)
+	}
+
+	for key, value := range flat {
+		old, ok := row[key]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		d.forgetMismatchUnlocked(key, id)
+		if ok {
+			d.unindexFieldUnlocked(id, key, old)
+		}
+		if d.entropyKeys[key] {
+			if derived, ok := row[key+EntropySuffix]; ok {
+				d.unindexFieldUnlocked(id, key+EntropySuffix, derived)
+			}
+		}
+		d.index(map[KeyName]interface{}{key: value}, "", id, &row)
+		changed = true
+	}
+
+	d.Data[id] = row
+	if changed {
+		d.recency.touch(id)
+		d.markChangedUnlocked(id)
+		d.publishUnlocked(ChangeUpdate, id, before, row)
+	}
+
+	return changed
+}
+
+// holdersUnlocked returns the number of rows holding the key, without acquiring locks.
+func (d *DataFrame) holdersUnlocked(key KeyName) int {
+	count := 0
+	for _, row := range d.Data {
+		if _, ok := row[key]; ok {
+			count++
+		}
+	}
+	return count
+}
+
+// matchedHoldersUnlocked returns the number of the given rows holding the key, without acquiring locks.
+func (d *DataFrame) matchedHoldersUnlocked(key KeyName, ids map[uuid.UUID]struct{}) int {
+	count := 0
+	for id := range ids {
+		if _, ok := d.Data[id][key]; ok {
+			count++
+		}
+	}
+	return count
+}
+
+// reindexRowUnlocked replaces the content of an existing row with data, removing the index entries of
+// the old values and indexing the new ones, without acquiring locks. The ID and expiration are kept.
+// Derived entropy keys are recomputed from their source keys.
+func (d *DataFrame) reindexRowUnlocked(id uuid.UUID, data map[KeyName]interface{}) {
+	before := d.Data[id]
+	d.unindexRowUnlocked(id)
+	d.forgetMismatchesUnlocked(id)
+
+	clean := make(map[KeyName]interface{}, len(data))
+	for k, v := range data {
+		if source, derived := strings.CutSuffix(string(k), EntropySuffix); derived && d.entropyKeys[KeyName(source)] {
+			continue
+		}
+		clean[k] = v
+	}
+
+	var row = make(Row)
+	d.index(clean, "", id, &row)
+	d.Data[id] = row
+	d.recency.touch(id)
+	d.markChangedUnlocked(id)
+	d.publishUnlocked(ChangeUpdate, id, before, row)
+}
+
+// unindexRowUnlocked removes the index entries of the values of a row without removing the row itself
+// and without acquiring locks. Keys left without values are removed.
+func (d *DataFrame) unindexRowUnlocked(id uuid.UUID) {
+	for key, value := range d.Data[id] {
+		d.unindexFieldUnlocked(id, key, value)
+	}
+}
+
+// unindexFieldUnlocked removes the index entry of a single value of a row without acquiring locks.
+func (d *DataFrame) unindexFieldUnlocked(id uuid.UUID, key KeyName, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		unindexValue(d, d.Strings, key, v, id)
+	case float64:
+		unindexValue(d, d.Numerics, key, v, id)
+	case bool:
+		unindexValue(d, d.Booleans, key, v, id)
+	case time.Time:
+		unindexValue(d, d.Times, key, v, id)
+	}
+}
+
+// unindexValue removes the ID from the entry of the value in a typed index, cleaning up the value
+// and the key once they are empty.
+func unindexValue[T comparable](d *DataFrame, index map[KeyName]map[T]map[uuid.UUID]bool, key KeyName, value T, id uuid.UUID) {
+	ids, ok := index[key][value]
+	if !ok {
+		return
+	}
+
+	delete(ids, id)
+	d.onRowValueGone(key, value, id)
+	if len(ids) > 0 {
+		return
+	}
+
+	delete(index[key], value)
+	d.onValueGone(key, value)
+
+	if len(index[key]) == 0 {
+		delete(index, key)
+		delete(d.Keys, key)
+	}
+}
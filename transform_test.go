@@ -0,0 +1,211 @@
+package mframe_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestApply(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"host": "WEB-01", "bytes": 2048},
+		{"host": "web-01", "bytes": 1024},
+		{"host": "DB-01", "bytes": 4096},
+		{"bytes": 512},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+	ids := make(map[string]time.Time)
+	for id, expireAt := range cache.ExpireAt {
+		ids[id.String()] = expireAt
+	}
+
+	modified := cache.Apply("host", func(v interface{}) interface{} {
+		return strings.ToLower(v.(string))
+	})
+	if modified != 2 {
+		t.Errorf("expected 2 modified rows, but got %d", modified)
+	}
+	if count := cache.CountWhere(mframe.Equals, "host", "web-01", nil); count != 2 {
+		t.Errorf("expected 2 rows for web-01, but got %d", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "host", "WEB-01", nil); count != 0 {
+		t.Errorf("expected the old value to be removed from the index, but got %d rows", count)
+	}
+
+	cache.Apply("bytes", func(v interface{}) interface{} { return v.(float64) / 1024 })
+	if count := cache.CountWhere(mframe.Equals, "bytes", 4.0, nil); count != 1 {
+		t.Errorf("expected 1 row with 4 kilobytes, but got %d", count)
+	}
+
+	for id, expireAt := range cache.ExpireAt {
+		if !ids[id.String()].Equal(expireAt) {
+			t.Errorf("expected row %s to keep its ID and expiration", id)
+		}
+	}
+
+	cache.Apply("host", func(v interface{}) interface{} { return nil })
+	if _, ok := cache.Keys["host"]; ok {
+		t.Error("expected the key to be removed once no row holds it")
+	}
+}
+
+func TestMapRows(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetEntropyKeys("domain")
+
+	cache.Insert(map[mframe.KeyName]interface{}{"domain": "aaaa", "ms": 1500})
+	cache.Insert(map[mframe.KeyName]interface{}{"domain": "abcd", "ms": 500})
+
+	modified := cache.MapRows(func(row mframe.Row) mframe.Row {
+		if row["ms"].(float64) < 1000 {
+			return nil
+		}
+		row["seconds"] = row["ms"].(float64) / 1000
+		delete(row, "ms")
+		row["domain"] = "abab"
+		return row
+	})
+	if modified != 1 {
+		t.Errorf("expected 1 modified row, but got %d", modified)
+	}
+
+	if count := cache.CountWhere(mframe.Equals, "seconds", 1.5, nil); count != 1 {
+		t.Errorf("expected 1 row with 1.5 seconds, but got %d", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "ms", 1500.0, nil); count != 0 {
+		t.Errorf("expected the removed key to leave the index, but got %d rows", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "domain"+mframe.EntropySuffix, 1.0, nil); count != 1 {
+		t.Errorf("expected the derived entropy to be recomputed, but got %d rows", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "domain"+mframe.EntropySuffix, 0.0, nil); count != 0 {
+		t.Errorf("expected the old derived entropy to leave the index, but got %d rows", count)
+	}
+}
+
+func TestUpdateWhere(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	kvs := []map[mframe.KeyName]interface{}{
+		{"host": "a", "severity": "high", "status": "open"},
+		{"host": "b", "severity": "high", "status": "open"},
+		{"host": "c", "severity": "low", "status": "open"},
+	}
+
+	for _, v := range kvs {
+		cache.Insert(v)
+	}
+
+	high := mframe.FilterSpec{Conditions: []mframe.Condition{{Operator: mframe.Equals, Key: "severity", Value: "high"}}}
+
+	modified, err := cache.UpdateWhere(high, map[mframe.KeyName]interface{}{"status": "escalated", "owner": "soc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if modified != 2 {
+		t.Errorf("expected 2 modified rows, but got %d", modified)
+	}
+	if count := cache.CountWhere(mframe.Equals, "status", "escalated", nil); count != 2 {
+		t.Errorf("expected 2 escalated rows, but got %d", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "status", "open", nil); count != 1 {
+		t.Errorf("expected 1 open row, but got %d", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "owner", "soc", nil); count != 2 {
+		t.Errorf("expected 2 rows owned by soc, but got %d", count)
+	}
+
+	if _, err := cache.UpdateWhere(high, map[mframe.KeyName]interface{}{"owner": nil}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.Keys["owner"]; ok {
+		t.Error("expected nil changes to remove the key")
+	}
+
+	if _, err := cache.UpdateWhere(high, nil); err == nil {
+		t.Error("expected error for empty changes")
+	}
+}
+
+func TestTransformWhere(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "WEB-01", "source": "syslog"})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "DB-01", "source": "agent"})
+
+	modified, err := cache.TransformWhere(
+		mframe.FilterSpec{Conditions: []mframe.Condition{{Operator: mframe.Equals, Key: "source", Value: "syslog"}}},
+		func(row mframe.Row) mframe.Row {
+			row["host"] = strings.ToLower(row["host"].(string))
+			return row
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if modified != 1 {
+		t.Errorf("expected 1 modified row, but got %d", modified)
+	}
+	if cache.CountWhere(mframe.Equals, "host", "web-01", nil) != 1 || cache.CountWhere(mframe.Equals, "host", "DB-01", nil) != 1 {
+		t.Error("expected only the matching row to be transformed")
+	}
+}
+
+func TestUpdateWhereAtomic(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "a", "port": "22"})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "b", "port": "443"})
+	cache.Insert(map[mframe.KeyName]interface{}{"host": "c", "port": "80"})
+
+	expireAt := make(map[string]time.Time)
+	for id, row := range cache.Data {
+		expireAt[row["host"].(string)] = cache.ExpireAt[id]
+	}
+
+	hostA := mframe.FilterSpec{Conditions: []mframe.Condition{{Operator: mframe.Equals, Key: "host", Value: "a"}}}
+	if _, err := cache.UpdateWhere(hostA, map[mframe.KeyName]interface{}{"port": 22, "status": "fixed"}); err == nil {
+		t.Fatal("expected error when changing the type of a key held by other rows")
+	}
+	if _, ok := cache.Keys["status"]; ok {
+		t.Error("expected no change to be applied when the update is rejected")
+	}
+
+	modified, err := cache.UpdateWhere(mframe.FilterSpec{}, map[mframe.KeyName]interface{}{
+		"port": 0,
+		"geo":  map[string]interface{}{"country": "US"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if modified != 3 {
+		t.Errorf("expected 3 modified rows, but got %d", modified)
+	}
+	if cache.Keys["port"] != mframe.Numeric {
+		t.Errorf("expected port to be numeric, but got %v", cache.Keys["port"])
+	}
+	if count := cache.CountWhere(mframe.Equals, "port", 0.0, nil); count != 3 {
+		t.Errorf("expected 3 rows with port 0, but got %d", count)
+	}
+	if count := cache.CountWhere(mframe.Equals, "geo.country", "US", nil); count != 3 {
+		t.Errorf("expected nested changes to be flattened, but got %d rows", count)
+	}
+
+	for id, row := range cache.Data {
+		if !cache.ExpireAt[id].Equal(expireAt[row["host"].(string)]) {
+			t.Errorf("expected %v to keep its expiration", row["host"])
+		}
+	}
+}
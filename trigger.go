@@ -0,0 +1,142 @@
+package mframe
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// TriggerAction is called with a copy of every inserted row matching the filter of a trigger.
+type TriggerAction func(row Row)
+
+// trigger is a registered filter and the action run for the inserted rows matching it.
+type trigger struct {
+	id      int
+	matcher *rowMatcher
+	action  TriggerAction
+}
+
+// triggerRun is an action waiting to be run once the lock is released.
+type triggerRun struct {
+	action TriggerAction
+	row    Row
+}
+
+// triggers holds the triggers of a DataFrame and the runs pending delivery, under the lock of the DataFrame.
+type triggers struct {
+	list    []*trigger
+	nextID  int
+	pending []triggerRun
+	pool    triggerPool
+}
+
+// triggerPool runs the actions of the triggers in worker goroutines when started by SetTriggerWorkers.
+type triggerPool struct {
+	mutex sync.RWMutex
+	runs  chan triggerRun
+	done  sync.WaitGroup
+}
+
+// AddTrigger registers an action called with a copy of every row inserted afterwards by Insert or InsertBatch,
+// or inserted or replaced by Upsert, that matches every condition of spec, turning the DataFrame into a
+// lightweight streaming rule engine.
+// Actions run after the lock is released, synchronously in the inserting goroutine unless SetTriggerWorkers
+// started a worker pool, so they may safely query the DataFrame. Returns the ID of the trigger for RemoveTrigger.
+func (d *DataFrame) AddTrigger(spec FilterSpec, action TriggerAction) (int, error) {
+	if action == nil {
+		return 0, fmt.Errorf("trigger action cannot be nil")
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.triggers.nextID++
+	d.triggers.list = append(d.triggers.list, &trigger{
+		id:      d.triggers.nextID,
+		matcher: newRowMatcher(spec),
+		action:  action,
+	})
+
+	return d.triggers.nextID, nil
+}
+
+// RemoveTrigger unregisters the trigger with the given ID and reports whether it existed.
+func (d *DataFrame) RemoveTrigger(id int) bool {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	for i, t := range d.triggers.list {
+		if t.id == id {
+			d.triggers.list = append(d.triggers.list[:i], d.triggers.list[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetTriggerWorkers runs the actions of the triggers in a pool of workers goroutines fed by a queue of the given
+// size, instead of in the inserting goroutine. Inserts block while the queue is full. Zero workers stops the pool,
+// waiting for the queued actions to run, and restores synchronous actions. It must not be called from an action.
+func (d *DataFrame) SetTriggerWorkers(workers, queue int) {
+	pool := &d.triggers.pool
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if pool.runs != nil {
+		close(pool.runs)
+		pool.done.Wait()
+		pool.runs = nil
+	}
+
+	if workers <= 0 {
+		return
+	}
+
+	if queue < 0 {
+		queue = 0
+	}
+
+	pool.runs = make(chan triggerRun, queue)
+	for i := 0; i < workers; i++ {
+		pool.done.Add(1)
+		go func(runs <-chan triggerRun) {
+			defer pool.done.Done()
+			for run := range runs {
+				run.action(run.row)
+			}
+		}(pool.runs)
+	}
+}
+
+// matchTriggersUnlocked queues the actions of the triggers matching an inserted or replaced row, without acquiring locks.
+// The DataFrame must be write-locked.
+func (d *DataFrame) matchTriggersUnlocked(id uuid.UUID, row Row) {
+	for _, t := range d.triggers.list {
+		if t.matcher.matches(id, row) {
+			d.triggers.pending = append(d.triggers.pending, triggerRun{action: t.action, row: copyRow(row)})
+		}
+	}
+}
+
+// runTriggers runs the actions of the matched triggers, in the worker pool if it is started.
+func (d *DataFrame) runTriggers(runs []triggerRun) {
+	if len(runs) == 0 {
+		return
+	}
+
+	pool := &d.triggers.pool
+
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+
+	for _, run := range runs {
+		if pool.runs != nil {
+			pool.runs <- run
+		} else {
+			run.action(run.row)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package mframe_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestAddTrigger(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	var fired []mframe.Row
+	id, err := cache.AddTrigger(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "action", Value: "login"},
+		{Operator: mframe.Equals, Key: "success", Value: false},
+	}}, func(row mframe.Row) {
+		if cache.Count() == 0 {
+			t.Error("expected the action to query the frame")
+		}
+		fired = append(fired, row)
+	})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"action": "login", "success": false, "user": "alice"})
+	cache.Insert(map[mframe.KeyName]interface{}{"action": "login", "success": true, "user": "bob"})
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"action": "login", "success": false, "user": "carol"},
+		{"action": "logout", "success": false, "user": "dave"},
+	})
+
+	if len(fired) != 2 {
+		t.Fatalf("expected 2 fired actions, but got %d", len(fired))
+	}
+	if fired[0]["user"] != "alice" || fired[1]["user"] != "carol" {
+		t.Errorf("expected alice and carol, but got %v", fired)
+	}
+
+	if !cache.RemoveTrigger(id) || cache.RemoveTrigger(id) {
+		t.Error("expected the trigger to be removed once")
+	}
+	cache.Insert(map[mframe.KeyName]interface{}{"action": "login", "success": false})
+	if len(fired) != 2 {
+		t.Errorf("expected no actions after removal, but got %d", len(fired))
+	}
+
+	if _, err := cache.AddTrigger(mframe.FilterSpec{}, nil); err == nil {
+		t.Error("expected an error for a nil action")
+	}
+}
+
+func TestTriggerOnUpsert(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	var fired []mframe.Row
+	_, err := cache.AddTrigger(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "status", Value: "down"},
+	}}, func(row mframe.Row) {
+		fired = append(fired, row)
+	})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	cache.Upsert("sensor", map[mframe.KeyName]interface{}{"sensor": "s1", "status": "up"})
+	if len(fired) != 0 {
+		t.Fatalf("expected no fired actions, but got %d", len(fired))
+	}
+
+	cache.Upsert("sensor", map[mframe.KeyName]interface{}{"sensor": "s1", "status": "down"})
+	if len(fired) != 1 || fired[0]["sensor"] != "s1" {
+		t.Fatalf("expected the replaced row to fire, but got %v", fired)
+	}
+
+	cache.Upsert("sensor", map[mframe.KeyName]interface{}{"sensor": "s2", "status": "down"})
+	if len(fired) != 2 || fired[1]["sensor"] != "s2" {
+		t.Errorf("expected the inserted row to fire, but got %v", fired)
+	}
+}
+
+func TestSetTriggerWorkers(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	cache.SetTriggerWorkers(4, 16)
+
+	var mutex sync.Mutex
+	fired := 0
+	cache.AddTrigger(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Greater, Key: "bytes", Value: 1000.0},
+	}}, func(row mframe.Row) {
+		mutex.Lock()
+		fired++
+		mutex.Unlock()
+	})
+
+	for i := 0; i < 100; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"bytes": i * 20})
+	}
+
+	cache.SetTriggerWorkers(0, 0)
+
+	if fired != 49 {
+		t.Errorf("expected 49 fired actions, but got %d", fired)
+	}
+}
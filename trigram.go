@@ -0,0 +1,187 @@
+package mframe
+
+import (
+	"regexp/syntax"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// trigramIndex maps the trigrams of the lower case form of the values of a string key to the values.
+type trigramIndex map[string]map[string]bool
+
+// trigrams returns the distinct trigrams of the lower case form of s, in bytes.
+func trigrams(s string) map[string]bool {
+	s = strings.ToLower(s)
+	grams := make(map[string]bool, max(len(s)-2, 0))
+	for i := 0; i+3 <= len(s); i++ {
+		grams[s[i:i+3]] = true
+	}
+	return grams
+}
+
+// add indexes the trigrams of a value.
+func (t trigramIndex) add(value string) {
+	for gram := range trigrams(value) {
+		if t[gram] == nil {
+			t[gram] = make(map[string]bool)
+		}
+		t[gram][value] = true
+	}
+}
+
+// remove drops the trigrams of a value.
+func (t trigramIndex) remove(value string) {
+	for gram := range trigrams(value) {
+		delete(t[gram], value)
+		if len(t[gram]) == 0 {
+			delete(t, gram)
+		}
+	}
+}
+
+// candidates returns the values holding every trigram of every required substring, or false when the
+// substrings are too short to have trigrams.
+func (t trigramIndex) candidates(required []string) (map[string]bool, bool) {
+	var smallest map[string]bool
+	var grams []string
+	for _, s := range required {
+		for gram := range trigrams(s) {
+			if grams == nil || len(t[gram]) < len(smallest) {
+				smallest = t[gram]
+			}
+			grams = append(grams, gram)
+		}
+	}
+	if grams == nil {
+		return nil, false
+	}
+
+	result := make(map[string]bool, len(smallest))
+	for value := range smallest {
+		result[value] = true
+		for _, gram := range grams {
+			if !t[gram][value] {
+				delete(result, value)
+				break
+			}
+		}
+	}
+	return result, true
+}
+
+// matchTrigramUnlocked walks the rows of a string key matching Contains, NotContains or RegExp through
+// the trigram index of the key, calling visit like matchUnlocked. The candidate values sharing the trigrams
+// of the substrings the query requires are then checked with the query itself. Returns handled as false
+// when the key has no trigram index or the query requires no substring of at least three bytes, and
+// stopped as true when visit stopped the walk.
+func (d *DataFrame) matchTrigramUnlocked(poll *scanPoll, key KeyName, operator Operator, value string, options map[FilterOption]bool, visit func(id uuid.UUID) bool) (handled bool, stopped bool) {
+	index, ok := d.accel.trigrams[key]
+	if !ok || !utf8.ValidString(value) {
+		return false, false
+	}
+
+	var match func(string) bool
+	var required []string
+	switch operator {
+	case Contains, NotContains:
+		if sensitive, set := options[CaseSensitive]; set && !sensitive {
+			lower := strings.ToLower(value)
+			match = func(v string) bool { return ContainsF(strings.ToLower(v), lower) }
+		} else {
+			match = func(v string) bool { return ContainsF(v, value) }
+		}
+		required = []string{value}
+	case RegExp:
+		re, err := d.getCompiledRegex(value)
+		if err != nil {
+			return false, false
+		}
+		parsed, err := syntax.Parse(value, syntax.Perl)
+		if err != nil {
+			return false, false
+		}
+		match = re.MatchString
+		required = requiredLiterals(parsed.Simplify())
+	default:
+		return false, false
+	}
+
+	candidates, ok := index.candidates(required)
+	if !ok {
+		return false, false
+	}
+
+	matched := make(map[string]bool, len(candidates))
+	for v := range candidates {
+		if poll.interrupted() {
+			return true, true
+		}
+		if match(v) {
+			matched[v] = true
+		}
+	}
+
+	walk := func(v string) bool {
+		if poll.interrupted() {
+			return false
+		}
+		for id := range d.Strings[key][v] {
+			if !visit(id) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if operator == NotContains {
+		for v := range d.Strings[key] {
+			if !matched[v] && !walk(v) {
+				return true, true
+			}
+		}
+		return true, false
+	}
+
+	for v := range matched {
+		if !walk(v) {
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// requiredLiterals returns literal substrings every match of the regular expression contains.
+// It only looks through concatenations, captures and repetitions of at least one, so it may miss some.
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min >= 1 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var literals []string
+		var run []rune
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run = append(run, sub.Rune...)
+				continue
+			}
+			if run != nil {
+				literals = append(literals, string(run))
+				run = nil
+			}
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+		if run != nil {
+			literals = append(literals, string(run))
+		}
+		return literals
+	}
+	return nil
+}
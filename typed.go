@@ -0,0 +1,38 @@
+package mframe
+
+import "time"
+
+// FilterString filters the rows of a String key like Filter, with the value type checked at compile time.
+// It applies to the operators taking a single string: Equals, NotEquals, RegExp, NotRegExp, InCIDR,
+// NotInCIDR, Contains, NotContains, StartsWith, NotStartsWith, EndsWith and NotEndsWith.
+func (d *DataFrame) FilterString(key KeyName, operator Operator, value string) *DataFrame {
+	return d.Filter(operator, key, value, nil)
+}
+
+// FilterStrings filters the rows of a String key with InList or NotInList like Filter.
+func (d *DataFrame) FilterStrings(key KeyName, operator Operator, values []string) *DataFrame {
+	return d.Filter(operator, key, values, nil)
+}
+
+// FilterNumber filters the rows of a Numeric key like Filter, with the value type checked at compile time,
+// so integer constants such as 99 are passed as the float64 Filter requires. It applies to Equals,
+// NotEquals, Greater, Less, GreaterOrEqual and LessOrEqual.
+func (d *DataFrame) FilterNumber(key KeyName, operator Operator, value float64) *DataFrame {
+	return d.Filter(operator, key, value, nil)
+}
+
+// FilterNumbers filters the rows of a Numeric key with InList or NotInList like Filter.
+func (d *DataFrame) FilterNumbers(key KeyName, operator Operator, values []float64) *DataFrame {
+	return d.Filter(operator, key, values, nil)
+}
+
+// FilterBool filters the rows of a Boolean key with Equals or NotEquals like Filter.
+func (d *DataFrame) FilterBool(key KeyName, operator Operator, value bool) *DataFrame {
+	return d.Filter(operator, key, value, nil)
+}
+
+// FilterTime filters the rows of a Time key with Between or NotBetween like Filter, for the range from
+// from to to, both included.
+func (d *DataFrame) FilterTime(key KeyName, operator Operator, from, to time.Time) *DataFrame {
+	return d.Filter(operator, key, []time.Time{from, to}, nil)
+}
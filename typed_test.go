@@ -0,0 +1,44 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestTypedFilters(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{
+			"host":  []string{"web-1", "db-1"}[i%2],
+			"bytes": i * 10,
+			"up":    i < 3,
+			"seen":  base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+
+	tests := []struct {
+		name     string
+		result   *mframe.DataFrame
+		expected int
+	}{
+		{"string", cache.FilterString("host", mframe.StartsWith, "web"), 5},
+		{"strings", cache.FilterStrings("host", mframe.InList, []string{"db-1", "cache-1"}), 5},
+		{"integer constant", cache.FilterNumber("bytes", mframe.GreaterOrEqual, 70), 3},
+		{"numbers", cache.FilterNumbers("bytes", mframe.NotInList, []float64{0, 10}), 8},
+		{"bool", cache.FilterBool("up", mframe.Equals, true), 3},
+		{"time", cache.FilterTime("seen", mframe.Between, base.Add(2*time.Hour), base.Add(4*time.Hour)), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Count(); got != tt.expected {
+				t.Errorf("expected %d rows, but got %d", tt.expected, got)
+			}
+		})
+	}
+}
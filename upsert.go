@@ -0,0 +1,62 @@
+package mframe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upsert replaces the row having the same value for keyField as data, or inserts data as a new row when
+// there is none, so caches keyed by a natural key such as a sensor ID do not accumulate duplicates until
+// they expire. A replaced row keeps its ID and its expiration restarts from now. If several rows share
+// the value, one is replaced and the others are removed. Triggers are evaluated for the inserted or
+// replaced row. Returns the ID of the row.
+// Returns an error if data does not hold keyField, is rejected by a hook or breaks the schema.
+func (d *DataFrame) Upsert(keyField KeyName, data map[KeyName]interface{}) (uuid.UUID, error) {
+	if _, ok := data[keyField]; !ok {
+		return uuid.Nil, fmt.Errorf("data does not contain key '%s'", keyField)
+	}
+
+	data, err := d.runBeforeInsert(data)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	d.Locker.Lock()
+	if err := d.validateUnlocked(map[uuid.UUID]map[KeyName]interface{}{uuid.Nil: data}); err != nil {
+		d.Locker.Unlock()
+		return uuid.Nil, err
+	}
+
+	var id uuid.UUID
+	matches := make([]uuid.UUID, 0)
+	for match := range d.idsForValueUnlocked(keyField, data[keyField]) {
+		matches = append(matches, match)
+	}
+
+	if len(matches) == 0 {
+		id = d.insertUnlocked(data)
+	} else {
+		sortIDs(matches)
+		id = matches[0]
+		for _, duplicate := range matches[1:] {
+			d.evictElementUnlocked(duplicate, EvictRemoved)
+		}
+		d.reindexRowUnlocked(id, data)
+		d.setExpireAtUnlocked(id, time.Now().UTC().Add(d.TTL))
+		d.matchTriggersUnlocked(id, d.Data[id])
+	}
+
+	var row Row
+	if d.hooks.hasAfterInsert() {
+		row = copyRow(d.Data[id])
+	}
+	d.unlockAndNotify()
+
+	if row != nil {
+		d.runAfterInsert(id, row)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,65 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestUpsert(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	first, err := cache.Upsert("sensor_id", map[mframe.KeyName]interface{}{"sensor_id": "s1", "temp": 20, "unit": "C"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expireAt := cache.ExpireAt[first]
+
+	time.Sleep(time.Millisecond)
+
+	second, err := cache.Upsert("sensor_id", map[mframe.KeyName]interface{}{"sensor_id": "s1", "temp": 25})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second != first {
+		t.Errorf("expected the row to keep its ID %s, but got %s", first, second)
+	}
+	if cache.Count() != 1 {
+		t.Fatalf("expected 1 row, but got %d", cache.Count())
+	}
+	if !cache.ExpireAt[first].After(expireAt) {
+		t.Error("expected the expiration to restart")
+	}
+
+	row := cache.Data[first]
+	if row["temp"] != 25.0 {
+		t.Errorf("expected temp 25, but got %v", row["temp"])
+	}
+	if _, ok := row["unit"]; ok {
+		t.Error("expected the row to be replaced, not merged")
+	}
+	if count := cache.CountWhere(mframe.Equals, "temp", 20.0, nil); count != 0 {
+		t.Errorf("expected the old value to leave the index, but got %d rows", count)
+	}
+
+	if _, err := cache.Upsert("sensor_id", map[mframe.KeyName]interface{}{"sensor_id": "s2", "temp": 30}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cache.Count() != 2 {
+		t.Errorf("expected 2 rows, but got %d", cache.Count())
+	}
+
+	cache.Insert(map[mframe.KeyName]interface{}{"sensor_id": "s2", "temp": 31})
+	if _, err := cache.Upsert("sensor_id", map[mframe.KeyName]interface{}{"sensor_id": "s2", "temp": 32}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count := cache.CountWhere(mframe.Equals, "sensor_id", "s2", nil); count != 1 {
+		t.Errorf("expected duplicates to be removed, but got %d rows", count)
+	}
+
+	if _, err := cache.Upsert("sensor_id", map[mframe.KeyName]interface{}{"temp": 1}); err == nil {
+		t.Error("expected error when data lacks the key field")
+	}
+}
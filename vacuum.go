@@ -0,0 +1,114 @@
+package mframe
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ReindexAll rebuilds every index from the rows in Data, repairing a DataFrame whose indexes drifted from
+// its rows, e.g. after Data was modified directly. Values that no longer fit the type of their key are
+// dropped from the rows, as on insert. IDs, expirations and tags are kept, and acceleration structures
+// are rebuilt.
+func (d *DataFrame) ReindexAll() {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	previous := d.accel
+	d.accel = accelerators{}
+	d.Keys = make(KeysIndex)
+	d.Strings = make(StringsIndex)
+	d.Numerics = make(NumericsIndex)
+	d.Booleans = make(BooleansIndex)
+	d.Times = make(TimesIndex)
+
+	for id, data := range d.Data {
+		clean := make(map[KeyName]interface{}, len(data))
+		for k, v := range data {
+			if source, derived := strings.CutSuffix(string(k), EntropySuffix); derived && d.entropyKeys[KeyName(source)] {
+				continue
+			}
+			clean[k] = v
+		}
+
+		var row = make(Row)
+		d.index(clean, "", id, &row)
+		d.Data[id] = row
+	}
+
+	for key := range previous.sortedNumerics {
+		d.buildAcceleratorUnlocked(key, SortedNumericIndex)
+	}
+	for key := range previous.lowercase {
+		d.buildAcceleratorUnlocked(key, LowercaseIndex)
+	}
+	for key := range previous.sortedStrings {
+		d.buildAcceleratorUnlocked(key, PrefixIndex)
+	}
+	for key := range previous.sortedTimes {
+		d.buildAcceleratorUnlocked(key, SortedTimeIndex)
+	}
+	for key := range previous.trigrams {
+		d.buildAcceleratorUnlocked(key, TrigramIndex)
+	}
+	for _, composite := range previous.composites {
+		d.buildCompositeUnlocked(composite.keys)
+	}
+}
+
+// Vacuum removes the index entries, expirations, tags and insertion order entries referencing rows that no longer exist or no longer
+// hold the indexed value, along with the values and keys left empty, and returns the number of removed entries.
+// It is cheaper than ReindexAll when the rows themselves are sound.
+func (d *DataFrame) Vacuum() int {
+	d.Locker.Lock()
+	defer d.unlockAndNotify()
+
+	removed := vacuumIndex(d, d.Strings)
+	removed += vacuumIndex(d, d.Numerics)
+	removed += vacuumIndex(d, d.Booleans)
+	removed += vacuumIndex(d, d.Times)
+
+	for id := range d.ExpireAt {
+		if _, ok := d.Data[id]; !ok {
+			delete(d.ExpireAt, id)
+			removed++
+		}
+	}
+
+	for id := range d.tags.tags {
+		if _, ok := d.Data[id]; !ok {
+			d.untagRowUnlocked(id)
+			removed++
+		}
+	}
+
+	for id := range d.order.elements {
+		if _, ok := d.Data[id]; !ok {
+			d.order.remove(id)
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		d.rebuildExpiryUnlocked()
+	}
+
+	return removed
+}
+
+// vacuumIndex removes the stale entries of a typed index and returns their number.
+func vacuumIndex[T comparable](d *DataFrame, index map[KeyName]map[T]map[uuid.UUID]bool) int {
+	removed := 0
+	for key, values := range index {
+		for value, ids := range values {
+			for id := range ids {
+				if current, ok := d.Data[id][key].(T); ok && current == value {
+					continue
+				}
+				unindexValue(d, index, key, value, id)
+				removed++
+			}
+		}
+	}
+	return removed
+}
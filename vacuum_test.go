@@ -0,0 +1,85 @@
+package mframe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestRemoveElementCleansTimes(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"seen": time.Now().UTC(), "host": "a"})
+	cache.RemoveElement(id)
+
+	if len(cache.Times) != 0 {
+		t.Errorf("expected no time index entries, but got %v", cache.Times)
+	}
+	if _, ok := cache.Keys["seen"]; ok {
+		t.Error("expected the time key to be removed")
+	}
+}
+
+func TestVacuum(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	seen := time.Now().UTC()
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{
+		{"host": "a", "seen": seen},
+		{"host": "b", "seen": seen},
+	})
+
+	for id, row := range cache.Data {
+		if row["host"] == "a" {
+			delete(cache.Data, id)
+		}
+	}
+
+	// One entry for each of host, seen and the expiration.
+	if removed := cache.Vacuum(); removed != 3 {
+		t.Errorf("expected 3 removed entries, but got %d", removed)
+	}
+	if _, ok := cache.Strings["host"]["a"]; ok {
+		t.Error("expected the stale value to be removed")
+	}
+	if len(cache.Times["seen"][seen]) != 1 {
+		t.Errorf("expected the live time entry to be kept, but got %v", cache.Times["seen"])
+	}
+	if removed := cache.Vacuum(); removed != 0 {
+		t.Errorf("expected nothing left to remove, but got %d", removed)
+	}
+}
+
+func TestReindexAll(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	id, _ := cache.InsertReturningID(map[mframe.KeyName]interface{}{"host": "a", "port": 22})
+	cache.Data[id]["host"] = "b"
+	cache.Data[id]["user"] = "root"
+
+	cache.ReindexAll()
+
+	tests := []struct {
+		key      mframe.KeyName
+		value    interface{}
+		expected int
+	}{
+		{"host", "a", 0},
+		{"host", "b", 1},
+		{"user", "root", 1},
+		{"port", 22.0, 1},
+	}
+
+	for _, tt := range tests {
+		if count := cache.CountWhere(mframe.Equals, tt.key, tt.value, nil); count != tt.expected {
+			t.Errorf("expected %d rows with %s=%v, but got %d", tt.expected, tt.key, tt.value, count)
+		}
+	}
+	if _, ok := cache.ExpireAt[id]; !ok {
+		t.Error("expected the expiration to be kept")
+	}
+}
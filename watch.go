@@ -0,0 +1,182 @@
+package mframe
+
+import (
+	"fmt"
+	"time"
+)
+
+// WatchMetric selects the measure of the DataFrame observed by a Watch.
+type WatchMetric int
+
+const (
+	// WatchRows observes the number of rows.
+	WatchRows WatchMetric = 1
+	// WatchCardinality observes the number of unique values of a key.
+	WatchCardinality WatchMetric = 2
+	// WatchMemory observes the estimated memory used by the rows in bytes.
+	WatchMemory WatchMetric = 3
+)
+
+// memoryCheckInterval is the minimum time between two estimates of the memory used by the rows,
+// since each estimate samples the rows.
+const memoryCheckInterval = time.Second
+
+// String returns the name of the metric.
+func (m WatchMetric) String() string {
+	switch m {
+	case WatchRows:
+		return "rows"
+	case WatchCardinality:
+		return "cardinality"
+	case WatchMemory:
+		return "memory"
+	default:
+		return "unknown"
+	}
+}
+
+// Watch calls Callback when a metric of the DataFrame crosses Threshold, e.g. a sudden explosion of
+// unique user agents, so embedders can protect themselves by sampling, evicting or alerting.
+type Watch struct {
+	Metric WatchMetric
+	// Key is the key observed by WatchCardinality.
+	Key KeyName
+	// Threshold is the number of rows, unique values or bytes that fires the watch.
+	Threshold int
+	Callback  func(event WatchEvent)
+}
+
+// WatchEvent describes a crossing of the threshold of a Watch.
+type WatchEvent struct {
+	Metric    WatchMetric
+	Key       KeyName
+	Threshold int
+	Value     int
+	// Above is true when the metric rose above the threshold and false when it fell back to it or below.
+	Above bool
+	At    time.Time
+}
+
+// watcher is a registered Watch and whether its metric was above the threshold when last checked.
+type watcher struct {
+	id    int
+	watch Watch
+	above bool
+}
+
+// watchers holds the watches of a DataFrame, under the lock of the DataFrame.
+type watchers struct {
+	list      []*watcher
+	nextID    int
+	memory    int
+	memoryAt  time.Time
+	notices   []watchNotice
+	hasMemory bool
+}
+
+// watchNotice is an event waiting to be delivered once the lock is released.
+type watchNotice struct {
+	callback func(event WatchEvent)
+	event    WatchEvent
+}
+
+// AddWatch registers a watch and returns its ID, for RemoveWatch. Watches are checked after every insert
+// and removal, and their callbacks are called without holding the DataFrame lock, once when the metric
+// rises above the threshold and once when it falls back. A metric already above the threshold when the
+// watch is added fires on the next check. Memory is estimated at most once per second.
+// Returns an error if the callback is nil, the threshold is not positive or a cardinality watch has no key.
+func (d *DataFrame) AddWatch(watch Watch) (int, error) {
+	if watch.Callback == nil {
+		return 0, fmt.Errorf("watch callback cannot be nil")
+	}
+	if watch.Threshold <= 0 {
+		return 0, fmt.Errorf("watch threshold must be positive")
+	}
+	switch watch.Metric {
+	case WatchRows, WatchMemory:
+	case WatchCardinality:
+		if watch.Key == "" {
+			return 0, fmt.Errorf("cardinality watch requires a key")
+		}
+	default:
+		return 0, fmt.Errorf("unknown watch metric %d", watch.Metric)
+	}
+
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	d.watchers.nextID++
+	d.watchers.list = append(d.watchers.list, &watcher{id: d.watchers.nextID, watch: watch})
+	if watch.Metric == WatchMemory {
+		d.watchers.hasMemory = true
+	}
+
+	return d.watchers.nextID, nil
+}
+
+// RemoveWatch unregisters the watch with the specified ID and reports whether it existed.
+func (d *DataFrame) RemoveWatch(id int) bool {
+	d.Locker.Lock()
+	defer d.Locker.Unlock()
+
+	for i, w := range d.watchers.list {
+		if w.id != id {
+			continue
+		}
+		d.watchers.list = append(d.watchers.list[:i], d.watchers.list[i+1:]...)
+
+		d.watchers.hasMemory = false
+		for _, other := range d.watchers.list {
+			if other.watch.Metric == WatchMemory {
+				d.watchers.hasMemory = true
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// checkWatchesUnlocked compares the metrics with the thresholds of the watches and queues an event for
+// each crossing, without acquiring locks.
+func (d *DataFrame) checkWatchesUnlocked() {
+	if len(d.watchers.list) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	if d.watchers.hasMemory && now.Sub(d.watchers.memoryAt) >= memoryCheckInterval {
+		d.watchers.memory = d.averageRowSizeUnlocked() * len(d.Data)
+		d.watchers.memoryAt = now
+	}
+
+	for _, w := range d.watchers.list {
+		var value int
+		switch w.watch.Metric {
+		case WatchRows:
+			value = len(d.Data)
+		case WatchCardinality:
+			value = d.uniqueValuesUnlocked(w.watch.Key)
+		case WatchMemory:
+			value = d.watchers.memory
+		}
+
+		above := value > w.watch.Threshold
+		if above == w.above {
+			continue
+		}
+		w.above = above
+
+		d.watchers.notices = append(d.watchers.notices, watchNotice{
+			callback: w.watch.Callback,
+			event: WatchEvent{
+				Metric:    w.watch.Metric,
+				Key:       w.watch.Key,
+				Threshold: w.watch.Threshold,
+				Value:     value,
+				Above:     above,
+				At:        now,
+			},
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package mframe_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/threatwinds/mframe"
+)
+
+func TestAddWatch(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	events := make([]mframe.WatchEvent, 0)
+	record := func(event mframe.WatchEvent) {
+		if cache.Count() < 0 {
+			t.Error("expected the callback to be able to query the frame")
+		}
+		events = append(events, event)
+	}
+
+	rows, err := cache.AddWatch(mframe.Watch{Metric: mframe.WatchRows, Threshold: 3, Callback: record})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cache.AddWatch(mframe.Watch{Metric: mframe.WatchCardinality, Key: "agent", Threshold: 2, Callback: record}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"agent": fmt.Sprintf("agent-%d", i%3)})
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, but got %v", events)
+	}
+	if events[0].Metric != mframe.WatchCardinality || events[0].Value != 3 || !events[0].Above {
+		t.Errorf("expected the cardinality to rise above 2, but got %+v", events[0])
+	}
+	if events[1].Metric != mframe.WatchRows || events[1].Value != 4 || !events[1].Above {
+		t.Errorf("expected the row count to rise above 3, but got %+v", events[1])
+	}
+
+	cache.DeleteWhere(mframe.Equals, "agent", "agent-0", nil)
+	if len(events) != 4 || events[2].Metric != mframe.WatchRows || events[2].Above || events[3].Above {
+		t.Errorf("expected the row count and the cardinality to fall back, but got %v", events)
+	}
+
+	if !cache.RemoveWatch(rows) || cache.RemoveWatch(rows) {
+		t.Error("expected the watch to be removed once")
+	}
+	cache.InsertBatch([]map[mframe.KeyName]interface{}{{"agent": "agent-1"}, {"agent": "agent-1"}})
+	if len(events) != 4 {
+		t.Errorf("expected no event from a removed watch, but got %v", events)
+	}
+}
+
+func TestAddWatchValidation(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+	callback := func(mframe.WatchEvent) {}
+
+	tests := []struct {
+		name  string
+		watch mframe.Watch
+	}{
+		{"no callback", mframe.Watch{Metric: mframe.WatchRows, Threshold: 1}},
+		{"no threshold", mframe.Watch{Metric: mframe.WatchRows, Callback: callback}},
+		{"no key", mframe.Watch{Metric: mframe.WatchCardinality, Threshold: 1, Callback: callback}},
+		{"unknown metric", mframe.Watch{Metric: 9, Threshold: 1, Callback: callback}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cache.AddWatch(tt.watch); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestWatchAfterUpdates(t *testing.T) {
+	var cache mframe.DataFrame
+	cache.Init(24 * time.Hour)
+
+	var events []mframe.WatchEvent
+	if _, err := cache.AddWatch(mframe.Watch{Metric: mframe.WatchCardinality, Key: "agent", Threshold: 1, Callback: func(event mframe.WatchEvent) {
+		events = append(events, event)
+	}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		cache.Insert(map[mframe.KeyName]interface{}{"agent": "agent-0", "n": i})
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no event, but got %v", events)
+	}
+
+	updated, err := cache.UpdateWhere(mframe.FilterSpec{Conditions: []mframe.Condition{
+		{Operator: mframe.Equals, Key: "n", Value: 2.0},
+	}}, map[mframe.KeyName]interface{}{"agent": "agent-1"})
+	if err != nil || updated != 1 {
+		t.Fatalf("expected 1 updated row, but got %d (%v)", updated, err)
+	}
+	if len(events) != 1 || events[0].Value != 2 || !events[0].Above {
+		t.Fatalf("expected the cardinality to rise above 1 after UpdateWhere, but got %v", events)
+	}
+
+	cache.MapRows(func(row mframe.Row) mframe.Row {
+		row["agent"] = "agent-0"
+		return row
+	})
+	if len(events) != 2 || events[1].Above {
+		t.Errorf("expected the cardinality to fall back after MapRows, but got %v", events)
+	}
+}